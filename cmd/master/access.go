@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	orchestrator "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	"devops_console/internal/infrastructure/orchestrator/server"
+	"encoding/hex"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+)
+
+// accessConfig es el fichero de ACCESS_CONFIG: los tokens del API HTTP, con el sujeto al
+// que identifica cada uno, y los roles que se conceden a usuarios, grupos y service
+// accounts. Los tokens se guardan como SHA-256 en hexadecimal (p.ej. sha256sum).
+//
+//	tokens:
+//	  - sha256: 9f86d0...
+//	    subject: {kind: user, id: alice, groups: [sre]}
+//	    tenant: acme
+//	bindings:
+//	  - subject: {kind: group, id: sre}
+//	    role: operator
+//	    tenant: acme
+//	    workspace: payments
+type accessConfig struct {
+	Tokens   []tokenConfig   `yaml:"tokens"`
+	Bindings []bindingConfig `yaml:"bindings"`
+}
+
+// tokenConfig limita las peticiones del token al tenant y, si se indica, al workspace.
+// Sin tenant no hay ámbito; el audit log solo se puede leer con uno.
+type tokenConfig struct {
+	SHA256    string        `yaml:"sha256"`
+	Subject   subjectConfig `yaml:"subject"`
+	Tenant    string        `yaml:"tenant"`
+	Workspace string        `yaml:"workspace"`
+}
+
+type bindingConfig struct {
+	Subject   subjectConfig `yaml:"subject"`
+	Role      string        `yaml:"role"`
+	Tenant    string        `yaml:"tenant"`
+	Workspace string        `yaml:"workspace"`
+}
+
+// subjectConfig es un usuario (kind user, por defecto), un grupo o un service account.
+type subjectConfig struct {
+	Kind   string   `yaml:"kind"`
+	ID     string   `yaml:"id"`
+	Name   string   `yaml:"name"`
+	Groups []string `yaml:"groups"`
+}
+
+func (c subjectConfig) subject() (entities.Subject, error) {
+	if c.ID == "" {
+		return nil, fmt.Errorf("subject without id")
+	}
+	switch c.Kind {
+	case "", "user":
+		return entities.User{ID: c.ID, Name: c.Name, Groups: c.Groups}, nil
+	case "group":
+		return entities.Group{ID: c.ID, Name: c.Name}, nil
+	case "serviceAccount":
+		return entities.ServiceAccount{ID: c.ID, Name: c.Name}, nil
+	default:
+		return nil, fmt.Errorf("unknown subject kind %q: use user, group or serviceAccount", c.Kind)
+	}
+}
+
+// loadAccess lee la configuración de acceso de path y devuelve el authorizer con sus
+// bindings y el authenticator de sus tokens.
+func loadAccess(path string) (*orchestrator.RBACAuthorizer, *server.TokenAuthenticator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var config accessConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	identities := make(map[string]server.TokenIdentity, len(config.Tokens))
+	for i, token := range config.Tokens {
+		if digest, err := hex.DecodeString(token.SHA256); err != nil || len(digest) != 32 {
+			return nil, nil, fmt.Errorf("%s: token %d: sha256 must be 64 hexadecimal characters", path, i+1)
+		}
+		subject, err := token.Subject.subject()
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: token %d: %w", path, i+1, err)
+		}
+		if _, ok := subject.(entities.Group); ok {
+			return nil, nil, fmt.Errorf("%s: token %d: a token identifies a user or a service account, not a group", path, i+1)
+		}
+		if token.Workspace != "" && token.Tenant == "" {
+			return nil, nil, fmt.Errorf("%s: token %d: workspace without tenant", path, i+1)
+		}
+		if _, ok := identities[token.SHA256]; ok {
+			return nil, nil, fmt.Errorf("%s: token %d: duplicated token", path, i+1)
+		}
+		identities[token.SHA256] = server.TokenIdentity{
+			Subject: subject,
+			Scope:   entities.Scope{TenantID: token.Tenant, WorkspaceID: token.Workspace},
+		}
+	}
+
+	authorizer := orchestrator.NewRBACAuthorizer(nil)
+	for i, binding := range config.Bindings {
+		subject, err := binding.Subject.subject()
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: binding %d: %w", path, i+1, err)
+		}
+		err = authorizer.Bind(entities.RoleBinding{
+			Subject:     subject,
+			Role:        entities.Role(binding.Role),
+			TenantID:    binding.Tenant,
+			WorkspaceID: binding.Workspace,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: binding %d: %w", path, i+1, err)
+		}
+	}
+	return authorizer, server.NewTokenAuthenticator(identities), nil
+}
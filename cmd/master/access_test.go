@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	orchestrator "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	repositories "devops_console/internal/infrastructure/orchestrator/repositories"
+	"devops_console/internal/infrastructure/orchestrator/server"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Los tokens son "admin-token" y "viewer-token"; en el fichero va su SHA-256.
+const accessFile = `tokens:
+  - sha256: %s
+    subject: {kind: user, id: alice, groups: [platform]}
+    tenant: acme
+  - sha256: %s
+    subject: {kind: serviceAccount, id: ci}
+bindings:
+  - subject: {kind: group, id: platform}
+    role: admin
+    tenant: acme
+  - subject: {kind: serviceAccount, id: ci}
+    role: viewer
+    tenant: acme
+`
+
+func TestApplyAndExport_RequireATokenWithAccessConfig(t *testing.T) {
+	authorizer, authenticator, err := loadAccessFrom(t, fmt.Sprintf(accessFile, tokenDigest("admin-token"), tokenDigest("viewer-token")))
+	require.NoError(t, err)
+
+	taskRepo := repositories.NewInMemoryTaskRepository()
+	tasks := orchestrator.NewTaskServiceImpl(taskRepo)
+	defer tasks.Shutdown()
+	tasks.SetAuthorizer(authorizer)
+	api := httptest.NewServer(newAPIHandler(apiServices{
+		manifests:     newManifestService(taskRepo, tasks),
+		authenticator: authenticator,
+	}))
+	defer api.Close()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "tasks.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(manifest), 0o644))
+
+	// Sin token, o con uno desconocido, el API responde 401.
+	t.Setenv("MASTER_TOKEN", "")
+	assert.ErrorContains(t, runApply([]string{"-f", file, "--server", api.URL}), "401")
+	assert.ErrorContains(t, runApply([]string{"-f", file, "--server", api.URL, "--token", "guess"}), "401")
+
+	// El viewer se autentica pero no puede crear tareas.
+	err = runApply([]string{"-f", file, "--server", api.URL, "--token", "viewer-token"})
+	assert.ErrorContains(t, err, "permission denied")
+	_, err = taskRepo.GetByID(context.Background(), "nightly-backup")
+	assert.Error(t, err)
+
+	// El admin (por su grupo) sí, y el token se puede dar en MASTER_TOKEN.
+	t.Setenv("MASTER_TOKEN", "admin-token")
+	require.NoError(t, runApply([]string{"-f", file, "--server", api.URL}))
+	_, err = taskRepo.GetByID(context.Background(), "nightly-backup")
+	require.NoError(t, err)
+
+	exported := filepath.Join(dir, "exported.yaml")
+	require.NoError(t, runExport([]string{"-o", exported, "--server", api.URL, "--token", "viewer-token"}))
+	data, err := os.ReadFile(exported)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "id: nightly-backup")
+}
+
+func TestExport_LimitsTokensToTheirTenant(t *testing.T) {
+	// bob tiene rol en acme, pero su token solo vale para globex.
+	config := fmt.Sprintf(accessFile, tokenDigest("admin-token"), tokenDigest("viewer-token")) +
+		"  - subject: {id: bob}\n    role: viewer\n    tenant: globex\n" +
+		"  - subject: {id: bob}\n    role: admin\n    tenant: acme\n"
+	config = strings.Replace(config, "bindings:", "  - sha256: "+tokenDigest("globex-token")+"\n    subject: {id: bob}\n    tenant: globex\nbindings:", 1)
+	authorizer, authenticator, err := loadAccessFrom(t, config)
+	require.NoError(t, err)
+
+	taskRepo := repositories.NewInMemoryTaskRepository()
+	tasks := orchestrator.NewTaskServiceImpl(taskRepo)
+	defer tasks.Shutdown()
+	tasks.SetAuthorizer(authorizer)
+	api := httptest.NewServer(newAPIHandler(apiServices{
+		manifests:     newManifestService(taskRepo, tasks),
+		authenticator: authenticator,
+	}))
+	defer api.Close()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "tasks.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(manifest), 0o644))
+	require.NoError(t, runApply([]string{"-f", file, "--server", api.URL, "--token", "admin-token"}))
+
+	exported := filepath.Join(dir, "exported.yaml")
+	require.NoError(t, runExport([]string{"-o", exported, "--server", api.URL, "--token", "globex-token"}))
+	data, err := os.ReadFile(exported)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "nightly-backup")
+
+	assert.Error(t, runApply([]string{"-f", file, "--server", api.URL, "--token", "globex-token"}))
+}
+
+func TestLoadAccess_RejectsInvalidConfigs(t *testing.T) {
+	for name, content := range map[string]string{
+		"plain token":       "tokens:\n  - sha256: admin-token\n    subject: {id: alice}\n",
+		"group token":       "tokens:\n  - sha256: " + tokenDigest("t") + "\n    subject: {kind: group, id: sre}\n",
+		"unknown role":      "bindings:\n  - subject: {id: alice}\n    role: owner\n    tenant: acme\n",
+		"binding no tenant": "bindings:\n  - subject: {id: alice}\n    role: viewer\n",
+		"unknown field":     "tokens:\n  - token: admin-token\n",
+		"scope no tenant":   "tokens:\n  - sha256: " + tokenDigest("t") + "\n    subject: {id: alice}\n    workspace: payments\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := loadAccessFrom(t, content)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAPIHandler_LeavesWebhooksUnauthenticated(t *testing.T) {
+	_, authenticator, err := loadAccessFrom(t, fmt.Sprintf(accessFile, tokenDigest("admin-token"), tokenDigest("viewer-token")))
+	require.NoError(t, err)
+	taskRepo := repositories.NewInMemoryTaskRepository()
+	tasks := orchestrator.NewTaskServiceImpl(taskRepo)
+	defer tasks.Shutdown()
+	handler := newAPIHandler(apiServices{
+		manifests:     newManifestService(taskRepo, tasks),
+		webhooks:      orchestrator.NewWebhookServiceImpl(taskRepo, repositories.NewInMemoryWebhookDeliveryRepository(), tasks),
+		pipelines:     orchestrator.NewPipelineServiceImpl(repositories.NewInMemoryPipelineRepository(), tasks),
+		authenticator: authenticator,
+	})
+
+	for _, path := range []string{"/manifests/export", "/pipelines", "/audit", "/executions/e1/artifacts"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, path)
+	}
+	// Un token desconocido para el webhook no es un 401: lo decide la firma de la entrega.
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhooks/unknown", nil))
+	assert.NotEqual(t, http.StatusUnauthorized, rec.Code)
+}
+
+func loadAccessFrom(t *testing.T, content string) (*orchestrator.RBACAuthorizer, *server.TokenAuthenticator, error) {
+	config := filepath.Join(t.TempDir(), "access.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(content), 0o600))
+	return loadAccess(config)
+}
+
+func tokenDigest(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
+
+func TestAuditLog_RecordsAppliedTasksForTheTenant(t *testing.T) {
+	authorizer, authenticator, err := loadAccessFrom(t, fmt.Sprintf(accessFile, tokenDigest("admin-token"), tokenDigest("viewer-token")))
+	require.NoError(t, err)
+
+	taskRepo := repositories.NewInMemoryTaskRepository()
+	tasks := orchestrator.NewTaskServiceImpl(taskRepo)
+	defer tasks.Shutdown()
+	tasks.SetAuthorizer(authorizer)
+	audit := orchestrator.NewAuditServiceImpl(repositories.NewInMemoryAuditRepository())
+	tasks.SetAuditLog(audit)
+	api := httptest.NewServer(newAPIHandler(apiServices{
+		manifests:     newManifestService(taskRepo, tasks),
+		audit:         audit,
+		authenticator: authenticator,
+	}))
+	defer api.Close()
+
+	file := filepath.Join(t.TempDir(), "tasks.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(manifest), 0o644))
+	require.NoError(t, runApply([]string{"-f", file, "--server", api.URL, "--token", "admin-token"}))
+
+	resp, err := http.Get(api.URL + "/audit")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, err = request(http.MethodGet, api.URL+"/audit?task=nightly-backup", "admin-token", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var entry entities.AuditEntry
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&entry))
+	assert.Equal(t, entities.AuditCreate, entry.Action)
+	assert.Equal(t, "alice", entry.SubjectID)
+	assert.Equal(t, entities.AuditSucceeded, entry.Outcome)
+
+	resp, err = request(http.MethodGet, api.URL+"/audit/verify", "viewer-token", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
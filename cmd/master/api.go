@@ -0,0 +1,67 @@
+package main
+
+import (
+	orchestrator "devops_console/internal/application/orchestrator"
+	"devops_console/internal/infrastructure/orchestrator/server"
+	workers "devops_console/internal/infrastructure/orchestrator/workers"
+	ports "devops_console/internal/ports/orchestrator"
+	"log"
+	"net/http"
+	"os"
+)
+
+// apiServices son los servicios que expone el API HTTP del master.
+type apiServices struct {
+	manifests orchestrator.ManifestService
+	webhooks  orchestrator.WebhookService
+	pipelines orchestrator.PipelineService
+	audit     orchestrator.AuditService
+	artifacts orchestrator.ArtifactService
+
+	// authenticator, si no es nil, exige un token en todas las rutas salvo los webhooks,
+	// que se autentican con su firma.
+	authenticator *server.TokenAuthenticator
+}
+
+// newManifestService crea el servicio de manifiestos con los tipos de worker del master.
+func newManifestService(taskRepo ports.TaskRepository, tasks *orchestrator.TaskServiceImpl) *orchestrator.ManifestServiceImpl {
+	manifests := orchestrator.NewManifestServiceImpl(taskRepo, tasks)
+	manifests.RegisterWorkerType("Docker", workers.NewDockerWorkerFromManifest)
+	manifests.RegisterWorkerType("Kubernetes", workers.NewKubernetesWorkerFromManifest)
+	return manifests
+}
+
+// newAPIHandler monta los handlers del API HTTP del master en un único mux.
+func newAPIHandler(services apiServices) http.Handler {
+	authenticated := func(handler http.Handler) http.Handler {
+		if services.authenticator == nil {
+			return handler
+		}
+		return services.authenticator.Authenticate(handler)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/manifests/", authenticated(server.NewManifestHandler(services.manifests)))
+	mux.Handle("/webhooks/", server.NewWebhookHandler(services.webhooks))
+	pipelines := authenticated(server.NewPipelineHandler(services.pipelines))
+	mux.Handle("/pipelines", pipelines)
+	mux.Handle("/pipelines/", pipelines)
+	mux.Handle("/pipeline-executions/", pipelines)
+	audit := authenticated(server.NewAuditHandler(services.audit))
+	mux.Handle("/audit", audit)
+	mux.Handle("/audit/", audit)
+	mux.Handle("/executions/", authenticated(server.NewArtifactHandler(services.artifacts)))
+	return mux
+}
+
+// serveAPI sirve el API HTTP en API_ADDR (por defecto :8080), la dirección que usan por
+// defecto los subcomandos apply y export.
+func serveAPI(handler http.Handler) {
+	addr := os.Getenv("API_ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+	log.Printf("Serving HTTP API on %s", addr)
+	if err := http.ListenAndServe(addr, handler); err != nil {
+		log.Fatalf("failed to serve HTTP API: %v", err)
+	}
+}
@@ -1,22 +1,244 @@
 package main
 
 import (
+	"context"
+	orchestrator "devops_console/internal/application/orchestrator"
+	pb "devops_console/internal/infrastructure/agent/proto/agent/v1"
+	artifacts "devops_console/internal/infrastructure/orchestrator/artifacts"
+	eventstream "devops_console/internal/infrastructure/orchestrator/events"
+	executors "devops_console/internal/infrastructure/orchestrator/executors"
+	metrics "devops_console/internal/infrastructure/orchestrator/metrics"
+	repositories "devops_console/internal/infrastructure/orchestrator/repositories"
+	secrets "devops_console/internal/infrastructure/orchestrator/secrets"
 	"devops_console/internal/infrastructure/orchestrator/server"
+	filesync "devops_console/internal/infrastructure/orchestrator/sync"
+	ports "devops_console/internal/ports/orchestrator"
+	"encoding/hex"
+	"google.golang.org/grpc"
 	"log"
 	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
 )
 
 func main() {
+	// Subcomandos cliente: gestionan las tareas del master como manifiestos.
+	if len(os.Args) > 1 {
+		var err error
+		switch os.Args[1] {
+		case "apply":
+			err = runApply(os.Args[2:])
+		case "export":
+			err = runExport(os.Args[2:])
+		default:
+			log.Fatalf("unknown command %q; usage: master [apply|export]", os.Args[1])
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Crear el listener TCP
 	lis, err := net.Listen("tcp", ":50051")
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	// Crear e iniciar el servidor gRPC
-	grpcServer := server.NewGRPCServer()
+	// Servicios del orquestador
+	stream := eventstream.NewTaskEventStream()
+	taskRepo := repositories.NewInMemoryTaskRepository()
+	tasks := orchestrator.NewTaskServiceImpl(taskRepo)
+	tasks.EventStream = stream
+
+	// Registro de auditoría de las operaciones sobre tareas y ejecuciones
+	audit := orchestrator.NewAuditServiceImpl(repositories.NewInMemoryAuditRepository())
+	tasks.SetAuditLog(audit)
+
+	// Control de acceso (ACCESS_CONFIG): tokens del API HTTP y roles de cada sujeto
+	var authenticator *server.TokenAuthenticator
+	if path := os.Getenv("ACCESS_CONFIG"); path != "" {
+		authorizer, tokens, err := loadAccess(path)
+		if err != nil {
+			log.Fatalf("failed to load the access config: %v", err)
+		}
+		tasks.SetAuthorizer(authorizer)
+		authenticator = tokens
+	} else {
+		log.Printf("ACCESS_CONFIG not set: the HTTP API does not require authentication")
+	}
+
+	// Límites de ejecuciones simultáneas (MAX_RUNNING_PER_TASK, MAX_RUNNING_PER_WORKSPACE,
+	// MAX_RUNNING_PER_TENANT); sin ninguno no hay cola.
+	limits := orchestrator.ConcurrencyLimits{
+		PerTask:      envInt("MAX_RUNNING_PER_TASK"),
+		PerWorkspace: envInt("MAX_RUNNING_PER_WORKSPACE"),
+		PerTenant:    envInt("MAX_RUNNING_PER_TENANT"),
+	}
+	if limits.PerTask > 0 || limits.PerWorkspace > 0 || limits.PerTenant > 0 {
+		tasks.SetConcurrencyLimits(limits)
+	}
+
+	// Métricas en formato Prometheus en GET /metrics (METRICS_ADDR, por defecto :9090)
+	registry := metrics.NewRegistry()
+	orchestratorMetrics := metrics.NewOrchestratorMetrics(registry)
+	orchestratorMetrics.ObserveEventStream(stream)
+	tasks.Metrics = orchestratorMetrics
+	go serveMetrics(registry)
+
+	// Artefactos de las ejecuciones, guardados en ARTIFACTS_DIR
+	artifactStore := newArtifactStore()
+	artifactService := orchestrator.NewArtifactServiceImpl(artifactStore, tasks)
+	artifactService.Start()
+	defer artifactService.Stop()
+
+	secretStore := newSecretStore()
+	if docker, err := executors.NewDockerTaskExecutor(stream); err != nil {
+		log.Printf("Docker executor not available: %v", err)
+	} else {
+		docker.Metrics = orchestratorMetrics
+		docker.SecretStore = secretStore
+		if files, err := filesync.NewDockerFileSync(); err != nil {
+			log.Printf("Docker artifacts not available: %v", err)
+		} else {
+			docker.Artifacts = &executors.ArtifactSync{Files: files, Store: artifactStore}
+		}
+		tasks.RegisterExecutor("Docker", docker)
+	}
+	namespace := os.Getenv("K8S_NAMESPACE")
+	if namespace == "" {
+		namespace = "default"
+	}
+	if k8s, err := executors.NewK8sTaskExecutor(namespace, stream); err != nil {
+		log.Printf("Kubernetes executor not available: %v", err)
+	} else {
+		k8s.Metrics = orchestratorMetrics
+		k8s.SecretStore = secretStore
+		tasks.RegisterExecutor("Kubernetes", k8s)
+	}
+
+	// Planificar las tareas programadas guardadas y las que se creen o modifiquen
+	scheduler := orchestrator.NewSchedulerServiceImpl(taskRepo, tasks)
+	tasks.SetScheduler(scheduler)
+	if err := scheduler.ScheduleAll(context.Background()); err != nil {
+		log.Fatalf("failed to schedule tasks: %v", err)
+	}
+
+	// Lanzar las tareas con TaskCompletionTrigger al terminar la ejecución que esperan
+	chains := orchestrator.NewTaskChainServiceImpl(taskRepo, tasks, stream)
+	if err := chains.Start(); err != nil {
+		log.Fatalf("failed to start task chaining: %v", err)
+	}
+	defer chains.Stop()
+
+	// Notificaciones de los fallos, recuperaciones y aprobaciones (NOTIFICATIONS_CONFIG)
+	if path := os.Getenv("NOTIFICATIONS_CONFIG"); path != "" {
+		notifier := orchestrator.NewNotificationServiceImpl(taskRepo, tasks, stream)
+		if err := loadNotifications(path, notifier, secretStore); err != nil {
+			log.Fatalf("failed to load the notifications config: %v", err)
+		}
+		if err := notifier.Start(); err != nil {
+			log.Fatalf("failed to start notifications: %v", err)
+		}
+		defer notifier.Stop()
+	}
+
+	// API HTTP del master
+	go serveAPI(newAPIHandler(apiServices{
+		authenticator: authenticator,
+		manifests:     newManifestService(taskRepo, tasks),
+		webhooks:      orchestrator.NewWebhookServiceImpl(taskRepo, repositories.NewInMemoryWebhookDeliveryRepository(), tasks),
+		pipelines:     orchestrator.NewPipelineServiceImpl(repositories.NewInMemoryPipelineRepository(), tasks),
+	}))
+
+	// Crear e iniciar el servidor gRPC de los agentes
+	agentServer := server.NewAgentServer(stream)
+	agentServer.Metrics = orchestratorMetrics
+	agentServer.SecretStore = secretStore
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(server.SourceUnaryInterceptor),
+		grpc.ChainStreamInterceptor(server.SourceStreamInterceptor),
+	)
+	pb.RegisterAgentServiceServer(grpcServer, agentServer)
 	log.Printf("Starting gRPC server on %s", lis.Addr().String())
 	if err := grpcServer.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
 }
+
+// newSecretStore crea el almacén de secretos de SECRET_STORE: "env" (por defecto), que los
+// lee de las variables SECRET_*, o "file", cifrado en SECRET_STORE_PATH con la clave en
+// hexadecimal de SECRET_STORE_KEY.
+func newSecretStore() ports.SecretStore {
+	switch kind := os.Getenv("SECRET_STORE"); kind {
+	case "", "env":
+		return secrets.NewEnvSecretStore("SECRET_")
+	case "file":
+		key, err := hex.DecodeString(os.Getenv("SECRET_STORE_KEY"))
+		if err != nil {
+			log.Fatalf("invalid SECRET_STORE_KEY: %v", err)
+		}
+		store, err := secrets.NewFileSecretStore(os.Getenv("SECRET_STORE_PATH"), key)
+		if err != nil {
+			log.Fatalf("failed to open the secret store: %v", err)
+		}
+		return store
+	default:
+		log.Fatalf("unknown SECRET_STORE %q: use env or file", kind)
+		return nil
+	}
+}
+
+// newArtifactStore abre el almacén de artefactos en ARTIFACTS_DIR (por defecto
+// ./artifacts) con los límites de ARTIFACT_MAX_SIZE y ARTIFACT_MAX_TOTAL_SIZE, en bytes, y
+// la retención de ARTIFACT_RETENTION (p.ej. 720h). Sin ellos no hay límite.
+func newArtifactStore() *artifacts.LocalArtifactStore {
+	root := os.Getenv("ARTIFACTS_DIR")
+	if root == "" {
+		root = "artifacts"
+	}
+	limits := artifacts.ArtifactLimits{
+		MaxArtifactSize: int64(envInt("ARTIFACT_MAX_SIZE")),
+		MaxTotalSize:    int64(envInt("ARTIFACT_MAX_TOTAL_SIZE")),
+	}
+	if value := os.Getenv("ARTIFACT_RETENTION"); value != "" {
+		retention, err := time.ParseDuration(value)
+		if err != nil || retention < 0 {
+			log.Fatalf("invalid ARTIFACT_RETENTION %q: must be a non-negative duration", value)
+		}
+		limits.Retention = retention
+	}
+	store, err := artifacts.NewLocalArtifactStore(root, limits)
+	if err != nil {
+		log.Fatalf("failed to open the artifact store: %v", err)
+	}
+	return store
+}
+
+// envInt lee un entero no negativo de la variable de entorno; 0 si no está definida.
+func envInt(name string) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Fatalf("invalid %s %q: must be a non-negative integer", name, value)
+	}
+	return n
+}
+
+func serveMetrics(registry *metrics.Registry) {
+	addr := os.Getenv("METRICS_ADDR")
+	if addr == "" {
+		addr = ":9090"
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", registry)
+	log.Printf("Serving metrics on %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Printf("Error serving metrics: %v", err)
+	}
+}
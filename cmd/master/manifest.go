@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	orchestrator "devops_console/internal/application/orchestrator"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+)
+
+// defaultServerURL es la dirección del API HTTP del master que usan apply y export.
+const defaultServerURL = "http://localhost:8080"
+
+// runApply implementa "master apply -f <manifiesto> [--prune] [--dry-run] [--server <url>] [--token <token>]".
+func runApply(args []string) error {
+	flags := flag.NewFlagSet("apply", flag.ExitOnError)
+	file := flags.String("f", "", "manifest file (YAML or JSON); - reads from stdin")
+	prune := flags.Bool("prune", false, "delete tasks of the manifest workspaces that are not in the manifest")
+	dryRun := flags.Bool("dry-run", false, "show the changes without applying them")
+	server := flags.String("server", defaultServerURL, "master HTTP address")
+	token := flags.String("token", os.Getenv("MASTER_TOKEN"), "API token; $MASTER_TOKEN by default")
+	flags.Parse(args)
+	if *file == "" {
+		return fmt.Errorf("apply: -f is required")
+	}
+
+	var data []byte
+	var err error
+	if *file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*file)
+	}
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("prune", strconv.FormatBool(*prune))
+	query.Set("dryRun", strconv.FormatBool(*dryRun))
+	resp, err := request(http.MethodPost, *server+"/manifests/apply?"+query.Encode(), *token, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("apply: %s: use --token or MASTER_TOKEN", resp.Status)
+	}
+	var result struct {
+		Changes []orchestrator.ManifestChange `json:"changes"`
+		Error   string                        `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("apply: unexpected response (%s): %w", resp.Status, err)
+	}
+	printChanges(os.Stdout, result.Changes, *dryRun)
+	if result.Error != "" {
+		return fmt.Errorf("apply: %s", result.Error)
+	}
+	return nil
+}
+
+// request hace la petición al API del master con el token, si lo hay. Un cuerpo se envía
+// como manifiesto YAML, que el master también acepta en JSON.
+func request(method, url, token string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/yaml")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func printChanges(w io.Writer, changes []orchestrator.ManifestChange, dryRun bool) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No changes.")
+		return
+	}
+	symbols := map[orchestrator.ManifestAction]string{
+		orchestrator.ManifestCreate: "+",
+		orchestrator.ManifestUpdate: "~",
+		orchestrator.ManifestDelete: "-",
+	}
+	for _, change := range changes {
+		fmt.Fprintf(w, "%s %s\n", symbols[change.Action], change.TaskID)
+		for _, field := range change.Changes {
+			fmt.Fprintf(w, "    %s: %v -> %v\n", field.Field, field.From, field.To)
+		}
+	}
+	if dryRun {
+		fmt.Fprintf(w, "%d change(s) pending (dry run).\n", len(changes))
+	} else {
+		fmt.Fprintf(w, "%d change(s) applied.\n", len(changes))
+	}
+}
+
+// runExport implementa "master export [--workspace <id>] [--format yaml|json] [-o <fichero>] [--server <url>] [--token <token>]".
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	workspace := flags.String("workspace", "", "only export the tasks of this workspace")
+	format := flags.String("format", "yaml", "output format: yaml or json")
+	output := flags.String("o", "", "output file; stdout by default")
+	server := flags.String("server", defaultServerURL, "master HTTP address")
+	token := flags.String("token", os.Getenv("MASTER_TOKEN"), "API token; $MASTER_TOKEN by default")
+	flags.Parse(args)
+
+	query := url.Values{}
+	query.Set("format", *format)
+	if *workspace != "" {
+		query.Set("workspace", *workspace)
+	}
+	resp, err := request(http.MethodGet, *server+"/manifests/export?"+query.Encode(), *token, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("export: %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*output, data, 0o644)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	orchestrator "devops_console/internal/application/orchestrator"
+	repositories "devops_console/internal/infrastructure/orchestrator/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const manifest = `apiVersion: devops-console/v1
+kind: TaskManifest
+workspaces:
+  - id: payments
+    name: Payments
+    tenantId: acme
+tasks:
+  - id: nightly-backup
+    name: backup
+    type: MANUAL
+    workspace: payments
+    worker:
+      type: Docker
+      image: postgres:16
+      command: [pg_dump]
+`
+
+func TestApplyAndExport_AgainstTheAPIServer(t *testing.T) {
+	taskRepo := repositories.NewInMemoryTaskRepository()
+	tasks := orchestrator.NewTaskServiceImpl(taskRepo)
+	defer tasks.Shutdown()
+	api := httptest.NewServer(newAPIHandler(apiServices{
+		manifests: newManifestService(taskRepo, tasks),
+	}))
+	defer api.Close()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "tasks.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(manifest), 0o644))
+
+	require.NoError(t, runApply([]string{"-f", file, "--dry-run", "--server", api.URL}))
+	_, err := taskRepo.GetByID(context.Background(), "nightly-backup")
+	assert.Error(t, err)
+
+	require.NoError(t, runApply([]string{"-f", file, "--server", api.URL}))
+	task, err := taskRepo.GetByID(context.Background(), "nightly-backup")
+	require.NoError(t, err)
+	assert.Equal(t, "payments", task.Workspace.ID)
+
+	exported := filepath.Join(dir, "exported.yaml")
+	require.NoError(t, runExport([]string{"--workspace", "payments", "-o", exported, "--server", api.URL}))
+	data, err := os.ReadFile(exported)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "id: nightly-backup")
+	assert.Contains(t, string(data), "image: postgres:16")
+}
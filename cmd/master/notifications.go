@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	orchestrator "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	notifications "devops_console/internal/infrastructure/orchestrator/notifications"
+	ports "devops_console/internal/ports/orchestrator"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"net"
+	"net/smtp"
+	"os"
+)
+
+// notificationsConfig es el fichero de NOTIFICATIONS_CONFIG: los canales, con su nombre, y
+// las reglas que envían por ellos los sucesos de las tareas. Las URLs con token y las
+// contraseñas se pueden tomar del almacén de secretos con urlSecret y passwordSecret.
+//
+//	channels:
+//	  - name: ops
+//	    type: slack
+//	    urlSecret: SLACK_OPS_WEBHOOK
+//	  - name: oncall
+//	    type: email
+//	    addr: smtp.example.com:587
+//	    from: devops@example.com
+//	    to: [oncall@example.com]
+//	rules:
+//	  - workspace: payments
+//	    on: [FAILURE, RECOVERY]
+//	    channels: [ops, oncall]
+type notificationsConfig struct {
+	Channels []channelConfig `yaml:"channels"`
+	Rules    []ruleConfig    `yaml:"rules"`
+}
+
+// channelConfig es un canal slack (url), webhook (url, body y headers) o email (addr,
+// from, to y, si el servidor lo pide, username y passwordSecret).
+type channelConfig struct {
+	Name           string            `yaml:"name"`
+	Type           string            `yaml:"type"`
+	URL            string            `yaml:"url"`
+	URLSecret      string            `yaml:"urlSecret"`
+	Body           string            `yaml:"body"`
+	Headers        map[string]string `yaml:"headers"`
+	Addr           string            `yaml:"addr"`
+	From           string            `yaml:"from"`
+	To             []string          `yaml:"to"`
+	Username       string            `yaml:"username"`
+	PasswordSecret string            `yaml:"passwordSecret"`
+}
+
+type ruleConfig struct {
+	Task      string                         `yaml:"task"`
+	Workspace string                         `yaml:"workspace"`
+	On        []entities.NotificationTrigger `yaml:"on"`
+	Channels  []string                       `yaml:"channels"`
+}
+
+// loadNotifications registra en notifier los canales y las reglas del fichero path.
+func loadNotifications(path string, notifier *orchestrator.NotificationServiceImpl, secrets ports.SecretStore) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var config notificationsConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	registered := make(map[string]bool, len(config.Channels))
+	for _, channel := range config.Channels {
+		if channel.Name == "" || registered[channel.Name] {
+			return fmt.Errorf("%s: channel names must be unique and not empty", path)
+		}
+		sender, err := newNotificationChannel(channel, secrets)
+		if err != nil {
+			return fmt.Errorf("%s: channel %q: %w", path, channel.Name, err)
+		}
+		notifier.RegisterChannel(channel.Name, sender)
+		registered[channel.Name] = true
+	}
+	for i, rule := range config.Rules {
+		_, err := notifier.AddRule(entities.NotificationRule{
+			TaskID:      rule.Task,
+			WorkspaceID: rule.Workspace,
+			On:          rule.On,
+			Channels:    rule.Channels,
+		})
+		if err != nil {
+			return fmt.Errorf("%s: rule %d: %w", path, i+1, err)
+		}
+	}
+	return nil
+}
+
+func newNotificationChannel(config channelConfig, secrets ports.SecretStore) (ports.NotificationChannel, error) {
+	ctx := context.Background()
+	url := config.URL
+	if config.URLSecret != "" {
+		value, err := secrets.GetSecret(ctx, config.URLSecret)
+		if err != nil {
+			return nil, err
+		}
+		url = value
+	}
+
+	switch config.Type {
+	case "slack":
+		if url == "" {
+			return nil, fmt.Errorf("slack channel needs url or urlSecret")
+		}
+		return notifications.NewSlackChannel(url), nil
+	case "webhook":
+		if url == "" {
+			return nil, fmt.Errorf("webhook channel needs url or urlSecret")
+		}
+		channel, err := notifications.NewWebhookChannel(url, config.Body)
+		if err != nil {
+			return nil, err
+		}
+		channel.Headers = config.Headers
+		return channel, nil
+	case "email":
+		if config.Addr == "" || config.From == "" || len(config.To) == 0 {
+			return nil, fmt.Errorf("email channel needs addr, from and to")
+		}
+		channel := notifications.NewEmailChannel(config.Addr, config.From, config.To)
+		if config.Username != "" {
+			password, err := secrets.GetSecret(ctx, config.PasswordSecret)
+			if err != nil {
+				return nil, err
+			}
+			host, _, err := net.SplitHostPort(config.Addr)
+			if err != nil {
+				return nil, err
+			}
+			channel.Auth = smtp.PlainAuth("", config.Username, password, host)
+		}
+		return channel, nil
+	default:
+		return nil, fmt.Errorf("unknown channel type %q: use slack, webhook or email", config.Type)
+	}
+}
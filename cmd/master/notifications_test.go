@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	orchestrator "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	eventstream "devops_console/internal/infrastructure/orchestrator/events"
+	repositories "devops_console/internal/infrastructure/orchestrator/repositories"
+	secrets "devops_console/internal/infrastructure/orchestrator/secrets"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newNotifier(t *testing.T) *orchestrator.NotificationServiceImpl {
+	taskRepo := repositories.NewInMemoryTaskRepository()
+	tasks := orchestrator.NewTaskServiceImpl(taskRepo)
+	t.Cleanup(tasks.Shutdown)
+	return orchestrator.NewNotificationServiceImpl(taskRepo, tasks, eventstream.NewTaskEventStream())
+}
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "notifications.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadNotifications_RegistersChannelsAndRules(t *testing.T) {
+	notifier := newNotifier(t)
+	config := writeConfig(t, `channels:
+  - name: ops
+    type: webhook
+    url: http://localhost/hooks
+  - name: oncall
+    type: email
+    addr: smtp.example.com:587
+    from: devops@example.com
+    to: [oncall@example.com]
+rules:
+  - workspace: payments
+    on: [FAILURE, RECOVERY]
+    channels: [ops, oncall]
+`)
+	require.NoError(t, loadNotifications(config, notifier, secrets.NewEnvSecretStore("SECRET_")))
+
+	rules := notifier.Rules()
+	require.Len(t, rules, 1)
+	assert.Equal(t, "payments", rules[0].WorkspaceID)
+	assert.Equal(t, []entities.NotificationTrigger{entities.NotifyOnFailure, entities.NotifyOnRecovery}, rules[0].On)
+}
+
+func TestLoadNotifications_RejectsInvalidConfigs(t *testing.T) {
+	for name, content := range map[string]string{
+		"unknown channel":  "channels:\n  - name: ops\n    type: slack\n    url: http://x\nrules:\n  - workspace: p\n    on: [FAILURE]\n    channels: [pager]\n",
+		"unknown trigger":  "channels:\n  - name: ops\n    type: slack\n    url: http://x\nrules:\n  - workspace: p\n    on: [SUCCESS]\n    channels: [ops]\n",
+		"duplicated name":  "channels:\n  - name: ops\n    type: slack\n    url: http://x\n  - name: ops\n    type: slack\n    url: http://y\n",
+		"unknown type":     "channels:\n  - name: ops\n    type: pager\n",
+		"missing secret":   "channels:\n  - name: ops\n    type: slack\n    urlSecret: MISSING\n",
+		"email without to": "channels:\n  - name: ops\n    type: email\n    addr: smtp:25\n    from: a@b\n",
+		"unknown field":    "channels:\n  - name: ops\n    kind: slack\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := loadNotifications(writeConfig(t, content), newNotifier(t), secrets.NewEnvSecretStore("SECRET_"))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewNotificationChannel_TakesTheURLFromTheSecretStore(t *testing.T) {
+	var received entities.Notification
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer hook-token", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer hook.Close()
+	t.Setenv("SECRET_OPS_HOOK", hook.URL)
+
+	channel, err := newNotificationChannel(channelConfig{
+		Name:      "ops",
+		Type:      "webhook",
+		URLSecret: "OPS_HOOK",
+		Headers:   map[string]string{"Authorization": "Bearer hook-token"},
+	}, secrets.NewEnvSecretStore("SECRET_"))
+	require.NoError(t, err)
+
+	err = channel.Send(context.Background(), entities.Notification{Trigger: entities.NotifyOnFailure, TaskID: "nightly-backup"})
+	require.NoError(t, err)
+	assert.Equal(t, "nightly-backup", received.TaskID)
+}
@@ -60,19 +60,19 @@ func TestTaskExecutionWithDockerWorker(t *testing.T) {
 	}
 
 	// Save the task in the repository
-	_, err = taskService.CreateTask(task)
+	_, err = taskService.CreateTask(context.Background(), task)
 	if err != nil {
 		t.Fatalf("Error creating task: %v", err)
 	}
 
 	// Execute the task
-	executionID, err := taskService.ExecuteTask(task.ID)
+	executionID, err := taskService.ExecuteTask(context.Background(), task.ID)
 	if err != nil {
 		t.Fatalf("Error executing task: %v", err)
 	}
 
 	// Subscribe to task events
-	eventChan, err := taskService.SubscribeToTaskEvents(executionID)
+	eventChan, err := taskService.SubscribeToTaskEvents(context.Background(), executionID)
 	if err != nil {
 		t.Fatalf("Error subscribing to task events: %v", err)
 	}
@@ -168,19 +168,19 @@ func TestTaskExecutionWithKubernetesWorker(t *testing.T) {
 	}
 
 	// Save the task in the repository
-	_, err = taskService.CreateTask(task)
+	_, err = taskService.CreateTask(context.Background(), task)
 	if err != nil {
 		t.Fatalf("Error creating task: %v", err)
 	}
 
 	// Execute the task
-	executionID, err := taskService.ExecuteTask(task.ID)
+	executionID, err := taskService.ExecuteTask(context.Background(), task.ID)
 	if err != nil {
 		t.Fatalf("Error executing task: %v", err)
 	}
 
 	// Subscribe to task events
-	eventChan, err := taskService.SubscribeToTaskEvents(executionID)
+	eventChan, err := taskService.SubscribeToTaskEvents(context.Background(), executionID)
 	if err != nil {
 		t.Fatalf("Error subscribing to task events: %v", err)
 	}
@@ -281,19 +281,19 @@ func TestTaskCancellationWithDockerWorker(t *testing.T) {
 	}
 
 	// Save the task in the repository
-	_, err = taskService.CreateTask(task)
+	_, err = taskService.CreateTask(context.Background(), task)
 	if err != nil {
 		t.Fatalf("Error creating task: %v", err)
 	}
 
 	// Execute the task
-	executionID, err := taskService.ExecuteTask(task.ID)
+	executionID, err := taskService.ExecuteTask(context.Background(), task.ID)
 	if err != nil {
 		t.Fatalf("Error executing task: %v", err)
 	}
 
 	// Subscribe to task events
-	eventChan, err := taskService.SubscribeToTaskEvents(executionID)
+	eventChan, err := taskService.SubscribeToTaskEvents(context.Background(), executionID)
 	if err != nil {
 		t.Fatalf("Error subscribing to task events: %v", err)
 	}
@@ -338,7 +338,7 @@ func TestTaskCancellationWithDockerWorker(t *testing.T) {
 	time.Sleep(5 * time.Second)
 
 	// Cancel the task
-	err = taskService.CancelTask(executionID)
+	err = taskService.CancelTask(context.Background(), executionID)
 	if err != nil {
 		t.Fatalf("Error cancelling task: %v", err)
 	}
@@ -352,7 +352,7 @@ func TestTaskCancellationWithDockerWorker(t *testing.T) {
 	}
 
 	// Verify the task status is cancelled
-	status, err := taskService.GetTaskStatus(executionID)
+	status, err := taskService.GetTaskStatus(context.Background(), executionID)
 	if err != nil {
 		t.Fatalf("Error getting task status: %v", err)
 	}
@@ -0,0 +1,207 @@
+package orchestrator
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+var (
+	ErrApprovalNotPending = errors.New("execution is not waiting for approval")
+	ErrNotAnApprover      = errors.New("subject is not an approver of the task")
+	ErrSelfApproval       = errors.New("the subject that triggered the execution cannot approve it")
+	ErrAlreadyVoted       = errors.New("subject already voted on the execution")
+)
+
+// defaultApprovalTimeout se aplica cuando la política no define Timeout.
+const defaultApprovalTimeout = 24 * time.Hour
+
+// requestApproval registra una ejecución en espera de aprobación y programa su caducidad.
+// attempt aporta quién la lanza y, si viene de otra tarea, la ejecución de origen.
+func (s *TaskServiceImpl) requestApproval(ctx context.Context, task entities.DevOpsTask, attempt entities.TaskExecution, parameters map[string]interface{}) (string, error) {
+	if task.ApprovalPolicy == nil || len(task.ApprovalPolicy.Approvers) == 0 {
+		return "", fmt.Errorf("%w: approval task %q has no approvers", ErrInvalidTask, task.ID)
+	}
+
+	timeout := task.ApprovalPolicy.Timeout
+	if timeout <= 0 {
+		timeout = defaultApprovalTimeout
+	}
+	now := time.Now()
+	execution := attempt
+	execution.ID = s.GenerateID()
+	execution.DevOpsTaskID = task.ID
+	execution.Status = entities.TaskWaitingApproval
+	execution.StartedAt = now
+	execution.ApprovalDeadline = now.Add(timeout)
+	execution.Parameters = entities.MaskSecretParameters(task.AllParameters(), parameters)
+	execution.TaskRevision = task.Revision
+	if err := s.appendExecution(ctx, task.ID, &execution); err != nil {
+		return "", err
+	}
+
+	s.afterFunc(timeout, func(ctx context.Context) {
+		s.applyTerminalStatus(ctx, execution.ID, entities.TaskExpired, "approval request expired", "", nil, time.Now())
+	})
+	s.publishApprovalRequest(&task, &execution)
+	return execution.ID, nil
+}
+
+// publishApprovalRequest avisa de que la ejecución espera aprobación, p.ej. para notificarlo.
+func (s *TaskServiceImpl) publishApprovalRequest(task *entities.DevOpsTask, execution *entities.TaskExecution) {
+	if s.EventStream == nil {
+		return
+	}
+	approvers := make([]string, len(task.ApprovalPolicy.Approvers))
+	for i, approver := range task.ApprovalPolicy.Approvers {
+		approvers[i] = approver.GetID()
+	}
+	event := entities.TaskEvent{
+		ID:          s.GenerateID(),
+		ExecutionID: execution.ID,
+		Timestamp:   execution.StartedAt,
+		EventType:   entities.EventTypeTaskApprovalRequired,
+		Payload: entities.TaskApprovalPayload{
+			TaskID:      task.ID,
+			WorkspaceID: task.Workspace.ID,
+			Approvers:   approvers,
+			Quorum:      quorum(task.ApprovalPolicy),
+			Deadline:    execution.ApprovalDeadline,
+		},
+	}
+	if err := s.EventStream.Publish(event); err != nil {
+		log.Printf("Error publishing approval request of execution %s: %v", execution.ID, err)
+	}
+}
+
+// Approve registra la aprobación del sujeto y da la ejecución por superada al alcanzar el quórum.
+func (s *TaskServiceImpl) Approve(ctx context.Context, executionID string, subject entities.Subject, comment string) error {
+	return s.vote(ctx, executionID, subject, comment, true)
+}
+
+// Reject registra el rechazo del sujeto; basta un rechazo para terminar la ejecución.
+func (s *TaskServiceImpl) Reject(ctx context.Context, executionID string, subject entities.Subject, comment string) error {
+	return s.vote(ctx, executionID, subject, comment, false)
+}
+
+func (s *TaskServiceImpl) vote(ctx context.Context, executionID string, subject entities.Subject, comment string, approved bool) (err error) {
+	event := auditEvent{action: entities.AuditApprove, executionID: executionID}
+	if !approved {
+		event.action = entities.AuditReject
+	}
+	defer func() { s.recordAudit(ctx, &event, err) }()
+	// El voto cuenta a nombre del sujeto autenticado, no del que diga quien llama.
+	subject, err = s.actingSubject(ctx, subject)
+	if err != nil {
+		return err
+	}
+	if subject == nil {
+		return fmt.Errorf("%w: the request has no subject", ErrPermissionDenied)
+	}
+	event.subject = subject
+	if err := s.authorizeExecution(ctx, subject, entities.PermissionApprove, executionID); err != nil {
+		return err
+	}
+	now := time.Now()
+	expired := false
+
+	err = s.updateTask(ctx, executionID, func(task *entities.DevOpsTask, execution *entities.TaskExecution) error {
+		if execution.Status != entities.TaskWaitingApproval {
+			return ErrApprovalNotPending
+		}
+		if !now.Before(execution.ApprovalDeadline) {
+			expired = true
+			finishExecution(execution, entities.TaskExpired, "approval request expired", now)
+			return nil
+		}
+		if task.ApprovalPolicy == nil || !task.ApprovalPolicy.CanApprove(subject) {
+			return ErrNotAnApprover
+		}
+		if entities.IsSubject(execution.TriggeredByKind, execution.TriggeredBy, subject) {
+			return ErrSelfApproval
+		}
+
+		approvals := 0
+		for _, approval := range task.Approvals {
+			if approval.ExecutionID != executionID {
+				continue
+			}
+			if entities.IsSubject(approval.SubjectKind, approval.UserID, subject) {
+				return ErrAlreadyVoted
+			}
+			if approval.Approved {
+				approvals++
+			}
+		}
+
+		task.Approvals = append(task.Approvals, &entities.Approval{
+			ID:          s.GenerateID(),
+			ExecutionID: executionID,
+			UserID:      subject.GetID(),
+			SubjectKind: entities.SubjectKind(subject),
+			ApprovedAt:  now,
+			Approved:    approved,
+			Comment:     comment,
+		})
+		task.UpdatedAt = now
+
+		switch {
+		case !approved:
+			finishExecution(execution, entities.TaskRejected, fmt.Sprintf("rejected by %s: %s", subject.GetID(), comment), now)
+		case approvals+1 >= quorum(task.ApprovalPolicy):
+			finishExecution(execution, entities.TaskSucceeded, "", now)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.notifyFinishedIfTerminal(ctx, executionID) {
+		s.publishResult(ctx, executionID)
+	}
+	if expired {
+		return ErrApprovalNotPending
+	}
+	return nil
+}
+
+func finishExecution(execution *entities.TaskExecution, status entities.TaskStatus, errMsg string, finishedAt time.Time) {
+	execution.Status = status
+	execution.Error = errMsg
+	execution.FinishedAt = finishedAt
+}
+
+// notifyFinishedIfTerminal despierta a quien espera la ejecución si ya ha terminado y
+// devuelve si lo ha hecho.
+func (s *TaskServiceImpl) notifyFinishedIfTerminal(ctx context.Context, executionID string) bool {
+	if execution, err := s.getExecution(ctx, executionID); err == nil && execution.Status.IsTerminal() {
+		s.notifyFinished(executionID)
+		return true
+	}
+	return false
+}
+
+func quorum(policy *entities.ApprovalPolicy) int {
+	if policy.Quorum < 1 {
+		return 1
+	}
+	return policy.Quorum
+}
+
+func subjectID(subject entities.Subject) string {
+	if subject == nil {
+		return ""
+	}
+	return subject.GetID()
+}
+
+func subjectKind(subject entities.Subject) string {
+	if subject == nil {
+		return ""
+	}
+	return entities.SubjectKind(subject)
+}
@@ -0,0 +1,134 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type ApprovalTestSuite struct {
+	suite.Suite
+	service *orchestrator2.TaskServiceImpl
+	repo    *adapters.InMemoryTaskRepository
+}
+
+var (
+	alice   = entities.User{ID: "alice", Name: "Alice"}
+	bob     = entities.User{ID: "bob", Name: "Bob", Groups: []string{"sre"}}
+	charlie = entities.User{ID: "charlie", Name: "Charlie", Groups: []string{"sre"}}
+	mallory = entities.User{ID: "mallory", Name: "Mallory"}
+)
+
+func (suite *ApprovalTestSuite) SetupTest() {
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.service = orchestrator2.NewTaskServiceImpl(suite.repo)
+}
+
+func (suite *ApprovalTestSuite) approvalTask(quorum int, timeout time.Duration) string {
+	task := &entities.DevOpsTask{
+		ID:       "prod-deploy-approval",
+		TaskType: entities.TaskTypeApproval,
+		ApprovalPolicy: &entities.ApprovalPolicy{
+			Approvers: []entities.Subject{alice, entities.Group{ID: "sre", Name: "SRE"}},
+			Quorum:    quorum,
+			Timeout:   timeout,
+		},
+	}
+	suite.Require().NoError(suite.repo.Create(context.Background(), task))
+	return task.ID
+}
+
+func (suite *ApprovalTestSuite) status(executionID string) entities.TaskStatus {
+	status, err := suite.service.GetTaskStatus(context.Background(), executionID)
+	suite.Require().NoError(err)
+	return status
+}
+
+func (suite *ApprovalTestSuite) TestApprove_RequiresQuorum() {
+	taskID := suite.approvalTask(2, time.Hour)
+	executionID, err := suite.service.ExecuteTaskAs(context.Background(), taskID, alice)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.TaskWaitingApproval, suite.status(executionID))
+
+	assert.ErrorIs(suite.T(), suite.service.Approve(context.Background(), executionID, alice, "lgtm"), orchestrator2.ErrSelfApproval)
+	assert.ErrorIs(suite.T(), suite.service.Approve(context.Background(), executionID, mallory, "lgtm"), orchestrator2.ErrNotAnApprover)
+
+	suite.Require().NoError(suite.service.Approve(context.Background(), executionID, bob, "lgtm"))
+	assert.ErrorIs(suite.T(), suite.service.Approve(context.Background(), executionID, bob, "again"), orchestrator2.ErrAlreadyVoted)
+	assert.Equal(suite.T(), entities.TaskWaitingApproval, suite.status(executionID))
+
+	suite.Require().NoError(suite.service.Approve(context.Background(), executionID, charlie, "ship it"))
+	assert.Equal(suite.T(), entities.TaskSucceeded, suite.status(executionID))
+
+	task, err := suite.repo.GetByID(context.Background(), taskID)
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), task.Approvals, 2)
+	assert.Equal(suite.T(), "ship it", task.Approvals[1].Comment)
+}
+
+func (suite *ApprovalTestSuite) TestApprove_MatchesApproverKind() {
+	taskID := suite.approvalTask(1, time.Hour)
+	executionID, err := suite.service.ExecuteTaskAs(context.Background(), taskID, alice)
+	suite.Require().NoError(err)
+
+	// Un usuario con el ID del grupo aprobador no es el grupo.
+	impostor := entities.User{ID: "sre", Name: "Not the SRE group"}
+	assert.ErrorIs(suite.T(), suite.service.Approve(context.Background(), executionID, impostor, "lgtm"), orchestrator2.ErrNotAnApprover)
+	assert.Equal(suite.T(), entities.TaskWaitingApproval, suite.status(executionID))
+}
+
+func (suite *ApprovalTestSuite) TestApprove_ComparesVotersByKind() {
+	deployBot := entities.ServiceAccount{ID: "alice", Name: "Deploy bot"}
+	reviewBot := entities.ServiceAccount{ID: "bob", Name: "Review bot"}
+	task := &entities.DevOpsTask{
+		ID:       "prod-deploy-approval",
+		TaskType: entities.TaskTypeApproval,
+		ApprovalPolicy: &entities.ApprovalPolicy{
+			Approvers: []entities.Subject{alice, bob, reviewBot},
+			Quorum:    3,
+			Timeout:   time.Hour,
+		},
+	}
+	suite.Require().NoError(suite.repo.Create(context.Background(), task))
+	executionID, err := suite.service.ExecuteTaskAs(context.Background(), task.ID, deployBot)
+	suite.Require().NoError(err)
+
+	// La cuenta de servicio "alice" lanzó la ejecución, no la usuaria alice; y el voto del
+	// usuario bob no es el de la cuenta de servicio "bob".
+	suite.Require().NoError(suite.service.Approve(context.Background(), executionID, alice, "lgtm"))
+	suite.Require().NoError(suite.service.Approve(context.Background(), executionID, bob, "lgtm"))
+	suite.Require().NoError(suite.service.Approve(context.Background(), executionID, reviewBot, "checks passed"))
+	assert.Equal(suite.T(), entities.TaskSucceeded, suite.status(executionID))
+}
+
+func (suite *ApprovalTestSuite) TestReject_FinishesExecution() {
+	taskID := suite.approvalTask(2, time.Hour)
+	executionID, err := suite.service.ExecuteTaskAs(context.Background(), taskID, alice)
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(suite.service.Reject(context.Background(), executionID, bob, "not during the freeze"))
+	assert.Equal(suite.T(), entities.TaskRejected, suite.status(executionID))
+	assert.ErrorIs(suite.T(), suite.service.Approve(context.Background(), executionID, charlie, "lgtm"), orchestrator2.ErrApprovalNotPending)
+}
+
+func (suite *ApprovalTestSuite) TestApprovalRequest_Expires() {
+	taskID := suite.approvalTask(1, 20*time.Millisecond)
+	executionID, err := suite.service.ExecuteTask(context.Background(), taskID)
+	suite.Require().NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	status, err := suite.service.WaitForExecution(ctx, executionID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.TaskExpired, status)
+	assert.ErrorIs(suite.T(), suite.service.Approve(context.Background(), executionID, bob, "too late"), orchestrator2.ErrApprovalNotPending)
+}
+
+func TestApprovalTestSuite(t *testing.T) {
+	suite.Run(t, new(ApprovalTestSuite))
+}
@@ -0,0 +1,197 @@
+package orchestrator
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+type ArtifactService interface {
+	ListArtifacts(ctx context.Context, executionID string) ([]entities.Artifact, error)
+	// OpenArtifact devuelve el artefacto name de la ejecución y su contenido, que hay que cerrar.
+	OpenArtifact(ctx context.Context, executionID, name string) (entities.Artifact, io.ReadCloser, error)
+}
+
+var _ ArtifactService = (*ArtifactServiceImpl)(nil)
+
+var ErrArtifactInputUnavailable = errors.New("artifact input unavailable")
+
+const defaultArtifactPruneInterval = time.Hour
+
+// ArtifactServiceImpl sirve los artefactos que los executors recogen de las ejecuciones y
+// guardan en el ArtifactStore. Quien puede ver una ejecución puede descargar sus artefactos.
+// Mientras está arrancado borra cada PruneInterval el contenido que superó la retención.
+type ArtifactServiceImpl struct {
+	store         ports.ArtifactStore
+	tasks         *TaskServiceImpl
+	PruneInterval time.Duration
+	stop          chan struct{}
+	mu            sync.Mutex
+	wg            sync.WaitGroup
+}
+
+func NewArtifactServiceImpl(store ports.ArtifactStore, taskService *TaskServiceImpl) *ArtifactServiceImpl {
+	return &ArtifactServiceImpl{
+		store:         store,
+		tasks:         taskService,
+		PruneInterval: defaultArtifactPruneInterval,
+	}
+}
+
+func (s *ArtifactServiceImpl) ListArtifacts(ctx context.Context, executionID string) ([]entities.Artifact, error) {
+	execution, err := s.tasks.getExecution(ctx, executionID)
+	if errors.Is(err, ErrExecutionNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		// El repositorio no distingue la ejecución inexistente de la que está fuera del ámbito.
+		return nil, fmt.Errorf("%w: %s: %v", ErrExecutionNotFound, executionID, err)
+	}
+	if err := s.tasks.authorizeExecution(ctx, nil, entities.PermissionView, executionID); err != nil {
+		return nil, err
+	}
+	return execution.Artifacts, nil
+}
+
+func (s *ArtifactServiceImpl) OpenArtifact(ctx context.Context, executionID, name string) (entities.Artifact, io.ReadCloser, error) {
+	artifacts, err := s.ListArtifacts(ctx, executionID)
+	if err != nil {
+		return entities.Artifact{}, nil, err
+	}
+	for _, artifact := range artifacts {
+		if artifact.Name == name {
+			content, err := s.store.Open(ctx, artifact.Digest)
+			if err != nil {
+				return entities.Artifact{}, nil, err
+			}
+			return artifact, content, nil
+		}
+	}
+	return entities.Artifact{}, nil, fmt.Errorf("%w: %q in execution %s", ports.ErrArtifactNotFound, name, executionID)
+}
+
+// Start lanza la limpieza periódica del almacén.
+func (s *ArtifactServiceImpl) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	s.stop = stop
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.PruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if _, err := s.store.Prune(s.tasks.lifetime); err != nil {
+					log.Printf("Error pruning artifacts: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop detiene la limpieza periódica y espera a que termine la que esté en curso.
+func (s *ArtifactServiceImpl) Stop() {
+	s.mu.Lock()
+	if s.stop == nil {
+		s.mu.Unlock()
+		return
+	}
+	close(s.stop)
+	s.stop = nil
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// attachArtifacts enlaza con la ejecución los artefactos que recogió el executor. Se
+// enlazan aunque la ejecución ya esté terminada, p.ej. si se canceló mientras se recogían.
+func (s *TaskServiceImpl) attachArtifacts(ctx context.Context, executionID string, artifacts []entities.Artifact) {
+	if len(artifacts) == 0 {
+		return
+	}
+	err := s.updateTask(ctx, executionID, func(task *entities.DevOpsTask, execution *entities.TaskExecution) error {
+		execution.Artifacts = append(append([]entities.Artifact(nil), execution.Artifacts...), artifacts...)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error attaching artifacts to execution %s: %v", executionID, err)
+	}
+}
+
+// SetMaxInputSize limita el tamaño de cada artefacto que recibe una ejecución como input;
+// una ejecución con un input mayor no se lanza.
+func (s *TaskServiceImpl) SetMaxInputSize(size int64) {
+	s.maxInputSize = size
+}
+
+// resolveInputs elige el artefacto de cada input de la tarea: el de la ejecución que la
+// encadenó si viene de esa tarea y, si no, el de la última ejecución con éxito de la
+// tarea de origen. Los inputs solo pueden venir de tareas del mismo tenant.
+func (s *TaskServiceImpl) resolveInputs(ctx context.Context, task *entities.DevOpsTask, upstreamExecutionID string) ([]entities.ArtifactInput, error) {
+	if len(task.Config.Inputs) == 0 {
+		return nil, nil
+	}
+	tenantCtx := ports.WithScope(ctx, entities.Scope{TenantID: task.Workspace.TenantID})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resolved := make([]entities.ArtifactInput, len(task.Config.Inputs))
+	for i, input := range task.Config.Inputs {
+		source, err := s.repository.GetByID(tenantCtx, input.TaskID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: task %q not found", ErrArtifactInputUnavailable, input.TaskID)
+		}
+		execution := sourceExecution(source, upstreamExecutionID)
+		if execution == nil {
+			return nil, fmt.Errorf("%w: task %q has no successful execution", ErrArtifactInputUnavailable, input.TaskID)
+		}
+		artifact, ok := findArtifact(execution.Artifacts, input.Artifact)
+		if !ok {
+			return nil, fmt.Errorf("%w: execution %s of task %q has no artifact %q", ErrArtifactInputUnavailable, execution.ID, input.TaskID, input.Artifact)
+		}
+		if s.maxInputSize > 0 && artifact.Size > s.maxInputSize {
+			return nil, fmt.Errorf("%w: artifact %q of task %q is %d bytes, more than the %d bytes limit",
+				ErrArtifactInputUnavailable, input.Artifact, input.TaskID, artifact.Size, s.maxInputSize)
+		}
+		input.Source = &artifact
+		input.ExecutionID = execution.ID
+		resolved[i] = input
+	}
+	return resolved, nil
+}
+
+func sourceExecution(task entities.DevOpsTask, upstreamExecutionID string) *entities.TaskExecution {
+	for _, execution := range task.Executions {
+		if upstreamExecutionID != "" && execution.ID == upstreamExecutionID {
+			return execution
+		}
+	}
+	for i := len(task.Executions) - 1; i >= 0; i-- {
+		if task.Executions[i].Status == entities.TaskSucceeded {
+			return task.Executions[i]
+		}
+	}
+	return nil
+}
+
+func findArtifact(artifacts []entities.Artifact, name string) (entities.Artifact, bool) {
+	for _, artifact := range artifacts {
+		if artifact.Name == name {
+			return artifact, true
+		}
+	}
+	return entities.Artifact{}, false
+}
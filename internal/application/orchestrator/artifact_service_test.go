@@ -0,0 +1,247 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	artifacts "devops_console/internal/infrastructure/orchestrator/artifacts"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	ports "devops_console/internal/ports/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ArtifactTestSuite struct {
+	suite.Suite
+	service   *orchestrator2.TaskServiceImpl
+	artifacts *orchestrator2.ArtifactServiceImpl
+	store     *artifacts.LocalArtifactStore
+	executor  *fakeExecutor
+}
+
+func (suite *ArtifactTestSuite) SetupTest() {
+	suite.service = orchestrator2.NewTaskServiceImpl(adapters.NewInMemoryTaskRepository())
+	store, err := artifacts.NewLocalArtifactStore(suite.T().TempDir(), artifacts.ArtifactLimits{})
+	suite.Require().NoError(err)
+	suite.store = store
+	suite.artifacts = orchestrator2.NewArtifactServiceImpl(suite.store, suite.service)
+	suite.executor = newFakeExecutor()
+	suite.executor.store = suite.store
+	suite.executor.files = map[string]string{"/work/report.txt": "all tests passed", "/work/build.log": "build ok"}
+	suite.service.RegisterExecutor("Stub", suite.executor)
+}
+
+func (suite *ArtifactTestSuite) TearDownTest() {
+	suite.service.Shutdown()
+}
+
+// run crea una tarea que declara outputs, la ejecuta y espera a que termine.
+func (suite *ArtifactTestSuite) run(workspace entities.Workspace, outputs ...entities.OutputSpec) string {
+	_, err := suite.service.CreateTask(context.Background(), entities.DevOpsTask{
+		ID:        "build",
+		Workspace: workspace,
+		Worker:    &stubWorker{},
+		Config:    entities.TaskConfig{Outputs: outputs},
+	})
+	suite.Require().NoError(err)
+	return suite.execute("build", orchestrator2.ExecutionOptions{})
+}
+
+// execute lanza la tarea y espera a que termine con éxito.
+func (suite *ArtifactTestSuite) execute(taskID string, options orchestrator2.ExecutionOptions) string {
+	executionID, err := suite.service.ExecuteTaskWith(context.Background(), taskID, options)
+	suite.Require().NoError(err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	status, err := suite.service.WaitForExecution(ctx, executionID)
+	suite.Require().NoError(err)
+	suite.Require().Equal(entities.TaskSucceeded, status)
+	return executionID
+}
+
+func (suite *ArtifactTestSuite) read(content io.ReadCloser) string {
+	defer content.Close()
+	data, err := io.ReadAll(content)
+	suite.Require().NoError(err)
+	return string(data)
+}
+
+func (suite *ArtifactTestSuite) TestArtifacts_AreLinkedAndDownloadable() {
+	executionID := suite.run(payments,
+		entities.OutputSpec{Name: "report", Path: "/work/report.txt"},
+		entities.OutputSpec{Name: "log", Path: "/work/build.log"},
+	)
+
+	listed, err := suite.artifacts.ListArtifacts(context.Background(), executionID)
+	suite.Require().NoError(err)
+	suite.Require().Len(listed, 2)
+	assert.Equal(suite.T(), "report", listed[0].Name)
+	assert.True(suite.T(), strings.HasPrefix(listed[0].Digest, "sha256:"))
+	assert.Equal(suite.T(), int64(len("all tests passed")), listed[0].Size)
+
+	artifact, content, err := suite.artifacts.OpenArtifact(context.Background(), executionID, "report")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "all tests passed", suite.read(content))
+	assert.Equal(suite.T(), "/work/report.txt", artifact.Path)
+
+	_, _, err = suite.artifacts.OpenArtifact(context.Background(), executionID, "coverage")
+	assert.ErrorIs(suite.T(), err, ports.ErrArtifactNotFound)
+	_, err = suite.artifacts.ListArtifacts(context.Background(), "missing")
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrExecutionNotFound)
+}
+
+func (suite *ArtifactTestSuite) TestArtifacts_FollowExecutionVisibility() {
+	executionID := suite.run(payments, entities.OutputSpec{Name: "report", Path: "/work/report.txt"})
+
+	_, err := suite.artifacts.ListArtifacts(inScope(billing, true), executionID)
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrExecutionNotFound)
+
+	authorizer := orchestrator2.NewRBACAuthorizer(nil)
+	suite.service.SetAuthorizer(authorizer)
+	_, _, err = suite.artifacts.OpenArtifact(as(mallory), executionID, "report")
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPermissionDenied)
+
+	suite.Require().NoError(authorizer.Bind(entities.RoleBinding{Subject: alice, Role: entities.RoleViewer, TenantID: "acme", WorkspaceID: "payments"}))
+	_, content, err := suite.artifacts.OpenArtifact(as(alice), executionID, "report")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "all tests passed", suite.read(content))
+}
+
+func (suite *ArtifactTestSuite) TestOutputs_AreValidated() {
+	for _, outputs := range [][]entities.OutputSpec{
+		{{Name: "report", Path: "report.txt"}},
+		{{Path: "/work/report.txt"}},
+		{{Name: "report", Path: "/work/a"}, {Name: "report", Path: "/work/b"}},
+	} {
+		_, err := suite.service.CreateTask(context.Background(), entities.DevOpsTask{
+			ID: "build", Worker: &stubWorker{}, Config: entities.TaskConfig{Outputs: outputs},
+		})
+		assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidTask)
+	}
+}
+
+func (suite *ArtifactTestSuite) TestOutputs_RequireAnExecutorThatCollectsThem() {
+	outputs := []entities.OutputSpec{{Name: "report", Path: "/work/report.txt"}}
+	service := orchestrator2.NewTaskServiceImpl(adapters.NewInMemoryTaskRepository())
+	defer service.Shutdown()
+	service.RegisterExecutor("Stub", basicExecutor())
+	_, err := service.CreateTask(context.Background(), entities.DevOpsTask{
+		ID: "build", Worker: &stubWorker{}, Config: entities.TaskConfig{Outputs: outputs},
+	})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidTask)
+	assert.ErrorContains(suite.T(), err, `"Stub" workers do not collect outputs`)
+
+	_, err = service.CreateTask(context.Background(), entities.DevOpsTask{ID: "build", Worker: &stubWorker{}})
+	suite.Require().NoError(err)
+	_, err = service.UpdateTask(context.Background(), "build", ports.TaskUpdate{
+		Config: entities.TaskConfig{Parameters: map[string]interface{}{}, Outputs: outputs},
+	})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidTask)
+}
+
+// createConsumer crea la tarea deploy, que recibe el informe de build.
+func (suite *ArtifactTestSuite) createConsumer(workspace entities.Workspace, artifact string) {
+	_, err := suite.service.CreateTask(context.Background(), entities.DevOpsTask{
+		ID:        "deploy",
+		Workspace: workspace,
+		Worker:    &stubWorker{},
+		Config: entities.TaskConfig{Inputs: []entities.ArtifactInput{
+			{TaskID: "build", Artifact: artifact, Path: "/in/report.txt"},
+		}},
+	})
+	suite.Require().NoError(err)
+}
+
+func (suite *ArtifactTestSuite) TestInputs_ComeFromUpstreamExecution() {
+	first := suite.run(payments, entities.OutputSpec{Name: "report", Path: "/work/report.txt"})
+	latest := suite.execute("build", orchestrator2.ExecutionOptions{})
+	suite.createConsumer(search, "report")
+
+	// Sin ejecución que la encadene, la última con éxito.
+	executionID := suite.execute("deploy", orchestrator2.ExecutionOptions{})
+	launched := suite.executor.launchedTasks()[len(suite.executor.launchedTasks())-1]
+	suite.Require().Len(launched.Config.Inputs, 1)
+	input := launched.Config.Inputs[0]
+	assert.Equal(suite.T(), latest, input.ExecutionID)
+	suite.Require().NotNil(input.Source)
+	content, err := suite.store.Open(context.Background(), input.Source.Digest)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "all tests passed", suite.read(content))
+
+	task, err := suite.service.GetTask(context.Background(), "deploy")
+	suite.Require().NoError(err)
+	suite.Require().Len(task.Executions, 1)
+	assert.Equal(suite.T(), executionID, task.Executions[0].ID)
+	assert.Equal(suite.T(), []entities.ArtifactInput{input}, task.Executions[0].Inputs)
+
+	// Encadenada, la que la lanzó.
+	suite.execute("deploy", orchestrator2.ExecutionOptions{UpstreamExecutionID: first})
+	launched = suite.executor.launchedTasks()[len(suite.executor.launchedTasks())-1]
+	assert.Equal(suite.T(), first, launched.Config.Inputs[0].ExecutionID)
+}
+
+func (suite *ArtifactTestSuite) TestInputs_FailFastWhenUnavailable() {
+	suite.createConsumer(payments, "report")
+	_, err := suite.service.ExecuteTask(context.Background(), "deploy")
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrArtifactInputUnavailable)
+	assert.ErrorContains(suite.T(), err, `task "build" not found`)
+
+	suite.run(payments, entities.OutputSpec{Name: "report", Path: "/work/report.txt"})
+	suite.service.SetMaxInputSize(4)
+	_, err = suite.service.ExecuteTask(context.Background(), "deploy")
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrArtifactInputUnavailable)
+	assert.ErrorContains(suite.T(), err, `artifact "report" of task "build" is 16 bytes, more than the 4 bytes limit`)
+
+	suite.Require().NoError(suite.service.DeleteTask(context.Background(), "deploy"))
+	suite.createConsumer(payments, "coverage")
+	_, err = suite.service.ExecuteTask(context.Background(), "deploy")
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrArtifactInputUnavailable)
+	assert.ErrorContains(suite.T(), err, `has no artifact "coverage"`)
+
+	// Nada llegó al executor salvo la ejecución de build.
+	assert.Len(suite.T(), suite.executor.launchedTasks(), 1)
+}
+
+func (suite *ArtifactTestSuite) TestInputs_StayWithinTenant() {
+	suite.run(billing, entities.OutputSpec{Name: "report", Path: "/work/report.txt"})
+	suite.createConsumer(payments, "report")
+	_, err := suite.service.ExecuteTask(context.Background(), "deploy")
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrArtifactInputUnavailable)
+}
+
+func (suite *ArtifactTestSuite) TestInputs_AreValidated() {
+	for _, inputs := range [][]entities.ArtifactInput{
+		{{TaskID: "deploy", Artifact: "report", Path: "/in/report.txt"}},
+		{{TaskID: "build", Path: "/in/report.txt"}},
+		{{TaskID: "build", Artifact: "report", Path: "in/report.txt"}},
+		{{TaskID: "build", Artifact: "report", Path: "/in/a"}, {TaskID: "build", Artifact: "log", Path: "/in/a/"}},
+	} {
+		_, err := suite.service.CreateTask(context.Background(), entities.DevOpsTask{
+			ID: "deploy", Worker: &stubWorker{}, Config: entities.TaskConfig{Inputs: inputs},
+		})
+		assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidTask)
+	}
+}
+
+func (suite *ArtifactTestSuite) TestInputs_RequireAnExecutorThatInjectsThem() {
+	service := orchestrator2.NewTaskServiceImpl(adapters.NewInMemoryTaskRepository())
+	defer service.Shutdown()
+	service.RegisterExecutor("Stub", basicExecutor())
+	_, err := service.CreateTask(context.Background(), entities.DevOpsTask{
+		ID:     "deploy",
+		Worker: &stubWorker{},
+		Config: entities.TaskConfig{Inputs: []entities.ArtifactInput{
+			{TaskID: "build", Artifact: "report", Path: "/in/report.txt"},
+		}},
+	})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidTask)
+	assert.ErrorContains(suite.T(), err, `"Stub" workers do not receive inputs`)
+}
+
+func TestArtifactTestSuite(t *testing.T) {
+	suite.Run(t, new(ArtifactTestSuite))
+}
@@ -0,0 +1,214 @@
+package orchestrator
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+type AuditService interface {
+	Query(ctx context.Context, filters ports.AuditFilters) ([]entities.AuditEntry, error)
+	// Export escribe en w las entradas que cumplen filters en formato JSON lines.
+	Export(ctx context.Context, filters ports.AuditFilters, w io.Writer) error
+	// Verify recorre el registro completo y comprueba que la cadena de hashes está íntegra.
+	Verify(ctx context.Context) error
+}
+
+var _ AuditService = (*AuditServiceImpl)(nil)
+
+type sourceKey struct{}
+
+// WithSource devuelve un contexto que registra el origen de la petición (p.ej. la
+// dirección del cliente) para las entradas de auditoría.
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// SourceFromContext devuelve el origen registrado con WithSource.
+func SourceFromContext(ctx context.Context) (string, bool) {
+	source, ok := ctx.Value(sourceKey{}).(string)
+	return source, ok
+}
+
+// systemSubjectID figura en las entradas de las operaciones que lanza el propio
+// orquestador sin un sujeto concreto (p.ej. el planificador).
+const systemSubjectID = "system"
+
+// AuditServiceImpl mantiene el registro de auditoría de las operaciones del TaskService.
+// Cada entrada se encadena con la anterior por su hash.
+type AuditServiceImpl struct {
+	repository ports.AuditRepository
+	GenerateID IDGenerator
+	// mu serializa las escrituras para que cada entrada continúe la última.
+	mu sync.Mutex
+}
+
+func NewAuditServiceImpl(auditRepo ports.AuditRepository) *AuditServiceImpl {
+	return &AuditServiceImpl{repository: auditRepo, GenerateID: defaultIDGenerator}
+}
+
+// record añade la entrada al final de la cadena.
+func (a *AuditServiceImpl) record(ctx context.Context, entry entities.AuditEntry) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	last, ok, err := a.repository.Last(ctx)
+	if err != nil {
+		return err
+	}
+	entry.Sequence = 1
+	if ok {
+		entry.Sequence = last.Sequence + 1
+		entry.PreviousHash = last.Hash
+	}
+	entry.ID = a.GenerateID()
+	entry.Timestamp = time.Now().UTC()
+	entry.Hash = entry.ComputeHash()
+	return a.repository.Append(ctx, &entry)
+}
+
+// Query devuelve las entradas que cumplen filters, limitadas al ámbito de la petición. Una
+// petición sin ámbito no puede leer el registro: vería las entradas de todos los tenants.
+func (a *AuditServiceImpl) Query(ctx context.Context, filters ports.AuditFilters) ([]entities.AuditEntry, error) {
+	scope, ok := ports.ScopeFromContext(ctx)
+	if !ok && !isTrusted(ctx) {
+		return nil, fmt.Errorf("%w: the audit log can only be read within a tenant scope", ErrPermissionDenied)
+	}
+	if ok {
+		if filters.TenantID != "" && filters.TenantID != scope.TenantID ||
+			scope.WorkspaceID != "" && filters.WorkspaceID != "" && filters.WorkspaceID != scope.WorkspaceID {
+			return nil, nil
+		}
+		filters.TenantID = scope.TenantID
+		if scope.WorkspaceID != "" {
+			filters.WorkspaceID = scope.WorkspaceID
+		}
+	}
+	return a.repository.Query(ctx, filters)
+}
+
+func (a *AuditServiceImpl) Export(ctx context.Context, filters ports.AuditFilters, w io.Writer) error {
+	entries, err := a.Query(ctx, filters)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *AuditServiceImpl) Verify(ctx context.Context) error {
+	entries, err := a.repository.Query(ctx, ports.AuditFilters{})
+	if err != nil {
+		return err
+	}
+	return entities.VerifyAuditChain(entries)
+}
+
+// SetAuditLog activa la auditoría: a partir de ese momento cada operación del TaskService
+// que modifica tareas o ejecuciones deja una entrada en el registro, haya tenido éxito o no.
+func (s *TaskServiceImpl) SetAuditLog(audit *AuditServiceImpl) {
+	s.audit = audit
+}
+
+// auditEvent reúne los datos de la entrada de auditoría de una operación a medida que la
+// operación los va conociendo.
+type auditEvent struct {
+	action      entities.AuditAction
+	subject     entities.Subject
+	workspace   entities.Workspace
+	taskID      string
+	executionID string
+	// before y after son la tarea antes y después de la operación; nil si no existía.
+	before *entities.DevOpsTask
+	after  *entities.DevOpsTask
+}
+
+// recordUpdate registra una modificación de la tarea; before es nil si no llegó a leerse.
+func (s *TaskServiceImpl) recordUpdate(ctx context.Context, taskID string, before *entities.DevOpsTask, updated entities.DevOpsTask, err error) {
+	event := auditEvent{action: entities.AuditUpdate, taskID: taskID, before: before}
+	if before != nil {
+		event.workspace = before.Workspace
+	}
+	if err == nil {
+		event.workspace = updated.Workspace
+		event.after = &updated
+	}
+	s.recordAudit(ctx, &event, err)
+}
+
+// recordAudit registra el resultado de la operación. Un fallo al registrar no deshace la
+// operación, que ya se ha hecho, pero queda en el log.
+func (s *TaskServiceImpl) recordAudit(ctx context.Context, event *auditEvent, err error) {
+	if s.audit == nil {
+		return
+	}
+	// Las operaciones sobre ejecuciones solo conocen su ID.
+	if event.taskID == "" && event.executionID != "" {
+		if task, err := s.repository.GetByExecutionID(context.WithoutCancel(ctx), event.executionID); err == nil {
+			event.taskID = task.ID
+			event.workspace = task.Workspace
+		}
+	}
+	// Los intentos sobre tareas que no se encuentran quedan en el ámbito de quien los hace,
+	// que es desde donde se consulta el registro.
+	if scope, ok := ports.ScopeFromContext(ctx); ok {
+		event.workspace = scopedWorkspace(event.workspace, scope)
+	}
+	entry := entities.AuditEntry{
+		Action:      event.action,
+		TenantID:    event.workspace.TenantID,
+		WorkspaceID: event.workspace.ID,
+		TaskID:      event.taskID,
+		ExecutionID: event.executionID,
+		Outcome:     entities.AuditSucceeded,
+	}
+	entry.Source, _ = SourceFromContext(ctx)
+
+	subject := event.subject
+	if subject == nil {
+		subject, _ = SubjectFromContext(ctx)
+	}
+	if subject != nil {
+		entry.SubjectID = subject.GetID()
+		entry.SubjectKind = entities.SubjectKind(subject)
+	} else if isTrusted(ctx) {
+		entry.SubjectID = systemSubjectID
+	}
+
+	if event.before != nil || event.after != nil {
+		var before, after entities.DevOpsTask
+		if event.before != nil {
+			before = diffView(*event.before)
+		}
+		if event.after != nil {
+			after = diffView(*event.after)
+		}
+		entry.Changes = entities.AuditChanges(entities.DiffTasks(before, after))
+	}
+
+	switch {
+	case errors.Is(err, ErrPermissionDenied):
+		entry.Outcome = entities.AuditDenied
+		entry.Error = err.Error()
+	case err != nil:
+		entry.Outcome = entities.AuditFailed
+		entry.Error = err.Error()
+	}
+
+	// La entrada se registra aunque la petición se haya cancelado mientras tanto.
+	if err := s.audit.record(context.WithoutCancel(ctx), entry); err != nil {
+		log.Printf("Error recording audit entry %s of task %s: %v", event.action, event.taskID, err)
+	}
+}
@@ -0,0 +1,211 @@
+package orchestrator_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	ports "devops_console/internal/ports/orchestrator"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type AuditTestSuite struct {
+	suite.Suite
+	service  *orchestrator2.TaskServiceImpl
+	audit    *orchestrator2.AuditServiceImpl
+	executor *fakeExecutor
+}
+
+func (suite *AuditTestSuite) SetupTest() {
+	suite.executor = newFakeExecutor()
+	suite.executor.hold = true
+	suite.service = orchestrator2.NewTaskServiceImpl(adapters.NewInMemoryTaskRepository())
+	suite.service.RegisterExecutor("Stub", suite.executor)
+	suite.audit = orchestrator2.NewAuditServiceImpl(adapters.NewInMemoryAuditRepository())
+	suite.service.SetAuditLog(suite.audit)
+}
+
+func (suite *AuditTestSuite) TearDownTest() {
+	suite.service.Shutdown()
+}
+
+// request es una petición de alice desde su puesto, en el tenant de payments.
+func request() context.Context {
+	return orchestrator2.WithSource(ports.WithScope(as(alice), entities.Scope{TenantID: payments.TenantID}), "10.0.0.7")
+}
+
+func (suite *AuditTestSuite) entries(filters ports.AuditFilters) []entities.AuditEntry {
+	entries, err := suite.audit.Query(inScope(payments, true), filters)
+	suite.Require().NoError(err)
+	return entries
+}
+
+func (suite *AuditTestSuite) TestTaskMutations_AreRecordedWithDiff() {
+	_, err := suite.service.CreateTask(request(), entities.DevOpsTask{
+		ID:        "deploy",
+		Name:      "Deploy",
+		Workspace: payments,
+		Worker:    &stubWorker{},
+		Parameters: []entities.ParameterDefinition{
+			{Name: "token", Type: entities.ParameterString, Secret: true},
+		},
+		Config: entities.TaskConfig{Parameters: map[string]interface{}{"token": "s3cr3t"}},
+	})
+	suite.Require().NoError(err)
+	_, err = suite.service.UpdateTask(request(), "deploy", ports.TaskUpdate{Name: "Deploy payments"})
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.service.DeleteTask(request(), "deploy"))
+
+	entries := suite.entries(ports.AuditFilters{TaskID: "deploy"})
+	suite.Require().Len(entries, 3)
+	for i, action := range []entities.AuditAction{entities.AuditCreate, entities.AuditUpdate, entities.AuditDelete} {
+		assert.Equal(suite.T(), action, entries[i].Action)
+		assert.Equal(suite.T(), "alice", entries[i].SubjectID)
+		assert.Equal(suite.T(), "user", entries[i].SubjectKind)
+		assert.Equal(suite.T(), "10.0.0.7", entries[i].Source)
+		assert.Equal(suite.T(), "acme", entries[i].TenantID)
+		assert.Equal(suite.T(), "payments", entries[i].WorkspaceID)
+		assert.Equal(suite.T(), entities.AuditSucceeded, entries[i].Outcome)
+	}
+
+	assert.Contains(suite.T(), entries[0].Changes, entities.AuditChange{Field: "Name", From: `""`, To: `"Deploy"`})
+	assert.Equal(suite.T(), []entities.AuditChange{{Field: "Name", From: `"Deploy"`, To: `"Deploy payments"`}}, entries[1].Changes)
+	assert.Contains(suite.T(), entries[2].Changes, entities.AuditChange{Field: "Name", From: `"Deploy payments"`, To: `""`})
+
+	// Los secretos nunca llegan al registro.
+	data, err := json.Marshal(entries)
+	suite.Require().NoError(err)
+	assert.NotContains(suite.T(), string(data), "s3cr3t")
+}
+
+func (suite *AuditTestSuite) TestExecutions_AreRecorded() {
+	_, err := suite.service.CreateTask(context.Background(), entities.DevOpsTask{ID: "deploy", Workspace: payments, Worker: &stubWorker{}})
+	suite.Require().NoError(err)
+
+	executionID, err := suite.service.ExecuteTask(request(), "deploy")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.service.CancelTask(request(), executionID))
+	_, err = suite.service.ExecuteTask(request(), "missing")
+	suite.Require().Error(err)
+
+	entries := suite.entries(ports.AuditFilters{SubjectID: "alice"})
+	suite.Require().Len(entries, 3)
+	assert.Equal(suite.T(), entities.AuditExecute, entries[0].Action)
+	assert.Equal(suite.T(), executionID, entries[0].ExecutionID)
+	assert.Equal(suite.T(), entities.AuditCancel, entries[1].Action)
+	assert.Equal(suite.T(), "deploy", entries[1].TaskID)
+	assert.Equal(suite.T(), "payments", entries[1].WorkspaceID)
+	assert.Equal(suite.T(), entities.AuditFailed, entries[2].Outcome)
+	assert.Equal(suite.T(), "task not found", entries[2].Error)
+}
+
+func (suite *AuditTestSuite) TestApprovals_RecordTheVoter() {
+	_, err := suite.service.CreateTask(context.Background(), entities.DevOpsTask{
+		ID:             "release",
+		TaskType:       entities.TaskTypeApproval,
+		Workspace:      payments,
+		ApprovalPolicy: &entities.ApprovalPolicy{Approvers: []entities.Subject{bob}, Timeout: time.Hour},
+	})
+	suite.Require().NoError(err)
+	executionID, err := suite.service.ExecuteTask(request(), "release")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.service.Reject(context.Background(), executionID, bob, "not today"))
+
+	entries := suite.entries(ports.AuditFilters{Action: entities.AuditReject})
+	suite.Require().Len(entries, 1)
+	assert.Equal(suite.T(), "bob", entries[0].SubjectID)
+	assert.Equal(suite.T(), "release", entries[0].TaskID)
+}
+
+func (suite *AuditTestSuite) TestDeniedOperations_AreRecorded() {
+	suite.service.SetAuthorizer(orchestrator2.NewRBACAuthorizer(nil))
+	_, err := suite.service.CreateTask(as(mallory), entities.DevOpsTask{ID: "backdoor", Workspace: payments, Worker: &stubWorker{}})
+	suite.Require().ErrorIs(err, orchestrator2.ErrPermissionDenied)
+
+	entries := suite.entries(ports.AuditFilters{Outcome: entities.AuditDenied})
+	suite.Require().Len(entries, 1)
+	assert.Equal(suite.T(), "mallory", entries[0].SubjectID)
+	assert.Equal(suite.T(), "backdoor", entries[0].TaskID)
+	assert.Empty(suite.T(), entries[0].Changes)
+}
+
+func (suite *AuditTestSuite) TestForgedSubject_IsRecordedAsTheCaller() {
+	_, err := suite.service.CreateTask(context.Background(), entities.DevOpsTask{
+		ID:             "release",
+		TaskType:       entities.TaskTypeApproval,
+		Workspace:      payments,
+		ApprovalPolicy: &entities.ApprovalPolicy{Approvers: []entities.Subject{bob}, Timeout: time.Hour},
+	})
+	suite.Require().NoError(err)
+	executionID, err := suite.service.ExecuteTask(request(), "release")
+	suite.Require().NoError(err)
+	err = suite.service.Approve(as(mallory), executionID, bob, "lgtm")
+	suite.Require().ErrorIs(err, orchestrator2.ErrPermissionDenied)
+
+	entries := suite.entries(ports.AuditFilters{Action: entities.AuditApprove})
+	suite.Require().Len(entries, 1)
+	assert.Equal(suite.T(), "mallory", entries[0].SubjectID)
+	assert.Equal(suite.T(), entities.AuditDenied, entries[0].Outcome)
+}
+
+func (suite *AuditTestSuite) TestChain_IsTamperEvident() {
+	for _, id := range []string{"a", "b", "c"} {
+		_, err := suite.service.CreateTask(request(), entities.DevOpsTask{ID: id, Workspace: payments, Worker: &stubWorker{}})
+		suite.Require().NoError(err)
+	}
+	suite.Require().NoError(suite.audit.Verify(context.Background()))
+
+	// Exportar y volver a leer conserva los hashes.
+	var exported bytes.Buffer
+	suite.Require().NoError(suite.audit.Export(inScope(payments, true), ports.AuditFilters{}, &exported))
+	var entries []entities.AuditEntry
+	scanner := bufio.NewScanner(&exported)
+	for scanner.Scan() {
+		var entry entities.AuditEntry
+		suite.Require().NoError(json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	suite.Require().Len(entries, 3)
+	suite.Require().NoError(entities.VerifyAuditChain(entries))
+
+	modified := append([]entities.AuditEntry(nil), entries...)
+	modified[1].SubjectID = "mallory"
+	assert.ErrorIs(suite.T(), entities.VerifyAuditChain(modified), entities.ErrAuditChainBroken)
+	removed := []entities.AuditEntry{entries[0], entries[2]}
+	assert.ErrorIs(suite.T(), entities.VerifyAuditChain(removed), entities.ErrAuditChainBroken)
+
+	// El repositorio no admite entradas que no continúen la cadena.
+	forged := entries[2]
+	forged.Sequence = 4
+	assert.ErrorIs(suite.T(), adapters.NewInMemoryAuditRepository().Append(context.Background(), &forged), entities.ErrAuditChainBroken)
+}
+
+func (suite *AuditTestSuite) TestQuery_IsLimitedToScope() {
+	_, err := suite.service.CreateTask(context.Background(), entities.DevOpsTask{ID: "deploy", Workspace: payments, Worker: &stubWorker{}})
+	suite.Require().NoError(err)
+	_, err = suite.service.CreateTask(context.Background(), entities.DevOpsTask{ID: "invoice", Workspace: billing, Worker: &stubWorker{}})
+	suite.Require().NoError(err)
+
+	entries, err := suite.audit.Query(inScope(payments, true), ports.AuditFilters{})
+	suite.Require().NoError(err)
+	suite.Require().Len(entries, 1)
+	assert.Equal(suite.T(), "deploy", entries[0].TaskID)
+
+	entries, err = suite.audit.Query(inScope(payments, true), ports.AuditFilters{TenantID: "globex"})
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), entries)
+
+	// Sin ámbito no se lee nada, ni siquiera filtrando por tenant.
+	_, err = suite.audit.Query(context.Background(), ports.AuditFilters{TenantID: "acme"})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPermissionDenied)
+}
+
+func TestAuditTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditTestSuite))
+}
@@ -0,0 +1,198 @@
+package orchestrator
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrInvalidBinding   = errors.New("invalid role binding")
+)
+
+type subjectKey struct{}
+
+type trustedKey struct{}
+
+// WithSubject devuelve un contexto que identifica al sujeto que hace la petición. El
+// TaskService lo usa para autorizar las operaciones que no reciben el sujeto explícitamente.
+func WithSubject(ctx context.Context, subject entities.Subject) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// SubjectFromContext devuelve el sujeto registrado con WithSubject.
+func SubjectFromContext(ctx context.Context) (entities.Subject, bool) {
+	subject, ok := ctx.Value(subjectKey{}).(entities.Subject)
+	return subject, ok && subject != nil
+}
+
+// trusted marca el contexto de las operaciones que lanza el propio orquestador
+// (planificador, webhooks, encadenamiento), que no pasan por el Authorizer.
+func trusted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trustedKey{}, true)
+}
+
+// SetAuthorizer activa el control de acceso: a partir de ese momento cada operación del
+// TaskService se autoriza contra el workspace de la tarea.
+func (s *TaskServiceImpl) SetAuthorizer(authorizer ports.Authorizer) {
+	s.authorizer = authorizer
+}
+
+// isTrusted indica si la operación la lanza el propio orquestador.
+func isTrusted(ctx context.Context) bool {
+	trusted, _ := ctx.Value(trustedKey{}).(bool)
+	return trusted
+}
+
+// actingSubject devuelve el sujeto en nombre del que se hace la operación: el autenticado
+// del contexto. Un sujeto explícito tiene que ser ese mismo; solo las operaciones del propio
+// orquestador (trusted) pueden actuar en nombre de otro. Sin Authorizer no hay
+// autenticación y, si el contexto no trae sujeto, se acepta el explícito.
+func (s *TaskServiceImpl) actingSubject(ctx context.Context, explicit entities.Subject) (entities.Subject, error) {
+	authenticated, ok := SubjectFromContext(ctx)
+	switch {
+	case explicit == nil:
+		return authenticated, nil
+	case isTrusted(ctx):
+		return explicit, nil
+	case ok:
+		if entities.SubjectKind(explicit) != entities.SubjectKind(authenticated) || explicit.GetID() != authenticated.GetID() {
+			return nil, fmt.Errorf("%w: %s %q cannot act as %s %q", ErrPermissionDenied,
+				entities.SubjectKind(authenticated), authenticated.GetID(), entities.SubjectKind(explicit), explicit.GetID())
+		}
+		return authenticated, nil
+	case s.authorizer != nil:
+		return nil, fmt.Errorf("%w: the request has no subject", ErrPermissionDenied)
+	default:
+		return explicit, nil
+	}
+}
+
+// authorize comprueba que el sujeto de la operación tiene el permiso en el workspace. Sin
+// Authorizer configurado todo está permitido. subject es el sujeto que la operación recibe
+// explícitamente, que tiene que coincidir con el del contexto (ver actingSubject).
+func (s *TaskServiceImpl) authorize(ctx context.Context, subject entities.Subject, permission entities.Permission, workspace entities.Workspace) error {
+	subject, err := s.actingSubject(ctx, subject)
+	if err != nil {
+		return err
+	}
+	if s.authorizer == nil || isTrusted(ctx) {
+		return nil
+	}
+	if subject == nil {
+		return fmt.Errorf("%w: the request has no subject", ErrPermissionDenied)
+	}
+	return s.authorizer.Authorize(ctx, subject, permission, workspace)
+}
+
+// authorizeExecution autoriza una operación sobre una ejecución con el workspace de su tarea.
+func (s *TaskServiceImpl) authorizeExecution(ctx context.Context, subject entities.Subject, permission entities.Permission, executionID string) error {
+	if s.authorizer == nil {
+		return nil
+	}
+	task, err := s.repository.GetByExecutionID(ctx, executionID)
+	if err != nil {
+		return err
+	}
+	return s.authorize(ctx, subject, permission, task.Workspace)
+}
+
+// RBACAuthorizer autoriza según los roles concedidos con Bind. Los permisos de un sujeto son
+// la unión de los de sus bindings, los directos y los de sus grupos, que se toman de
+// User.Groups y, si se configura, del GroupResolver.
+type RBACAuthorizer struct {
+	groups   ports.GroupResolver
+	mu       sync.RWMutex
+	bindings []entities.RoleBinding
+}
+
+// NewRBACAuthorizer crea un authorizer sin bindings. groups puede ser nil.
+func NewRBACAuthorizer(groups ports.GroupResolver) *RBACAuthorizer {
+	return &RBACAuthorizer{groups: groups}
+}
+
+// Bind concede el rol del binding a su sujeto.
+func (a *RBACAuthorizer) Bind(binding entities.RoleBinding) error {
+	switch {
+	case binding.Subject == nil:
+		return fmt.Errorf("%w: missing subject", ErrInvalidBinding)
+	case !binding.Role.IsValid():
+		return fmt.Errorf("%w: unknown role %q", ErrInvalidBinding, binding.Role)
+	case binding.TenantID == "":
+		return fmt.Errorf("%w: missing tenant", ErrInvalidBinding)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, existing := range a.bindings {
+		if sameBinding(existing, binding) {
+			return nil
+		}
+	}
+	a.bindings = append(a.bindings, binding)
+	return nil
+}
+
+// Unbind retira el binding; no hace nada si no existe.
+func (a *RBACAuthorizer) Unbind(binding entities.RoleBinding) {
+	if binding.Subject == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i, existing := range a.bindings {
+		if sameBinding(existing, binding) {
+			a.bindings = append(a.bindings[:i:i], a.bindings[i+1:]...)
+			return
+		}
+	}
+}
+
+// Bindings devuelve una copia de los bindings concedidos.
+func (a *RBACAuthorizer) Bindings() []entities.RoleBinding {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return append([]entities.RoleBinding(nil), a.bindings...)
+}
+
+func (a *RBACAuthorizer) Authorize(ctx context.Context, subject entities.Subject, permission entities.Permission, workspace entities.Workspace) error {
+	groups, err := a.groupsOf(ctx, subject)
+	if err != nil {
+		return fmt.Errorf("resolving groups of %s %q: %w", entities.SubjectKind(subject), subject.GetID(), err)
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, binding := range a.bindings {
+		if binding.Role.Grants(permission) && binding.Covers(workspace) && binding.Binds(subject, groups) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s %q cannot %s tasks in workspace %q of tenant %q",
+		ErrPermissionDenied, entities.SubjectKind(subject), subject.GetID(), permission, workspace.ID, workspace.TenantID)
+}
+
+func (a *RBACAuthorizer) groupsOf(ctx context.Context, subject entities.Subject) ([]string, error) {
+	var groups []string
+	if user, ok := subject.(entities.User); ok {
+		groups = append(groups, user.Groups...)
+	}
+	if a.groups != nil {
+		resolved, err := a.groups.GroupsOf(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, resolved...)
+	}
+	return groups, nil
+}
+
+func sameBinding(a, b entities.RoleBinding) bool {
+	return entities.SubjectKind(a.Subject) == entities.SubjectKind(b.Subject) &&
+		a.Subject.GetID() == b.Subject.GetID() &&
+		a.Role == b.Role && a.TenantID == b.TenantID && a.WorkspaceID == b.WorkspaceID
+}
@@ -0,0 +1,257 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	ports "devops_console/internal/ports/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type staticGroups map[string][]string
+
+func (g staticGroups) GroupsOf(ctx context.Context, subject entities.Subject) ([]string, error) {
+	return g[subject.GetID()], nil
+}
+
+var (
+	payments = entities.Workspace{ID: "payments", Name: "Payments", TenantID: "acme"}
+	search   = entities.Workspace{ID: "search", Name: "Search", TenantID: "acme"}
+	billing  = entities.Workspace{ID: "billing", Name: "Billing", TenantID: "globex"}
+)
+
+type AuthorizerTestSuite struct {
+	suite.Suite
+	service    *orchestrator2.TaskServiceImpl
+	repo       *adapters.InMemoryTaskRepository
+	executor   *fakeExecutor
+	authorizer *orchestrator2.RBACAuthorizer
+}
+
+func (suite *AuthorizerTestSuite) SetupTest() {
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.executor = newFakeExecutor()
+	suite.executor.hold = true
+	suite.service = orchestrator2.NewTaskServiceImpl(suite.repo)
+	suite.service.RegisterExecutor("Stub", suite.executor)
+	suite.authorizer = orchestrator2.NewRBACAuthorizer(staticGroups{"dave": {"payments-oncall"}})
+	suite.service.SetAuthorizer(suite.authorizer)
+
+	for _, task := range []*entities.DevOpsTask{
+		{ID: "deploy-payments", Workspace: payments, Worker: &stubWorker{}},
+		{ID: "reindex-search", Workspace: search, Worker: &stubWorker{}},
+		{ID: "invoice-billing", Workspace: billing, Worker: &stubWorker{}},
+	} {
+		suite.Require().NoError(suite.repo.Create(context.Background(), task))
+	}
+}
+
+func (suite *AuthorizerTestSuite) TearDownTest() {
+	suite.service.Shutdown()
+}
+
+func (suite *AuthorizerTestSuite) bind(subject entities.Subject, role entities.Role, workspace entities.Workspace, tenantWide bool) {
+	binding := entities.RoleBinding{Subject: subject, Role: role, TenantID: workspace.TenantID}
+	if !tenantWide {
+		binding.WorkspaceID = workspace.ID
+	}
+	suite.Require().NoError(suite.authorizer.Bind(binding))
+}
+
+func as(subject entities.Subject) context.Context {
+	return orchestrator2.WithSubject(context.Background(), subject)
+}
+
+func (suite *AuthorizerTestSuite) TestRequestWithoutSubject_IsDenied() {
+	_, err := suite.service.GetTask(context.Background(), "deploy-payments")
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPermissionDenied)
+}
+
+func (suite *AuthorizerTestSuite) TestViewer_CanReadButNotExecute() {
+	suite.bind(alice, entities.RoleViewer, payments, false)
+
+	_, err := suite.service.GetTask(as(alice), "deploy-payments")
+	suite.Require().NoError(err)
+
+	_, err = suite.service.ExecuteTask(as(alice), "deploy-payments")
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPermissionDenied)
+	assert.EqualError(suite.T(), err, `permission denied: user "alice" cannot execute tasks in workspace "payments" of tenant "acme"`)
+}
+
+func (suite *AuthorizerTestSuite) TestOperator_ExecutesAndCancelsButCannotManageTasks() {
+	suite.bind(bob, entities.RoleOperator, payments, false)
+
+	executionID, err := suite.service.ExecuteTask(as(bob), "deploy-payments")
+	suite.Require().NoError(err)
+	_, err = suite.service.SubscribeToTaskEvents(as(bob), executionID)
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.service.CancelTask(as(bob), executionID))
+
+	// Sin sujeto explícito la ejecución queda a nombre del de la petición.
+	task, err := suite.repo.GetByID(context.Background(), "deploy-payments")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "bob", task.Executions[0].TriggeredBy)
+
+	_, err = suite.service.UpdateTask(as(bob), "deploy-payments", ports.TaskUpdate{Name: "renamed"})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPermissionDenied)
+	assert.ErrorIs(suite.T(), suite.service.DeleteTask(as(bob), "deploy-payments"), orchestrator2.ErrPermissionDenied)
+	_, err = suite.service.CreateTask(as(bob), entities.DevOpsTask{ID: "new", Workspace: payments, Worker: &stubWorker{}})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPermissionDenied)
+}
+
+func (suite *AuthorizerTestSuite) TestBindingScope() {
+	// A nivel de workspace solo cubre ese workspace.
+	suite.bind(alice, entities.RoleAdmin, payments, false)
+	_, err := suite.service.GetTask(as(alice), "reindex-search")
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPermissionDenied)
+
+	// A nivel de tenant cubre todos sus workspaces, pero no los de otros tenants.
+	suite.bind(alice, entities.RoleAdmin, search, true)
+	_, err = suite.service.GetTask(as(alice), "reindex-search")
+	suite.Require().NoError(err)
+	_, err = suite.service.GetTask(as(alice), "invoice-billing")
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPermissionDenied)
+
+	page, err := suite.service.GetTasks(as(alice), ports.TaskFilters{})
+	suite.Require().NoError(err)
+	ids := make([]string, len(page.Tasks))
+	for i, task := range page.Tasks {
+		ids[i] = task.ID
+	}
+	assert.ElementsMatch(suite.T(), []string{"deploy-payments", "reindex-search"}, ids)
+}
+
+func (suite *AuthorizerTestSuite) TestGroupBinding_CoversMembers() {
+	suite.bind(entities.Group{ID: "sre"}, entities.RoleOperator, payments, true)
+	suite.bind(entities.Group{ID: "payments-oncall"}, entities.RoleOperator, payments, false)
+
+	// bob declara el grupo en User.Groups; dave lo obtiene del GroupResolver.
+	_, err := suite.service.ExecuteTask(as(bob), "reindex-search")
+	suite.Require().NoError(err)
+	dave := entities.User{ID: "dave", Name: "Dave"}
+	_, err = suite.service.ExecuteTask(as(dave), "deploy-payments")
+	suite.Require().NoError(err)
+
+	_, err = suite.service.ExecuteTask(as(mallory), "deploy-payments")
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPermissionDenied)
+
+	// Un usuario con el mismo ID que el grupo no queda cubierto por el binding del grupo.
+	_, err = suite.service.ExecuteTask(as(entities.User{ID: "sre"}), "deploy-payments")
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPermissionDenied)
+}
+
+func (suite *AuthorizerTestSuite) TestApprove_RequiresApproverRoleAndPolicy() {
+	task := &entities.DevOpsTask{
+		ID:        "release-approval",
+		TaskType:  entities.TaskTypeApproval,
+		Workspace: payments,
+		ApprovalPolicy: &entities.ApprovalPolicy{
+			Approvers: []entities.Subject{bob, charlie},
+			Timeout:   time.Hour,
+		},
+	}
+	suite.Require().NoError(suite.repo.Create(context.Background(), task))
+	suite.bind(alice, entities.RoleOperator, payments, false)
+	suite.bind(bob, entities.RoleApprover, payments, false)
+	suite.bind(mallory, entities.RoleApprover, payments, false)
+
+	executionID, err := suite.service.ExecuteTask(as(alice), task.ID)
+	suite.Require().NoError(err)
+
+	// charlie figura en la política pero no tiene el rol; mallory tiene el rol pero no figura.
+	assert.ErrorIs(suite.T(), suite.service.Approve(as(charlie), executionID, charlie, "lgtm"), orchestrator2.ErrPermissionDenied)
+	assert.ErrorIs(suite.T(), suite.service.Approve(as(mallory), executionID, mallory, "lgtm"), orchestrator2.ErrNotAnApprover)
+	suite.Require().NoError(suite.service.Approve(as(bob), executionID, bob, "lgtm"))
+
+	status, err := suite.service.GetTaskStatus(as(bob), executionID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.TaskSucceeded, status)
+}
+
+func (suite *AuthorizerTestSuite) TestExplicitSubject_MustBeTheAuthenticatedOne() {
+	task := &entities.DevOpsTask{
+		ID:        "release-approval",
+		TaskType:  entities.TaskTypeApproval,
+		Workspace: payments,
+		ApprovalPolicy: &entities.ApprovalPolicy{
+			Approvers: []entities.Subject{alice, bob},
+			Timeout:   time.Hour,
+		},
+	}
+	suite.Require().NoError(suite.repo.Create(context.Background(), task))
+	suite.bind(alice, entities.RoleOperator, payments, false)
+	suite.bind(alice, entities.RoleApprover, payments, false)
+	suite.bind(bob, entities.RoleApprover, payments, false)
+	suite.bind(mallory, entities.RoleApprover, payments, false)
+
+	// alice no puede lanzar a nombre de bob para aprobar después su propia petición.
+	_, err := suite.service.ExecuteTaskAs(as(alice), task.ID, bob)
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPermissionDenied)
+	executionID, err := suite.service.ExecuteTaskAs(as(alice), task.ID, alice)
+	suite.Require().NoError(err)
+
+	// mallory no puede votar como bob, ni nadie sin identificarse.
+	err = suite.service.Approve(as(mallory), executionID, bob, "lgtm")
+	assert.EqualError(suite.T(), err, `permission denied: user "mallory" cannot act as user "bob"`)
+	assert.ErrorIs(suite.T(), suite.service.Approve(context.Background(), executionID, bob, "lgtm"), orchestrator2.ErrPermissionDenied)
+	assert.ErrorIs(suite.T(), suite.service.Approve(as(alice), executionID, alice, "lgtm"), orchestrator2.ErrSelfApproval)
+
+	stored, err := suite.service.GetTask(as(bob), task.ID)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), stored.Approvals)
+	suite.Require().NoError(suite.service.Approve(as(bob), executionID, bob, "lgtm"))
+}
+
+func (suite *AuthorizerTestSuite) TestBind_RejectsInvalidBindings() {
+	err := suite.authorizer.Bind(entities.RoleBinding{Subject: alice, Role: "owner", TenantID: "acme"})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidBinding)
+	err = suite.authorizer.Bind(entities.RoleBinding{Subject: alice, Role: entities.RoleViewer})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidBinding)
+
+	suite.bind(alice, entities.RoleViewer, payments, false)
+	suite.bind(alice, entities.RoleViewer, payments, false)
+	assert.Len(suite.T(), suite.authorizer.Bindings(), 1)
+	suite.authorizer.Unbind(entities.RoleBinding{Subject: alice, Role: entities.RoleViewer, TenantID: "acme", WorkspaceID: "payments"})
+	assert.Empty(suite.T(), suite.authorizer.Bindings())
+}
+
+func (suite *AuthorizerTestSuite) TestPipeline_RunsNodesAsTheCaller() {
+	pipelines := orchestrator2.NewPipelineServiceImpl(adapters.NewInMemoryPipelineRepository(), suite.service)
+	suite.bind(alice, entities.RoleAdmin, payments, false)
+	suite.bind(alice, entities.RoleViewer, search, false)
+	release := entities.Pipeline{
+		ID:        "release",
+		Workspace: payments,
+		Nodes:     []entities.PipelineNode{{ID: "reindex", TaskID: "reindex-search"}},
+	}
+
+	_, err := pipelines.CreatePipeline(as(mallory), release)
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPermissionDenied)
+	_, err = pipelines.CreatePipeline(as(alice), release)
+	suite.Require().NoError(err)
+	_, err = pipelines.GetPipeline(as(mallory), "release")
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPermissionDenied)
+	_, err = pipelines.ExecutePipeline(as(mallory), "release")
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPermissionDenied)
+
+	// alice puede lanzar el pipeline pero no ejecutar las tareas de search.
+	executionID, err := pipelines.ExecutePipeline(as(alice), "release")
+	suite.Require().NoError(err)
+	var execution entities.PipelineExecution
+	suite.Require().Eventually(func() bool {
+		execution, err = pipelines.GetPipelineExecution(as(alice), executionID)
+		return err == nil && execution.Status.IsTerminal()
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(suite.T(), entities.TaskFailed, execution.Nodes["reindex"].Status)
+	assert.Contains(suite.T(), execution.Nodes["reindex"].Error, "permission denied")
+	_, err = pipelines.GetPipelineExecution(as(mallory), executionID)
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPermissionDenied)
+}
+
+func TestAuthorizerTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthorizerTestSuite))
+}
@@ -0,0 +1,119 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	pb "devops_console/internal/infrastructure/agent/proto/agent/v1"
+	eventstream "devops_console/internal/infrastructure/orchestrator/events"
+	executors "devops_console/internal/infrastructure/orchestrator/executors"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	secrets "devops_console/internal/infrastructure/orchestrator/secrets"
+	server "devops_console/internal/infrastructure/orchestrator/server"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type DryRunTestSuite struct {
+	suite.Suite
+	service  *orchestrator2.TaskServiceImpl
+	repo     *adapters.InMemoryTaskRepository
+	executor *fakeExecutor
+	task     entities.DevOpsTask
+}
+
+func (suite *DryRunTestSuite) SetupTest() {
+	suite.T().Setenv("SECRET_REGISTRY_TOKEN", "t0k3n")
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.executor = newFakeExecutor()
+	suite.service = orchestrator2.NewTaskServiceImpl(suite.repo)
+	suite.service.RegisterExecutor("Stub", suite.executor)
+
+	task, err := suite.service.CreateTask(context.Background(), entities.DevOpsTask{
+		ID:   "release",
+		Name: "release",
+		Worker: &detailsWorker{details: map[string]interface{}{
+			"Image":   "registry/app:${{ params.version }}",
+			"Command": []string{"deploy", "--run=${{ execution.id }}"},
+			"Environment": map[string]string{
+				"PASSWORD": "${{ secrets.password }}",
+			},
+		}},
+		Config: entities.TaskConfig{Secrets: []entities.SecretReference{{EnvVar: "TOKEN", Key: "registry/token"}}},
+		Parameters: []entities.ParameterDefinition{
+			{Name: "version", Type: entities.ParameterString, Required: true},
+			{Name: "password", Type: entities.ParameterString, Secret: true},
+		},
+	})
+	suite.Require().NoError(err)
+	suite.task = task
+}
+
+func (suite *DryRunTestSuite) options() orchestrator2.ExecutionOptions {
+	return orchestrator2.ExecutionOptions{Parameters: map[string]interface{}{"version": "v2", "password": "hunter2"}}
+}
+
+func (suite *DryRunTestSuite) TestDryRunTask_HasNoSideEffects() {
+	plan, err := suite.service.DryRunTask(context.Background(), suite.task.ID, suite.options())
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.WorkerSpec{
+		Image:   "registry/app:v2",
+		Command: []string{"deploy", "--run=dry-run"},
+		Env:     map[string]string{"PASSWORD": entities.MaskedParameterValue},
+	}, plan.Spec)
+
+	assert.Empty(suite.T(), suite.executor.executedTasks())
+	stored, err := suite.repo.GetByID(context.Background(), suite.task.ID)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), stored.Executions)
+
+	_, err = suite.service.DryRunTask(context.Background(), suite.task.ID, orchestrator2.ExecutionOptions{})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidParameters)
+
+	suite.service.RegisterExecutor("Stub", basicExecutor())
+	_, err = suite.service.DryRunTask(context.Background(), suite.task.ID, suite.options())
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPlanNotSupported)
+}
+
+func (suite *DryRunTestSuite) TestExecutorPlans_MaskSecrets() {
+	store := secrets.NewEnvSecretStore("SECRET_")
+	task := suite.task
+	task.Config.Parameters = map[string]interface{}{"version": "v2", "password": "hunter2"}
+
+	docker, err := executors.NewDockerTaskExecutor(eventstream.NewTaskEventStream())
+	suite.Require().NoError(err)
+	docker.SecretStore = store
+	k8s := &executors.K8sTaskExecutor{SecretStore: store}
+
+	for _, planner := range []interface {
+		Plan(context.Context, *entities.DevOpsTask) (entities.ExecutionPlan, error)
+	}{docker, k8s} {
+		plan, err := planner.Plan(context.Background(), &task)
+		suite.Require().NoError(err)
+		assert.Contains(suite.T(), plan.Manifest, "registry/app:v2")
+		assert.Contains(suite.T(), plan.Manifest, "TOKEN")
+		assert.NotContains(suite.T(), plan.Manifest, "hunter2")
+		assert.NotContains(suite.T(), plan.Manifest, "t0k3n")
+	}
+
+	agent := server.NewAgentServer(eventstream.NewTaskEventStream())
+	agent.SecretStore = store
+	command := &pb.Command{CommandId: "cmd-1", Command: "deploy"}
+	plan, err := agent.Plan(context.Background(), command, task.Config.Secrets)
+	suite.Require().NoError(err)
+	// protojson varía a propósito los espacios de su salida; se compara el JSON decodificado.
+	var manifest struct{ Environment map[string]string }
+	suite.Require().NoError(json.Unmarshal([]byte(plan.Manifest), &manifest))
+	assert.Equal(suite.T(), map[string]string{"TOKEN": entities.MaskedParameterValue}, manifest.Environment)
+	assert.Nil(suite.T(), command.Environment)
+	assert.Empty(suite.T(), agent.TaskQueue)
+
+	_, err = agent.Plan(context.Background(), command, []entities.SecretReference{{EnvVar: "MISSING", Key: "missing"}})
+	assert.Error(suite.T(), err)
+}
+
+func TestDryRunTestSuite(t *testing.T) {
+	suite.Run(t, new(DryRunTestSuite))
+}
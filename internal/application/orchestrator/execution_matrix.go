@@ -0,0 +1,234 @@
+package orchestrator
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	"fmt"
+	"log"
+	"time"
+)
+
+// matrixChild es una combinación de la matriz con sus parámetros ya resueltos.
+type matrixChild struct {
+	values     map[string]interface{}
+	parameters map[string]interface{}
+}
+
+// matrixRun es una ejecución de matriz en curso; done se cierra cuando termina.
+type matrixRun struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type matrixResult struct {
+	child       int
+	executionID string
+	status      entities.TaskStatus
+	err         error
+}
+
+// launchMatrix registra la ejecución de matriz y lanza en segundo plano una ejecución por
+// combinación. Los valores de la combinación prevalecen sobre los de la petición. Los
+// parámetros, las plantillas y los inputs se comprueban antes de registrar nada.
+func (s *TaskServiceImpl) launchMatrix(ctx context.Context, task *entities.DevOpsTask, attempt entities.TaskExecution, values map[string]interface{}) (string, error) {
+	combinations := task.Config.Matrix.Combinations()
+	children := make([]matrixChild, len(combinations))
+	for i, combination := range combinations {
+		merged := make(map[string]interface{}, len(values)+len(combination))
+		for name, value := range values {
+			merged[name] = value
+		}
+		for name, value := range combination {
+			merged[name] = value
+		}
+		parameters, err := entities.ResolveParameters(task.Parameters, merged)
+		if err != nil {
+			return "", fmt.Errorf("%w: combination %s: %v", ErrInvalidParameters, entities.FormatCombination(combination), err)
+		}
+		if err := checkTemplates(task, parameters); err != nil {
+			return "", err
+		}
+		children[i] = matrixChild{values: combination, parameters: parameters}
+	}
+	if _, err := s.resolveInputs(ctx, task, attempt.UpstreamExecutionID); err != nil {
+		return "", err
+	}
+
+	parent := attempt
+	parent.ID = s.GenerateID()
+	parent.DevOpsTaskID = task.ID
+	parent.Status = entities.TaskRunning
+	parent.StartedAt = time.Now()
+	parent.Parameters = entities.MaskSecretParameters(task.Parameters, values)
+	parent.TaskRevision = task.Revision
+	parent.MatrixSize = len(children)
+	if err := s.appendExecution(ctx, task.ID, &parent); err != nil {
+		return "", err
+	}
+
+	// La matriz sigue cuando la petición termina. Lanza y espera sus combinaciones en
+	// nombre de quien la lanzó, que ya tenía permiso para hacerlo.
+	runCtx, cancel := s.detach(trusted(ctx))
+	run := &matrixRun{cancel: cancel, done: make(chan struct{})}
+	s.matricesMu.Lock()
+	s.matrices[parent.ID] = run
+	s.matricesMu.Unlock()
+	go func() {
+		defer func() {
+			s.matricesMu.Lock()
+			delete(s.matrices, parent.ID)
+			s.matricesMu.Unlock()
+			cancel()
+			close(run.done)
+		}()
+		s.runMatrix(runCtx, *task, parent, children)
+	}()
+	return parent.ID, nil
+}
+
+// runMatrix lanza las combinaciones, como mucho MaxParallel a la vez, espera a que
+// terminen y agrega su resultado en la ejecución de matriz.
+func (s *TaskServiceImpl) runMatrix(ctx context.Context, task entities.DevOpsTask, parent entities.TaskExecution, children []matrixChild) {
+	matrix := task.Config.Matrix
+	limit := matrix.MaxParallel
+	if limit <= 0 || limit > len(children) {
+		limit = len(children)
+	}
+	// childrenCtx se cancela con la matriz o, con FailFast, cuando falla una combinación.
+	childrenCtx, stopChildren := context.WithCancel(ctx)
+	defer stopChildren()
+
+	results := make(chan matrixResult)
+	next, running := 0, 0
+	var failures []matrixResult
+	for {
+		for childrenCtx.Err() == nil && next < len(children) && running < limit {
+			s.startMatrixChild(childrenCtx, &task, parent, next, children[next], results)
+			next++
+			running++
+		}
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+		// Las combinaciones que cancela la propia matriz no cuentan como fallos.
+		if result.status == entities.TaskSucceeded || (result.status == entities.TaskCanceled && childrenCtx.Err() != nil) {
+			continue
+		}
+		failures = append(failures, result)
+		if matrix.FailFast {
+			stopChildren()
+		}
+	}
+
+	s.finishMatrix(ctx, parent, children, failures)
+}
+
+// startMatrixChild lanza una combinación y espera en segundo plano a que termine. Si ctx
+// se cancela antes de que termine, la cancela.
+func (s *TaskServiceImpl) startMatrixChild(ctx context.Context, task *entities.DevOpsTask, parent entities.TaskExecution, index int, child matrixChild, results chan<- matrixResult) {
+	executionID, err := s.launchAttempt(ctx, task, entities.TaskExecution{
+		Attempt:             1,
+		TriggeredBy:         parent.TriggeredBy,
+		TriggeredByKind:     parent.TriggeredByKind,
+		UpstreamExecutionID: parent.UpstreamExecutionID,
+		TriggerChain:        parent.TriggerChain,
+		ParentExecutionID:   parent.ID,
+		MatrixValues:        child.values,
+	}, child.parameters)
+	if err == nil {
+		linkErr := s.updateExecution(ctx, parent.ID, func(execution *entities.TaskExecution) {
+			execution.ChildExecutionIDs = append(execution.ChildExecutionIDs, executionID)
+		})
+		if linkErr != nil {
+			log.Printf("Error linking execution %s to matrix %s: %v", executionID, parent.ID, linkErr)
+		}
+	}
+
+	go func() {
+		if err != nil {
+			results <- matrixResult{child: index, status: entities.TaskError, err: err}
+			return
+		}
+		status, err := s.WaitForExecution(ctx, executionID)
+		if err != nil && ctx.Err() != nil {
+			// La combinación pudo terminar a la vez que se cancelaba ctx.
+			last, lastErr := s.lastAttempt(context.WithoutCancel(ctx), executionID)
+			if lastErr == nil && last.Status.IsTerminal() && last.RetryAt.IsZero() {
+				results <- matrixResult{child: index, executionID: executionID, status: last.Status}
+				return
+			}
+			// La cancelación de la combinación no puede depender de ctx, que ya terminó.
+			if cancelErr := s.CancelTask(context.WithoutCancel(ctx), executionID); cancelErr != nil {
+				log.Printf("Error canceling execution %s of matrix %s: %v", executionID, parent.ID, cancelErr)
+			}
+			results <- matrixResult{child: index, executionID: executionID, status: entities.TaskCanceled}
+			return
+		}
+		results <- matrixResult{child: index, executionID: executionID, status: status, err: err}
+	}()
+}
+
+// finishMatrix calcula el estado de la matriz: éxito si todas las combinaciones terminaron
+// bien, cancelada si se canceló la matriz y fallida en cualquier otro caso, con el error
+// de la primera combinación que falló.
+func (s *TaskServiceImpl) finishMatrix(ctx context.Context, parent entities.TaskExecution, children []matrixChild, failures []matrixResult) {
+	status, errMsg := entities.TaskSucceeded, ""
+	switch {
+	case ctx.Err() != nil:
+		status = entities.TaskCanceled
+	case len(failures) > 0:
+		status = entities.TaskFailed
+		first := failures[0]
+		reason := string(first.status)
+		if first.err != nil {
+			reason = first.err.Error()
+		} else if execution, err := s.lastAttempt(ctx, first.executionID); err == nil && execution.Error != "" {
+			reason = execution.Error
+		}
+		errMsg = fmt.Sprintf("%d of %d combinations failed; first: %s: %s",
+			len(failures), len(children), entities.FormatCombination(children[first.child].values), reason)
+	}
+	s.applyTerminalStatus(context.WithoutCancel(ctx), parent.ID, status, errMsg, "", nil, time.Now())
+}
+
+// cancelMatrix cancela una ejecución de matriz y espera a que se cancelen sus combinaciones
+// en curso. Devuelve false si la ejecución no es de matriz.
+func (s *TaskServiceImpl) cancelMatrix(ctx context.Context, executionID string) bool {
+	s.matricesMu.Lock()
+	run, ok := s.matrices[executionID]
+	s.matricesMu.Unlock()
+	if ok {
+		run.cancel()
+		select {
+		case <-run.done:
+		case <-ctx.Done():
+		}
+		return true
+	}
+
+	execution, err := s.getExecution(ctx, executionID)
+	if err != nil || execution.MatrixSize == 0 {
+		return false
+	}
+	// La matriz ya no está en curso: si no terminó (p.ej. se apagó el servicio) se marca.
+	s.applyTerminalStatus(ctx, executionID, entities.TaskCanceled, "", "", nil, time.Now())
+	return true
+}
+
+// validateMatrix comprueba la matriz de la tarea contra sus parámetros. Las tareas que
+// esperan una aprobación o datos del operador no admiten matriz.
+func validateMatrix(task *entities.DevOpsTask) error {
+	if task.Config.Matrix == nil {
+		return nil
+	}
+	if task.TaskType == entities.TaskTypeApproval || (task.TaskType == entities.TaskTypeManual && task.InputForm != nil) {
+		return fmt.Errorf("%w: matrix is not allowed in tasks that wait for approval or input", ErrInvalidTask)
+	}
+	if err := entities.ValidateMatrix(task.Config.Matrix, task.Parameters); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTask, err)
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	eventstream "devops_console/internal/infrastructure/orchestrator/events"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	ports "devops_console/internal/ports/orchestrator"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type MatrixTestSuite struct {
+	suite.Suite
+	service  *orchestrator2.TaskServiceImpl
+	repo     *adapters.InMemoryTaskRepository
+	executor *fakeExecutor
+	results  <-chan entities.TaskEvent
+}
+
+func (suite *MatrixTestSuite) SetupTest() {
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.executor = newFakeExecutor()
+	suite.executor.hold = true
+	suite.service = orchestrator2.NewTaskServiceImpl(suite.repo)
+	suite.service.RegisterExecutor("Stub", suite.executor)
+	suite.service.EventStream = eventstream.NewTaskEventStream()
+	results, err := suite.service.EventStream.Subscribe(ports.AllExecutions)
+	suite.Require().NoError(err)
+	suite.results = results
+}
+
+func (suite *MatrixTestSuite) TearDownTest() {
+	suite.service.Shutdown()
+}
+
+func (suite *MatrixTestSuite) createTask(matrix *entities.Matrix) string {
+	task, err := suite.service.CreateTask(context.Background(), entities.DevOpsTask{
+		ID:     "test",
+		Worker: &stubWorker{},
+		Config: entities.TaskConfig{Matrix: matrix},
+		Parameters: []entities.ParameterDefinition{
+			{Name: "go", Type: entities.ParameterString, Required: true},
+			{Name: "cluster", Type: entities.ParameterString, Enum: []string{"eu", "us", "asia"}},
+			{Name: "race", Type: entities.ParameterBoolean, Default: false},
+		},
+	})
+	suite.Require().NoError(err)
+	return task.ID
+}
+
+// finish termina la ejecución lanzada en la posición index (empezando en 1).
+func (suite *MatrixTestSuite) finish(index int, status entities.TaskStatus, errMsg string) {
+	suite.executor.finish(fmt.Sprintf("executor-%d", index), status, errMsg)
+}
+
+func (suite *MatrixTestSuite) waitForLaunched(count int) {
+	deadline := time.Now().Add(time.Second)
+	for len(suite.executor.launched()) < count {
+		if time.Now().After(deadline) {
+			suite.T().Fatalf("expected %d launched executions, got %v", count, suite.executor.launched())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (suite *MatrixTestSuite) wait(executionID string) entities.TaskExecution {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := suite.service.WaitForExecution(ctx, executionID)
+	suite.Require().NoError(err)
+	task, err := suite.repo.GetByExecutionID(context.Background(), executionID)
+	suite.Require().NoError(err)
+	for _, execution := range task.Executions {
+		if execution.ID == executionID {
+			return *execution
+		}
+	}
+	suite.T().Fatalf("execution %s not found", executionID)
+	return entities.TaskExecution{}
+}
+
+func (suite *MatrixTestSuite) TestMatrix_FansOutAndAggregatesResult() {
+	taskID := suite.createTask(&entities.Matrix{
+		Axes: []entities.MatrixAxis{
+			{Parameter: "go", Values: []interface{}{"1.21", "1.22"}},
+			{Parameter: "cluster", Values: []interface{}{"eu", "us"}},
+		},
+		Exclude: []map[string]interface{}{{"go": "1.21", "cluster": "us"}},
+		Include: []map[string]interface{}{{"go": "1.23", "cluster": "asia", "race": true}},
+	})
+
+	parentID, err := suite.service.ExecuteTaskWith(context.Background(), taskID, orchestrator2.ExecutionOptions{
+		Parameters: map[string]interface{}{"race": "true", "go": "1.20"},
+	})
+	suite.Require().NoError(err)
+	suite.waitForLaunched(4)
+	for i := 1; i <= 4; i++ {
+		suite.finish(i, entities.TaskSucceeded, "")
+	}
+
+	parent := suite.wait(parentID)
+	assert.Equal(suite.T(), entities.TaskSucceeded, parent.Status)
+	assert.Equal(suite.T(), 4, parent.MatrixSize)
+	suite.Require().Len(parent.ChildExecutionIDs, 4)
+	// Los valores de la combinación prevalecen sobre los de la petición.
+	assert.Equal(suite.T(), []map[string]interface{}{
+		{"go": "1.21", "cluster": "eu", "race": true},
+		{"go": "1.22", "cluster": "eu", "race": true},
+		{"go": "1.22", "cluster": "us", "race": true},
+		{"go": "1.23", "cluster": "asia", "race": true},
+	}, suite.executor.launched())
+	child := suite.wait(parent.ChildExecutionIDs[2])
+	assert.Equal(suite.T(), parentID, child.ParentExecutionID)
+	assert.Equal(suite.T(), map[string]interface{}{"go": "1.22", "cluster": "us"}, child.MatrixValues)
+
+	// Solo se publica el resultado de la matriz, no el de cada combinación.
+	select {
+	case event := <-suite.results:
+		assert.Equal(suite.T(), parentID, event.ExecutionID)
+		assert.Equal(suite.T(), entities.EventTypeTaskCompleted, event.EventType)
+	case <-time.After(time.Second):
+		suite.T().Fatal("matrix result not published")
+	}
+	select {
+	case event := <-suite.results:
+		suite.T().Fatalf("unexpected event %+v", event)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func (suite *MatrixTestSuite) TestMatrix_LimitsParallelCombinations() {
+	taskID := suite.createTask(&entities.Matrix{
+		Axes:        []entities.MatrixAxis{{Parameter: "go", Values: []interface{}{"1.21", "1.22", "1.23"}}},
+		MaxParallel: 2,
+	})
+
+	parentID, err := suite.service.ExecuteTask(context.Background(), taskID)
+	suite.Require().NoError(err)
+	suite.waitForLaunched(2)
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(suite.T(), suite.executor.launched(), 2)
+
+	suite.finish(1, entities.TaskFailed, "tests failed")
+	suite.waitForLaunched(3)
+	suite.finish(2, entities.TaskSucceeded, "")
+	suite.finish(3, entities.TaskSucceeded, "")
+
+	// Sin FailFast se lanzan todas las combinaciones aunque falle una.
+	parent := suite.wait(parentID)
+	assert.Equal(suite.T(), entities.TaskFailed, parent.Status)
+	assert.Equal(suite.T(), "1 of 3 combinations failed; first: go=1.21: tests failed", parent.Error)
+}
+
+func (suite *MatrixTestSuite) TestMatrix_FailFastCancelsRemainingCombinations() {
+	taskID := suite.createTask(&entities.Matrix{
+		Axes:        []entities.MatrixAxis{{Parameter: "go", Values: []interface{}{"1.21", "1.22", "1.23"}}},
+		MaxParallel: 2,
+		FailFast:    true,
+	})
+
+	parentID, err := suite.service.ExecuteTask(context.Background(), taskID)
+	suite.Require().NoError(err)
+	suite.waitForLaunched(2)
+	suite.finish(2, entities.TaskFailed, "tests failed")
+
+	parent := suite.wait(parentID)
+	assert.Equal(suite.T(), entities.TaskFailed, parent.Status)
+	assert.Equal(suite.T(), "1 of 3 combinations failed; first: go=1.22: tests failed", parent.Error)
+	assert.Len(suite.T(), suite.executor.launched(), 2)
+	assert.Equal(suite.T(), []string{"executor-1"}, suite.executor.canceledExecutions())
+	suite.Require().Len(parent.ChildExecutionIDs, 2)
+	assert.Equal(suite.T(), entities.TaskCanceled, suite.wait(parent.ChildExecutionIDs[0]).Status)
+}
+
+func (suite *MatrixTestSuite) TestMatrix_CancelCancelsCombinations() {
+	taskID := suite.createTask(&entities.Matrix{
+		Axes: []entities.MatrixAxis{{Parameter: "go", Values: []interface{}{"1.21", "1.22"}}},
+	})
+
+	parentID, err := suite.service.ExecuteTask(context.Background(), taskID)
+	suite.Require().NoError(err)
+	suite.waitForLaunched(2)
+	suite.finish(1, entities.TaskSucceeded, "")
+	running, err := suite.service.GetTask(context.Background(), taskID)
+	suite.Require().NoError(err)
+	children := running.Executions[0].ChildExecutionIDs
+	suite.Require().Len(children, 2)
+	assert.Equal(suite.T(), entities.TaskSucceeded, suite.wait(children[0]).Status)
+
+	suite.Require().NoError(suite.service.CancelTask(context.Background(), parentID))
+	assert.Equal(suite.T(), entities.TaskCanceled, suite.wait(parentID).Status)
+	assert.Equal(suite.T(), []string{"executor-2"}, suite.executor.canceledExecutions())
+	assert.Equal(suite.T(), entities.TaskCanceled, suite.wait(children[1]).Status)
+}
+
+func (suite *MatrixTestSuite) TestMatrix_ValidatesDefinitionAndValues() {
+	ctx := context.Background()
+	invalid := []entities.DevOpsTask{
+		{ID: "undefined", Worker: &stubWorker{}, Config: entities.TaskConfig{Matrix: &entities.Matrix{
+			Axes: []entities.MatrixAxis{{Parameter: "os", Values: []interface{}{"linux"}}},
+		}}},
+		{ID: "empty", Worker: &stubWorker{}, Parameters: []entities.ParameterDefinition{{Name: "go", Type: entities.ParameterString}},
+			Config: entities.TaskConfig{Matrix: &entities.Matrix{
+				Axes:    []entities.MatrixAxis{{Parameter: "go", Values: []interface{}{"1.22"}}},
+				Exclude: []map[string]interface{}{{"go": "1.22"}},
+			}}},
+		{ID: "approval", Worker: &stubWorker{}, TaskType: entities.TaskTypeApproval, Parameters: []entities.ParameterDefinition{{Name: "go", Type: entities.ParameterString}},
+			Config: entities.TaskConfig{Matrix: &entities.Matrix{
+				Axes: []entities.MatrixAxis{{Parameter: "go", Values: []interface{}{"1.22"}}},
+			}}},
+	}
+	for _, task := range invalid {
+		_, err := suite.service.CreateTask(ctx, task)
+		assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidTask, task.ID)
+	}
+
+	// Un valor de la petición que no cumple las definiciones no lanza ninguna combinación.
+	taskID := suite.createTask(&entities.Matrix{
+		Axes: []entities.MatrixAxis{{Parameter: "go", Values: []interface{}{"1.21", "1.22"}}},
+	})
+	_, err := suite.service.ExecuteTaskWith(ctx, taskID, orchestrator2.ExecutionOptions{
+		Parameters: map[string]interface{}{"cluster": "mars"},
+	})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidParameters)
+	task, err := suite.repo.GetByID(ctx, taskID)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), task.Executions)
+}
+
+func TestMatrixTestSuite(t *testing.T) {
+	suite.Run(t, new(MatrixTestSuite))
+}
+
+func TestMatrix_Combinations(t *testing.T) {
+	matrix := entities.Matrix{
+		Axes: []entities.MatrixAxis{
+			{Parameter: "go", Values: []interface{}{"1.21", "1.22"}},
+			{Parameter: "replicas", Values: []interface{}{1, 3}},
+		},
+		Exclude: []map[string]interface{}{{"replicas": "3", "go": "1.21"}},
+		Include: []map[string]interface{}{{"go": "1.22", "replicas": 1}, {"go": "tip"}},
+	}
+	var combinations []string
+	for _, combination := range matrix.Combinations() {
+		combinations = append(combinations, entities.FormatCombination(combination))
+	}
+	assert.Equal(t, []string{
+		"go=1.21, replicas=1",
+		"go=1.22, replicas=1",
+		"go=1.22, replicas=3",
+		"go=tip",
+	}, combinations)
+
+	definitions := []entities.ParameterDefinition{
+		{Name: "go", Type: entities.ParameterString},
+		{Name: "replicas", Type: entities.ParameterInteger},
+	}
+	assert.NoError(t, entities.ValidateMatrix(&matrix, definitions))
+
+	values := make([]interface{}, 17)
+	for i := range values {
+		values[i] = i
+	}
+	huge := entities.Matrix{Axes: []entities.MatrixAxis{
+		{Parameter: "go", Values: values},
+		{Parameter: "replicas", Values: values},
+	}}
+	assert.Error(t, entities.ValidateMatrix(&huge, definitions))
+}
@@ -0,0 +1,53 @@
+package orchestrator
+
+import "devops_console/internal/domain/entities/orchestrator"
+
+// rememberParameters guarda los valores reales de una ejecución para lanzarla desde la cola
+// o reintentarla, ya que la ejecución persistida solo guarda los secretos enmascarados.
+func (s *TaskServiceImpl) rememberParameters(executionID string, parameters map[string]interface{}) {
+	if len(parameters) == 0 {
+		return
+	}
+	s.parametersMu.Lock()
+	defer s.parametersMu.Unlock()
+	s.runParameters[executionID] = parameters
+}
+
+func (s *TaskServiceImpl) parametersOf(executionID string) map[string]interface{} {
+	s.parametersMu.Lock()
+	defer s.parametersMu.Unlock()
+	return s.runParameters[executionID]
+}
+
+func (s *TaskServiceImpl) forgetParameters(executionID string) {
+	s.parametersMu.Lock()
+	defer s.parametersMu.Unlock()
+	delete(s.runParameters, executionID)
+}
+
+// withParameters devuelve una copia de la tarea con los valores de la ejecución en
+// Config.Parameters, que es donde los leen los executors.
+func withParameters(task entities.DevOpsTask, parameters map[string]interface{}) entities.DevOpsTask {
+	if len(parameters) == 0 {
+		return task
+	}
+	merged := make(map[string]interface{}, len(task.Config.Parameters)+len(parameters))
+	for name, value := range task.Config.Parameters {
+		merged[name] = value
+	}
+	for name, value := range parameters {
+		merged[name] = value
+	}
+	task.Config.Parameters = merged
+	return task
+}
+
+// renderedSpec repite la sustitución de plantillas que hace el executor, con los secretos
+// enmascarados, para guardar en la ejecución lo que se lanzó.
+func renderedSpec(task *entities.DevOpsTask, executorID string) *entities.WorkerSpec {
+	spec, err := entities.RenderWorkerSpec(task.Worker.GetDetails(), entities.NewTemplateContext(task, executorID, true))
+	if err != nil {
+		return nil
+	}
+	return &spec
+}
@@ -0,0 +1,201 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// detailsWorker es un worker "Stub" con detalles configurables.
+type detailsWorker struct {
+	details map[string]interface{}
+}
+
+func (w *detailsWorker) GetID() string                      { return "details-worker" }
+func (w *detailsWorker) GetType() string                    { return "Stub" }
+func (w *detailsWorker) GetDetails() map[string]interface{} { return w.details }
+
+type ExecutionParametersTestSuite struct {
+	suite.Suite
+	service  *orchestrator2.TaskServiceImpl
+	repo     *adapters.InMemoryTaskRepository
+	executor *fakeExecutor
+}
+
+func (suite *ExecutionParametersTestSuite) SetupTest() {
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.executor = newFakeExecutor()
+	suite.service = orchestrator2.NewTaskServiceImpl(suite.repo)
+	suite.service.RegisterExecutor("Stub", suite.executor)
+}
+
+func (suite *ExecutionParametersTestSuite) createTask(retry *entities.RetryPolicy) string {
+	task, err := suite.service.CreateTask(context.Background(), entities.DevOpsTask{
+		ID:     "deploy",
+		Worker: &stubWorker{},
+		Config: entities.TaskConfig{Retry: retry},
+		Parameters: []entities.ParameterDefinition{
+			{Name: "environment", Type: entities.ParameterString, Required: true, Enum: []string{"staging", "production"}},
+			{Name: "replicas", Type: entities.ParameterInteger, Default: 2},
+			{Name: "version", Type: entities.ParameterString, Pattern: `^v\d+\.\d+\.\d+$`},
+			{Name: "token", Type: entities.ParameterString, Secret: true},
+		},
+	})
+	suite.Require().NoError(err)
+	return task.ID
+}
+
+func (suite *ExecutionParametersTestSuite) wait(executionID string) entities.TaskStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	status, err := suite.service.WaitForExecution(ctx, executionID)
+	suite.Require().NoError(err)
+	return status
+}
+
+func (suite *ExecutionParametersTestSuite) TestExecuteTaskWith_RecordsMaskedParameters() {
+	taskID := suite.createTask(nil)
+
+	executionID, err := suite.service.ExecuteTaskWith(context.Background(), taskID, orchestrator2.ExecutionOptions{
+		Parameters: map[string]interface{}{"environment": "staging", "version": "v1.2.3", "token": "s3cr3t"},
+	})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.TaskSucceeded, suite.wait(executionID))
+
+	task, err := suite.repo.GetByExecutionID(context.Background(), executionID)
+	suite.Require().NoError(err)
+	suite.Require().Len(task.Executions, 1)
+	assert.Equal(suite.T(), map[string]interface{}{
+		"environment": "staging",
+		"replicas":    int64(2),
+		"version":     "v1.2.3",
+		"token":       entities.MaskedParameterValue,
+	}, task.Executions[0].Parameters)
+
+	launched := suite.executor.launched()
+	suite.Require().Len(launched, 1)
+	assert.Equal(suite.T(), "s3cr3t", launched[0]["token"])
+	assert.Equal(suite.T(), int64(2), launched[0]["replicas"])
+}
+
+func (suite *ExecutionParametersTestSuite) TestExecuteTaskWith_RejectsInvalidValues() {
+	taskID := suite.createTask(nil)
+
+	cases := map[string]map[string]interface{}{
+		"missing required": {"replicas": 3},
+		"not in enum":      {"environment": "qa"},
+		"pattern mismatch": {"environment": "staging", "version": "latest"},
+		"wrong type":       {"environment": "staging", "replicas": "many"},
+		"unknown":          {"environment": "staging", "region": "eu"},
+	}
+	for name, parameters := range cases {
+		_, err := suite.service.ExecuteTaskWith(context.Background(), taskID, orchestrator2.ExecutionOptions{Parameters: parameters})
+		assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidParameters, name)
+	}
+	assert.Empty(suite.T(), suite.executor.executedTasks())
+}
+
+func (suite *ExecutionParametersTestSuite) TestRetry_ReusesParameters() {
+	taskID := suite.createTask(&entities.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond})
+	suite.executor.failures[taskID] = []entities.FailureClass{entities.FailureInfrastructure}
+
+	executionID, err := suite.service.ExecuteTaskWith(context.Background(), taskID, orchestrator2.ExecutionOptions{
+		Parameters: map[string]interface{}{"environment": "production", "token": "s3cr3t"},
+	})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.TaskSucceeded, suite.wait(executionID))
+
+	launched := suite.executor.launched()
+	suite.Require().Len(launched, 2)
+	assert.Equal(suite.T(), launched[0], launched[1])
+	assert.Equal(suite.T(), "s3cr3t", launched[1]["token"])
+}
+
+func (suite *ExecutionParametersTestSuite) TestCreateTask_ValidatesDefinitions() {
+	invalid := [][]entities.ParameterDefinition{
+		{{Name: "count", Type: "DATE"}},
+		{{Name: "count", Type: entities.ParameterInteger}, {Name: "count", Type: entities.ParameterString}},
+		{{Name: "version", Type: entities.ParameterString, Pattern: "("}},
+		{{Name: "replicas", Type: entities.ParameterInteger, Default: "two"}},
+		{{Name: "environment", Type: entities.ParameterString, Enum: []string{"staging"}, Default: "production"}},
+	}
+	for _, definitions := range invalid {
+		_, err := suite.service.CreateTask(context.Background(), entities.DevOpsTask{Worker: &stubWorker{}, Parameters: definitions})
+		assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidTask)
+	}
+}
+
+func (suite *ExecutionParametersTestSuite) TestExecuteTaskWith_StoresRenderedSpec() {
+	task, err := suite.service.CreateTask(context.Background(), entities.DevOpsTask{
+		ID:        "release",
+		Name:      "release",
+		Workspace: entities.Workspace{ID: "team-a"},
+		Worker: &detailsWorker{details: map[string]interface{}{
+			"Image":       "registry/app:${{ params.version }}",
+			"Command":     []string{"deploy", "--workspace=${{ workspace.id }}"},
+			"Args":        []string{"--run=${{ execution.id }}"},
+			"WorkingDir":  "/srv/${{ task.name }}",
+			"Environment": map[string]string{"TOKEN": "${{ secrets.token }}"},
+		}},
+		Parameters: []entities.ParameterDefinition{
+			{Name: "version", Type: entities.ParameterString, Default: "v1"},
+			{Name: "token", Type: entities.ParameterString, Secret: true},
+		},
+	})
+	suite.Require().NoError(err)
+
+	executionID, err := suite.service.ExecuteTaskWith(context.Background(), task.ID, orchestrator2.ExecutionOptions{
+		Parameters: map[string]interface{}{"token": "s3cr3t"},
+	})
+	suite.Require().NoError(err)
+	suite.wait(executionID)
+
+	stored, err := suite.repo.GetByExecutionID(context.Background(), executionID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), &entities.WorkerSpec{
+		Image:      "registry/app:v1",
+		Command:    []string{"deploy", "--workspace=team-a"},
+		Args:       []string{"--run=" + executionID},
+		WorkingDir: "/srv/release",
+		Env:        map[string]string{"TOKEN": entities.MaskedParameterValue},
+	}, stored.Executions[0].RenderedSpec)
+
+	// Sin el secreto la plantilla no se puede resolver y la ejecución no se lanza.
+	_, err = suite.service.ExecuteTaskWith(context.Background(), task.ID, orchestrator2.ExecutionOptions{})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidTask)
+	assert.Len(suite.T(), suite.executor.executedTasks(), 1)
+}
+
+func TestExecutionParametersTestSuite(t *testing.T) {
+	suite.Run(t, new(ExecutionParametersTestSuite))
+}
+
+func TestTemplateContext_Render(t *testing.T) {
+	variables := entities.TemplateContext{"params.env": "prod", "execution.id": "42"}
+
+	rendered, err := variables.Render("deploy-${{params.env}}-${{ execution.id }}")
+	assert.NoError(t, err)
+	assert.Equal(t, "deploy-prod-42", rendered)
+
+	_, err = variables.Render("${{ params.region }} ${{ secrets.token }}")
+	assert.ErrorIs(t, err, entities.ErrUndefinedTemplateVariable)
+	assert.Contains(t, err.Error(), "params.region, secrets.token")
+}
+
+func TestSecretParameterValues_AreTheEnvironmentValues(t *testing.T) {
+	task := entities.DevOpsTask{
+		Parameters: []entities.ParameterDefinition{
+			{Name: "token", Type: entities.ParameterString, Secret: true},
+			{Name: "pin", Type: entities.ParameterInteger, Secret: true},
+			{Name: "region", Type: entities.ParameterString},
+		},
+		Config: entities.TaskConfig{Parameters: map[string]interface{}{"token": "s3cr3t", "pin": 1234, "region": "eu-west-1"}},
+	}
+	assert.ElementsMatch(t, []string{"s3cr3t", "1234"}, task.SecretParameterValues())
+	assert.Contains(t, entities.ParameterEnv(task.ParameterValues()), "PIN=1234")
+}
@@ -0,0 +1,310 @@
+package orchestrator
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+var ErrExecutionNotQueued = errors.New("execution not queued")
+
+// ConcurrencyLimits limita las ejecuciones simultáneas. Un límite 0 significa sin límite.
+// Workspaces y Tenants permiten sobrescribir PerWorkspace y PerTenant para IDs concretos.
+type ConcurrencyLimits struct {
+	PerTask      int
+	PerWorkspace int
+	PerTenant    int
+	Workspaces   map[string]int
+	Tenants      map[string]int
+}
+
+func (l ConcurrencyLimits) workspaceLimit(workspaceID string) int {
+	if limit, ok := l.Workspaces[workspaceID]; ok {
+		return limit
+	}
+	return l.PerWorkspace
+}
+
+func (l ConcurrencyLimits) tenantLimit(tenantID string) int {
+	if limit, ok := l.Tenants[tenantID]; ok {
+		return limit
+	}
+	return l.PerTenant
+}
+
+type queuedExecution struct {
+	executionID string
+	taskID      string
+	workspaceID string
+	tenantID    string
+	priority    int
+}
+
+// executionQueue ordena las ejecuciones pendientes por prioridad y, a igual prioridad, por
+// orden de llegada, y lleva la cuenta de las que están en marcha para aplicar los límites.
+type executionQueue struct {
+	limits       ConcurrencyLimits
+	pending      []*queuedExecution
+	running      map[string]*queuedExecution
+	perTask      map[string]int
+	perWorkspace map[string]int
+	perTenant    map[string]int
+	mu           sync.Mutex
+}
+
+func newExecutionQueue(limits ConcurrencyLimits) *executionQueue {
+	return &executionQueue{
+		limits:       limits,
+		running:      make(map[string]*queuedExecution),
+		perTask:      make(map[string]int),
+		perWorkspace: make(map[string]int),
+		perTenant:    make(map[string]int),
+	}
+}
+
+// push inserta la ejecución detrás de todas las de prioridad igual o mayor.
+func (q *executionQueue) push(item *queuedExecution) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := len(q.pending)
+	for i > 0 && q.pending[i-1].priority < item.priority {
+		i--
+	}
+	q.pending = append(q.pending, nil)
+	copy(q.pending[i+1:], q.pending[i:])
+	q.pending[i] = item
+}
+
+// pop saca, en orden, las ejecuciones que caben en los límites y las marca en marcha. Una
+// ejecución bloqueada por su límite no impide lanzar las siguientes de otros ámbitos.
+func (q *executionQueue) pop() []*queuedExecution {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var ready []*queuedExecution
+	remaining := q.pending[:0]
+	for _, item := range q.pending {
+		if !q.fits(item) {
+			remaining = append(remaining, item)
+			continue
+		}
+		q.running[item.executionID] = item
+		q.perTask[item.taskID]++
+		q.perWorkspace[item.workspaceID]++
+		q.perTenant[item.tenantID]++
+		ready = append(ready, item)
+	}
+	q.pending = remaining
+	return ready
+}
+
+func (q *executionQueue) fits(item *queuedExecution) bool {
+	if limit := q.limits.PerTask; limit > 0 && q.perTask[item.taskID] >= limit {
+		return false
+	}
+	if limit := q.limits.workspaceLimit(item.workspaceID); limit > 0 && q.perWorkspace[item.workspaceID] >= limit {
+		return false
+	}
+	if limit := q.limits.tenantLimit(item.tenantID); limit > 0 && q.perTenant[item.tenantID] >= limit {
+		return false
+	}
+	return true
+}
+
+// release libera el hueco de una ejecución en marcha. Devuelve false si no lo ocupaba.
+func (q *executionQueue) release(executionID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	item, ok := q.running[executionID]
+	if !ok {
+		return false
+	}
+	delete(q.running, executionID)
+	q.perTask[item.taskID]--
+	q.perWorkspace[item.workspaceID]--
+	q.perTenant[item.tenantID]--
+	return true
+}
+
+// remove saca de la cola una ejecución pendiente. Devuelve false si no estaba pendiente.
+func (q *executionQueue) remove(executionID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, item := range q.pending {
+		if item.executionID == executionID {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// positions devuelve los IDs pendientes en orden de salida.
+func (q *executionQueue) positions() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	ids := make([]string, len(q.pending))
+	for i, item := range q.pending {
+		ids[i] = item.executionID
+	}
+	return ids
+}
+
+// SetConcurrencyLimits activa la cola de ejecución: a partir de ese momento ExecuteTask
+// deja las ejecuciones en TaskPending hasta que haya hueco dentro de los límites.
+func (s *TaskServiceImpl) SetConcurrencyLimits(limits ConcurrencyLimits) {
+	s.queue = newExecutionQueue(limits)
+}
+
+// GetQueuePosition devuelve la posición (empezando en 1) de una ejecución en la cola. Como
+// GetTaskStatus, solo responde por ejecuciones visibles para quien pregunta.
+func (s *TaskServiceImpl) GetQueuePosition(ctx context.Context, executionID string) (int, error) {
+	if err := s.authorizeExecution(ctx, nil, entities.PermissionView, executionID); err != nil {
+		return 0, err
+	}
+	if _, err := s.getExecution(ctx, executionID); err != nil {
+		return 0, err
+	}
+	if s.queue != nil {
+		for i, id := range s.queue.positions() {
+			if id == executionID {
+				return i + 1, nil
+			}
+		}
+	}
+	return 0, ErrExecutionNotQueued
+}
+
+// enqueue registra la ejecución como pendiente y la pone en la cola.
+func (s *TaskServiceImpl) enqueue(ctx context.Context, task *entities.DevOpsTask, attempt entities.TaskExecution, parameters map[string]interface{}) (string, error) {
+	execution := attempt
+	execution.ID = s.GenerateID()
+	execution.DevOpsTaskID = task.ID
+	execution.Status = entities.TaskPending
+	execution.StartedAt = time.Now()
+	if err := s.appendExecution(ctx, task.ID, &execution); err != nil {
+		return "", err
+	}
+	s.rememberParameters(execution.ID, parameters)
+
+	s.queue.push(&queuedExecution{
+		executionID: execution.ID,
+		taskID:      task.ID,
+		workspaceID: task.Workspace.ID,
+		tenantID:    task.Workspace.TenantID,
+		priority:    task.Priority,
+	})
+	s.dispatchQueued()
+	return execution.ID, nil
+}
+
+// dispatchQueued lanza las ejecuciones que caben y avisa del resto de su nueva posición.
+func (s *TaskServiceImpl) dispatchQueued() {
+	for _, item := range s.queue.pop() {
+		go s.runQueued(item)
+	}
+	s.publishQueuePositions()
+}
+
+// runQueued lanza una ejecución que sale de la cola. No hay petición detrás: la lanza el
+// final de otra, así que cuelga del contexto del servicio.
+func (s *TaskServiceImpl) runQueued(item *queuedExecution) {
+	ctx := s.lifetime
+	err := s.startQueued(ctx, item)
+	if err != nil {
+		log.Printf("Error starting queued execution %s: %v", item.executionID, err)
+		s.applyTerminalStatus(ctx, item.executionID, entities.TaskError, err.Error(), entities.FailureInfrastructure, nil, time.Now())
+	}
+}
+
+func (s *TaskServiceImpl) startQueued(ctx context.Context, item *queuedExecution) error {
+	task, err := s.repository.GetByID(ctx, item.taskID)
+	if err != nil {
+		return err
+	}
+	executor, ok := s.executors[task.Worker.GetType()]
+	if !ok {
+		return errors.New("unsupported worker type")
+	}
+
+	queued, err := s.getExecution(ctx, item.executionID)
+	if err != nil {
+		return err
+	}
+	if s.Metrics != nil {
+		s.Metrics.ExecutionDequeued(task.Worker.GetType(), task.Workspace.ID, time.Since(queued.StartedAt))
+	}
+	runTask := withParameters(task, s.parametersOf(item.executionID))
+	inputs, err := s.resolveInputs(ctx, &task, queued.UpstreamExecutionID)
+	if err != nil {
+		return err
+	}
+	runTask.Config.Inputs = inputs
+	executorID, err := executor.ExecuteTask(ctx, &runTask)
+	if err != nil {
+		return err
+	}
+	events, err := executor.SubscribeToTaskEvents(executorID)
+	if err != nil {
+		log.Printf("Error subscribing to events of execution %s: %v", item.executionID, err)
+	}
+
+	err = s.updateExecution(ctx, item.executionID, func(execution *entities.TaskExecution) {
+		execution.TaskExecutorID = executorID
+		execution.Status = entities.TaskRunning
+		execution.StartedAt = time.Now()
+		execution.RenderedSpec = renderedSpec(&runTask, executorID)
+		execution.Inputs = inputs
+		// La tarea pudo cambiar mientras la ejecución esperaba en la cola.
+		execution.TaskRevision = task.Revision
+	})
+	if err != nil {
+		return fmt.Errorf("registering executor execution %s: %w", executorID, err)
+	}
+	if s.Metrics != nil {
+		s.Metrics.ExecutionStarted(task.Worker.GetType(), task.Workspace.ID)
+	}
+
+	if events != nil {
+		go s.reconcileExecution(ctx, executor, item.executionID, executorID, events)
+	}
+	return nil
+}
+
+// releaseSlot libera el hueco de una ejecución terminada y lanza las siguientes.
+func (s *TaskServiceImpl) releaseSlot(executionID string) {
+	if s.queue != nil && s.queue.release(executionID) {
+		s.dispatchQueued()
+	}
+}
+
+// dequeue saca de la cola una ejecución pendiente y avisa al resto de su nueva posición.
+func (s *TaskServiceImpl) dequeue(executionID string) bool {
+	if s.queue == nil || !s.queue.remove(executionID) {
+		return false
+	}
+	s.publishQueuePositions()
+	return true
+}
+
+func (s *TaskServiceImpl) publishQueuePositions() {
+	if s.EventStream == nil {
+		return
+	}
+	pending := s.queue.positions()
+	for i, executionID := range pending {
+		event := entities.TaskEvent{
+			ID:          s.GenerateID(),
+			ExecutionID: executionID,
+			Timestamp:   time.Now(),
+			EventType:   entities.EventTypeTaskQueued,
+			Payload:     entities.TaskQueuePayload{Position: i + 1, QueueLength: len(pending)},
+		}
+		if err := s.EventStream.Publish(event); err != nil {
+			log.Printf("Error publishing queue position of execution %s: %v", executionID, err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	eventstream "devops_console/internal/infrastructure/orchestrator/events"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	ports "devops_console/internal/ports/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type ExecutionQueueTestSuite struct {
+	suite.Suite
+	service  *orchestrator2.TaskServiceImpl
+	repo     *adapters.InMemoryTaskRepository
+	executor *fakeExecutor
+}
+
+func (suite *ExecutionQueueTestSuite) SetupTest() {
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.executor = newFakeExecutor()
+	suite.executor.hold = true
+	suite.service = orchestrator2.NewTaskServiceImpl(suite.repo)
+	suite.service.RegisterExecutor("Stub", suite.executor)
+	suite.service.EventStream = eventstream.NewTaskEventStream()
+	suite.service.SetConcurrencyLimits(orchestrator2.ConcurrencyLimits{PerWorkspace: 1})
+}
+
+func (suite *ExecutionQueueTestSuite) createTask(id, workspaceID string, priority int) {
+	task := &entities.DevOpsTask{
+		ID:        id,
+		Worker:    &stubWorker{},
+		Workspace: entities.Workspace{ID: workspaceID, TenantID: "acme"},
+		Priority:  priority,
+	}
+	suite.Require().NoError(suite.repo.Create(context.Background(), task))
+}
+
+func (suite *ExecutionQueueTestSuite) waitForStarted(count int) {
+	deadline := time.Now().Add(time.Second)
+	for len(suite.executor.executedTasks()) < count {
+		if time.Now().After(deadline) {
+			suite.T().Fatalf("expected %d started executions, got %v", count, suite.executor.executedTasks())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (suite *ExecutionQueueTestSuite) TestQueue_LimitsPerWorkspaceAndOrdersByPriority() {
+	suite.createTask("build", "team-a", 0)
+	suite.createTask("low", "team-a", 0)
+	suite.createTask("urgent", "team-a", 10)
+	suite.createTask("other", "team-b", 0)
+
+	first, err := suite.service.ExecuteTask(context.Background(), "build")
+	suite.Require().NoError(err)
+	low, err := suite.service.ExecuteTask(context.Background(), "low")
+	suite.Require().NoError(err)
+	urgent, err := suite.service.ExecuteTask(context.Background(), "urgent")
+	suite.Require().NoError(err)
+	_, err = suite.service.ExecuteTask(context.Background(), "other")
+	suite.Require().NoError(err)
+
+	// team-b no está bloqueado por la cola de team-a.
+	suite.waitForStarted(2)
+	assert.ElementsMatch(suite.T(), []string{"build", "other"}, suite.executor.executedTasks())
+
+	status, err := suite.service.GetTaskStatus(context.Background(), low)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.TaskPending, status)
+	position, err := suite.service.GetQueuePosition(context.Background(), urgent)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 1, position)
+	position, err = suite.service.GetQueuePosition(context.Background(), low)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 2, position)
+
+	lowEvents, err := suite.service.SubscribeToTaskEvents(context.Background(), low)
+	suite.Require().NoError(err)
+
+	firstExecution, err := suite.repo.GetByExecutionID(context.Background(), first)
+	suite.Require().NoError(err)
+	suite.executor.finish(firstExecution.Executions[0].TaskExecutorID, entities.TaskSucceeded, "")
+
+	suite.waitForStarted(3)
+	assert.Equal(suite.T(), "urgent", suite.executor.executedTasks()[2])
+
+	select {
+	case event := <-lowEvents:
+		assert.Equal(suite.T(), entities.EventTypeTaskQueued, event.EventType)
+		assert.Equal(suite.T(), entities.TaskQueuePayload{Position: 1, QueueLength: 1}, event.Payload)
+	case <-time.After(time.Second):
+		suite.T().Fatal("queue position event was not published")
+	}
+}
+
+func (suite *ExecutionQueueTestSuite) TestQueue_CancelPendingExecution() {
+	suite.createTask("build", "team-a", 0)
+	suite.createTask("deploy", "team-a", 0)
+
+	_, err := suite.service.ExecuteTask(context.Background(), "build")
+	suite.Require().NoError(err)
+	pending, err := suite.service.ExecuteTask(context.Background(), "deploy")
+	suite.Require().NoError(err)
+	suite.waitForStarted(1)
+
+	suite.Require().NoError(suite.service.CancelTask(context.Background(), pending))
+
+	status, err := suite.service.GetTaskStatus(context.Background(), pending)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.TaskCanceled, status)
+	_, err = suite.service.GetQueuePosition(context.Background(), pending)
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrExecutionNotQueued)
+}
+
+func (suite *ExecutionQueueTestSuite) TestGetQueuePosition_HidesExecutionsOutOfScope() {
+	suite.createTask("build", "team-a", 0)
+	suite.createTask("deploy", "team-a", 0)
+
+	_, err := suite.service.ExecuteTask(context.Background(), "build")
+	suite.Require().NoError(err)
+	pending, err := suite.service.ExecuteTask(context.Background(), "deploy")
+	suite.Require().NoError(err)
+	suite.waitForStarted(1)
+
+	globex := ports.WithScope(context.Background(), entities.Scope{TenantID: "globex"})
+	_, err = suite.service.GetQueuePosition(globex, pending)
+	assert.EqualError(suite.T(), err, "task not found")
+
+	acme := ports.WithScope(context.Background(), entities.Scope{TenantID: "acme", WorkspaceID: "team-a"})
+	position, err := suite.service.GetQueuePosition(acme, pending)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 1, position)
+}
+
+func TestExecutionQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(ExecutionQueueTestSuite))
+}
@@ -0,0 +1,316 @@
+package orchestrator
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"fmt"
+	"log"
+	"time"
+)
+
+// reconcileExecution consume los eventos que publica el executor para una ejecución y
+// persiste en el repositorio su estado final, la hora de fin, el error y los detalles
+// propios del executor (nombre del pod, ID del contenedor...). executorID es el ID que
+// asignó el executor, que difiere de executionID cuando la ejecución pasó por la cola.
+// Deja de reconciliar cuando ctx termina, es decir, al apagar el servicio.
+func (s *TaskServiceImpl) reconcileExecution(ctx context.Context, executor ports.TaskExecutor, executionID, executorID string, events <-chan entities.TaskEvent) {
+	// El estado terminal pudo publicarse antes de que nos suscribiéramos.
+	if status, err := executor.GetTaskStatus(ctx, executorID); err == nil && status.IsTerminal() {
+		if !s.drainBufferedEvents(ctx, executionID, events) {
+			s.applyTerminalStatus(ctx, executionID, status, "", "", nil, time.Now())
+		}
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				// El canal se cerró sin evento terminal: consultamos al executor por última vez.
+				if status, err := executor.GetTaskStatus(ctx, executorID); err == nil && status.IsTerminal() {
+					s.applyTerminalStatus(ctx, executionID, status, "", "", nil, time.Now())
+				}
+				return
+			}
+			if s.applyEvent(ctx, executionID, event) {
+				return
+			}
+		}
+	}
+}
+
+// drainBufferedEvents aplica los eventos ya encolados en el canal sin bloquearse.
+// Devuelve true si alguno de ellos era terminal.
+func (s *TaskServiceImpl) drainBufferedEvents(ctx context.Context, executionID string, events <-chan entities.TaskEvent) bool {
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			if s.applyEvent(ctx, executionID, event) {
+				return true
+			}
+		default:
+			return false
+		}
+	}
+}
+
+// applyEvent refleja un evento del executor en la ejecución persistida.
+// Devuelve true cuando el evento es terminal.
+func (s *TaskServiceImpl) applyEvent(ctx context.Context, executionID string, event entities.TaskEvent) bool {
+	if event.EventType == entities.EventTypePodName {
+		if podName, ok := event.Payload.(string); ok {
+			s.mergeExecutionDetails(ctx, executionID, map[string]interface{}{"PodName": podName})
+		}
+		return false
+	}
+	if event.EventType == entities.EventTypeTaskInputRequired {
+		s.awaitInput(ctx, executionID)
+		return false
+	}
+
+	status, terminal := entities.StatusFromEventType(event.EventType)
+	if !terminal {
+		return false
+	}
+
+	var errMsg string
+	var class entities.FailureClass
+	var details map[string]interface{}
+	var artifacts []entities.Artifact
+	switch payload := event.Payload.(type) {
+	case entities.TaskProgressPayload:
+		if payload.Status != "" {
+			status = payload.Status
+		}
+		errMsg = payload.Error
+		class = payload.FailureClass
+		details = payload.ExecutionDetails
+		artifacts = payload.Artifacts
+	case string:
+		if status != entities.TaskSucceeded {
+			errMsg = payload
+		}
+	}
+
+	finishedAt := event.Timestamp
+	if finishedAt.IsZero() {
+		finishedAt = time.Now()
+	}
+	s.attachArtifacts(ctx, executionID, artifacts)
+	s.applyTerminalStatus(ctx, executionID, status, errMsg, class, details, finishedAt)
+	return true
+}
+
+func (s *TaskServiceImpl) applyTerminalStatus(ctx context.Context, executionID string, status entities.TaskStatus, errMsg string, class entities.FailureClass, details map[string]interface{}, finishedAt time.Time) {
+	var retryDelay time.Duration
+	applied := false
+	// Solo se miden las ejecuciones que llegaron a lanzarse en un executor.
+	var workerType, workspaceID string
+	var duration time.Duration
+	err := s.updateTask(ctx, executionID, func(task *entities.DevOpsTask, execution *entities.TaskExecution) error {
+		// Un estado terminal no se sobrescribe (p.ej. una cancelación seguida del fallo del contenedor).
+		if execution.Status.IsTerminal() {
+			return nil
+		}
+		applied = true
+		execution.Status = status
+		execution.FinishedAt = finishedAt
+		if errMsg != "" {
+			execution.Error = errMsg
+		}
+		if class != "" {
+			execution.FailureClass = class
+		}
+		mergeDetails(execution, details)
+		retryDelay = s.planRetry(task, execution)
+		if execution.TaskExecutorID != "" && task.Worker != nil {
+			workerType, workspaceID = task.Worker.GetType(), task.Workspace.ID
+			duration = finishedAt.Sub(execution.StartedAt)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error reconciling execution %s: %v", executionID, err)
+		return
+	}
+	if s.Metrics != nil && workerType != "" {
+		s.Metrics.ExecutionFinished(workerType, workspaceID, status, duration)
+	}
+	if retryDelay > 0 {
+		s.afterFunc(retryDelay, func(ctx context.Context) { s.launchRetry(ctx, executionID) })
+	} else {
+		s.forgetParameters(executionID)
+	}
+	s.releaseSlot(executionID)
+	s.notifyFinished(executionID)
+	if applied && retryDelay == 0 {
+		s.publishResult(ctx, executionID)
+	}
+}
+
+// publishResult publica en EventStream el evento terminal definitivo de la ejecución, con
+// la tarea y el workspace, para quien reacciona al final de las tareas (p.ej. el encadenamiento).
+func (s *TaskServiceImpl) publishResult(ctx context.Context, executionID string) {
+	if s.EventStream == nil {
+		return
+	}
+	execution, err := s.getExecution(ctx, executionID)
+	if err != nil {
+		log.Printf("Error publishing result of execution %s: %v", executionID, err)
+		return
+	}
+	// El resultado de una combinación solo cuenta a través del de su matriz.
+	if execution.ParentExecutionID != "" {
+		return
+	}
+	task, err := s.repository.GetByID(ctx, execution.DevOpsTaskID)
+	if err != nil {
+		log.Printf("Error publishing result of execution %s: %v", executionID, err)
+		return
+	}
+	event := entities.TaskEvent{
+		ID:          s.GenerateID(),
+		ExecutionID: executionID,
+		Timestamp:   execution.FinishedAt,
+		EventType:   entities.EventTypeFromStatus(execution.Status),
+		Payload: entities.TaskResultPayload{
+			TaskID:      task.ID,
+			WorkspaceID: task.Workspace.ID,
+			Status:      execution.Status,
+			Error:       execution.Error,
+		},
+	}
+	if err := s.EventStream.Publish(event); err != nil {
+		log.Printf("Error publishing result of execution %s: %v", executionID, err)
+	}
+}
+
+// afterFunc llama a f con el contexto del servicio pasado delay, salvo que el servicio se
+// haya apagado entretanto.
+func (s *TaskServiceImpl) afterFunc(delay time.Duration, f func(ctx context.Context)) {
+	time.AfterFunc(delay, func() {
+		if s.lifetime.Err() == nil {
+			f(s.lifetime)
+		}
+	})
+}
+
+func (s *TaskServiceImpl) mergeExecutionDetails(ctx context.Context, executionID string, details map[string]interface{}) {
+	err := s.updateExecution(ctx, executionID, func(execution *entities.TaskExecution) {
+		mergeDetails(execution, details)
+	})
+	if err != nil {
+		log.Printf("Error reconciling execution %s: %v", executionID, err)
+	}
+}
+
+// updateExecution aplica update sobre la ejecución indicada y persiste la tarea.
+func (s *TaskServiceImpl) updateExecution(ctx context.Context, executionID string, update func(execution *entities.TaskExecution)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.repository.GetByExecutionID(ctx, executionID)
+	if err != nil {
+		return err
+	}
+	for _, execution := range task.Executions {
+		if execution.ID == executionID {
+			update(execution)
+			return s.repository.Update(ctx, &task)
+		}
+	}
+	return fmt.Errorf("execution %s not found", executionID)
+}
+
+// updateTask aplica update sobre la tarea y la ejecución indicada y persiste la tarea si
+// update no devuelve error.
+func (s *TaskServiceImpl) updateTask(ctx context.Context, executionID string, update func(task *entities.DevOpsTask, execution *entities.TaskExecution) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.repository.GetByExecutionID(ctx, executionID)
+	if err != nil {
+		return err
+	}
+	for _, execution := range task.Executions {
+		if execution.ID == executionID {
+			if err := update(&task, execution); err != nil {
+				return err
+			}
+			return s.repository.Update(ctx, &task)
+		}
+	}
+	return fmt.Errorf("execution %s not found", executionID)
+}
+
+func mergeDetails(execution *entities.TaskExecution, details map[string]interface{}) {
+	if len(details) == 0 {
+		return
+	}
+	if execution.ExecutionDetails == nil {
+		execution.ExecutionDetails = make(map[string]interface{}, len(details))
+	}
+	for key, value := range details {
+		execution.ExecutionDetails[key] = value
+	}
+}
+
+// WaitForExecution bloquea hasta que la ejecución alcanza un estado terminal o el
+// contexto termina, y devuelve el estado final persistido. Si la ejecución se reintenta,
+// espera al último intento.
+func (s *TaskServiceImpl) WaitForExecution(ctx context.Context, executionID string) (entities.TaskStatus, error) {
+	if err := s.authorizeExecution(ctx, nil, entities.PermissionView, executionID); err != nil {
+		return "", err
+	}
+	for {
+		// Obtenemos el canal antes de consultar el estado para no perder la notificación.
+		finished := s.finishedChannel(executionID)
+
+		execution, err := s.getExecution(ctx, executionID)
+		if err != nil {
+			s.notifyFinished(executionID)
+			return "", err
+		}
+		if execution.NextAttemptID != "" {
+			s.notifyFinished(executionID)
+			executionID = execution.NextAttemptID
+			continue
+		}
+		if execution.Status.IsTerminal() && execution.RetryAt.IsZero() {
+			s.notifyFinished(executionID)
+			return execution.Status, nil
+		}
+
+		select {
+		case <-finished:
+		case <-ctx.Done():
+			return execution.Status, ctx.Err()
+		}
+	}
+}
+
+func (s *TaskServiceImpl) finishedChannel(executionID string) chan struct{} {
+	s.finishedMu.Lock()
+	defer s.finishedMu.Unlock()
+	ch, ok := s.finished[executionID]
+	if !ok {
+		ch = make(chan struct{})
+		s.finished[executionID] = ch
+	}
+	return ch
+}
+
+func (s *TaskServiceImpl) notifyFinished(executionID string) {
+	s.finishedMu.Lock()
+	defer s.finishedMu.Unlock()
+	if ch, ok := s.finished[executionID]; ok {
+		close(ch)
+		delete(s.finished, executionID)
+	}
+}
@@ -0,0 +1,149 @@
+package orchestrator
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+)
+
+// planRetry decide si la ejecución que acaba de fallar debe reintentarse según la RetryPolicy
+// de la tarea. Si es así anota RetryAt y devuelve la espera; si no, devuelve 0.
+// Se llama con s.mu bloqueado.
+func (s *TaskServiceImpl) planRetry(task *entities.DevOpsTask, execution *entities.TaskExecution) time.Duration {
+	policy := task.Config.Retry
+	// En las matrices se reintenta cada combinación, no la matriz.
+	if policy == nil || execution.MatrixSize > 0 || (execution.Status != entities.TaskFailed && execution.Status != entities.TaskError) {
+		return 0
+	}
+	class := execution.FailureClass
+	if class == "" {
+		class = entities.FailureUnknown
+	}
+	attempt := attemptNumber(execution)
+	if !policy.ShouldRetry(attempt, class) {
+		return 0
+	}
+
+	delay := policy.Backoff(attempt, rand.Float64())
+	if delay <= 0 {
+		delay = time.Millisecond
+	}
+	execution.RetryAt = time.Now().Add(delay)
+	return delay
+}
+
+// launchRetry lanza el siguiente intento de una ejecución fallida y lo enlaza con ella.
+func (s *TaskServiceImpl) launchRetry(ctx context.Context, previousID string) {
+	previous, err := s.getExecution(ctx, previousID)
+	if err != nil {
+		log.Printf("Error retrying execution %s: %v", previousID, err)
+		return
+	}
+	// El reintento se anuló con CancelTask.
+	if previous.RetryAt.IsZero() || previous.NextAttemptID != "" {
+		return
+	}
+
+	task, err := s.repository.GetByID(ctx, previous.DevOpsTaskID)
+	if err == nil {
+		var nextID string
+		nextID, err = s.launchAttempt(ctx, &task, entities.TaskExecution{
+			Attempt:             attemptNumber(&previous) + 1,
+			PreviousAttemptID:   previousID,
+			TriggeredBy:         previous.TriggeredBy,
+			TriggeredByKind:     previous.TriggeredByKind,
+			UpstreamExecutionID: previous.UpstreamExecutionID,
+			TriggerChain:        previous.TriggerChain,
+			ParentExecutionID:   previous.ParentExecutionID,
+			MatrixValues:        previous.MatrixValues,
+		}, s.parametersOf(previousID))
+		if err == nil {
+			s.forgetParameters(previousID)
+			s.linkAttempt(ctx, previousID, nextID, &task)
+			return
+		}
+	}
+
+	log.Printf("Error retrying execution %s: %v", previousID, err)
+	err = s.updateExecution(ctx, previousID, func(execution *entities.TaskExecution) {
+		execution.RetryAt = time.Time{}
+		execution.Error = fmt.Sprintf("%s (retry failed: %v)", execution.Error, err)
+	})
+	if err != nil {
+		log.Printf("Error reconciling execution %s: %v", previousID, err)
+	}
+	s.notifyFinished(previousID)
+	s.publishResult(ctx, previousID)
+}
+
+func (s *TaskServiceImpl) linkAttempt(ctx context.Context, previousID, nextID string, task *entities.DevOpsTask) {
+	var attempt int
+	err := s.updateExecution(ctx, previousID, func(execution *entities.TaskExecution) {
+		execution.NextAttemptID = nextID
+		execution.RetryAt = time.Time{}
+		attempt = attemptNumber(execution) + 1
+	})
+	if err != nil {
+		log.Printf("Error reconciling execution %s: %v", previousID, err)
+	}
+	s.notifyFinished(previousID)
+
+	if s.EventStream != nil {
+		event := entities.TaskEvent{
+			ID:          s.GenerateID(),
+			ExecutionID: previousID,
+			Timestamp:   time.Now(),
+			EventType:   entities.EventTypeTaskRetrying,
+			Payload: entities.TaskRetryPayload{
+				Attempt:         attempt,
+				MaxAttempts:     task.Config.Retry.MaxAttempts,
+				NextExecutionID: nextID,
+			},
+		}
+		if err := s.EventStream.Publish(event); err != nil {
+			log.Printf("Error publishing retry of execution %s: %v", previousID, err)
+		}
+	}
+}
+
+// cancelPendingRetry anula el reintento programado de una ejecución fallida. Devuelve
+// true si había uno pendiente.
+func (s *TaskServiceImpl) cancelPendingRetry(ctx context.Context, executionID string) bool {
+	canceled := false
+	err := s.updateExecution(ctx, executionID, func(execution *entities.TaskExecution) {
+		if !execution.RetryAt.IsZero() && execution.NextAttemptID == "" {
+			execution.RetryAt = time.Time{}
+			canceled = true
+		}
+	})
+	if err != nil || !canceled {
+		return false
+	}
+	s.forgetParameters(executionID)
+	s.notifyFinished(executionID)
+	s.publishResult(ctx, executionID)
+	return true
+}
+
+// lastAttempt sigue los reintentos de una ejecución y devuelve el último intento.
+func (s *TaskServiceImpl) lastAttempt(ctx context.Context, executionID string) (entities.TaskExecution, error) {
+	for {
+		execution, err := s.getExecution(ctx, executionID)
+		if err != nil || execution.NextAttemptID == "" {
+			return execution, err
+		}
+		executionID = execution.NextAttemptID
+	}
+}
+
+// attemptNumber devuelve el número de intento; las ejecuciones anteriores a las políticas
+// de reintento no lo tienen y cuentan como el primero.
+func attemptNumber(execution *entities.TaskExecution) int {
+	if execution.Attempt < 1 {
+		return 1
+	}
+	return execution.Attempt
+}
@@ -0,0 +1,110 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	eventstream "devops_console/internal/infrastructure/orchestrator/events"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type RetryTestSuite struct {
+	suite.Suite
+	service  *orchestrator2.TaskServiceImpl
+	repo     *adapters.InMemoryTaskRepository
+	executor *fakeExecutor
+}
+
+func (suite *RetryTestSuite) SetupTest() {
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.executor = newFakeExecutor()
+	suite.service = orchestrator2.NewTaskServiceImpl(suite.repo)
+	suite.service.RegisterExecutor("Stub", suite.executor)
+	suite.service.EventStream = eventstream.NewTaskEventStream()
+}
+
+func (suite *RetryTestSuite) createTask(policy *entities.RetryPolicy) string {
+	task := &entities.DevOpsTask{ID: "flaky", Worker: &stubWorker{}, Config: entities.TaskConfig{Retry: policy}}
+	suite.Require().NoError(suite.repo.Create(context.Background(), task))
+	return task.ID
+}
+
+func (suite *RetryTestSuite) wait(executionID string) entities.TaskStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	status, err := suite.service.WaitForExecution(ctx, executionID)
+	suite.Require().NoError(err)
+	return status
+}
+
+func (suite *RetryTestSuite) TestRetry_LinksAttemptsUntilSuccess() {
+	taskID := suite.createTask(&entities.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	suite.executor.failures[taskID] = []entities.FailureClass{entities.FailureImagePull, entities.FailureInfrastructure}
+
+	executionID, err := suite.service.ExecuteTask(context.Background(), taskID)
+	suite.Require().NoError(err)
+	events, err := suite.service.EventStream.Subscribe(executionID)
+	suite.Require().NoError(err)
+
+	assert.Equal(suite.T(), entities.TaskSucceeded, suite.wait(executionID))
+
+	task, err := suite.repo.GetByID(context.Background(), taskID)
+	suite.Require().NoError(err)
+	suite.Require().Len(task.Executions, 3)
+	for i, execution := range task.Executions {
+		assert.Equal(suite.T(), i+1, execution.Attempt)
+		if i > 0 {
+			assert.Equal(suite.T(), task.Executions[i-1].ID, execution.PreviousAttemptID)
+			assert.Equal(suite.T(), execution.ID, task.Executions[i-1].NextAttemptID)
+		}
+	}
+	assert.Equal(suite.T(), entities.FailureImagePull, task.Executions[0].FailureClass)
+
+	select {
+	case event := <-events:
+		assert.Equal(suite.T(), entities.EventTypeTaskRetrying, event.EventType)
+		assert.Equal(suite.T(), entities.TaskRetryPayload{Attempt: 2, MaxAttempts: 3, NextExecutionID: task.Executions[1].ID}, event.Payload)
+	case <-time.After(time.Second):
+		suite.T().Fatal("retry event was not published")
+	}
+}
+
+func (suite *RetryTestSuite) TestRetry_StopsAtMaxAttempts() {
+	taskID := suite.createTask(&entities.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond})
+	suite.executor.failures[taskID] = []entities.FailureClass{entities.FailureInfrastructure, entities.FailureInfrastructure, entities.FailureInfrastructure}
+
+	executionID, err := suite.service.ExecuteTask(context.Background(), taskID)
+	suite.Require().NoError(err)
+
+	assert.Equal(suite.T(), entities.TaskFailed, suite.wait(executionID))
+	assert.Len(suite.T(), suite.executor.executedTasks(), 2)
+}
+
+func (suite *RetryTestSuite) TestRetry_IgnoresNonRetryableFailures() {
+	taskID := suite.createTask(&entities.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	suite.executor.failures[taskID] = []entities.FailureClass{entities.FailureNonZeroExit}
+
+	executionID, err := suite.service.ExecuteTask(context.Background(), taskID)
+	suite.Require().NoError(err)
+
+	assert.Equal(suite.T(), entities.TaskFailed, suite.wait(executionID))
+	assert.Len(suite.T(), suite.executor.executedTasks(), 1)
+}
+
+func TestRetryTestSuite(t *testing.T) {
+	suite.Run(t, new(RetryTestSuite))
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := entities.RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2, Jitter: 0.5}
+
+	assert.Equal(t, time.Second, policy.Backoff(1, 0.5))
+	assert.Equal(t, 4*time.Second, policy.Backoff(3, 0.5))
+	assert.Equal(t, 5*time.Second, policy.Backoff(10, 0.5))
+	assert.Equal(t, 1500*time.Millisecond, policy.Backoff(1, 1))
+	assert.Equal(t, 500*time.Millisecond, policy.Backoff(1, 0))
+}
@@ -0,0 +1,217 @@
+package orchestrator_test
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeExecutor es el executor de los tests del servicio. Por defecto termina cada ejecución
+// en cuanto se lanza con el estado configurado para su tarea: los fallos de failures se
+// consumen uno por intento antes de aplicar results. Con hold las deja en marcha hasta que
+// el test llama a finish y con askInput piden datos al operador y terminan al recibirlos.
+// Implementa todas las capacidades opcionales de los executors; basicExecutor no tiene
+// ninguna.
+type fakeExecutor struct {
+	mu       sync.Mutex
+	results  map[string]entities.TaskStatus
+	failures map[string][]entities.FailureClass
+	hold     bool
+	askInput bool
+	// store y files simulan las salidas: el contenido de cada ruta de files se guarda en
+	// store como el artefacto del output que la declara.
+	store ports.ArtifactStore
+	files map[string]string
+
+	events   map[string]chan entities.TaskEvent
+	running  map[string]bool
+	tasks    []entities.DevOpsTask
+	contexts map[string]context.Context
+	canceled []string
+	inputs   map[string]map[string]interface{}
+	secrets  map[string][]string
+	stopped  bool
+}
+
+func newFakeExecutor() *fakeExecutor {
+	return &fakeExecutor{
+		results:  make(map[string]entities.TaskStatus),
+		failures: make(map[string][]entities.FailureClass),
+		events:   make(map[string]chan entities.TaskEvent),
+		running:  make(map[string]bool),
+		contexts: make(map[string]context.Context),
+		inputs:   make(map[string]map[string]interface{}),
+		secrets:  make(map[string][]string),
+	}
+}
+
+// basicExecutor devuelve un fakeExecutor que solo tiene los métodos de ports.TaskExecutor.
+func basicExecutor() ports.TaskExecutor {
+	return struct{ ports.TaskExecutor }{newFakeExecutor()}
+}
+
+// ExecuteTask devuelve los IDs executor-1, executor-2... en el orden en que se lanzan.
+func (e *fakeExecutor) ExecuteTask(ctx context.Context, task *entities.DevOpsTask) (string, error) {
+	artifacts, err := e.collect(ctx, task.Config.Outputs)
+	if err != nil {
+		return "", err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.tasks = append(e.tasks, *task)
+	executionID := fmt.Sprintf("executor-%d", len(e.tasks))
+	e.events[executionID] = make(chan entities.TaskEvent, 2)
+	e.running[executionID] = true
+	e.contexts[executionID] = ctx
+	switch {
+	case e.askInput:
+		e.events[executionID] <- entities.TaskEvent{ExecutionID: executionID, EventType: entities.EventTypeTaskInputRequired, Timestamp: time.Now()}
+	case !e.hold:
+		status, ok := e.results[task.ID]
+		if !ok {
+			status = entities.TaskSucceeded
+		}
+		var class entities.FailureClass
+		if failures := e.failures[task.ID]; len(failures) > 0 {
+			status, class = entities.TaskFailed, failures[0]
+			e.failures[task.ID] = failures[1:]
+		}
+		e.publish(executionID, entities.TaskProgressPayload{Status: status, FailureClass: class, Artifacts: artifacts})
+	}
+	return executionID, nil
+}
+
+// collect guarda en store el contenido de files de cada salida declarada.
+func (e *fakeExecutor) collect(ctx context.Context, outputs []entities.OutputSpec) ([]entities.Artifact, error) {
+	var artifacts []entities.Artifact
+	for _, output := range outputs {
+		content, ok := e.files[output.Path]
+		if !ok || e.store == nil {
+			continue
+		}
+		digest, size, err := e.store.Put(ctx, strings.NewReader(content))
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, entities.Artifact{
+			Name: output.Name, Path: output.Path, Type: "text/plain", Digest: digest, Size: size, CreatedAt: time.Now(),
+		})
+	}
+	return artifacts, nil
+}
+
+// finish termina una ejecución que sigue en marcha.
+func (e *fakeExecutor) finish(executionID string, status entities.TaskStatus, errMsg string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.publish(executionID, entities.TaskProgressPayload{Status: status, Error: errMsg})
+}
+
+// publish envía el evento terminal de la ejecución si sigue en marcha. Se llama con mu
+// bloqueado.
+func (e *fakeExecutor) publish(executionID string, payload entities.TaskProgressPayload) {
+	if !e.running[executionID] {
+		return
+	}
+	e.running[executionID] = false
+	e.events[executionID] <- entities.TaskEvent{
+		ExecutionID: executionID,
+		EventType:   entities.EventTypeFromStatus(payload.Status),
+		Timestamp:   time.Now(),
+		Payload:     payload,
+	}
+	close(e.events[executionID])
+}
+
+func (e *fakeExecutor) CancelTask(ctx context.Context, executionID string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.canceled = append(e.canceled, executionID)
+	return nil
+}
+
+func (e *fakeExecutor) GetTaskStatus(ctx context.Context, taskExecutionID string) (entities.TaskStatus, error) {
+	return entities.TaskRunning, nil
+}
+
+func (e *fakeExecutor) SubscribeToTaskEvents(taskExecutionID string) (<-chan entities.TaskEvent, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	events, ok := e.events[taskExecutionID]
+	if !ok {
+		return nil, errors.New("execution not found")
+	}
+	return events, nil
+}
+
+func (e *fakeExecutor) Plan(ctx context.Context, task *entities.DevOpsTask) (entities.ExecutionPlan, error) {
+	masked := task.MaskedCopy()
+	spec, err := entities.RenderWorkerSpec(task.Worker.GetDetails(), entities.NewTemplateContext(&masked, entities.DryRunExecutionID, true))
+	return entities.ExecutionPlan{WorkerType: task.Worker.GetType(), Spec: spec}, err
+}
+
+func (e *fakeExecutor) ProvideInput(ctx context.Context, executionID string, values map[string]interface{}, secrets []string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.inputs[executionID] = values
+	e.secrets[executionID] = secrets
+	e.publish(executionID, entities.TaskProgressPayload{Status: entities.TaskSucceeded})
+	return nil
+}
+
+func (e *fakeExecutor) CollectsOutputs() bool {
+	return true
+}
+
+func (e *fakeExecutor) InjectsInputs() bool {
+	return true
+}
+
+func (e *fakeExecutor) Shutdown() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stopped = true
+}
+
+// launchedTasks devuelve las tareas tal como llegaron al executor.
+func (e *fakeExecutor) launchedTasks() []entities.DevOpsTask {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]entities.DevOpsTask{}, e.tasks...)
+}
+
+// launched devuelve los parámetros con los que se lanzó cada tarea.
+func (e *fakeExecutor) launched() []map[string]interface{} {
+	var parameters []map[string]interface{}
+	for _, task := range e.launchedTasks() {
+		parameters = append(parameters, task.ParameterValues())
+	}
+	return parameters
+}
+
+func (e *fakeExecutor) executedTasks() []string {
+	var executed []string
+	for _, task := range e.launchedTasks() {
+		executed = append(executed, task.ID)
+	}
+	return executed
+}
+
+func (e *fakeExecutor) canceledExecutions() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string{}, e.canceled...)
+}
+
+// received devuelve los datos del operador que recibió la ejecución y los secretos que
+// había que ocultar.
+func (e *fakeExecutor) received(executionID string) (map[string]interface{}, []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.inputs[executionID], e.secrets[executionID]
+}
@@ -0,0 +1,217 @@
+package orchestrator
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+var (
+	ErrInputNotPending = errors.New("execution is not waiting for input")
+	ErrInvalidInput    = errors.New("invalid input")
+)
+
+// defaultInputTimeout se aplica cuando el formulario no define Timeout.
+const defaultInputTimeout = 24 * time.Hour
+
+// awaitInput deja esperando los datos del operador la ejecución en curso cuyo executor los
+// ha pedido, publica el formulario de la revisión con la que se lanzó y programa su
+// caducidad. Mientras espera conserva su hueco en la cola.
+func (s *TaskServiceImpl) awaitInput(ctx context.Context, executionID string) {
+	var form *entities.InputForm
+	var deadline time.Time
+	err := s.updateTask(ctx, executionID, func(task *entities.DevOpsTask, execution *entities.TaskExecution) error {
+		if execution.Status != entities.TaskRunning {
+			return ErrInputNotPending
+		}
+		form = pinnedTask(task, execution).InputForm
+		if form == nil {
+			return fmt.Errorf("%w: task %q has no input form", ErrInvalidTask, task.ID)
+		}
+		timeout := form.Timeout
+		if timeout <= 0 {
+			timeout = defaultInputTimeout
+		}
+		execution.Status = entities.TaskWaitingInput
+		execution.InputDeadline = time.Now().Add(timeout)
+		deadline = execution.InputDeadline
+		return nil
+	})
+	if errors.Is(err, ErrInvalidTask) {
+		// Nadie puede enviarle los datos: sin esto solo terminaría por su timeout.
+		s.stopExecution(ctx, executionID, entities.TaskFailed, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error requesting input of execution %s: %v", executionID, err)
+		return
+	}
+	s.publishInputRequest(executionID, form, deadline)
+
+	s.afterFunc(time.Until(deadline), func(ctx context.Context) {
+		s.finishInput(ctx, executionID, entities.TaskExpired, "input request expired")
+	})
+}
+
+// SubmitInput valida los datos del operador contra el formulario de la revisión con la
+// que se lanzó la ejecución y se los entrega a través de su executor para que continúe.
+func (s *TaskServiceImpl) SubmitInput(ctx context.Context, executionID string, subject entities.Subject, values map[string]interface{}) (err error) {
+	event := auditEvent{action: entities.AuditSubmitInput, executionID: executionID}
+	defer func() { s.recordAudit(ctx, &event, err) }()
+	subject, err = s.actingSubject(ctx, subject)
+	if err != nil {
+		return err
+	}
+	event.subject = subject
+	if err := s.authorizeExecution(ctx, subject, entities.PermissionExecute, executionID); err != nil {
+		return err
+	}
+	now := time.Now()
+	expired := false
+	var receiver ports.InputReceiver
+	var input map[string]interface{}
+	var secrets []string
+	var runningID string
+
+	err = s.updateTask(ctx, executionID, func(t *entities.DevOpsTask, execution *entities.TaskExecution) error {
+		if execution.Status != entities.TaskWaitingInput {
+			return ErrInputNotPending
+		}
+		if !now.Before(execution.InputDeadline) {
+			expired = true
+			return nil
+		}
+		task := pinnedTask(t, execution)
+		if task.InputForm == nil {
+			return fmt.Errorf("%w: task %q has no input form", ErrInvalidTask, t.ID)
+		}
+		var ok bool
+		receiver, ok = s.executors[task.Worker.GetType()].(ports.InputReceiver)
+		if !ok {
+			return fmt.Errorf("%w: %q workers do not receive input", ErrInvalidTask, task.Worker.GetType())
+		}
+		var err error
+		input, err = entities.ResolveParameters(task.InputForm.Fields, values)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		withInput := withParameters(task, input)
+		secrets = withInput.SecretParameterValues()
+
+		// Los parámetros guardados ya llevan los secretos enmascarados.
+		parameters := make(map[string]interface{}, len(execution.Parameters)+len(input))
+		for name, value := range execution.Parameters {
+			parameters[name] = value
+		}
+		for name, value := range input {
+			parameters[name] = value
+		}
+		execution.Status = entities.TaskRunning
+		execution.Parameters = entities.MaskSecretParameters(task.AllParameters(), parameters)
+		execution.InputSubmittedBy = subjectID(subject)
+		runningID = executorID(*execution)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if expired {
+		s.finishInput(ctx, executionID, entities.TaskExpired, "input request expired")
+		return ErrInputNotPending
+	}
+
+	if err := receiver.ProvideInput(ctx, runningID, input, secrets); err != nil {
+		s.stopExecution(ctx, executionID, entities.TaskError, fmt.Sprintf("delivering input: %v", err))
+		return err
+	}
+	return nil
+}
+
+// AbortInput termina como cancelada una ejecución que espera datos del operador.
+func (s *TaskServiceImpl) AbortInput(ctx context.Context, executionID string, subject entities.Subject, reason string) (err error) {
+	event := auditEvent{action: entities.AuditAbortInput, executionID: executionID}
+	defer func() { s.recordAudit(ctx, &event, err) }()
+	subject, err = s.actingSubject(ctx, subject)
+	if err != nil {
+		return err
+	}
+	event.subject = subject
+	if err := s.authorizeExecution(ctx, subject, entities.PermissionCancel, executionID); err != nil {
+		return err
+	}
+	if !s.finishInput(ctx, executionID, entities.TaskCanceled, fmt.Sprintf("aborted by %s: %s", subjectID(subject), reason)) {
+		return ErrInputNotPending
+	}
+	return nil
+}
+
+// finishInput termina con el estado indicado la ejecución si sigue esperando datos.
+// Devuelve false si ya no los esperaba.
+func (s *TaskServiceImpl) finishInput(ctx context.Context, executionID string, status entities.TaskStatus, errMsg string) bool {
+	waiting := false
+	err := s.updateExecution(ctx, executionID, func(execution *entities.TaskExecution) {
+		// Se saca de la espera antes de pararla para que nadie más la termine ni le envíe
+		// los datos entretanto.
+		if execution.Status == entities.TaskWaitingInput {
+			execution.Status = entities.TaskRunning
+			waiting = true
+		}
+	})
+	if err != nil {
+		log.Printf("Error finishing execution %s: %v", executionID, err)
+		return false
+	}
+	if !waiting {
+		return false
+	}
+	s.stopExecution(ctx, executionID, status, errMsg)
+	return true
+}
+
+// stopExecution termina con el estado indicado la ejecución en curso y la cancela en su
+// executor.
+func (s *TaskServiceImpl) stopExecution(ctx context.Context, executionID string, status entities.TaskStatus, errMsg string) {
+	task, err := s.repository.GetByExecutionID(ctx, executionID)
+	if err != nil {
+		log.Printf("Error stopping execution %s: %v", executionID, err)
+		return
+	}
+	execution, err := s.getExecution(ctx, executionID)
+	if err != nil {
+		log.Printf("Error stopping execution %s: %v", executionID, err)
+		return
+	}
+	// El estado se aplica antes de cancelar para que no lo pise el que publique el executor.
+	s.applyTerminalStatus(ctx, executionID, status, errMsg, "", nil, time.Now())
+	worker := pinnedTask(&task, &execution).Worker
+	if executor, ok := s.executors[worker.GetType()]; ok {
+		if err := executor.CancelTask(ctx, executorID(execution)); err != nil {
+			log.Printf("Error canceling execution %s: %v", executionID, err)
+		}
+	}
+}
+
+// publishInputRequest publica el formulario de la ejecución para que la interfaz lo muestre.
+func (s *TaskServiceImpl) publishInputRequest(executionID string, form *entities.InputForm, deadline time.Time) {
+	if s.EventStream == nil {
+		return
+	}
+	event := entities.TaskEvent{
+		ID:          s.GenerateID(),
+		ExecutionID: executionID,
+		Timestamp:   time.Now(),
+		EventType:   entities.EventTypeTaskInputRequired,
+		Payload: entities.TaskInputPayload{
+			Prompt:   form.Prompt,
+			Fields:   form.Fields,
+			Deadline: deadline,
+		},
+	}
+	if err := s.EventStream.Publish(event); err != nil {
+		log.Printf("Error publishing input request of execution %s: %v", executionID, err)
+	}
+}
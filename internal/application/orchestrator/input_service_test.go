@@ -0,0 +1,178 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	eventstream "devops_console/internal/infrastructure/orchestrator/events"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	ports "devops_console/internal/ports/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type InputServiceTestSuite struct {
+	suite.Suite
+	service  *orchestrator2.TaskServiceImpl
+	repo     *adapters.InMemoryTaskRepository
+	executor *fakeExecutor
+	operator entities.User
+}
+
+func (suite *InputServiceTestSuite) SetupTest() {
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.executor = newFakeExecutor()
+	suite.executor.askInput = true
+	suite.service = orchestrator2.NewTaskServiceImpl(suite.repo)
+	suite.service.EventStream = eventstream.NewTaskEventStream()
+	suite.service.RegisterExecutor("Stub", suite.executor)
+	suite.operator = entities.User{ID: "alice"}
+}
+
+func (suite *InputServiceTestSuite) TearDownTest() {
+	suite.service.Shutdown()
+}
+
+func (suite *InputServiceTestSuite) createTask(timeout time.Duration) string {
+	task, err := suite.service.CreateTask(context.Background(), entities.DevOpsTask{
+		ID:       "release",
+		TaskType: entities.TaskTypeManual,
+		Worker:   &stubWorker{},
+		Parameters: []entities.ParameterDefinition{
+			{Name: "environment", Type: entities.ParameterString, Default: "staging"},
+		},
+		InputForm: &entities.InputForm{
+			Prompt: "Confirm the release",
+			Fields: []entities.ParameterDefinition{
+				{Name: "version", Type: entities.ParameterString, Required: true, Pattern: `^v\d+$`},
+				{Name: "token", Type: entities.ParameterString, Secret: true},
+			},
+			Timeout: timeout,
+		},
+	})
+	suite.Require().NoError(err)
+	return task.ID
+}
+
+// execute lanza la tarea y espera a que su executor pida los datos.
+func (suite *InputServiceTestSuite) execute(taskID string) string {
+	executionID, err := suite.service.ExecuteTask(context.Background(), taskID)
+	suite.Require().NoError(err)
+	suite.Require().Eventually(func() bool {
+		status, err := suite.service.GetTaskStatus(context.Background(), executionID)
+		return err == nil && status == entities.TaskWaitingInput
+	}, time.Second, 5*time.Millisecond)
+	return executionID
+}
+
+func (suite *InputServiceTestSuite) wait(executionID string) entities.TaskStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	status, err := suite.service.WaitForExecution(ctx, executionID)
+	suite.Require().NoError(err)
+	return status
+}
+
+// form devuelve el formulario que recibe quien se suscribe a la ejecución.
+func (suite *InputServiceTestSuite) form(executionID string) entities.TaskInputPayload {
+	events, err := suite.service.SubscribeToTaskEvents(context.Background(), executionID)
+	suite.Require().NoError(err)
+	select {
+	case event := <-events:
+		suite.Require().Equal(entities.EventTypeTaskInputRequired, event.EventType)
+		return event.Payload.(entities.TaskInputPayload)
+	case <-time.After(time.Second):
+		suite.FailNow("input request not published")
+	}
+	return entities.TaskInputPayload{}
+}
+
+func (suite *InputServiceTestSuite) TestSubmitInput_ResumesExecution() {
+	executionID := suite.execute(suite.createTask(time.Minute))
+	task, err := suite.repo.GetByExecutionID(context.Background(), executionID)
+	suite.Require().NoError(err)
+	// La ejecución ya está en el executor cuando pide los datos.
+	assert.NotEmpty(suite.T(), task.Executions[0].TaskExecutorID)
+
+	payload := suite.form(executionID)
+	assert.Equal(suite.T(), "Confirm the release", payload.Prompt)
+	assert.Len(suite.T(), payload.Fields, 2)
+
+	err = suite.service.SubmitInput(context.Background(), executionID, suite.operator, map[string]interface{}{"version": "latest"})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidInput)
+	input, _ := suite.executor.received(task.Executions[0].TaskExecutorID)
+	assert.Nil(suite.T(), input)
+
+	suite.Require().NoError(suite.service.SubmitInput(context.Background(), executionID, suite.operator, map[string]interface{}{"version": "v3", "token": "s3cr3t"}))
+	assert.Equal(suite.T(), entities.TaskSucceeded, suite.wait(executionID))
+	assert.ErrorIs(suite.T(), suite.service.SubmitInput(context.Background(), executionID, suite.operator, map[string]interface{}{"version": "v4"}), orchestrator2.ErrInputNotPending)
+
+	input, secrets := suite.executor.received(task.Executions[0].TaskExecutorID)
+	assert.Equal(suite.T(), map[string]interface{}{"version": "v3", "token": "s3cr3t"}, input)
+	assert.Equal(suite.T(), []string{"s3cr3t"}, secrets)
+
+	task, err = suite.repo.GetByExecutionID(context.Background(), executionID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "alice", task.Executions[0].InputSubmittedBy)
+	assert.Equal(suite.T(), "v3", task.Executions[0].Parameters["version"])
+	assert.Equal(suite.T(), entities.MaskedParameterValue, task.Executions[0].Parameters["token"])
+}
+
+func (suite *InputServiceTestSuite) TestSubmitInput_UsesTheFormOfTheLaunchedRevision() {
+	taskID := suite.createTask(time.Minute)
+	executionID := suite.execute(taskID)
+	_, err := suite.service.UpdateTask(context.Background(), taskID, ports.TaskUpdate{InputForm: &entities.InputForm{
+		Prompt: "Pick a release candidate",
+		Fields: []entities.ParameterDefinition{{Name: "version", Type: entities.ParameterString, Required: true, Pattern: `^rc\d+$`}},
+	}})
+	suite.Require().NoError(err)
+
+	assert.Equal(suite.T(), "Confirm the release", suite.form(executionID).Prompt)
+	suite.Require().NoError(suite.service.SubmitInput(context.Background(), executionID, suite.operator, map[string]interface{}{"version": "v3"}))
+	assert.Equal(suite.T(), entities.TaskSucceeded, suite.wait(executionID))
+}
+
+func (suite *InputServiceTestSuite) TestInputRequest_Expires() {
+	executionID := suite.execute(suite.createTask(50 * time.Millisecond))
+
+	assert.Equal(suite.T(), entities.TaskExpired, suite.wait(executionID))
+	assert.ErrorIs(suite.T(), suite.service.SubmitInput(context.Background(), executionID, suite.operator, map[string]interface{}{"version": "v1"}), orchestrator2.ErrInputNotPending)
+	assert.Len(suite.T(), suite.executor.canceledExecutions(), 1)
+}
+
+func (suite *InputServiceTestSuite) TestAbortInput() {
+	taskID := suite.createTask(time.Minute)
+	aborted := suite.execute(taskID)
+	canceled := suite.execute(taskID)
+
+	suite.Require().NoError(suite.service.AbortInput(context.Background(), aborted, suite.operator, "not today"))
+	suite.Require().NoError(suite.service.CancelTask(context.Background(), canceled))
+	assert.Equal(suite.T(), entities.TaskCanceled, suite.wait(aborted))
+	assert.Equal(suite.T(), entities.TaskCanceled, suite.wait(canceled))
+	assert.ErrorIs(suite.T(), suite.service.AbortInput(context.Background(), aborted, suite.operator, ""), orchestrator2.ErrInputNotPending)
+	assert.Len(suite.T(), suite.executor.canceledExecutions(), 2)
+
+	task, err := suite.repo.GetByExecutionID(context.Background(), aborted)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "aborted by alice: not today", task.Executions[0].Error)
+}
+
+func (suite *InputServiceTestSuite) TestInputForm_RequiresAnExecutorThatReceivesIt() {
+	service := orchestrator2.NewTaskServiceImpl(adapters.NewInMemoryTaskRepository())
+	defer service.Shutdown()
+	service.RegisterExecutor("Stub", basicExecutor())
+	_, err := service.CreateTask(context.Background(), entities.DevOpsTask{
+		ID:        "release",
+		TaskType:  entities.TaskTypeManual,
+		Worker:    &stubWorker{},
+		InputForm: &entities.InputForm{Fields: []entities.ParameterDefinition{{Name: "version", Type: entities.ParameterString}}},
+	})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidTask)
+	assert.ErrorContains(suite.T(), err, `"Stub" workers do not receive input from operators`)
+}
+
+func TestInputServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(InputServiceTestSuite))
+}
@@ -0,0 +1,652 @@
+package orchestrator
+
+import (
+	"bytes"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"time"
+)
+
+// Versión y tipo del formato de manifiesto que admite Apply y genera Export.
+const (
+	ManifestAPIVersion = "devops-console/v1"
+	ManifestKind       = "TaskManifest"
+)
+
+var ErrInvalidManifest = errors.New("invalid manifest")
+
+// Manifest es la definición declarativa de un conjunto de tareas y de los workspaces en
+// los que viven, pensada para guardarse en git. Se escribe en YAML o en JSON.
+type Manifest struct {
+	APIVersion string              `yaml:"apiVersion" json:"apiVersion"`
+	Kind       string              `yaml:"kind" json:"kind"`
+	Workspaces []WorkspaceManifest `yaml:"workspaces,omitempty" json:"workspaces,omitempty"`
+	Tasks      []TaskManifest      `yaml:"tasks,omitempty" json:"tasks,omitempty"`
+}
+
+type WorkspaceManifest struct {
+	ID       string `yaml:"id" json:"id"`
+	Name     string `yaml:"name,omitempty" json:"name,omitempty"`
+	TenantID string `yaml:"tenantId,omitempty" json:"tenantId,omitempty"`
+}
+
+// TaskManifest describe una tarea. Workspace es el ID de uno de los workspaces del manifiesto.
+type TaskManifest struct {
+	ID          string               `yaml:"id" json:"id"`
+	Name        string               `yaml:"name,omitempty" json:"name,omitempty"`
+	Title       string               `yaml:"title,omitempty" json:"title,omitempty"`
+	Description string               `yaml:"description,omitempty" json:"description,omitempty"`
+	Type        entities.TaskType    `yaml:"type" json:"type"`
+	Workspace   string               `yaml:"workspace" json:"workspace"`
+	Tags        []string             `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Priority    int                  `yaml:"priority,omitempty" json:"priority,omitempty"`
+	Worker      ports.WorkerManifest `yaml:"worker" json:"worker"`
+	Parameters  []ParameterManifest  `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	Config      *ConfigManifest      `yaml:"config,omitempty" json:"config,omitempty"`
+	Trigger     *TriggerManifest     `yaml:"trigger,omitempty" json:"trigger,omitempty"`
+	Approval    *ApprovalManifest    `yaml:"approval,omitempty" json:"approval,omitempty"`
+	Input       *InputManifest       `yaml:"input,omitempty" json:"input,omitempty"`
+}
+
+type ParameterManifest struct {
+	Name        string                 `yaml:"name" json:"name"`
+	Description string                 `yaml:"description,omitempty" json:"description,omitempty"`
+	Type        entities.ParameterType `yaml:"type" json:"type"`
+	Default     interface{}            `yaml:"default,omitempty" json:"default,omitempty"`
+	Required    bool                   `yaml:"required,omitempty" json:"required,omitempty"`
+	Enum        []string               `yaml:"enum,omitempty" json:"enum,omitempty"`
+	Pattern     string                 `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Secret      bool                   `yaml:"secret,omitempty" json:"secret,omitempty"`
+}
+
+type ConfigManifest struct {
+	Parameters map[string]interface{}  `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	Workspace  string                  `yaml:"workspace,omitempty" json:"workspace,omitempty"`
+	Retry      *RetryManifest          `yaml:"retry,omitempty" json:"retry,omitempty"`
+	Secrets    []SecretManifest        `yaml:"secrets,omitempty" json:"secrets,omitempty"`
+	Outputs    []OutputManifest        `yaml:"outputs,omitempty" json:"outputs,omitempty"`
+	Inputs     []ArtifactInputManifest `yaml:"inputs,omitempty" json:"inputs,omitempty"`
+	Matrix     *MatrixManifest         `yaml:"matrix,omitempty" json:"matrix,omitempty"`
+}
+
+type RetryManifest struct {
+	MaxAttempts    int                     `yaml:"maxAttempts" json:"maxAttempts"`
+	InitialBackoff Duration                `yaml:"initialBackoff,omitempty" json:"initialBackoff,omitempty"`
+	MaxBackoff     Duration                `yaml:"maxBackoff,omitempty" json:"maxBackoff,omitempty"`
+	Multiplier     float64                 `yaml:"multiplier,omitempty" json:"multiplier,omitempty"`
+	Jitter         float64                 `yaml:"jitter,omitempty" json:"jitter,omitempty"`
+	RetryOn        []entities.FailureClass `yaml:"retryOn,omitempty" json:"retryOn,omitempty"`
+}
+
+type SecretManifest struct {
+	EnvVar string `yaml:"envVar" json:"envVar"`
+	Key    string `yaml:"key" json:"key"`
+}
+
+type OutputManifest struct {
+	Name string `yaml:"name" json:"name"`
+	Path string `yaml:"path" json:"path"`
+}
+
+type ArtifactInputManifest struct {
+	Task     string `yaml:"task" json:"task"`
+	Artifact string `yaml:"artifact" json:"artifact"`
+	Path     string `yaml:"path" json:"path"`
+}
+
+type MatrixManifest struct {
+	Axes        []MatrixAxisManifest     `yaml:"axes" json:"axes"`
+	Exclude     []map[string]interface{} `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+	Include     []map[string]interface{} `yaml:"include,omitempty" json:"include,omitempty"`
+	MaxParallel int                      `yaml:"maxParallel,omitempty" json:"maxParallel,omitempty"`
+	FailFast    bool                     `yaml:"failFast,omitempty" json:"failFast,omitempty"`
+}
+
+type MatrixAxisManifest struct {
+	Parameter string        `yaml:"parameter" json:"parameter"`
+	Values    []interface{} `yaml:"values" json:"values"`
+}
+
+// TriggerManifest debe definir exactamente uno de sus campos.
+type TriggerManifest struct {
+	Schedule   *ScheduleManifest   `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+	Webhook    *WebhookManifest    `yaml:"webhook,omitempty" json:"webhook,omitempty"`
+	Completion *CompletionManifest `yaml:"completion,omitempty" json:"completion,omitempty"`
+}
+
+type ScheduleManifest struct {
+	Expression      string                   `yaml:"expression" json:"expression"`
+	Timezone        string                   `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+	MissedRunPolicy entities.MissedRunPolicy `yaml:"missedRunPolicy,omitempty" json:"missedRunPolicy,omitempty"`
+}
+
+// WebhookManifest no incluye el token: se genera al crear la tarea y se conserva al
+// actualizarla. Si Secret se omite se conserva el de la tarea existente; sin secreto hace
+// falta AllowUnsigned para aceptar entregas sin firmar.
+type WebhookManifest struct {
+	Secret        string                     `yaml:"secret,omitempty" json:"secret,omitempty"`
+	AllowUnsigned bool                       `yaml:"allowUnsigned,omitempty" json:"allowUnsigned,omitempty"`
+	Events        []string                   `yaml:"events,omitempty" json:"events,omitempty"`
+	Parameters    map[string]string          `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	Conditions    []WebhookConditionManifest `yaml:"conditions,omitempty" json:"conditions,omitempty"`
+}
+
+type WebhookConditionManifest struct {
+	Path    string `yaml:"path" json:"path"`
+	Equals  string `yaml:"equals,omitempty" json:"equals,omitempty"`
+	Matches string `yaml:"matches,omitempty" json:"matches,omitempty"`
+}
+
+type CompletionManifest struct {
+	TaskID      string                `yaml:"taskId,omitempty" json:"taskId,omitempty"`
+	WorkspaceID string                `yaml:"workspaceId,omitempty" json:"workspaceId,omitempty"`
+	On          []entities.TaskStatus `yaml:"on,omitempty" json:"on,omitempty"`
+	Parameters  map[string]string     `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+}
+
+type ApprovalManifest struct {
+	Approvers []SubjectManifest `yaml:"approvers" json:"approvers"`
+	Quorum    int               `yaml:"quorum,omitempty" json:"quorum,omitempty"`
+	Timeout   Duration          `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// SubjectManifest identifica a un aprobador; Kind es "user", "group" o "serviceAccount".
+type SubjectManifest struct {
+	Kind string `yaml:"kind" json:"kind"`
+	ID   string `yaml:"id" json:"id"`
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+}
+
+type InputManifest struct {
+	Prompt  string              `yaml:"prompt,omitempty" json:"prompt,omitempty"`
+	Fields  []ParameterManifest `yaml:"fields,omitempty" json:"fields,omitempty"`
+	Timeout Duration            `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// Duration se escribe en los manifiestos con el formato de time.ParseDuration, p.ej. "90s".
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid duration %q", value.Line, value.Value)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// parsedManifest guarda junto al manifiesto su árbol YAML para situar los errores.
+type parsedManifest struct {
+	Manifest
+	root *yaml.Node
+}
+
+// parseManifest lee un manifiesto YAML o JSON. Los campos desconocidos son un error que
+// indica la línea en la que aparecen.
+func parseManifest(data []byte) (*parsedManifest, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+
+	parsed := &parsedManifest{root: &root}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&parsed.Manifest); err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: empty manifest", ErrInvalidManifest)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	if parsed.APIVersion != ManifestAPIVersion {
+		return nil, parsed.errorAt(fmt.Sprintf("unsupported apiVersion %q, expected %q", parsed.APIVersion, ManifestAPIVersion), "apiVersion")
+	}
+	if parsed.Kind != ManifestKind {
+		return nil, parsed.errorAt(fmt.Sprintf("unsupported kind %q, expected %q", parsed.Kind, ManifestKind), "kind")
+	}
+	return parsed, nil
+}
+
+// errorAt devuelve un ErrInvalidManifest con la línea del nodo al que lleva path. Cada
+// elemento de path es una clave (string) o una posición de una lista (int); si el nodo no
+// existe se usa la línea del último que sí existe.
+func (m *parsedManifest) errorAt(message string, path ...interface{}) error {
+	return fmt.Errorf("%w: line %d: %s", ErrInvalidManifest, m.line(path...), message)
+}
+
+func (m *parsedManifest) line(path ...interface{}) int {
+	node := m.root
+	if node == nil {
+		return 0
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, step := range path {
+		next := childNode(node, step)
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return node.Line
+}
+
+func childNode(node *yaml.Node, step interface{}) *yaml.Node {
+	switch key := step.(type) {
+	case string:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i+1]
+			}
+		}
+	case int:
+		if node.Kind == yaml.SequenceNode && key < len(node.Content) {
+			return node.Content[key]
+		}
+	}
+	return nil
+}
+
+// buildTasks valida el manifiesto y lo convierte en las tareas que describe.
+func (m *parsedManifest) buildTasks(workers map[string]ports.WorkerFactory) ([]entities.DevOpsTask, error) {
+	workspaces := make(map[string]entities.Workspace, len(m.Workspaces))
+	for i, workspace := range m.Workspaces {
+		if workspace.ID == "" {
+			return nil, m.errorAt("workspace without id", "workspaces", i)
+		}
+		if _, ok := workspaces[workspace.ID]; ok {
+			return nil, m.errorAt(fmt.Sprintf("duplicated workspace %q", workspace.ID), "workspaces", i, "id")
+		}
+		workspaces[workspace.ID] = entities.Workspace{ID: workspace.ID, Name: workspace.Name, TenantID: workspace.TenantID}
+	}
+
+	tasks := make([]entities.DevOpsTask, 0, len(m.Tasks))
+	ids := make(map[string]bool, len(m.Tasks))
+	for i, spec := range m.Tasks {
+		if spec.ID == "" {
+			return nil, m.errorAt("task without id", "tasks", i)
+		}
+		if ids[spec.ID] {
+			return nil, m.errorAt(fmt.Sprintf("duplicated task %q", spec.ID), "tasks", i, "id")
+		}
+		ids[spec.ID] = true
+
+		task, err := m.buildTask(i, spec, workspaces, workers)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (m *parsedManifest) buildTask(i int, spec TaskManifest, workspaces map[string]entities.Workspace, workers map[string]ports.WorkerFactory) (entities.DevOpsTask, error) {
+	at := func(message string, path ...interface{}) error {
+		return m.errorAt(fmt.Sprintf("task %q: %s", spec.ID, message), append([]interface{}{"tasks", i}, path...)...)
+	}
+
+	workspace, ok := workspaces[spec.Workspace]
+	if !ok {
+		return entities.DevOpsTask{}, at(fmt.Sprintf("workspace %q is not declared in the manifest", spec.Workspace), "workspace")
+	}
+	switch spec.Type {
+	case entities.TaskTypeScheduled, entities.TaskTypeTriggered, entities.TaskTypeApproval, entities.TaskTypeManual:
+	default:
+		return entities.DevOpsTask{}, at(fmt.Sprintf("unknown task type %q", spec.Type), "type")
+	}
+	factory, ok := workers[spec.Worker.Type]
+	if !ok {
+		return entities.DevOpsTask{}, at(fmt.Sprintf("unknown worker type %q", spec.Worker.Type), "worker", "type")
+	}
+	worker, err := factory(spec.Worker)
+	if err != nil {
+		return entities.DevOpsTask{}, at(err.Error(), "worker")
+	}
+
+	task := entities.DevOpsTask{
+		ID:          spec.ID,
+		Name:        spec.Name,
+		Title:       spec.Title,
+		Description: spec.Description,
+		Workspace:   workspace,
+		TaskType:    spec.Type,
+		Tags:        spec.Tags,
+		Worker:      worker,
+		Priority:    spec.Priority,
+		Parameters:  parameterDefinitions(spec.Parameters),
+	}
+	if spec.Config != nil {
+		task.Config = taskConfig(*spec.Config)
+	}
+	if spec.Approval != nil {
+		if spec.Type != entities.TaskTypeApproval {
+			return entities.DevOpsTask{}, at("approval is only allowed in APPROVAL tasks", "approval")
+		}
+		policy := &entities.ApprovalPolicy{Quorum: spec.Approval.Quorum, Timeout: time.Duration(spec.Approval.Timeout)}
+		for j, approver := range spec.Approval.Approvers {
+			subject, err := approverSubject(approver)
+			if err != nil {
+				return entities.DevOpsTask{}, at(err.Error(), "approval", "approvers", j, "kind")
+			}
+			policy.Approvers = append(policy.Approvers, subject)
+		}
+		task.ApprovalPolicy = policy
+	}
+	if spec.Input != nil {
+		if spec.Type != entities.TaskTypeManual {
+			return entities.DevOpsTask{}, at("input is only allowed in MANUAL tasks", "input")
+		}
+		task.InputForm = &entities.InputForm{
+			Prompt:  spec.Input.Prompt,
+			Fields:  parameterDefinitions(spec.Input.Fields),
+			Timeout: time.Duration(spec.Input.Timeout),
+		}
+	}
+	if err := entities.ValidateParameterDefinitions(task.AllParameters()); err != nil {
+		return entities.DevOpsTask{}, at(err.Error(), "parameters")
+	}
+	if spec.Trigger != nil {
+		trigger, field, err := buildTrigger(*spec.Trigger, spec.Type)
+		if err != nil {
+			return entities.DevOpsTask{}, at(err.Error(), "trigger", field)
+		}
+		task.Trigger = &trigger
+	}
+	return task, nil
+}
+
+// buildTrigger devuelve también el campo del manifiesto al que se refiere un error.
+func buildTrigger(spec TriggerManifest, taskType entities.TaskType) (entities.Trigger, string, error) {
+	defined := 0
+	for _, set := range []bool{spec.Schedule != nil, spec.Webhook != nil, spec.Completion != nil} {
+		if set {
+			defined++
+		}
+	}
+	if defined != 1 {
+		return nil, "", errors.New("trigger must define exactly one of schedule, webhook or completion")
+	}
+
+	switch {
+	case spec.Schedule != nil:
+		if taskType != entities.TaskTypeScheduled {
+			return nil, "schedule", errors.New("schedule triggers require a SCHEDULED task")
+		}
+		switch spec.Schedule.MissedRunPolicy {
+		case "", entities.MissedRunSkip, entities.MissedRunOnce, entities.MissedRunCatchUp:
+		default:
+			return nil, "schedule", fmt.Errorf("unknown missedRunPolicy %q", spec.Schedule.MissedRunPolicy)
+		}
+		trigger := &entities.ScheduledTrigger{
+			Expression:      spec.Schedule.Expression,
+			Timezone:        spec.Schedule.Timezone,
+			MissedRunPolicy: spec.Schedule.MissedRunPolicy,
+		}
+		if _, err := trigger.Schedule(); err != nil {
+			return nil, "schedule", err
+		}
+		return trigger, "", nil
+	case spec.Webhook != nil:
+		if taskType != entities.TaskTypeTriggered {
+			return nil, "webhook", errors.New("webhook triggers require a TRIGGERED task")
+		}
+		trigger := &entities.WebhookTrigger{
+			Secret:        spec.Webhook.Secret,
+			AllowUnsigned: spec.Webhook.AllowUnsigned,
+			Events:        spec.Webhook.Events,
+			Parameters:    spec.Webhook.Parameters,
+		}
+		for _, condition := range spec.Webhook.Conditions {
+			trigger.Conditions = append(trigger.Conditions, entities.WebhookCondition{
+				Path:    condition.Path,
+				Equals:  condition.Equals,
+				Matches: condition.Matches,
+			})
+		}
+		return trigger, "", nil
+	default:
+		if taskType != entities.TaskTypeTriggered {
+			return nil, "completion", errors.New("completion triggers require a TRIGGERED task")
+		}
+		return &entities.TaskCompletionTrigger{
+			TaskID:      spec.Completion.TaskID,
+			WorkspaceID: spec.Completion.WorkspaceID,
+			On:          spec.Completion.On,
+			Parameters:  spec.Completion.Parameters,
+		}, "", nil
+	}
+}
+
+func approverSubject(spec SubjectManifest) (entities.Subject, error) {
+	switch spec.Kind {
+	case "user":
+		return entities.User{ID: spec.ID, Name: spec.Name}, nil
+	case "group":
+		return entities.Group{ID: spec.ID, Name: spec.Name}, nil
+	case "serviceAccount":
+		return entities.ServiceAccount{ID: spec.ID, Name: spec.Name}, nil
+	default:
+		return nil, fmt.Errorf("unknown approver kind %q", spec.Kind)
+	}
+}
+
+func parameterDefinitions(specs []ParameterManifest) []entities.ParameterDefinition {
+	if specs == nil {
+		return nil
+	}
+	definitions := make([]entities.ParameterDefinition, len(specs))
+	for i, spec := range specs {
+		definitions[i] = entities.ParameterDefinition{
+			Name:        spec.Name,
+			Description: spec.Description,
+			Type:        spec.Type,
+			Default:     spec.Default,
+			Required:    spec.Required,
+			Enum:        spec.Enum,
+			Pattern:     spec.Pattern,
+			Secret:      spec.Secret,
+		}
+	}
+	return definitions
+}
+
+func taskConfig(spec ConfigManifest) entities.TaskConfig {
+	config := entities.TaskConfig{Parameters: spec.Parameters, Workspace: spec.Workspace}
+	if spec.Retry != nil {
+		config.Retry = &entities.RetryPolicy{
+			MaxAttempts:    spec.Retry.MaxAttempts,
+			InitialBackoff: time.Duration(spec.Retry.InitialBackoff),
+			MaxBackoff:     time.Duration(spec.Retry.MaxBackoff),
+			Multiplier:     spec.Retry.Multiplier,
+			Jitter:         spec.Retry.Jitter,
+			RetryOn:        spec.Retry.RetryOn,
+		}
+	}
+	for _, secret := range spec.Secrets {
+		config.Secrets = append(config.Secrets, entities.SecretReference{EnvVar: secret.EnvVar, Key: secret.Key})
+	}
+	for _, output := range spec.Outputs {
+		config.Outputs = append(config.Outputs, entities.OutputSpec{Name: output.Name, Path: output.Path})
+	}
+	for _, input := range spec.Inputs {
+		config.Inputs = append(config.Inputs, entities.ArtifactInput{TaskID: input.Task, Artifact: input.Artifact, Path: input.Path})
+	}
+	if spec.Matrix != nil {
+		config.Matrix = &entities.Matrix{
+			Exclude:     spec.Matrix.Exclude,
+			Include:     spec.Matrix.Include,
+			MaxParallel: spec.Matrix.MaxParallel,
+			FailFast:    spec.Matrix.FailFast,
+		}
+		for _, axis := range spec.Matrix.Axes {
+			config.Matrix.Axes = append(config.Matrix.Axes, entities.MatrixAxis{Parameter: axis.Parameter, Values: axis.Values})
+		}
+	}
+	return config
+}
+
+// taskManifest convierte una tarea en su manifiesto. Los tokens y secretos de los
+// webhooks no se exportan.
+func taskManifest(task entities.DevOpsTask) TaskManifest {
+	spec := TaskManifest{
+		ID:          task.ID,
+		Name:        task.Name,
+		Title:       task.Title,
+		Description: task.Description,
+		Type:        task.TaskType,
+		Workspace:   task.Workspace.ID,
+		Tags:        task.Tags,
+		Priority:    task.Priority,
+		Parameters:  parameterManifests(task.Parameters),
+	}
+	if task.Worker != nil {
+		spec.Worker = workerManifest(task.Worker)
+	}
+	if config := task.Config; config.Parameters != nil || config.Workspace != "" || config.Retry != nil || len(config.Secrets) > 0 || len(config.Outputs) > 0 || len(config.Inputs) > 0 || config.Matrix != nil {
+		spec.Config = &ConfigManifest{Parameters: config.Parameters, Workspace: config.Workspace}
+		if retry := config.Retry; retry != nil {
+			spec.Config.Retry = &RetryManifest{
+				MaxAttempts:    retry.MaxAttempts,
+				InitialBackoff: Duration(retry.InitialBackoff),
+				MaxBackoff:     Duration(retry.MaxBackoff),
+				Multiplier:     retry.Multiplier,
+				Jitter:         retry.Jitter,
+				RetryOn:        retry.RetryOn,
+			}
+		}
+		for _, secret := range config.Secrets {
+			spec.Config.Secrets = append(spec.Config.Secrets, SecretManifest{EnvVar: secret.EnvVar, Key: secret.Key})
+		}
+		for _, output := range config.Outputs {
+			spec.Config.Outputs = append(spec.Config.Outputs, OutputManifest{Name: output.Name, Path: output.Path})
+		}
+		for _, input := range config.Inputs {
+			spec.Config.Inputs = append(spec.Config.Inputs, ArtifactInputManifest{Task: input.TaskID, Artifact: input.Artifact, Path: input.Path})
+		}
+		if matrix := config.Matrix; matrix != nil {
+			spec.Config.Matrix = &MatrixManifest{
+				Exclude:     matrix.Exclude,
+				Include:     matrix.Include,
+				MaxParallel: matrix.MaxParallel,
+				FailFast:    matrix.FailFast,
+			}
+			for _, axis := range matrix.Axes {
+				spec.Config.Matrix.Axes = append(spec.Config.Matrix.Axes, MatrixAxisManifest{Parameter: axis.Parameter, Values: axis.Values})
+			}
+		}
+	}
+	if task.Trigger != nil {
+		spec.Trigger = triggerManifest(*task.Trigger)
+	}
+	if policy := task.ApprovalPolicy; policy != nil {
+		spec.Approval = &ApprovalManifest{Quorum: policy.Quorum, Timeout: Duration(policy.Timeout)}
+		for _, approver := range policy.Approvers {
+			spec.Approval.Approvers = append(spec.Approval.Approvers, subjectManifest(approver))
+		}
+	}
+	if form := task.InputForm; form != nil {
+		spec.Input = &InputManifest{Prompt: form.Prompt, Fields: parameterManifests(form.Fields), Timeout: Duration(form.Timeout)}
+	}
+	return spec
+}
+
+func triggerManifest(trigger entities.Trigger) *TriggerManifest {
+	switch t := trigger.(type) {
+	case *entities.ScheduledTrigger:
+		return &TriggerManifest{Schedule: &ScheduleManifest{
+			Expression:      t.Expression,
+			Timezone:        t.Timezone,
+			MissedRunPolicy: t.MissedRunPolicy,
+		}}
+	case *entities.WebhookTrigger:
+		webhook := &WebhookManifest{AllowUnsigned: t.AllowUnsigned, Events: t.Events, Parameters: t.Parameters}
+		for _, condition := range t.Conditions {
+			webhook.Conditions = append(webhook.Conditions, WebhookConditionManifest{
+				Path:    condition.Path,
+				Equals:  condition.Equals,
+				Matches: condition.Matches,
+			})
+		}
+		return &TriggerManifest{Webhook: webhook}
+	case *entities.TaskCompletionTrigger:
+		return &TriggerManifest{Completion: &CompletionManifest{
+			TaskID:      t.TaskID,
+			WorkspaceID: t.WorkspaceID,
+			On:          t.On,
+			Parameters:  t.Parameters,
+		}}
+	default:
+		return nil
+	}
+}
+
+func subjectManifest(subject entities.Subject) SubjectManifest {
+	return SubjectManifest{Kind: entities.SubjectKind(subject), ID: subject.GetID(), Name: subject.GetName()}
+}
+
+func parameterManifests(definitions []entities.ParameterDefinition) []ParameterManifest {
+	if definitions == nil {
+		return nil
+	}
+	specs := make([]ParameterManifest, len(definitions))
+	for i, definition := range definitions {
+		specs[i] = ParameterManifest{
+			Name:        definition.Name,
+			Description: definition.Description,
+			Type:        definition.Type,
+			Default:     definition.Default,
+			Required:    definition.Required,
+			Enum:        definition.Enum,
+			Pattern:     definition.Pattern,
+			Secret:      definition.Secret,
+		}
+	}
+	return specs
+}
+
+// workerManifest reconstruye el manifiesto del worker a partir de GetDetails.
+func workerManifest(worker entities.Worker) ports.WorkerManifest {
+	details := worker.GetDetails()
+	spec := ports.WorkerManifest{Type: worker.GetType(), Name: worker.GetID()}
+	spec.Image, _ = details["Image"].(string)
+	spec.Command, _ = details["Command"].([]string)
+	spec.Args, _ = details["Args"].([]string)
+	spec.WorkingDir, _ = details["WorkingDir"].(string)
+	spec.Environment, _ = details["Environment"].(map[string]string)
+	spec.Namespace, _ = details["Namespace"].(string)
+	return spec
+}
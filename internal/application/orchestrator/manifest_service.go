@@ -0,0 +1,263 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"sort"
+)
+
+type ManifestService interface {
+	Apply(ctx context.Context, data []byte, options ApplyOptions) ([]ManifestChange, error)
+	Export(ctx context.Context, filters ports.TaskFilters, format string) ([]byte, error)
+}
+
+type ApplyOptions struct {
+	// Prune borra las tareas de los workspaces del manifiesto que no aparecen en él.
+	Prune bool
+	// DryRun calcula los cambios sin aplicarlos.
+	DryRun bool
+}
+
+type ManifestAction string
+
+const (
+	ManifestCreate ManifestAction = "CREATE"
+	ManifestUpdate ManifestAction = "UPDATE"
+	ManifestDelete ManifestAction = "DELETE"
+)
+
+// ManifestChange es el cambio que Apply hace, o haría con DryRun, sobre una tarea.
+// Changes solo se rellena en las actualizaciones; los secretos van enmascarados.
+type ManifestChange struct {
+	Action  ManifestAction         `json:"action"`
+	TaskID  string                 `json:"taskId"`
+	Changes []entities.FieldChange `json:"changes,omitempty"`
+}
+
+// ManifestServiceImpl aplica y exporta manifiestos de tareas. Los tipos de worker admitidos
+// son los registrados con RegisterWorkerType.
+type ManifestServiceImpl struct {
+	repository ports.TaskRepository
+	tasks      *TaskServiceImpl
+	workers    map[string]ports.WorkerFactory
+}
+
+func NewManifestServiceImpl(taskRepo ports.TaskRepository, taskService *TaskServiceImpl) *ManifestServiceImpl {
+	return &ManifestServiceImpl{
+		repository: taskRepo,
+		tasks:      taskService,
+		workers:    make(map[string]ports.WorkerFactory),
+	}
+}
+
+func (s *ManifestServiceImpl) RegisterWorkerType(workerType string, factory ports.WorkerFactory) {
+	s.workers[workerType] = factory
+}
+
+// Apply crea las tareas del manifiesto que no existen y actualiza las que difieren. Con
+// Prune también borra las tareas de sus workspaces que no aparecen en él. Devuelve los
+// cambios en el orden en que se aplican; si uno falla, devuelve los ya aplicados y el error.
+func (s *ManifestServiceImpl) Apply(ctx context.Context, data []byte, options ApplyOptions) ([]ManifestChange, error) {
+	parsed, err := parseManifest(data)
+	if err != nil {
+		return nil, err
+	}
+	desired, err := parsed.buildTasks(s.workers)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.repository.GetAll(ctx, ports.TaskFilters{})
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]entities.DevOpsTask, len(page.Tasks))
+	for _, task := range page.Tasks {
+		existing[task.ID] = task
+	}
+
+	var changes []ManifestChange
+	for i := range desired {
+		task := &desired[i]
+		current, ok := existing[task.ID]
+		if !ok {
+			changes = append(changes, ManifestChange{Action: ManifestCreate, TaskID: task.ID})
+			continue
+		}
+		preserveRuntimeState(task, current)
+		if diff := s.diff(current, *task); len(diff) > 0 {
+			changes = append(changes, ManifestChange{Action: ManifestUpdate, TaskID: task.ID, Changes: diff})
+		}
+	}
+	if options.Prune {
+		changes = append(changes, pruneChanges(parsed.Workspaces, desired, page.Tasks)...)
+	}
+	if options.DryRun {
+		return changes, nil
+	}
+
+	byID := make(map[string]entities.DevOpsTask, len(desired))
+	for _, task := range desired {
+		byID[task.ID] = task
+	}
+	for i, change := range changes {
+		var err error
+		switch change.Action {
+		case ManifestCreate:
+			_, err = s.tasks.CreateTask(ctx, byID[change.TaskID])
+		case ManifestUpdate:
+			_, err = s.tasks.ReplaceTask(ctx, byID[change.TaskID])
+		case ManifestDelete:
+			err = s.tasks.DeleteTask(ctx, change.TaskID)
+		}
+		if err != nil {
+			return changes[:i], fmt.Errorf("applying %s of task %s: %w", change.Action, change.TaskID, err)
+		}
+	}
+	return changes, nil
+}
+
+// diff compara la tarea existente con la del manifiesto. La existente se pasa antes por
+// su propio manifiesto para no mostrar diferencias que el formato no puede expresar.
+func (s *ManifestServiceImpl) diff(current, desired entities.DevOpsTask) []entities.FieldChange {
+	normalized := current.Snapshot()
+	spec := taskManifest(current)
+	workspaces := map[string]entities.Workspace{current.Workspace.ID: current.Workspace}
+	if task, err := (&parsedManifest{}).buildTask(0, spec, workspaces, s.workers); err == nil {
+		preserveRuntimeState(&task, current)
+		normalized = task
+	}
+
+	changes := entities.DiffTasks(diffView(normalized), diffView(desired))
+	if webhookSecret(normalized) != webhookSecret(desired) {
+		changes = append(changes, entities.FieldChange{
+			Field: "Trigger.Secret",
+			From:  entities.MaskedParameterValue,
+			To:    entities.MaskedParameterValue,
+		})
+	}
+	return changes
+}
+
+// pruneChanges devuelve, ordenados por ID, los borrados de las tareas de los workspaces
+// del manifiesto que no aparecen en él. Las tareas de otros workspaces no se tocan.
+func pruneChanges(workspaces []WorkspaceManifest, desired, existing []entities.DevOpsTask) []ManifestChange {
+	managed := make(map[string]bool, len(workspaces))
+	for _, workspace := range workspaces {
+		managed[workspace.ID] = true
+	}
+	declared := make(map[string]bool, len(desired))
+	for _, task := range desired {
+		declared[task.ID] = true
+	}
+
+	var pruned []string
+	for _, task := range existing {
+		if managed[task.Workspace.ID] && !declared[task.ID] {
+			pruned = append(pruned, task.ID)
+		}
+	}
+	sort.Strings(pruned)
+	changes := make([]ManifestChange, len(pruned))
+	for i, id := range pruned {
+		changes[i] = ManifestChange{Action: ManifestDelete, TaskID: id}
+	}
+	return changes
+}
+
+// preserveRuntimeState copia en la tarea del manifiesto el estado que genera el propio
+// orquestador: el token del webhook (y su secreto si el manifiesto no lo define) y la
+// última activación programada.
+func preserveRuntimeState(task *entities.DevOpsTask, current entities.DevOpsTask) {
+	if webhook, ok := task.WebhookTrigger(); ok {
+		if previous, ok := current.WebhookTrigger(); ok {
+			webhook.Token = previous.Token
+			if webhook.Secret == "" {
+				webhook.Secret = previous.Secret
+			}
+		}
+	}
+	if task.Trigger == nil || current.Trigger == nil {
+		return
+	}
+	if schedule, ok := (*task.Trigger).(*entities.ScheduledTrigger); ok {
+		if previous, ok := (*current.Trigger).(*entities.ScheduledTrigger); ok {
+			schedule.LastRun = previous.LastRun
+		}
+	}
+}
+
+// diffView es la versión de la tarea que se muestra en el diff: sin el token del webhook
+// y con los secretos enmascarados.
+func diffView(task entities.DevOpsTask) entities.DevOpsTask {
+	view := task.MaskedCopy()
+	if webhook, ok := task.WebhookTrigger(); ok {
+		masked := *webhook
+		masked.Token = ""
+		if masked.Secret != "" {
+			masked.Secret = entities.MaskedParameterValue
+		}
+		var trigger entities.Trigger = &masked
+		view.Trigger = &trigger
+	}
+	return view
+}
+
+func webhookSecret(task entities.DevOpsTask) string {
+	if webhook, ok := task.WebhookTrigger(); ok {
+		return webhook.Secret
+	}
+	return ""
+}
+
+// Export genera el manifiesto, en "yaml" (por defecto) o "json", de las tareas que cumplen
+// filters y de sus workspaces que el sujeto puede ver. Ignora la paginación de filters y
+// ordena las tareas por ID.
+func (s *ManifestServiceImpl) Export(ctx context.Context, filters ports.TaskFilters, format string) ([]byte, error) {
+	filters.Limit = 0
+	filters.Cursor = ""
+	page, err := s.tasks.GetTasks(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	tasks := page.Tasks
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+
+	manifest := Manifest{APIVersion: ManifestAPIVersion, Kind: ManifestKind}
+	workspaces := make(map[string]bool)
+	for _, task := range tasks {
+		if !workspaces[task.Workspace.ID] {
+			workspaces[task.Workspace.ID] = true
+			manifest.Workspaces = append(manifest.Workspaces, WorkspaceManifest{
+				ID:       task.Workspace.ID,
+				Name:     task.Workspace.Name,
+				TenantID: task.Workspace.TenantID,
+			})
+		}
+		manifest.Tasks = append(manifest.Tasks, taskManifest(task))
+	}
+	sort.Slice(manifest.Workspaces, func(i, j int) bool { return manifest.Workspaces[i].ID < manifest.Workspaces[j].ID })
+
+	switch format {
+	case "", "yaml":
+		var buffer bytes.Buffer
+		encoder := yaml.NewEncoder(&buffer)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(manifest); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	case "json":
+		return json.MarshalIndent(manifest, "", "  ")
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidManifest, format)
+	}
+}
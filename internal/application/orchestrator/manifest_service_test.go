@@ -0,0 +1,278 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	workers "devops_console/internal/infrastructure/orchestrator/workers"
+	ports "devops_console/internal/ports/orchestrator"
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"testing"
+)
+
+const baseManifest = `apiVersion: devops-console/v1
+kind: TaskManifest
+workspaces:
+  - id: payments
+    name: Payments
+    tenantId: acme
+tasks:
+  - id: nightly-backup
+    name: backup
+    type: SCHEDULED
+    workspace: payments
+    tags: [backup]
+    worker:
+      type: Docker
+      image: postgres:16
+      command: [pg_dump]
+    parameters:
+      - name: database
+        type: STRING
+        default: payments
+    config:
+      retry:
+        maxAttempts: 3
+        initialBackoff: 30s
+    trigger:
+      schedule:
+        expression: "0 2 * * *"
+        timezone: Europe/Madrid
+  - id: deploy
+    name: deploy
+    type: TRIGGERED
+    workspace: payments
+    worker:
+      type: Kubernetes
+      namespace: payments
+      image: registry/deployer:1.4
+      args: ["--ref=${{ params.ref }}"]
+    parameters:
+      - name: ref
+        type: STRING
+        required: true
+    trigger:
+      webhook:
+        secret: s3cr3t
+        events: [push]
+        parameters:
+          ref: $.ref
+`
+
+type ManifestServiceTestSuite struct {
+	suite.Suite
+	repo    *adapters.InMemoryTaskRepository
+	tasks   *orchestrator2.TaskServiceImpl
+	service *orchestrator2.ManifestServiceImpl
+}
+
+func (suite *ManifestServiceTestSuite) SetupTest() {
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.tasks = orchestrator2.NewTaskServiceImpl(suite.repo)
+	suite.service = orchestrator2.NewManifestServiceImpl(suite.repo, suite.tasks)
+	suite.service.RegisterWorkerType("Docker", workers.NewDockerWorkerFromManifest)
+	suite.service.RegisterWorkerType("Kubernetes", workers.NewKubernetesWorkerFromManifest)
+}
+
+func (suite *ManifestServiceTestSuite) TestApplyCreatesTasks() {
+	changes, err := suite.service.Apply(context.Background(), []byte(baseManifest), orchestrator2.ApplyOptions{})
+	suite.Require().NoError(err)
+	suite.Equal([]orchestrator2.ManifestChange{
+		{Action: orchestrator2.ManifestCreate, TaskID: "nightly-backup"},
+		{Action: orchestrator2.ManifestCreate, TaskID: "deploy"},
+	}, changes)
+
+	task, err := suite.repo.GetByID(context.Background(), "deploy")
+	suite.Require().NoError(err)
+	suite.Equal(entities.Workspace{ID: "payments", Name: "Payments", TenantID: "acme"}, task.Workspace)
+	suite.Equal(&workers.KubernetesWorker{
+		Namespace: "payments",
+		Image:     "registry/deployer:1.4",
+		Args:      []string{"--ref=${{ params.ref }}"},
+	}, task.Worker)
+	webhook, ok := task.WebhookTrigger()
+	suite.Require().True(ok)
+	suite.NotEmpty(webhook.Token)
+	suite.Equal("s3cr3t", webhook.Secret)
+}
+
+func (suite *ManifestServiceTestSuite) TestUnknownFieldFailsWithLine() {
+	manifest := `apiVersion: devops-console/v1
+kind: TaskManifest
+workspaces:
+  - id: payments
+tasks:
+  - id: deploy
+    type: MANUAL
+    workspace: payments
+    worker:
+      type: Docker
+      image: alpine
+      imagePullPolicy: Always
+`
+	_, err := suite.service.Apply(context.Background(), []byte(manifest), orchestrator2.ApplyOptions{})
+	suite.ErrorIs(err, orchestrator2.ErrInvalidManifest)
+	suite.ErrorContains(err, "line 12")
+	suite.ErrorContains(err, "imagePullPolicy")
+}
+
+func (suite *ManifestServiceTestSuite) TestUnknownWorkerTypeFailsWithLine() {
+	manifest := `apiVersion: devops-console/v1
+kind: TaskManifest
+workspaces:
+  - id: payments
+tasks:
+  - id: deploy
+    type: MANUAL
+    workspace: payments
+    worker:
+      type: Nomad
+      image: alpine
+`
+	_, err := suite.service.Apply(context.Background(), []byte(manifest), orchestrator2.ApplyOptions{})
+	suite.ErrorIs(err, orchestrator2.ErrInvalidManifest)
+	suite.ErrorContains(err, "line 10")
+	suite.ErrorContains(err, `unknown worker type "Nomad"`)
+}
+
+func (suite *ManifestServiceTestSuite) TestTriggerMustMatchTaskType() {
+	manifest := `apiVersion: devops-console/v1
+kind: TaskManifest
+workspaces:
+  - id: payments
+tasks:
+  - id: deploy
+    type: MANUAL
+    workspace: payments
+    worker: {type: Docker, image: alpine}
+    trigger:
+      schedule:
+        expression: "0 2 * * *"
+`
+	_, err := suite.service.Apply(context.Background(), []byte(manifest), orchestrator2.ApplyOptions{})
+	suite.ErrorIs(err, orchestrator2.ErrInvalidManifest)
+	suite.ErrorContains(err, "line 12")
+}
+
+func (suite *ManifestServiceTestSuite) TestDryRunShowsDiffWithoutApplying() {
+	_, err := suite.service.Apply(context.Background(), []byte(baseManifest), orchestrator2.ApplyOptions{})
+	suite.Require().NoError(err)
+	before, err := suite.repo.GetByID(context.Background(), "nightly-backup")
+	suite.Require().NoError(err)
+
+	changed := replace(suite.T(), baseManifest, "image: postgres:16", "image: postgres:17")
+	changed = replace(suite.T(), changed, "secret: s3cr3t", "secret: n3w")
+	changes, err := suite.service.Apply(context.Background(), []byte(changed), orchestrator2.ApplyOptions{DryRun: true})
+	suite.Require().NoError(err)
+	suite.Require().Len(changes, 2)
+	suite.Equal(orchestrator2.ManifestUpdate, changes[0].Action)
+	suite.Equal("nightly-backup", changes[0].TaskID)
+	suite.Require().Len(changes[0].Changes, 1)
+	suite.Equal("Worker", changes[0].Changes[0].Field)
+	suite.Equal("deploy", changes[1].TaskID)
+	suite.Equal([]entities.FieldChange{{
+		Field: "Trigger.Secret",
+		From:  entities.MaskedParameterValue,
+		To:    entities.MaskedParameterValue,
+	}}, changes[1].Changes)
+
+	after, err := suite.repo.GetByID(context.Background(), "nightly-backup")
+	suite.Require().NoError(err)
+	suite.Equal(before.Revision, after.Revision)
+
+	_, err = suite.service.Apply(context.Background(), []byte(changed), orchestrator2.ApplyOptions{})
+	suite.Require().NoError(err)
+	after, err = suite.repo.GetByID(context.Background(), "nightly-backup")
+	suite.Require().NoError(err)
+	suite.Equal(before.Revision+1, after.Revision)
+	suite.Equal(before.CreatedAt, after.CreatedAt)
+	suite.Equal("postgres:17", after.Worker.GetDetails()["Image"])
+}
+
+func (suite *ManifestServiceTestSuite) TestUpdateKeepsWebhookToken() {
+	_, err := suite.service.Apply(context.Background(), []byte(baseManifest), orchestrator2.ApplyOptions{})
+	suite.Require().NoError(err)
+	before, _ := suite.repo.GetByID(context.Background(), "deploy")
+	token := mustWebhook(suite.T(), before).Token
+
+	withoutSecret := replace(suite.T(), baseManifest, "        secret: s3cr3t\n", "")
+	changes, err := suite.service.Apply(context.Background(), []byte(replace(suite.T(), withoutSecret, "deployer:1.4", "deployer:1.5")), orchestrator2.ApplyOptions{})
+	suite.Require().NoError(err)
+	suite.Len(changes, 1)
+
+	after, _ := suite.repo.GetByID(context.Background(), "deploy")
+	suite.Equal(token, mustWebhook(suite.T(), after).Token)
+	suite.Equal("s3cr3t", mustWebhook(suite.T(), after).Secret)
+}
+
+func (suite *ManifestServiceTestSuite) TestPruneOnlyTouchesManifestWorkspaces() {
+	_, err := suite.service.Apply(context.Background(), []byte(baseManifest), orchestrator2.ApplyOptions{})
+	suite.Require().NoError(err)
+	_, err = suite.tasks.CreateTask(context.Background(), entities.DevOpsTask{ID: "other", Workspace: entities.Workspace{ID: "billing"}, Worker: &workers.DockerWorker{Image: "alpine"}})
+	suite.Require().NoError(err)
+
+	withoutBackup := `apiVersion: devops-console/v1
+kind: TaskManifest
+workspaces:
+  - id: payments
+    name: Payments
+    tenantId: acme
+`
+	changes, err := suite.service.Apply(context.Background(), []byte(withoutBackup), orchestrator2.ApplyOptions{})
+	suite.Require().NoError(err)
+	suite.Empty(changes)
+
+	changes, err = suite.service.Apply(context.Background(), []byte(withoutBackup), orchestrator2.ApplyOptions{Prune: true})
+	suite.Require().NoError(err)
+	suite.Equal([]orchestrator2.ManifestChange{
+		{Action: orchestrator2.ManifestDelete, TaskID: "deploy"},
+		{Action: orchestrator2.ManifestDelete, TaskID: "nightly-backup"},
+	}, changes)
+	page, err := suite.repo.GetAll(context.Background(), ports.TaskFilters{})
+	suite.Require().NoError(err)
+	suite.Require().Len(page.Tasks, 1)
+	suite.Equal("other", page.Tasks[0].ID)
+}
+
+func (suite *ManifestServiceTestSuite) TestExportRoundTrip() {
+	_, err := suite.service.Apply(context.Background(), []byte(baseManifest), orchestrator2.ApplyOptions{})
+	suite.Require().NoError(err)
+
+	for _, format := range []string{"yaml", "json"} {
+		exported, err := suite.service.Export(context.Background(), ports.TaskFilters{WorkspaceID: "payments"}, format)
+		suite.Require().NoError(err)
+		suite.NotContains(string(exported), "s3cr3t")
+
+		changes, err := suite.service.Apply(context.Background(), exported, orchestrator2.ApplyOptions{Prune: true, DryRun: true})
+		suite.Require().NoError(err, string(exported))
+		suite.Empty(changes, format)
+	}
+}
+
+func (suite *ManifestServiceTestSuite) TestExportRejectsUnknownFormat() {
+	_, err := suite.service.Export(context.Background(), ports.TaskFilters{}, "toml")
+	suite.ErrorIs(err, orchestrator2.ErrInvalidManifest)
+}
+
+func mustWebhook(t *testing.T, task entities.DevOpsTask) *entities.WebhookTrigger {
+	webhook, ok := task.WebhookTrigger()
+	if !ok {
+		t.Fatalf("task %s has no webhook trigger", task.ID)
+	}
+	return webhook
+}
+
+func replace(t *testing.T, text, old, new string) string {
+	t.Helper()
+	replaced := strings.Replace(text, old, new, 1)
+	if replaced == text {
+		t.Fatalf("%q not found", old)
+	}
+	return replaced
+}
+
+func TestManifestServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ManifestServiceTestSuite))
+}
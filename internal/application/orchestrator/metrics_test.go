@@ -0,0 +1,134 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	eventstream "devops_console/internal/infrastructure/orchestrator/events"
+	metrics "devops_console/internal/infrastructure/orchestrator/metrics"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	ports "devops_console/internal/ports/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type MetricsTestSuite struct {
+	suite.Suite
+	tasks    *orchestrator2.TaskServiceImpl
+	repo     *adapters.InMemoryTaskRepository
+	executor *fakeExecutor
+	stream   *eventstream.TaskEventStreamImpl
+	metrics  *metrics.OrchestratorMetrics
+}
+
+func (suite *MetricsTestSuite) SetupTest() {
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.executor = newFakeExecutor()
+	suite.stream = eventstream.NewTaskEventStream()
+	suite.metrics = metrics.NewOrchestratorMetrics(metrics.NewRegistry())
+	suite.metrics.ObserveEventStream(suite.stream)
+	suite.tasks = orchestrator2.NewTaskServiceImpl(suite.repo)
+	suite.tasks.RegisterExecutor("Stub", suite.executor)
+	suite.tasks.EventStream = suite.stream
+	suite.tasks.Metrics = suite.metrics
+}
+
+func (suite *MetricsTestSuite) TearDownTest() {
+	suite.tasks.Shutdown()
+}
+
+func (suite *MetricsTestSuite) run(taskID string, status entities.TaskStatus) {
+	suite.executor.mu.Lock()
+	suite.executor.results[taskID] = status
+	suite.executor.mu.Unlock()
+	executionID, err := suite.tasks.ExecuteTask(context.Background(), taskID)
+	suite.Require().NoError(err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = suite.tasks.WaitForExecution(ctx, executionID)
+	suite.Require().NoError(err)
+}
+
+// scrape devuelve las métricas tal y como las sirve GET /metrics.
+func (suite *MetricsTestSuite) scrape() string {
+	server := httptest.NewServer(suite.metrics.Registry)
+	defer server.Close()
+	response, err := http.Get(server.URL + "/metrics")
+	suite.Require().NoError(err)
+	defer response.Body.Close()
+	assert.Equal(suite.T(), "text/plain; version=0.0.4; charset=utf-8", response.Header.Get("Content-Type"))
+	body, err := io.ReadAll(response.Body)
+	suite.Require().NoError(err)
+	return string(body)
+}
+
+func (suite *MetricsTestSuite) TestMetrics_CountsExecutionsByStatus() {
+	_, err := suite.tasks.CreateTask(context.Background(), entities.DevOpsTask{ID: "deploy", Workspace: payments, Worker: &stubWorker{}})
+	suite.Require().NoError(err)
+
+	suite.run("deploy", entities.TaskSucceeded)
+	suite.run("deploy", entities.TaskSucceeded)
+	suite.run("deploy", entities.TaskFailed)
+
+	body := suite.scrape()
+	assert.Contains(suite.T(), body, "# TYPE devops_executions_started_total counter\n")
+	assert.Contains(suite.T(), body, `devops_executions_started_total{worker_type="Stub",workspace="payments"} 3`+"\n")
+	assert.Contains(suite.T(), body, `devops_executions_finished_total{worker_type="Stub",workspace="payments",status="SUCCEEDED"} 2`+"\n")
+	assert.Contains(suite.T(), body, `devops_executions_finished_total{worker_type="Stub",workspace="payments",status="FAILED"} 1`+"\n")
+	assert.Contains(suite.T(), body, "# TYPE devops_execution_duration_seconds histogram\n")
+	assert.Contains(suite.T(), body, `devops_execution_duration_seconds_bucket{worker_type="Stub",workspace="payments",le="+Inf"} 3`+"\n")
+	assert.Contains(suite.T(), body, `devops_execution_duration_seconds_count{worker_type="Stub",workspace="payments"} 3`+"\n")
+	assert.NotContains(suite.T(), body, "devops_execution_queue_wait_seconds_count")
+}
+
+func (suite *MetricsTestSuite) TestMetrics_MeasuresQueueWait() {
+	suite.tasks.SetConcurrencyLimits(orchestrator2.ConcurrencyLimits{PerTask: 1})
+	_, err := suite.tasks.CreateTask(context.Background(), entities.DevOpsTask{ID: "deploy", Workspace: payments, Worker: &stubWorker{}})
+	suite.Require().NoError(err)
+
+	suite.run("deploy", entities.TaskSucceeded)
+	suite.run("deploy", entities.TaskSucceeded)
+
+	body := suite.scrape()
+	assert.Contains(suite.T(), body, `devops_execution_queue_wait_seconds_count{worker_type="Stub",workspace="payments"} 2`+"\n")
+	assert.Contains(suite.T(), body, `devops_executions_started_total{worker_type="Stub",workspace="payments"} 2`+"\n")
+}
+
+func (suite *MetricsTestSuite) TestMetrics_ExposesEventStreamAndAgents() {
+	events, err := suite.stream.Subscribe(ports.AllExecutions)
+	suite.Require().NoError(err)
+	_, err = suite.stream.Subscribe("execution-1")
+	suite.Require().NoError(err)
+	body := suite.scrape()
+	assert.Contains(suite.T(), body, "devops_event_stream_subscribers 2\n")
+	assert.Contains(suite.T(), body, "devops_event_stream_unrouted_events_total 0\n")
+
+	suite.stream.Unsubscribe(ports.AllExecutions, events)
+	suite.Require().NoError(suite.stream.Publish(entities.TaskEvent{ExecutionID: "execution-2", EventType: entities.EventTypeTaskOutput}))
+	body = suite.scrape()
+	assert.Contains(suite.T(), body, "devops_event_stream_subscribers 1\n")
+	assert.Contains(suite.T(), body, "# TYPE devops_event_stream_unrouted_events_total counter\n")
+	assert.Contains(suite.T(), body, "devops_event_stream_unrouted_events_total 1\n")
+
+	suite.metrics.AgentConnected("agent-1")
+	suite.metrics.AgentConnected("agent-2")
+	suite.metrics.AgentUsage("agent-1", 42.5, 63)
+	body = suite.scrape()
+	assert.Contains(suite.T(), body, "devops_agents_connected 2\n")
+	assert.Contains(suite.T(), body, `devops_agent_cpu_usage_percent{agent="agent-1"} 42.5`+"\n")
+	assert.Contains(suite.T(), body, `devops_agent_memory_usage_percent{agent="agent-1"} 63`+"\n")
+
+	suite.metrics.AgentDisconnected("agent-1")
+	body = suite.scrape()
+	assert.Contains(suite.T(), body, "devops_agents_connected 1\n")
+	assert.NotContains(suite.T(), body, `agent="agent-1"`)
+}
+
+func TestMetricsTestSuite(t *testing.T) {
+	suite.Run(t, new(MetricsTestSuite))
+}
@@ -0,0 +1,284 @@
+package orchestrator
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var ErrInvalidNotificationRule = errors.New("invalid notification rule")
+
+// defaultNotificationRetry reintenta cada envío durante unos minutos.
+var defaultNotificationRetry = entities.RetryPolicy{
+	MaxAttempts:    6,
+	InitialBackoff: 2 * time.Second,
+	MaxBackoff:     time.Minute,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// NotificationServiceImpl escucha los eventos que publica el TaskService y envía por los
+// canales de cada regla los fallos, las recuperaciones y las peticiones de aprobación de
+// las tareas. Cada envío fallido se reintenta según Retry salvo que el destino lo rechace
+// con ports.ErrNotificationRejected.
+type NotificationServiceImpl struct {
+	repository  ports.TaskRepository
+	tasks       *TaskServiceImpl
+	eventStream ports.TaskEventStream
+	// Retry define los intentos y las esperas de cada envío; RetryOn no se usa.
+	Retry    entities.RetryPolicy
+	channels map[string]ports.NotificationChannel
+	rules    []entities.NotificationRule
+	// events es la suscripción mientras el servicio está arrancado; stop cancela los envíos
+	// pendientes al pararlo.
+	events <-chan entities.TaskEvent
+	stop   context.CancelFunc
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+}
+
+func NewNotificationServiceImpl(taskRepo ports.TaskRepository, taskService *TaskServiceImpl, eventStream ports.TaskEventStream) *NotificationServiceImpl {
+	return &NotificationServiceImpl{
+		repository:  taskRepo,
+		tasks:       taskService,
+		eventStream: eventStream,
+		Retry:       defaultNotificationRetry,
+		channels:    make(map[string]ports.NotificationChannel),
+	}
+}
+
+// RegisterChannel da de alta un canal con el nombre con el que lo usan las reglas.
+func (s *NotificationServiceImpl) RegisterChannel(name string, channel ports.NotificationChannel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels[name] = channel
+}
+
+// AddRule añade una regla, cuyos canales tienen que estar ya registrados, y devuelve su ID.
+func (s *NotificationServiceImpl) AddRule(rule entities.NotificationRule) (string, error) {
+	if err := rule.Validate(); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidNotificationRule, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range rule.Channels {
+		if _, ok := s.channels[name]; !ok {
+			return "", fmt.Errorf("%w: unknown channel %q", ErrInvalidNotificationRule, name)
+		}
+	}
+	if rule.ID == "" {
+		rule.ID = s.tasks.GenerateID()
+	}
+	rule.On = append([]entities.NotificationTrigger(nil), rule.On...)
+	rule.Channels = append([]string(nil), rule.Channels...)
+	s.rules = append(s.rules, rule)
+	return rule.ID, nil
+}
+
+// RemoveRule quita la regla. Devuelve false si no existía.
+func (s *NotificationServiceImpl) RemoveRule(ruleID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, rule := range s.rules {
+		if rule.ID == ruleID {
+			s.rules = append(s.rules[:i:i], s.rules[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Rules devuelve las reglas configuradas.
+func (s *NotificationServiceImpl) Rules() []entities.NotificationRule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]entities.NotificationRule(nil), s.rules...)
+}
+
+// Start se suscribe a los eventos de todas las ejecuciones. El TaskService debe publicar
+// en el mismo stream (TaskServiceImpl.EventStream).
+func (s *NotificationServiceImpl) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.events != nil {
+		return nil
+	}
+	events, err := s.eventStream.Subscribe(ports.AllExecutions)
+	if err != nil {
+		return err
+	}
+	ctx, stop := context.WithCancel(s.tasks.lifetime)
+	s.events = events
+	s.stop = stop
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for event := range events {
+			// Se atiende aparte para no bloquear el stream mientras se envía.
+			switch payload := event.Payload.(type) {
+			case entities.TaskResultPayload:
+				s.wg.Add(1)
+				go func(executionID string) {
+					defer s.wg.Done()
+					s.handleResult(ctx, executionID, payload)
+				}(event.ExecutionID)
+			case entities.TaskApprovalPayload:
+				s.wg.Add(1)
+				go func(executionID string) {
+					defer s.wg.Done()
+					s.handleApprovalRequest(ctx, executionID, payload)
+				}(event.ExecutionID)
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop cierra la suscripción, cancela los reintentos pendientes y espera a que terminen
+// los envíos en curso.
+func (s *NotificationServiceImpl) Stop() {
+	s.mu.Lock()
+	events, stop := s.events, s.stop
+	s.events, s.stop = nil, nil
+	s.mu.Unlock()
+	if events == nil {
+		return
+	}
+	s.eventStream.Unsubscribe(ports.AllExecutions, events)
+	stop()
+	s.wg.Wait()
+}
+
+// handleResult notifica el fallo de una ejecución o, si termina bien tras un fallo, la
+// recuperación de la tarea.
+func (s *NotificationServiceImpl) handleResult(ctx context.Context, executionID string, result entities.TaskResultPayload) {
+	task, err := s.repository.GetByID(ctx, result.TaskID)
+	if err != nil {
+		log.Printf("Error notifying execution %s: %v", executionID, err)
+		return
+	}
+	var trigger entities.NotificationTrigger
+	switch {
+	case failed(result.Status):
+		trigger = entities.NotifyOnFailure
+	case result.Status == entities.TaskSucceeded && previousFailed(&task, executionID):
+		trigger = entities.NotifyOnRecovery
+	default:
+		return
+	}
+	s.notify(ctx, &task, entities.Notification{
+		Trigger:     trigger,
+		TaskID:      task.ID,
+		TaskName:    task.Name,
+		WorkspaceID: task.Workspace.ID,
+		ExecutionID: executionID,
+		Status:      result.Status,
+		Error:       result.Error,
+		Timestamp:   time.Now(),
+	})
+}
+
+func (s *NotificationServiceImpl) handleApprovalRequest(ctx context.Context, executionID string, request entities.TaskApprovalPayload) {
+	task, err := s.repository.GetByID(ctx, request.TaskID)
+	if err != nil {
+		log.Printf("Error notifying execution %s: %v", executionID, err)
+		return
+	}
+	s.notify(ctx, &task, entities.Notification{
+		Trigger:     entities.NotifyOnApprovalRequested,
+		TaskID:      task.ID,
+		TaskName:    task.Name,
+		WorkspaceID: task.Workspace.ID,
+		ExecutionID: executionID,
+		Status:      entities.TaskWaitingApproval,
+		Timestamp:   time.Now(),
+		Approvers:   request.Approvers,
+		Deadline:    request.Deadline,
+	})
+}
+
+// notify envía la notificación por cada canal de las reglas que la seleccionan, una sola
+// vez por canal aunque lo usen varias reglas.
+func (s *NotificationServiceImpl) notify(ctx context.Context, task *entities.DevOpsTask, notification entities.Notification) {
+	s.mu.Lock()
+	channels := make(map[string]ports.NotificationChannel)
+	for _, rule := range s.rules {
+		if !rule.Matches(task, notification.Trigger) {
+			continue
+		}
+		for _, name := range rule.Channels {
+			if channel, ok := s.channels[name]; ok {
+				channels[name] = channel
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	for name, channel := range channels {
+		s.wg.Add(1)
+		go func(name string, channel ports.NotificationChannel) {
+			defer s.wg.Done()
+			s.deliver(ctx, name, channel, notification)
+		}(name, channel)
+	}
+}
+
+// deliver envía la notificación y la reintenta con espera creciente mientras el error no
+// sea definitivo.
+func (s *NotificationServiceImpl) deliver(ctx context.Context, name string, channel ports.NotificationChannel, notification entities.Notification) {
+	for attempt := 1; ; attempt++ {
+		err := channel.Send(ctx, notification)
+		if err == nil {
+			return
+		}
+		if errors.Is(err, ports.ErrNotificationRejected) || attempt >= s.Retry.MaxAttempts {
+			log.Printf("Error sending %s notification of execution %s to %s after %d attempts: %v",
+				notification.Trigger, notification.ExecutionID, name, attempt, err)
+			return
+		}
+		timer := time.NewTimer(s.Retry.Backoff(attempt, rand.Float64()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Printf("Error sending %s notification of execution %s to %s: %v", notification.Trigger, notification.ExecutionID, name, err)
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// failed indica si el estado cuenta como fallo para las notificaciones. Los rechazos de
+// una aprobación y las cancelaciones son decisiones de alguien, no fallos.
+func failed(status entities.TaskStatus) bool {
+	return status == entities.TaskFailed || status == entities.TaskError || status == entities.TaskExpired
+}
+
+// previousFailed indica si la última ejecución terminada de la tarea antes de executionID
+// falló. No cuentan los intentos que se reintentaron, las combinaciones de una matriz ni
+// las ejecuciones canceladas u omitidas.
+func previousFailed(task *entities.DevOpsTask, executionID string) bool {
+	current := -1
+	for i, execution := range task.Executions {
+		if execution.ID == executionID {
+			current = i
+			break
+		}
+	}
+	for i := current - 1; i >= 0; i-- {
+		execution := task.Executions[i]
+		if !execution.Status.IsTerminal() || execution.NextAttemptID != "" || !execution.RetryAt.IsZero() || execution.ParentExecutionID != "" {
+			continue
+		}
+		if execution.Status == entities.TaskCanceled || execution.Status == entities.TaskSkipped {
+			continue
+		}
+		return failed(execution.Status)
+	}
+	return false
+}
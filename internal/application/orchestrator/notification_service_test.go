@@ -0,0 +1,223 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	eventstream "devops_console/internal/infrastructure/orchestrator/events"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	ports "devops_console/internal/ports/orchestrator"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingChannel guarda las notificaciones recibidas. Falla los primeros failures envíos
+// con err o, si err es nil, con un error que se puede reintentar.
+type recordingChannel struct {
+	mu            sync.Mutex
+	failures      int
+	err           error
+	attempts      int
+	notifications []entities.Notification
+}
+
+func (c *recordingChannel) Send(ctx context.Context, notification entities.Notification) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.attempts++
+	if c.failures > 0 {
+		c.failures--
+		if c.err != nil {
+			return c.err
+		}
+		return errors.New("connection refused")
+	}
+	c.notifications = append(c.notifications, notification)
+	return nil
+}
+
+func (c *recordingChannel) received() []entities.Notification {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]entities.Notification{}, c.notifications...)
+}
+
+func (c *recordingChannel) sendAttempts() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.attempts
+}
+
+type NotificationTestSuite struct {
+	suite.Suite
+	tasks    *orchestrator2.TaskServiceImpl
+	repo     *adapters.InMemoryTaskRepository
+	executor *fakeExecutor
+	notifier *orchestrator2.NotificationServiceImpl
+}
+
+func (suite *NotificationTestSuite) SetupTest() {
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.executor = newFakeExecutor()
+	suite.tasks = orchestrator2.NewTaskServiceImpl(suite.repo)
+	suite.tasks.RegisterExecutor("Stub", suite.executor)
+	suite.tasks.EventStream = eventstream.NewTaskEventStream()
+	suite.notifier = orchestrator2.NewNotificationServiceImpl(suite.repo, suite.tasks, suite.tasks.EventStream)
+	suite.notifier.Retry = entities.RetryPolicy{MaxAttempts: 4, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, Multiplier: 2}
+	suite.Require().NoError(suite.notifier.Start())
+}
+
+func (suite *NotificationTestSuite) TearDownTest() {
+	suite.notifier.Stop()
+	suite.tasks.Shutdown()
+}
+
+func (suite *NotificationTestSuite) createTask(id string, workspace entities.Workspace) {
+	_, err := suite.tasks.CreateTask(context.Background(), entities.DevOpsTask{ID: id, Name: id, Workspace: workspace, Worker: &stubWorker{}})
+	suite.Require().NoError(err)
+}
+
+// run ejecuta la tarea con el resultado indicado y espera a que termine.
+func (suite *NotificationTestSuite) run(taskID string, status entities.TaskStatus) string {
+	suite.executor.mu.Lock()
+	suite.executor.results[taskID] = status
+	suite.executor.mu.Unlock()
+	executionID, err := suite.tasks.ExecuteTask(context.Background(), taskID)
+	suite.Require().NoError(err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = suite.tasks.WaitForExecution(ctx, executionID)
+	suite.Require().NoError(err)
+	return executionID
+}
+
+func (suite *NotificationTestSuite) waitForNotifications(channel *recordingChannel, count int) []entities.Notification {
+	deadline := time.Now().Add(time.Second)
+	for len(channel.received()) < count {
+		if time.Now().After(deadline) {
+			suite.T().Fatalf("expected %d notifications, got %v", count, channel.received())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return channel.received()
+}
+
+func (suite *NotificationTestSuite) TestNotifier_NotifiesFailuresAndRecoveries() {
+	team, other := &recordingChannel{}, &recordingChannel{}
+	suite.notifier.RegisterChannel("team", team)
+	suite.notifier.RegisterChannel("other", other)
+	_, err := suite.notifier.AddRule(entities.NotificationRule{
+		WorkspaceID: payments.ID,
+		On:          []entities.NotificationTrigger{entities.NotifyOnFailure, entities.NotifyOnRecovery},
+		Channels:    []string{"team"},
+	})
+	suite.Require().NoError(err)
+	_, err = suite.notifier.AddRule(entities.NotificationRule{
+		WorkspaceID: "billing",
+		On:          []entities.NotificationTrigger{entities.NotifyOnFailure},
+		Channels:    []string{"other"},
+	})
+	suite.Require().NoError(err)
+	suite.createTask("deploy", payments)
+
+	suite.run("deploy", entities.TaskSucceeded)
+	first := suite.run("deploy", entities.TaskFailed)
+	suite.waitForNotifications(team, 1)
+	suite.run("deploy", entities.TaskFailed)
+	suite.waitForNotifications(team, 2)
+	recovery := suite.run("deploy", entities.TaskSucceeded)
+	suite.waitForNotifications(team, 3)
+	suite.run("deploy", entities.TaskSucceeded)
+	suite.notifier.Stop()
+
+	received := team.received()
+	suite.Require().Len(received, 3)
+	assert.Equal(suite.T(), entities.NotifyOnFailure, received[0].Trigger)
+	assert.Equal(suite.T(), first, received[0].ExecutionID)
+	assert.Equal(suite.T(), entities.TaskFailed, received[0].Status)
+	assert.Equal(suite.T(), payments.ID, received[0].WorkspaceID)
+	assert.Equal(suite.T(), entities.NotifyOnFailure, received[1].Trigger)
+	assert.Equal(suite.T(), entities.NotifyOnRecovery, received[2].Trigger)
+	assert.Equal(suite.T(), recovery, received[2].ExecutionID)
+	assert.Empty(suite.T(), other.received())
+}
+
+func (suite *NotificationTestSuite) TestNotifier_NotifiesApprovalRequests() {
+	approvers := &recordingChannel{}
+	suite.notifier.RegisterChannel("approvers", approvers)
+	suite.Require().NoError(suite.repo.Create(context.Background(), &entities.DevOpsTask{
+		ID:        "prod-deploy-approval",
+		Name:      "prod-deploy-approval",
+		Workspace: payments,
+		TaskType:  entities.TaskTypeApproval,
+		ApprovalPolicy: &entities.ApprovalPolicy{
+			Approvers: []entities.Subject{bob, entities.Group{ID: "sre", Name: "SRE"}},
+			Timeout:   time.Hour,
+		},
+	}))
+	_, err := suite.notifier.AddRule(entities.NotificationRule{
+		TaskID:   "prod-deploy-approval",
+		On:       []entities.NotificationTrigger{entities.NotifyOnApprovalRequested},
+		Channels: []string{"approvers"},
+	})
+	suite.Require().NoError(err)
+
+	executionID, err := suite.tasks.ExecuteTaskAs(context.Background(), "prod-deploy-approval", alice)
+	suite.Require().NoError(err)
+
+	received := suite.waitForNotifications(approvers, 1)
+	assert.Equal(suite.T(), entities.NotifyOnApprovalRequested, received[0].Trigger)
+	assert.Equal(suite.T(), executionID, received[0].ExecutionID)
+	assert.Equal(suite.T(), []string{"bob", "sre"}, received[0].Approvers)
+	assert.WithinDuration(suite.T(), time.Now().Add(time.Hour), received[0].Deadline, time.Minute)
+	assert.Contains(suite.T(), received[0].Text(), "Approvers: bob, sre")
+}
+
+func (suite *NotificationTestSuite) TestNotifier_RetriesDeliveries() {
+	flaky := &recordingChannel{failures: 2}
+	rejecting := &recordingChannel{failures: 10, err: fmt.Errorf("%w: 404 Not Found", ports.ErrNotificationRejected)}
+	suite.notifier.RegisterChannel("flaky", flaky)
+	suite.notifier.RegisterChannel("rejecting", rejecting)
+	_, err := suite.notifier.AddRule(entities.NotificationRule{
+		TaskID:   "deploy",
+		On:       []entities.NotificationTrigger{entities.NotifyOnFailure},
+		Channels: []string{"flaky", "rejecting"},
+	})
+	suite.Require().NoError(err)
+	suite.createTask("deploy", payments)
+
+	suite.run("deploy", entities.TaskFailed)
+	suite.waitForNotifications(flaky, 1)
+	suite.notifier.Stop()
+
+	assert.Equal(suite.T(), 3, flaky.sendAttempts())
+	assert.Equal(suite.T(), 1, rejecting.sendAttempts())
+	assert.Empty(suite.T(), rejecting.received())
+}
+
+func (suite *NotificationTestSuite) TestAddRule_Validates() {
+	suite.notifier.RegisterChannel("team", &recordingChannel{})
+
+	_, err := suite.notifier.AddRule(entities.NotificationRule{WorkspaceID: payments.ID, On: []entities.NotificationTrigger{entities.NotifyOnFailure}, Channels: []string{"pager"}})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidNotificationRule)
+	_, err = suite.notifier.AddRule(entities.NotificationRule{On: []entities.NotificationTrigger{entities.NotifyOnFailure}, Channels: []string{"team"}})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidNotificationRule)
+	_, err = suite.notifier.AddRule(entities.NotificationRule{WorkspaceID: payments.ID, On: []entities.NotificationTrigger{"SOMETIMES"}, Channels: []string{"team"}})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidNotificationRule)
+
+	id, err := suite.notifier.AddRule(entities.NotificationRule{WorkspaceID: payments.ID, On: []entities.NotificationTrigger{entities.NotifyOnFailure}, Channels: []string{"team"}})
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), suite.notifier.Rules(), 1)
+	assert.True(suite.T(), suite.notifier.RemoveRule(id))
+	assert.False(suite.T(), suite.notifier.RemoveRule(id))
+	assert.Empty(suite.T(), suite.notifier.Rules())
+}
+
+func TestNotificationTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationTestSuite))
+}
@@ -0,0 +1,434 @@
+package orchestrator
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+type PipelineService interface {
+	CreatePipeline(ctx context.Context, pipeline entities.Pipeline) (entities.Pipeline, error)
+	UpdatePipeline(ctx context.Context, pipeline entities.Pipeline) (entities.Pipeline, error)
+	DeletePipeline(ctx context.Context, pipelineID string) error
+	GetPipeline(ctx context.Context, pipelineID string) (entities.Pipeline, error)
+	GetPipelines(ctx context.Context) ([]entities.Pipeline, error)
+	ExecutePipeline(ctx context.Context, pipelineID string) (string, error)
+	GetPipelineExecution(ctx context.Context, executionID string) (entities.PipelineExecution, error)
+	CancelPipelineExecution(ctx context.Context, executionID string) error
+}
+
+var (
+	ErrInvalidPipeline             = errors.New("invalid pipeline")
+	ErrPipelineExecutionNotRunning = errors.New("pipeline execution not running")
+)
+
+// PipelineServiceImpl ejecuta los nodos de un pipeline a través del TaskService: lanza en
+// paralelo los nodos cuyas dependencias han terminado con éxito y omite los que dependen
+// de un nodo fallido.
+type PipelineServiceImpl struct {
+	repository ports.PipelineRepository
+	tasks      *TaskServiceImpl
+	GenerateID IDGenerator
+	running    map[string]context.CancelFunc
+	mu         sync.Mutex
+}
+
+func NewPipelineServiceImpl(pipelineRepo ports.PipelineRepository, taskService *TaskServiceImpl) *PipelineServiceImpl {
+	return &PipelineServiceImpl{
+		repository: pipelineRepo,
+		tasks:      taskService,
+		GenerateID: defaultIDGenerator,
+		running:    make(map[string]context.CancelFunc),
+	}
+}
+
+func (s *PipelineServiceImpl) CreatePipeline(ctx context.Context, pipeline entities.Pipeline) (entities.Pipeline, error) {
+	if scope, ok := ports.ScopeFromContext(ctx); ok {
+		pipeline.Workspace = scopedWorkspace(pipeline.Workspace, scope)
+	}
+	if err := s.tasks.authorize(ctx, nil, entities.PermissionCreate, pipeline.Workspace); err != nil {
+		return entities.Pipeline{}, err
+	}
+	if pipeline.ID == "" {
+		pipeline.ID = s.GenerateID()
+	}
+	if err := s.validate(ctx, &pipeline); err != nil {
+		return entities.Pipeline{}, err
+	}
+	pipeline.CreatedAt = time.Now()
+	pipeline.UpdatedAt = time.Now()
+
+	if err := s.repository.Create(ctx, &pipeline); err != nil {
+		return entities.Pipeline{}, err
+	}
+	return pipeline, nil
+}
+
+func (s *PipelineServiceImpl) UpdatePipeline(ctx context.Context, pipeline entities.Pipeline) (entities.Pipeline, error) {
+	current, err := s.repository.GetByID(ctx, pipeline.ID)
+	if err != nil {
+		return entities.Pipeline{}, err
+	}
+	if err := s.tasks.authorize(ctx, nil, entities.PermissionUpdate, current.Workspace); err != nil {
+		return entities.Pipeline{}, err
+	}
+	if scope, ok := ports.ScopeFromContext(ctx); ok {
+		pipeline.Workspace = scopedWorkspace(pipeline.Workspace, scope)
+	}
+	// Moverlo a otro workspace es también crearlo en él.
+	if pipeline.Workspace != current.Workspace {
+		if err := s.tasks.authorize(ctx, nil, entities.PermissionCreate, pipeline.Workspace); err != nil {
+			return entities.Pipeline{}, err
+		}
+	}
+	if err := s.validate(ctx, &pipeline); err != nil {
+		return entities.Pipeline{}, err
+	}
+	pipeline.CreatedAt = current.CreatedAt
+	pipeline.UpdatedAt = time.Now()
+
+	if err := s.repository.Update(ctx, &pipeline); err != nil {
+		return entities.Pipeline{}, err
+	}
+	return pipeline, nil
+}
+
+func (s *PipelineServiceImpl) DeletePipeline(ctx context.Context, pipelineID string) error {
+	pipeline, err := s.repository.GetByID(ctx, pipelineID)
+	if err != nil {
+		return err
+	}
+	if err := s.tasks.authorize(ctx, nil, entities.PermissionDelete, pipeline.Workspace); err != nil {
+		return err
+	}
+	return s.repository.Delete(ctx, pipelineID)
+}
+
+func (s *PipelineServiceImpl) GetPipeline(ctx context.Context, pipelineID string) (entities.Pipeline, error) {
+	pipeline, err := s.repository.GetByID(ctx, pipelineID)
+	if err != nil {
+		return entities.Pipeline{}, err
+	}
+	if err := s.tasks.authorize(ctx, nil, entities.PermissionView, pipeline.Workspace); err != nil {
+		return entities.Pipeline{}, err
+	}
+	return pipeline, nil
+}
+
+// GetPipelines devuelve los pipelines que el sujeto puede ver.
+func (s *PipelineServiceImpl) GetPipelines(ctx context.Context) ([]entities.Pipeline, error) {
+	pipelines, err := s.repository.GetAll(ctx)
+	if err != nil || s.tasks.authorizer == nil {
+		return pipelines, err
+	}
+	visible := pipelines[:0:0]
+	for _, pipeline := range pipelines {
+		err := s.tasks.authorize(ctx, nil, entities.PermissionView, pipeline.Workspace)
+		switch {
+		case err == nil:
+			visible = append(visible, pipeline)
+		case !errors.Is(err, ErrPermissionDenied):
+			return nil, err
+		}
+	}
+	return visible, nil
+}
+
+func (s *PipelineServiceImpl) GetPipelineExecution(ctx context.Context, executionID string) (entities.PipelineExecution, error) {
+	execution, err := s.repository.GetExecution(ctx, executionID)
+	if err != nil {
+		return entities.PipelineExecution{}, err
+	}
+	if _, err := s.GetPipeline(ctx, execution.PipelineID); err != nil {
+		return entities.PipelineExecution{}, err
+	}
+	return execution, nil
+}
+
+// ExecutePipeline crea la ejecución del pipeline y la lanza en segundo plano. Los nodos se
+// lanzan en nombre de quien ejecuta el pipeline, que necesita permiso para ejecutar cada
+// una de sus tareas.
+func (s *PipelineServiceImpl) ExecutePipeline(ctx context.Context, pipelineID string) (string, error) {
+	pipeline, err := s.repository.GetByID(ctx, pipelineID)
+	if err != nil {
+		return "", err
+	}
+	if err := s.tasks.authorize(ctx, nil, entities.PermissionExecute, pipeline.Workspace); err != nil {
+		return "", err
+	}
+	if err := pipeline.Validate(); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidPipeline, err)
+	}
+
+	execution := &entities.PipelineExecution{
+		ID:         s.GenerateID(),
+		PipelineID: pipeline.ID,
+		Status:     entities.TaskRunning,
+		StartedAt:  time.Now(),
+		Nodes:      make(map[string]*entities.PipelineNodeExecution, len(pipeline.Nodes)),
+	}
+	for _, node := range pipeline.Nodes {
+		execution.Nodes[node.ID] = &entities.PipelineNodeExecution{
+			NodeID: node.ID,
+			TaskID: node.TaskID,
+			Status: entities.TaskPending,
+		}
+	}
+	if err := s.repository.SaveExecution(ctx, execution); err != nil {
+		return "", err
+	}
+
+	// La ejecución sigue cuando termina la petición que la lanza, pero con su sujeto y su ámbito.
+	runCtx, cancel := context.WithCancel(pipelineContext(context.WithoutCancel(ctx), pipeline))
+	s.mu.Lock()
+	s.running[execution.ID] = cancel
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, execution.ID)
+			s.mu.Unlock()
+			cancel()
+		}()
+		newPipelineRun(s, pipeline, execution).run(runCtx)
+	}()
+
+	return execution.ID, nil
+}
+
+// CancelPipelineExecution cancela los nodos en curso y marca los pendientes como cancelados.
+func (s *PipelineServiceImpl) CancelPipelineExecution(ctx context.Context, executionID string) error {
+	execution, err := s.repository.GetExecution(ctx, executionID)
+	if err != nil {
+		return err
+	}
+	pipeline, err := s.repository.GetByID(ctx, execution.PipelineID)
+	if err != nil {
+		return err
+	}
+	if err := s.tasks.authorize(ctx, nil, entities.PermissionCancel, pipeline.Workspace); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	cancel, ok := s.running[executionID]
+	s.mu.Unlock()
+	if !ok {
+		return ErrPipelineExecutionNotRunning
+	}
+	cancel()
+	return nil
+}
+
+// validate comprueba el grafo y que el sujeto puede ver cada tarea que referencia.
+func (s *PipelineServiceImpl) validate(ctx context.Context, pipeline *entities.Pipeline) error {
+	if err := pipeline.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPipeline, err)
+	}
+	ctx = pipelineContext(ctx, *pipeline)
+	for _, node := range pipeline.Nodes {
+		if _, err := s.tasks.GetTask(ctx, node.TaskID); err != nil {
+			return fmt.Errorf("%w: node %q references task %q: %v", ErrInvalidPipeline, node.ID, node.TaskID, err)
+		}
+	}
+	return nil
+}
+
+// pipelineContext limita al tenant del pipeline las tareas que puede referenciar y lanzar
+// si la petición no trae ya un ámbito, que en ese caso incluye al pipeline.
+func pipelineContext(ctx context.Context, pipeline entities.Pipeline) context.Context {
+	if _, ok := ports.ScopeFromContext(ctx); ok {
+		return ctx
+	}
+	return ports.WithScope(ctx, entities.Scope{TenantID: pipeline.Workspace.TenantID})
+}
+
+// pipelineRun mantiene el estado de una ejecución de pipeline en curso.
+type pipelineRun struct {
+	service   *PipelineServiceImpl
+	pipeline  entities.Pipeline
+	execution *entities.PipelineExecution
+	mu        sync.Mutex
+}
+
+type nodeResult struct {
+	nodeID string
+	status entities.TaskStatus
+	err    error
+}
+
+func newPipelineRun(service *PipelineServiceImpl, pipeline entities.Pipeline, execution *entities.PipelineExecution) *pipelineRun {
+	return &pipelineRun{
+		service:   service,
+		pipeline:  pipeline,
+		execution: execution,
+	}
+}
+
+func (r *pipelineRun) run(ctx context.Context) {
+	results := make(chan nodeResult)
+	running := 0
+
+	for {
+		if ctx.Err() == nil {
+			r.skipBlockedNodes()
+			for _, nodeID := range r.readyNodes() {
+				r.startNode(ctx, nodeID, results)
+				running++
+			}
+		}
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+		r.finishNode(result)
+	}
+
+	r.finish(ctx)
+}
+
+// readyNodes devuelve los nodos pendientes cuyas dependencias han terminado con éxito.
+func (r *pipelineRun) readyNodes() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ready []string
+	for _, node := range r.pipeline.Nodes {
+		if r.execution.Nodes[node.ID].Status != entities.TaskPending {
+			continue
+		}
+		satisfied := true
+		for _, dep := range r.pipeline.Dependencies(node.ID) {
+			if r.execution.Nodes[dep].Status != entities.TaskSucceeded {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			ready = append(ready, node.ID)
+		}
+	}
+	return ready
+}
+
+// skipBlockedNodes omite los nodos pendientes con alguna dependencia que no terminó con éxito.
+func (r *pipelineRun) skipBlockedNodes() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for changed := true; changed; {
+		changed = false
+		for _, node := range r.pipeline.Nodes {
+			nodeExecution := r.execution.Nodes[node.ID]
+			if nodeExecution.Status != entities.TaskPending {
+				continue
+			}
+			for _, dep := range r.pipeline.Dependencies(node.ID) {
+				depStatus := r.execution.Nodes[dep].Status
+				if depStatus.IsTerminal() && depStatus != entities.TaskSucceeded {
+					nodeExecution.Status = entities.TaskSkipped
+					nodeExecution.Error = fmt.Sprintf("dependency %q finished with status %s", dep, depStatus)
+					nodeExecution.FinishedAt = time.Now()
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	r.save()
+}
+
+func (r *pipelineRun) startNode(ctx context.Context, nodeID string, results chan<- nodeResult) {
+	r.mu.Lock()
+	nodeExecution := r.execution.Nodes[nodeID]
+	nodeExecution.Status = entities.TaskRunning
+	nodeExecution.StartedAt = time.Now()
+	taskID := nodeExecution.TaskID
+	r.save()
+	r.mu.Unlock()
+
+	go func() {
+		executionID, err := r.service.tasks.ExecuteTask(ctx, taskID)
+		if err != nil {
+			results <- nodeResult{nodeID: nodeID, status: entities.TaskFailed, err: err}
+			return
+		}
+
+		r.mu.Lock()
+		nodeExecution.ExecutionID = executionID
+		r.save()
+		r.mu.Unlock()
+
+		status, err := r.service.tasks.WaitForExecution(ctx, executionID)
+		if ctx.Err() != nil {
+			// El pipeline ya está cancelado: la cancelación del nodo no puede depender de ctx ni
+			// de que quien lo lanzó tenga permiso para cancelar la tarea.
+			if cancelErr := r.service.tasks.CancelTask(trusted(context.WithoutCancel(ctx)), executionID); cancelErr != nil {
+				log.Printf("Error canceling execution %s of pipeline node %s: %v", executionID, nodeID, cancelErr)
+			}
+			results <- nodeResult{nodeID: nodeID, status: entities.TaskCanceled}
+			return
+		}
+		results <- nodeResult{nodeID: nodeID, status: status, err: err}
+	}()
+}
+
+func (r *pipelineRun) finishNode(result nodeResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	nodeExecution := r.execution.Nodes[result.nodeID]
+	nodeExecution.Status = result.status
+	nodeExecution.FinishedAt = time.Now()
+	if result.err != nil {
+		nodeExecution.Status = entities.TaskFailed
+		nodeExecution.Error = result.err.Error()
+	} else if nodeExecution.ExecutionID != "" && result.status != entities.TaskSucceeded {
+		if execution, err := r.service.tasks.lastAttempt(context.Background(), nodeExecution.ExecutionID); err == nil {
+			nodeExecution.Error = execution.Error
+		}
+	}
+	r.save()
+}
+
+// finish calcula el estado global: éxito si todos los nodos terminaron bien, cancelado si
+// se canceló la ejecución y fallido en cualquier otro caso.
+func (r *pipelineRun) finish(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := entities.TaskSucceeded
+	for _, node := range r.pipeline.Nodes {
+		nodeExecution := r.execution.Nodes[node.ID]
+		if nodeExecution.Status == entities.TaskPending {
+			nodeExecution.Status = entities.TaskCanceled
+			nodeExecution.FinishedAt = time.Now()
+		}
+		if nodeExecution.Status != entities.TaskSucceeded && status == entities.TaskSucceeded {
+			status = entities.TaskFailed
+			r.execution.Error = fmt.Sprintf("node %q finished with status %s", node.ID, nodeExecution.Status)
+		}
+	}
+	if ctx.Err() != nil {
+		status = entities.TaskCanceled
+		r.execution.Error = "pipeline execution canceled"
+	}
+
+	r.execution.Status = status
+	r.execution.FinishedAt = time.Now()
+	r.save()
+}
+
+// save persiste el estado actual. Debe llamarse con r.mu bloqueado.
+func (r *pipelineRun) save() {
+	if err := r.service.repository.SaveExecution(context.Background(), r.execution); err != nil {
+		log.Printf("Error saving pipeline execution %s: %v", r.execution.ID, err)
+	}
+}
@@ -0,0 +1,145 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	"devops_console/internal/infrastructure/orchestrator/server"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type PipelineServiceTestSuite struct {
+	suite.Suite
+	service  *orchestrator2.PipelineServiceImpl
+	executor *fakeExecutor
+}
+
+func (suite *PipelineServiceTestSuite) SetupTest() {
+	taskRepo := adapters.NewInMemoryTaskRepository()
+	for _, id := range []string{"build", "unit-tests", "lint", "deploy"} {
+		_ = taskRepo.Create(context.Background(), &entities.DevOpsTask{ID: id, Name: id, Worker: &stubWorker{}})
+	}
+	suite.executor = newFakeExecutor()
+	taskService := orchestrator2.NewTaskServiceImpl(taskRepo)
+	taskService.RegisterExecutor("Stub", suite.executor)
+	suite.service = orchestrator2.NewPipelineServiceImpl(adapters.NewInMemoryPipelineRepository(), taskService)
+}
+
+// fanOutPipeline: build -> (unit-tests, lint) -> deploy
+func fanOutPipeline() entities.Pipeline {
+	return entities.Pipeline{
+		Name:   "fan-out",
+		Stages: []string{"build", "verify", "release"},
+		Nodes: []entities.PipelineNode{
+			{ID: "build", TaskID: "build", Stage: "build"},
+			{ID: "unit-tests", TaskID: "unit-tests", Stage: "verify"},
+			{ID: "lint", TaskID: "lint", Stage: "verify"},
+			{ID: "deploy", TaskID: "deploy", Stage: "release"},
+		},
+	}
+}
+
+func (suite *PipelineServiceTestSuite) waitForPipeline(executionID string) entities.PipelineExecution {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		execution, err := suite.service.GetPipelineExecution(context.Background(), executionID)
+		suite.Require().NoError(err)
+		if execution.Status.IsTerminal() {
+			return execution
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	suite.T().Fatal("pipeline execution did not finish")
+	return entities.PipelineExecution{}
+}
+
+func (suite *PipelineServiceTestSuite) TestExecutePipeline_RunsAllNodes() {
+	pipeline, err := suite.service.CreatePipeline(context.Background(), fanOutPipeline())
+	suite.Require().NoError(err)
+
+	executionID, err := suite.service.ExecutePipeline(context.Background(), pipeline.ID)
+	suite.Require().NoError(err)
+
+	execution := suite.waitForPipeline(executionID)
+	assert.Equal(suite.T(), entities.TaskSucceeded, execution.Status)
+	for nodeID, node := range execution.Nodes {
+		assert.Equal(suite.T(), entities.TaskSucceeded, node.Status, nodeID)
+		assert.NotEmpty(suite.T(), node.ExecutionID, nodeID)
+	}
+
+	executed := suite.executor.executedTasks()
+	assert.Equal(suite.T(), "build", executed[0])
+	assert.Equal(suite.T(), "deploy", executed[len(executed)-1])
+}
+
+func (suite *PipelineServiceTestSuite) TestExecutePipeline_SkipsDownstreamOnFailure() {
+	suite.executor.results["lint"] = entities.TaskFailed
+	pipeline, err := suite.service.CreatePipeline(context.Background(), fanOutPipeline())
+	suite.Require().NoError(err)
+
+	executionID, err := suite.service.ExecutePipeline(context.Background(), pipeline.ID)
+	suite.Require().NoError(err)
+
+	execution := suite.waitForPipeline(executionID)
+	assert.Equal(suite.T(), entities.TaskFailed, execution.Status)
+	assert.Equal(suite.T(), entities.TaskSucceeded, execution.Nodes["build"].Status)
+	assert.Equal(suite.T(), entities.TaskSucceeded, execution.Nodes["unit-tests"].Status)
+	assert.Equal(suite.T(), entities.TaskFailed, execution.Nodes["lint"].Status)
+	assert.Equal(suite.T(), entities.TaskSkipped, execution.Nodes["deploy"].Status)
+	assert.NotContains(suite.T(), suite.executor.executedTasks(), "deploy")
+}
+
+func (suite *PipelineServiceTestSuite) TestCreatePipeline_RejectsCycles() {
+	pipeline := entities.Pipeline{
+		Name: "cyclic",
+		Nodes: []entities.PipelineNode{
+			{ID: "build", TaskID: "build", DependsOn: []string{"deploy"}},
+			{ID: "deploy", TaskID: "deploy", DependsOn: []string{"build"}},
+		},
+	}
+
+	_, err := suite.service.CreatePipeline(context.Background(), pipeline)
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidPipeline)
+}
+
+func (suite *PipelineServiceTestSuite) TestPipelineHandler() {
+	handler := server.NewPipelineHandler(suite.service)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return recorder
+	}
+
+	definition, err := json.Marshal(fanOutPipeline())
+	suite.Require().NoError(err)
+	response := send(http.MethodPost, "/pipelines", string(definition))
+	suite.Require().Equal(http.StatusCreated, response.Code)
+	var pipeline entities.Pipeline
+	suite.Require().NoError(json.NewDecoder(response.Body).Decode(&pipeline))
+
+	response = send(http.MethodPost, "/pipelines/"+pipeline.ID+"/executions", "")
+	suite.Require().Equal(http.StatusAccepted, response.Code)
+	var started struct {
+		ExecutionID string `json:"executionId"`
+	}
+	suite.Require().NoError(json.NewDecoder(response.Body).Decode(&started))
+	assert.Equal(suite.T(), entities.TaskSucceeded, suite.waitForPipeline(started.ExecutionID).Status)
+	assert.Equal(suite.T(), http.StatusOK, send(http.MethodGet, "/pipeline-executions/"+started.ExecutionID, "").Code)
+	assert.Equal(suite.T(), http.StatusConflict, send(http.MethodDelete, "/pipeline-executions/"+started.ExecutionID, "").Code)
+
+	cyclic := `{"Name":"cyclic","Nodes":[{"ID":"a","TaskID":"build","DependsOn":["a"]}]}`
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, send(http.MethodPost, "/pipelines", cyclic).Code)
+	assert.Equal(suite.T(), http.StatusNoContent, send(http.MethodDelete, "/pipelines/"+pipeline.ID, "").Code)
+}
+
+func TestPipelineServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PipelineServiceTestSuite))
+}
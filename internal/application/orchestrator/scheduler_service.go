@@ -0,0 +1,234 @@
+package orchestrator
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+var ErrTaskNotScheduled = errors.New("task not scheduled")
+
+// defaultMaxCatchUpRuns limita las ejecuciones lanzadas con MissedRunCatchUp tras una caída larga.
+const defaultMaxCatchUpRuns = 100
+
+// Clock abstrae el paso del tiempo para poder probar el scheduler sin esperar.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// SchedulerServiceImpl implementa ports.TaskScheduler: lanza las tareas TaskTypeScheduled
+// según la expresión cron de su ScheduledTrigger y guarda la última activación en la tarea
+// para aplicar su MissedRunPolicy tras una caída del master.
+type SchedulerServiceImpl struct {
+	repository     ports.TaskRepository
+	tasks          *TaskServiceImpl
+	Clock          Clock
+	MaxCatchUpRuns int
+	scheduled      map[string]context.CancelFunc
+	mu             sync.Mutex
+}
+
+var _ ports.TaskScheduler = (*SchedulerServiceImpl)(nil)
+
+func NewSchedulerServiceImpl(taskRepo ports.TaskRepository, taskService *TaskServiceImpl) *SchedulerServiceImpl {
+	return &SchedulerServiceImpl{
+		repository:     taskRepo,
+		tasks:          taskService,
+		Clock:          systemClock{},
+		MaxCatchUpRuns: defaultMaxCatchUpRuns,
+		scheduled:      make(map[string]context.CancelFunc),
+	}
+}
+
+// SetScheduler hace que las tareas TaskTypeScheduled se planifiquen al crearlas o
+// modificarlas y dejen de planificarse al borrarlas o cambiarlas de tipo.
+func (s *TaskServiceImpl) SetScheduler(scheduler ports.TaskScheduler) {
+	s.scheduler = scheduler
+}
+
+// reschedule aplica a la planificación la tarea recién guardada. Como en ScheduleAll, las
+// activaciones no son de quien guarda la tarea sino del propio orquestador.
+func (s *TaskServiceImpl) reschedule(task *entities.DevOpsTask) {
+	if s.scheduler == nil {
+		return
+	}
+	if task.TaskType != entities.TaskTypeScheduled {
+		s.unschedule(task.ID)
+		return
+	}
+	if err := s.scheduler.ScheduleTask(context.Background(), task); err != nil {
+		log.Printf("Error scheduling task %s: %v", task.ID, err)
+	}
+}
+
+func (s *TaskServiceImpl) unschedule(taskID string) {
+	if s.scheduler == nil {
+		return
+	}
+	if err := s.scheduler.CancelScheduledTask(context.Background(), taskID); err != nil && !errors.Is(err, ErrTaskNotScheduled) {
+		log.Printf("Error unscheduling task %s: %v", taskID, err)
+	}
+}
+
+// ScheduleAll planifica todas las tareas programadas del repositorio. Se llama al arrancar el master.
+func (s *SchedulerServiceImpl) ScheduleAll(ctx context.Context) error {
+	page, err := s.repository.GetAll(ctx, ports.TaskFilters{TaskType: entities.TaskTypeScheduled})
+	if err != nil {
+		return err
+	}
+	tasks := page.Tasks
+	for i := range tasks {
+		if tasks[i].TaskType != entities.TaskTypeScheduled {
+			continue
+		}
+		if err := s.ScheduleTask(ctx, &tasks[i]); err != nil {
+			log.Printf("Error scheduling task %s: %v", tasks[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// ScheduleTask planifica la tarea, sustituyendo una planificación previa, y aplica la
+// política de activaciones perdidas desde su LastRun.
+func (s *SchedulerServiceImpl) ScheduleTask(ctx context.Context, task *entities.DevOpsTask) error {
+	if task.TaskType != entities.TaskTypeScheduled {
+		return fmt.Errorf("%w: task %q is of type %s, not %s", ErrInvalidTask, task.ID, task.TaskType, entities.TaskTypeScheduled)
+	}
+	trigger, err := scheduledTrigger(task)
+	if err != nil {
+		return err
+	}
+	schedule, err := trigger.Schedule()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTask, err)
+	}
+
+	now := s.Clock.Now()
+	missed := s.missedRuns(trigger, schedule, now)
+
+	// La planificación sigue tras la llamada y solo se detiene al cancelarla o al apagar el TaskService.
+	runCtx, cancel := s.tasks.detach(ctx)
+	s.mu.Lock()
+	if previous, ok := s.scheduled[task.ID]; ok {
+		previous()
+	}
+	s.scheduled[task.ID] = cancel
+	s.mu.Unlock()
+
+	go s.run(runCtx, task.ID, schedule, missed, now)
+	return nil
+}
+
+func (s *SchedulerServiceImpl) CancelScheduledTask(ctx context.Context, taskID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cancel, ok := s.scheduled[taskID]
+	if !ok {
+		return ErrTaskNotScheduled
+	}
+	cancel()
+	delete(s.scheduled, taskID)
+	return nil
+}
+
+// missedRuns devuelve las activaciones entre LastRun y now que deben lanzarse según la política.
+func (s *SchedulerServiceImpl) missedRuns(trigger *entities.ScheduledTrigger, schedule *entities.CronSchedule, now time.Time) []time.Time {
+	if trigger.LastRun.IsZero() {
+		return nil
+	}
+
+	switch trigger.MissedRunPolicy {
+	case entities.MissedRunOnce:
+		if next := schedule.Next(trigger.LastRun); !next.IsZero() && !next.After(now) {
+			return []time.Time{now}
+		}
+	case entities.MissedRunCatchUp:
+		var runs []time.Time
+		for next := schedule.Next(trigger.LastRun); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+			if len(runs) == s.MaxCatchUpRuns {
+				log.Printf("Too many missed runs, catching up only the first %d", s.MaxCatchUpRuns)
+				break
+			}
+			runs = append(runs, next)
+		}
+		return runs
+	}
+	return nil
+}
+
+func (s *SchedulerServiceImpl) run(ctx context.Context, taskID string, schedule *entities.CronSchedule, missed []time.Time, from time.Time) {
+	for _, scheduledAt := range missed {
+		if ctx.Err() != nil {
+			return
+		}
+		s.fire(ctx, taskID, scheduledAt)
+	}
+
+	for next := schedule.Next(from); !next.IsZero(); {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.Clock.After(next.Sub(s.Clock.Now())):
+		}
+		s.fire(ctx, taskID, next)
+
+		// Si el proceso estuvo suspendido no recuperamos las activaciones intermedias.
+		if now := s.Clock.Now(); now.After(next) {
+			next = schedule.Next(now)
+		} else {
+			next = schedule.Next(next)
+		}
+	}
+	log.Printf("Schedule of task %s has no more activations", taskID)
+}
+
+func (s *SchedulerServiceImpl) fire(ctx context.Context, taskID string, scheduledAt time.Time) {
+	if _, err := s.tasks.ExecuteTask(trusted(ctx), taskID); err != nil {
+		log.Printf("Error executing scheduled task %s: %v", taskID, err)
+	}
+	if err := s.recordLastRun(ctx, taskID, scheduledAt); err != nil {
+		log.Printf("Error saving last run of scheduled task %s: %v", taskID, err)
+	}
+}
+
+// recordLastRun guarda la activación en el trigger de la tarea bajo el mismo cerrojo que
+// usa el TaskService para añadir ejecuciones.
+func (s *SchedulerServiceImpl) recordLastRun(ctx context.Context, taskID string, scheduledAt time.Time) error {
+	s.tasks.mu.Lock()
+	defer s.tasks.mu.Unlock()
+
+	task, err := s.repository.GetByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	trigger, err := scheduledTrigger(&task)
+	if err != nil {
+		return err
+	}
+
+	updated := *trigger
+	updated.LastRun = scheduledAt
+	var t entities.Trigger = &updated
+	task.Trigger = &t
+	return s.repository.Update(ctx, &task)
+}
+
+func scheduledTrigger(task *entities.DevOpsTask) (*entities.ScheduledTrigger, error) {
+	if task.Trigger != nil {
+		if trigger, ok := (*task.Trigger).(*entities.ScheduledTrigger); ok && trigger != nil {
+			return trigger, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: task %q has no scheduled trigger", ErrInvalidTask, task.ID)
+}
@@ -0,0 +1,244 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	ports "devops_console/internal/ports/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock solo avanza con Advance.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []clockWaiter
+}
+
+type clockWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, clockWaiter{deadline: c.now.Add(d), ch: ch})
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if !w.deadline.After(c.now) {
+			w.ch <- c.now
+		} else {
+			pending = append(pending, w)
+		}
+	}
+	c.waiters = pending
+}
+
+func (c *fakeClock) pendingWaiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+type SchedulerServiceTestSuite struct {
+	suite.Suite
+	service  *orchestrator2.SchedulerServiceImpl
+	tasks    *orchestrator2.TaskServiceImpl
+	repo     *adapters.InMemoryTaskRepository
+	executor *fakeExecutor
+	clock    *fakeClock
+}
+
+func (suite *SchedulerServiceTestSuite) SetupTest() {
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.executor = newFakeExecutor()
+	suite.clock = &fakeClock{now: time.Date(2024, time.March, 4, 9, 0, 0, 0, time.UTC)}
+	suite.tasks = orchestrator2.NewTaskServiceImpl(suite.repo)
+	suite.tasks.RegisterExecutor("Stub", suite.executor)
+	suite.service = orchestrator2.NewSchedulerServiceImpl(suite.repo, suite.tasks)
+	suite.service.Clock = suite.clock
+}
+
+func (suite *SchedulerServiceTestSuite) scheduledTask(trigger *entities.ScheduledTrigger) *entities.DevOpsTask {
+	var t entities.Trigger = trigger
+	task := &entities.DevOpsTask{ID: "nightly", TaskType: entities.TaskTypeScheduled, Trigger: &t, Worker: &stubWorker{}}
+	suite.Require().NoError(suite.repo.Create(context.Background(), task))
+	return task
+}
+
+func (suite *SchedulerServiceTestSuite) waitFor(condition func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			suite.T().Fatal("condition not met")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (suite *SchedulerServiceTestSuite) TestScheduleTask_FiresOnSchedule() {
+	task := suite.scheduledTask(&entities.ScheduledTrigger{Expression: "*/15 * * * *", Timezone: "UTC"})
+	suite.Require().NoError(suite.service.ScheduleTask(context.Background(), task))
+
+	suite.waitFor(func() bool { return suite.clock.pendingWaiters() == 1 })
+	suite.clock.Advance(10 * time.Minute)
+	assert.Empty(suite.T(), suite.executor.executedTasks())
+
+	suite.clock.Advance(5 * time.Minute)
+	suite.waitFor(func() bool { return len(suite.executor.executedTasks()) == 1 })
+
+	stored, err := suite.repo.GetByID(context.Background(), task.ID)
+	suite.Require().NoError(err)
+	trigger := (*stored.Trigger).(*entities.ScheduledTrigger)
+	assert.Equal(suite.T(), time.Date(2024, time.March, 4, 9, 15, 0, 0, time.UTC), trigger.LastRun)
+}
+
+func (suite *SchedulerServiceTestSuite) TestCancelScheduledTask_StopsFiring() {
+	task := suite.scheduledTask(&entities.ScheduledTrigger{Expression: "* * * * *", Timezone: "UTC"})
+	suite.Require().NoError(suite.service.ScheduleTask(context.Background(), task))
+	suite.waitFor(func() bool { return suite.clock.pendingWaiters() == 1 })
+
+	suite.Require().NoError(suite.service.CancelScheduledTask(context.Background(), task.ID))
+	suite.clock.Advance(time.Hour)
+	time.Sleep(10 * time.Millisecond)
+
+	assert.Empty(suite.T(), suite.executor.executedTasks())
+	assert.ErrorIs(suite.T(), suite.service.CancelScheduledTask(context.Background(), task.ID), orchestrator2.ErrTaskNotScheduled)
+}
+
+func (suite *SchedulerServiceTestSuite) TestTaskChanges_UpdateTheSchedule() {
+	suite.tasks.SetScheduler(suite.service)
+	ctx := context.Background()
+	var trigger entities.Trigger = &entities.ScheduledTrigger{Expression: "*/15 * * * *", Timezone: "UTC"}
+	for _, id := range []string{"nightly", "hourly"} {
+		_, err := suite.tasks.CreateTask(ctx, entities.DevOpsTask{ID: id, TaskType: entities.TaskTypeScheduled, Trigger: &trigger, Worker: &stubWorker{}})
+		suite.Require().NoError(err)
+	}
+	suite.waitFor(func() bool { return suite.clock.pendingWaiters() == 2 })
+	suite.clock.Advance(15 * time.Minute)
+	suite.waitFor(func() bool { return len(suite.executor.executedTasks()) == 2 })
+
+	// Al dejar de ser programada o borrarse, la tarea deja de planificarse.
+	_, err := suite.tasks.UpdateTask(ctx, "nightly", ports.TaskUpdate{TaskType: entities.TaskTypeManual})
+	suite.Require().NoError(err)
+	assert.ErrorIs(suite.T(), suite.service.CancelScheduledTask(ctx, "nightly"), orchestrator2.ErrTaskNotScheduled)
+	suite.Require().NoError(suite.tasks.DeleteTask(ctx, "hourly"))
+	assert.ErrorIs(suite.T(), suite.service.CancelScheduledTask(ctx, "hourly"), orchestrator2.ErrTaskNotScheduled)
+}
+
+func (suite *SchedulerServiceTestSuite) TestScheduleTask_MissedRunPolicies() {
+	lastRun := suite.clock.Now().Add(-3 * time.Hour)
+	cases := map[entities.MissedRunPolicy]int{
+		entities.MissedRunSkip:    0,
+		entities.MissedRunOnce:    1,
+		entities.MissedRunCatchUp: 3,
+	}
+	for policy, expected := range cases {
+		suite.SetupTest()
+		task := suite.scheduledTask(&entities.ScheduledTrigger{
+			Expression:      "0 * * * *",
+			Timezone:        "UTC",
+			MissedRunPolicy: policy,
+			LastRun:         lastRun,
+		})
+		suite.Require().NoError(suite.service.ScheduleTask(context.Background(), task))
+
+		suite.waitFor(func() bool { return suite.clock.pendingWaiters() == 1 })
+		assert.Len(suite.T(), suite.executor.executedTasks(), expected, string(policy))
+	}
+}
+
+func (suite *SchedulerServiceTestSuite) TestScheduleTask_RejectsInvalidExpression() {
+	task := suite.scheduledTask(&entities.ScheduledTrigger{Expression: "61 * * * *"})
+	assert.ErrorIs(suite.T(), suite.service.ScheduleTask(context.Background(), task), orchestrator2.ErrInvalidTask)
+}
+
+func (suite *SchedulerServiceTestSuite) TestCreateTask_RejectsInvalidSchedule() {
+	for _, trigger := range []entities.Trigger{
+		&entities.ScheduledTrigger{Expression: "61 * * * *", Timezone: "UTC"},
+		&entities.ScheduledTrigger{Expression: "*/15 * * * *", Timezone: "Mars/Olympus_Mons"},
+	} {
+		_, err := suite.tasks.CreateTask(context.Background(), entities.DevOpsTask{ID: "nightly", TaskType: entities.TaskTypeScheduled, Trigger: &trigger, Worker: &stubWorker{}})
+		assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidTask)
+	}
+	_, err := suite.tasks.CreateTask(context.Background(), entities.DevOpsTask{ID: "nightly", TaskType: entities.TaskTypeScheduled, Worker: &stubWorker{}})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidTask)
+}
+
+func (suite *SchedulerServiceTestSuite) TestRollbackTask_RestoresTheSchedule() {
+	suite.tasks.SetScheduler(suite.service)
+	ctx := context.Background()
+	var trigger entities.Trigger = &entities.ScheduledTrigger{Expression: "*/15 * * * *", Timezone: "UTC"}
+	created, err := suite.tasks.CreateTask(ctx, entities.DevOpsTask{ID: "nightly", TaskType: entities.TaskTypeScheduled, Trigger: &trigger, Worker: &stubWorker{}})
+	suite.Require().NoError(err)
+	_, err = suite.tasks.UpdateTask(ctx, "nightly", ports.TaskUpdate{TaskType: entities.TaskTypeManual})
+	suite.Require().NoError(err)
+
+	suite.Require().ErrorIs(suite.service.CancelScheduledTask(ctx, "nightly"), orchestrator2.ErrTaskNotScheduled)
+
+	// Al volver a la revisión programada se vuelve a planificar.
+	_, err = suite.tasks.RollbackTask(ctx, "nightly", created.Revision)
+	suite.Require().NoError(err)
+	assert.NoError(suite.T(), suite.service.CancelScheduledTask(ctx, "nightly"))
+}
+
+func TestSchedulerServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(SchedulerServiceTestSuite))
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skip("timezone database not available")
+	}
+	from := time.Date(2024, time.March, 4, 9, 7, 30, 0, time.UTC) // lunes
+
+	cases := []struct {
+		expression string
+		loc        *time.Location
+		expected   time.Time
+	}{
+		{"*/15 * * * *", time.UTC, time.Date(2024, time.March, 4, 9, 15, 0, 0, time.UTC)},
+		{"30 */10 * * * *", time.UTC, time.Date(2024, time.March, 4, 9, 10, 30, 0, time.UTC)},
+		{"0 2 * * MON-FRI", time.UTC, time.Date(2024, time.March, 5, 2, 0, 0, 0, time.UTC)},
+		{"0 0 1 JAN *", time.UTC, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.UTC, time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.UTC, time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * *", madrid, time.Date(2024, time.March, 4, 11, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		schedule, err := entities.ParseCronExpression(c.expression, c.loc)
+		if assert.NoError(t, err, c.expression) {
+			assert.True(t, c.expected.Equal(schedule.Next(from)), "%s: got %v", c.expression, schedule.Next(from))
+		}
+	}
+
+	for _, invalid := range []string{"* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *"} {
+		_, err := entities.ParseCronExpression(invalid, time.UTC)
+		assert.Error(t, err, invalid)
+	}
+}
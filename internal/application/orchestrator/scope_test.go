@@ -0,0 +1,137 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	ports "devops_console/internal/ports/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type ScopeTestSuite struct {
+	suite.Suite
+	service  *orchestrator2.TaskServiceImpl
+	repo     *adapters.InMemoryTaskRepository
+	executor *fakeExecutor
+	// globexExecution es una ejecución de otro tenant cuyo ID conoce el test.
+	globexExecution string
+}
+
+func (suite *ScopeTestSuite) SetupTest() {
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.executor = newFakeExecutor()
+	suite.executor.hold = true
+	suite.service = orchestrator2.NewTaskServiceImpl(suite.repo)
+	suite.service.RegisterExecutor("Stub", suite.executor)
+
+	for _, task := range []*entities.DevOpsTask{
+		{ID: "deploy-payments", Workspace: payments, Worker: &stubWorker{}},
+		{ID: "reindex-search", Workspace: search, Worker: &stubWorker{}},
+		{ID: "invoice-billing", Workspace: billing, Worker: &stubWorker{}},
+	} {
+		suite.Require().NoError(suite.repo.Create(context.Background(), task))
+	}
+	executionID, err := suite.service.ExecuteTask(inScope(billing, false), "invoice-billing")
+	suite.Require().NoError(err)
+	suite.globexExecution = executionID
+}
+
+func (suite *ScopeTestSuite) TearDownTest() {
+	suite.service.Shutdown()
+}
+
+func inScope(workspace entities.Workspace, tenantWide bool) context.Context {
+	scope := entities.Scope{TenantID: workspace.TenantID, WorkspaceID: workspace.ID}
+	if tenantWide {
+		scope.WorkspaceID = ""
+	}
+	return ports.WithScope(context.Background(), scope)
+}
+
+func (suite *ScopeTestSuite) TestTasksOutsideScope_AreNotFound() {
+	acme := inScope(payments, true)
+
+	_, err := suite.service.GetTask(acme, "invoice-billing")
+	assert.EqualError(suite.T(), err, "task not found")
+	_, err = suite.service.GetTask(inScope(payments, false), "reindex-search")
+	assert.EqualError(suite.T(), err, "task not found")
+	_, err = suite.service.ExecuteTask(acme, "invoice-billing")
+	assert.EqualError(suite.T(), err, "task not found")
+
+	page, err := suite.service.GetTasks(acme, ports.TaskFilters{})
+	suite.Require().NoError(err)
+	ids := make([]string, len(page.Tasks))
+	for i, task := range page.Tasks {
+		ids[i] = task.ID
+	}
+	assert.ElementsMatch(suite.T(), []string{"deploy-payments", "reindex-search"}, ids)
+
+	// Ni siquiera el filtro por tenant da acceso a otro.
+	page, err = suite.service.GetTasks(acme, ports.TaskFilters{TenantID: "globex"})
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), page.Tasks)
+
+	assert.EqualError(suite.T(), suite.service.DeleteTask(acme, "invoice-billing"), "task not found")
+	_, err = suite.service.GetTask(inScope(billing, true), "invoice-billing")
+	suite.Require().NoError(err)
+}
+
+func (suite *ScopeTestSuite) TestExecutionsOutsideScope_AreNotFound() {
+	acme := inScope(payments, true)
+
+	_, err := suite.service.GetTaskStatus(acme, suite.globexExecution)
+	assert.Error(suite.T(), err)
+	_, err = suite.service.SubscribeToTaskEvents(acme, suite.globexExecution)
+	assert.EqualError(suite.T(), err, "task not found")
+	assert.EqualError(suite.T(), suite.service.CancelTask(acme, suite.globexExecution), "task not found")
+
+	status, err := suite.service.GetTaskStatus(inScope(billing, true), suite.globexExecution)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.TaskRunning, status)
+}
+
+func (suite *ScopeTestSuite) TestCreateTask_StaysInScope() {
+	task, err := suite.service.CreateTask(inScope(payments, false), entities.DevOpsTask{ID: "rollback", Worker: &stubWorker{}})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.Workspace{ID: "payments", TenantID: "acme"}, task.Workspace)
+
+	_, err = suite.service.CreateTask(inScope(payments, true), entities.DevOpsTask{ID: "steal", Workspace: billing, Worker: &stubWorker{}})
+	assert.ErrorIs(suite.T(), err, ports.ErrOutOfScope)
+
+	// Reutilizar el ID de una tarea de otro tenant no la sobrescribe.
+	_, err = suite.service.CreateTask(inScope(payments, true), entities.DevOpsTask{ID: "invoice-billing", Worker: &stubWorker{}})
+	assert.EqualError(suite.T(), err, "task not found")
+	existing, err := suite.repo.GetByID(context.Background(), "invoice-billing")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), billing, existing.Workspace)
+}
+
+func (suite *ScopeTestSuite) TestUpdateTask_MovesWorkspaceOnlyWithinScope() {
+	acme := inScope(payments, true)
+
+	task, err := suite.service.UpdateTask(acme, "deploy-payments", ports.TaskUpdate{Workspace: entities.Workspace{ID: "search"}})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.Workspace{ID: "search", TenantID: "acme"}, task.Workspace)
+
+	_, err = suite.service.UpdateTask(acme, "reindex-search", ports.TaskUpdate{Workspace: billing})
+	assert.ErrorIs(suite.T(), err, ports.ErrOutOfScope)
+	_, err = suite.service.UpdateTask(inScope(search, false), "reindex-search", ports.TaskUpdate{Workspace: payments})
+	assert.ErrorIs(suite.T(), err, ports.ErrOutOfScope)
+}
+
+func (suite *ScopeTestSuite) TestPipeline_CannotReferenceTasksOfAnotherTenant() {
+	pipelines := orchestrator2.NewPipelineServiceImpl(adapters.NewInMemoryPipelineRepository(), suite.service)
+	_, err := pipelines.CreatePipeline(context.Background(), entities.Pipeline{
+		ID:        "release",
+		Workspace: payments,
+		Nodes:     []entities.PipelineNode{{ID: "invoice", TaskID: "invoice-billing"}},
+	})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidPipeline)
+}
+
+func TestScopeTestSuite(t *testing.T) {
+	suite.Run(t, new(ScopeTestSuite))
+}
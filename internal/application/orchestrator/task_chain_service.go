@@ -0,0 +1,154 @@
+package orchestrator
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+var ErrChainLoop = errors.New("task chain loop")
+
+// defaultMaxChainDepth limita la longitud de una cadena aunque no repita tareas.
+const defaultMaxChainDepth = 10
+
+// chainSubject es quien figura como TriggeredBy en las ejecuciones lanzadas por encadenamiento.
+var chainSubject = entities.ServiceAccount{ID: "task-chain", Name: "Task chain"}
+
+// TaskChainServiceImpl escucha los resultados que publica el TaskService y lanza las
+// tareas cuyo TaskCompletionTrigger coincide con la ejecución que acaba de terminar,
+// pasándoles su ID y sus salidas como parámetros. Una cadena no puede volver a una tarea
+// que ya forma parte de ella ni superar MaxDepth eslabones.
+type TaskChainServiceImpl struct {
+	repository  ports.TaskRepository
+	tasks       *TaskServiceImpl
+	eventStream ports.TaskEventStream
+	MaxDepth    int
+	// events es la suscripción mientras el servicio está arrancado.
+	events <-chan entities.TaskEvent
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+}
+
+func NewTaskChainServiceImpl(taskRepo ports.TaskRepository, taskService *TaskServiceImpl, eventStream ports.TaskEventStream) *TaskChainServiceImpl {
+	return &TaskChainServiceImpl{
+		repository:  taskRepo,
+		tasks:       taskService,
+		eventStream: eventStream,
+		MaxDepth:    defaultMaxChainDepth,
+	}
+}
+
+// Start se suscribe a los eventos de todas las ejecuciones. El TaskService debe publicar
+// en el mismo stream (TaskServiceImpl.EventStream).
+func (s *TaskChainServiceImpl) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.events != nil {
+		return nil
+	}
+	events, err := s.eventStream.Subscribe(ports.AllExecutions)
+	if err != nil {
+		return err
+	}
+	s.events = events
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for event := range events {
+			result, ok := event.Payload.(entities.TaskResultPayload)
+			if !ok {
+				continue
+			}
+			// Se lanza aparte para no bloquear el stream mientras se lanzan las tareas.
+			s.wg.Add(1)
+			go func(executionID string) {
+				defer s.wg.Done()
+				s.handleResult(s.tasks.lifetime, executionID, result)
+			}(event.ExecutionID)
+		}
+	}()
+	return nil
+}
+
+// Stop cierra la suscripción y espera a que terminen los lanzamientos en curso.
+func (s *TaskChainServiceImpl) Stop() {
+	s.mu.Lock()
+	events := s.events
+	s.events = nil
+	s.mu.Unlock()
+	if events == nil {
+		return
+	}
+	// Solo se cierra la suscripción propia: otros servicios escuchan el mismo stream.
+	s.eventStream.Unsubscribe(ports.AllExecutions, events)
+	s.wg.Wait()
+}
+
+// handleResult lanza las tareas encadenadas a la ejecución que acaba de terminar. Solo se
+// encadenan tareas del mismo tenant, que son las únicas que pueden ver sus salidas.
+func (s *TaskChainServiceImpl) handleResult(ctx context.Context, executionID string, result entities.TaskResultPayload) {
+	upstreamTask, err := s.repository.GetByID(ctx, result.TaskID)
+	if err != nil {
+		log.Printf("Error chaining execution %s: %v", executionID, err)
+		return
+	}
+	ctx = ports.WithScope(ctx, entities.Scope{TenantID: upstreamTask.Workspace.TenantID})
+	page, err := s.repository.GetAll(ctx, ports.TaskFilters{TaskType: entities.TaskTypeTriggered})
+	if err != nil {
+		log.Printf("Error chaining execution %s: %v", executionID, err)
+		return
+	}
+	var downstream []entities.DevOpsTask
+	for _, task := range page.Tasks {
+		if trigger, ok := task.CompletionTrigger(); ok && trigger.Matches(result) {
+			downstream = append(downstream, task)
+		}
+	}
+	if len(downstream) == 0 {
+		return
+	}
+
+	upstream, err := s.tasks.getExecution(ctx, executionID)
+	if err != nil {
+		log.Printf("Error chaining execution %s: %v", executionID, err)
+		return
+	}
+	chain := append(append([]string{}, upstream.TriggerChain...), upstreamTask.ID)
+	document := entities.CompletionDocument(&upstreamTask, &upstream)
+
+	for i := range downstream {
+		if _, err := s.launch(ctx, &downstream[i], executionID, chain, document); err != nil {
+			log.Printf("Error chaining task %s after execution %s: %v", downstream[i].ID, executionID, err)
+		}
+	}
+}
+
+func (s *TaskChainServiceImpl) launch(ctx context.Context, task *entities.DevOpsTask, upstreamID string, chain []string, document map[string]interface{}) (string, error) {
+	for _, taskID := range chain {
+		if taskID == task.ID {
+			return "", fmt.Errorf("%w: task %s already in chain %v", ErrChainLoop, task.ID, chain)
+		}
+	}
+	if s.MaxDepth > 0 && len(chain) > s.MaxDepth {
+		return "", fmt.Errorf("%w: chain %v exceeds %d tasks", ErrChainLoop, chain, s.MaxDepth)
+	}
+
+	trigger, _ := task.CompletionTrigger()
+	parameters := make(map[string]interface{}, len(trigger.Parameters))
+	for name, path := range trigger.Parameters {
+		if value, ok := entities.LookupJSONPath(document, path); ok {
+			parameters[name] = value
+		}
+	}
+	// El encadenamiento lo declara quien configura la tarea, no quien lanzó la de origen.
+	return s.tasks.ExecuteTaskWith(trusted(ctx), task.ID, ExecutionOptions{
+		TriggeredBy:         chainSubject,
+		Parameters:          parameters,
+		UpstreamExecutionID: upstreamID,
+		TriggerChain:        chain,
+	})
+}
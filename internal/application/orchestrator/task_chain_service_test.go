@@ -0,0 +1,168 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	eventstream "devops_console/internal/infrastructure/orchestrator/events"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type TaskChainServiceTestSuite struct {
+	suite.Suite
+	service  *orchestrator2.TaskChainServiceImpl
+	tasks    *orchestrator2.TaskServiceImpl
+	repo     *adapters.InMemoryTaskRepository
+	executor *fakeExecutor
+}
+
+func (suite *TaskChainServiceTestSuite) SetupTest() {
+	stream := eventstream.NewTaskEventStream()
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.executor = newFakeExecutor()
+	suite.tasks = orchestrator2.NewTaskServiceImpl(suite.repo)
+	suite.tasks.EventStream = stream
+	suite.tasks.RegisterExecutor("Stub", suite.executor)
+	suite.service = orchestrator2.NewTaskChainServiceImpl(suite.repo, suite.tasks, stream)
+	suite.Require().NoError(suite.service.Start())
+}
+
+func (suite *TaskChainServiceTestSuite) TearDownTest() {
+	suite.service.Stop()
+}
+
+func (suite *TaskChainServiceTestSuite) createTask(id string, trigger *entities.TaskCompletionTrigger, parameters ...entities.ParameterDefinition) {
+	task := entities.DevOpsTask{ID: id, Name: id, Worker: &stubWorker{}, Workspace: entities.Workspace{ID: "team-a"}, Parameters: parameters}
+	if trigger != nil {
+		var t entities.Trigger = trigger
+		task.TaskType = entities.TaskTypeTriggered
+		task.Trigger = &t
+	}
+	_, err := suite.tasks.CreateTask(context.Background(), task)
+	suite.Require().NoError(err)
+}
+
+func (suite *TaskChainServiceTestSuite) run(taskID string) {
+	executionID, err := suite.tasks.ExecuteTask(context.Background(), taskID)
+	suite.Require().NoError(err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = suite.tasks.WaitForExecution(ctx, executionID)
+	suite.Require().NoError(err)
+}
+
+// executions espera a que la tarea tenga count ejecuciones terminadas y las devuelve.
+func (suite *TaskChainServiceTestSuite) executions(taskID string, count int) []*entities.TaskExecution {
+	var executions []*entities.TaskExecution
+	suite.Require().Eventually(func() bool {
+		task, err := suite.repo.GetByID(context.Background(), taskID)
+		suite.Require().NoError(err)
+		executions = task.Executions
+		return len(executions) >= count
+	}, time.Second, 5*time.Millisecond)
+	for _, execution := range executions {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		_, err := suite.tasks.WaitForExecution(ctx, execution.ID)
+		cancel()
+		suite.Require().NoError(err)
+	}
+	return executions
+}
+
+func (suite *TaskChainServiceTestSuite) TestChain_PassesUpstreamExecution() {
+	suite.createTask("build", nil)
+	suite.createTask("deploy", &entities.TaskCompletionTrigger{
+		TaskID:      "build",
+		WorkspaceID: "team-a",
+		Parameters:  map[string]string{"upstream": "$.execution.id", "source": "$.task.name"},
+	},
+		entities.ParameterDefinition{Name: "upstream", Type: entities.ParameterString, Required: true},
+		entities.ParameterDefinition{Name: "source", Type: entities.ParameterString})
+	suite.createTask("elsewhere", &entities.TaskCompletionTrigger{TaskID: "build", WorkspaceID: "team-b"})
+
+	suite.run("build")
+	build := suite.executions("build", 1)[0]
+	deploy := suite.executions("deploy", 1)[0]
+
+	assert.Equal(suite.T(), build.ID, deploy.UpstreamExecutionID)
+	assert.Equal(suite.T(), []string{"build"}, deploy.TriggerChain)
+	assert.Equal(suite.T(), "task-chain", deploy.TriggeredBy)
+	assert.Equal(suite.T(), map[string]interface{}{"upstream": build.ID, "source": "build"}, deploy.Parameters)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(suite.T(), suite.executions("elsewhere", 0))
+}
+
+func (suite *TaskChainServiceTestSuite) TestChain_MatchesStatus() {
+	suite.createTask("build", nil)
+	suite.createTask("deploy", &entities.TaskCompletionTrigger{TaskID: "build"})
+	suite.createTask("notify", &entities.TaskCompletionTrigger{TaskID: "build", On: []entities.TaskStatus{entities.TaskFailed}})
+	suite.executor.failures["build"] = []entities.FailureClass{entities.FailureNonZeroExit}
+
+	suite.run("build")
+	suite.executions("notify", 1)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(suite.T(), suite.executions("deploy", 0))
+}
+
+func (suite *TaskChainServiceTestSuite) TestChain_StaysWithinTenant() {
+	suite.createTask("build", nil)
+	// Mismo workspace e ID de tarea de origen, pero en otro tenant.
+	var trigger entities.Trigger = &entities.TaskCompletionTrigger{TaskID: "build", WorkspaceID: "team-a"}
+	_, err := suite.tasks.CreateTask(context.Background(), entities.DevOpsTask{
+		ID:        "foreign",
+		Worker:    &stubWorker{},
+		Workspace: entities.Workspace{ID: "team-a", TenantID: "globex"},
+		TaskType:  entities.TaskTypeTriggered,
+		Trigger:   &trigger,
+	})
+	suite.Require().NoError(err)
+
+	suite.run("build")
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(suite.T(), suite.executions("foreign", 0))
+}
+
+func (suite *TaskChainServiceTestSuite) TestChain_StopsLoops() {
+	suite.createTask("a", &entities.TaskCompletionTrigger{TaskID: "b"})
+	suite.createTask("b", &entities.TaskCompletionTrigger{TaskID: "a"})
+
+	suite.run("a")
+	b := suite.executions("b", 1)[0]
+	assert.Equal(suite.T(), []string{"a"}, b.TriggerChain)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(suite.T(), suite.executions("a", 1), 1)
+	assert.Len(suite.T(), suite.executions("b", 1), 1)
+
+	var self entities.Trigger = &entities.TaskCompletionTrigger{TaskID: "c"}
+	_, err := suite.tasks.CreateTask(context.Background(), entities.DevOpsTask{ID: "c", Worker: &stubWorker{}, TaskType: entities.TaskTypeTriggered, Trigger: &self})
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidTask)
+}
+
+func TestTaskChainServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TaskChainServiceTestSuite))
+}
+
+func TestCompletionDocument(t *testing.T) {
+	task := &entities.DevOpsTask{ID: "build", Name: "Build"}
+	execution := &entities.TaskExecution{
+		ID:               "exec-1",
+		Status:           entities.TaskSucceeded,
+		Output:           &entities.Artifact{Type: "application/json", Data: []byte(`{"version":"v1.2.3"}`)},
+		ExecutionDetails: map[string]interface{}{"PodName": "build-abc"},
+	}
+	document := entities.CompletionDocument(task, execution)
+
+	value, ok := entities.LookupJSONPath(document, "$.outputs.version")
+	assert.True(t, ok)
+	assert.Equal(t, "v1.2.3", value)
+	value, ok = entities.LookupJSONPath(document, "$.details.PodName")
+	assert.True(t, ok)
+	assert.Equal(t, "build-abc", value)
+}
@@ -0,0 +1,95 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	ports "devops_console/internal/ports/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type requestIDKey struct{}
+
+type TaskContextTestSuite struct {
+	suite.Suite
+	service  *orchestrator2.TaskServiceImpl
+	executor *fakeExecutor
+}
+
+func (suite *TaskContextTestSuite) SetupTest() {
+	repo := adapters.NewInMemoryTaskRepository()
+	suite.executor = newFakeExecutor()
+	suite.executor.hold = true
+	suite.service = orchestrator2.NewTaskServiceImpl(repo)
+	suite.service.RegisterExecutor("Stub", suite.executor)
+	_, err := suite.service.CreateTask(context.Background(), entities.DevOpsTask{ID: "deploy", Worker: &stubWorker{}})
+	suite.Require().NoError(err)
+}
+
+func (suite *TaskContextTestSuite) TearDownTest() {
+	suite.service.Shutdown()
+}
+
+func (suite *TaskContextTestSuite) TestCanceledRequest_DoesNotStopExecution() {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), requestIDKey{}, "req-1"))
+	executionID, err := suite.service.ExecuteTask(ctx, "deploy")
+	suite.Require().NoError(err)
+	cancel()
+
+	suite.executor.finish(executionID, entities.TaskSucceeded, "")
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), time.Second)
+	defer waitCancel()
+	status, err := suite.service.WaitForExecution(waitCtx, executionID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.TaskSucceeded, status)
+
+	// El executor recibe los valores de la petición.
+	suite.executor.mu.Lock()
+	defer suite.executor.mu.Unlock()
+	assert.Equal(suite.T(), "req-1", suite.executor.contexts[executionID].Value(requestIDKey{}))
+}
+
+func (suite *TaskContextTestSuite) TestCanceledContext_ReachesRepository() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := suite.service.ExecuteTask(ctx, "deploy")
+	assert.ErrorIs(suite.T(), err, context.Canceled)
+	_, err = suite.service.GetTask(ctx, "deploy")
+	assert.ErrorIs(suite.T(), err, context.Canceled)
+}
+
+func (suite *TaskContextTestSuite) TestExpiredDeadline_ReachesRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	_, err := suite.service.GetTasks(ctx, ports.TaskFilters{})
+	assert.ErrorIs(suite.T(), err, context.DeadlineExceeded)
+}
+
+func (suite *TaskContextTestSuite) TestShutdown_StopsReconciliationAndExecutors() {
+	executionID, err := suite.service.ExecuteTask(context.Background(), "deploy")
+	suite.Require().NoError(err)
+
+	suite.service.Shutdown()
+	suite.executor.mu.Lock()
+	assert.True(suite.T(), suite.executor.stopped)
+	suite.executor.mu.Unlock()
+
+	// Tras el apagado nadie reconcilia la ejecución: su final ya no se registra.
+	time.Sleep(20 * time.Millisecond)
+	suite.executor.finish(executionID, entities.TaskSucceeded, "")
+	time.Sleep(20 * time.Millisecond)
+	status, err := suite.service.GetTaskStatus(context.Background(), executionID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.TaskRunning, status)
+}
+
+func TestTaskContextTestSuite(t *testing.T) {
+	suite.Run(t, new(TaskContextTestSuite))
+}
@@ -0,0 +1,89 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	ports "devops_console/internal/ports/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type TaskFiltersTestSuite struct {
+	suite.Suite
+	service *orchestrator2.TaskServiceImpl
+	repo    *adapters.InMemoryTaskRepository
+	start   time.Time
+}
+
+func (suite *TaskFiltersTestSuite) SetupTest() {
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.service = orchestrator2.NewTaskServiceImpl(suite.repo)
+	suite.start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tasks := []entities.DevOpsTask{
+		{ID: "a", Name: "Build API", Workspace: entities.Workspace{ID: "team-a", TenantID: "acme"}, TaskType: entities.TaskTypeManual, Tags: []string{"ci", "api"}},
+		{ID: "b", Name: "Deploy API", Workspace: entities.Workspace{ID: "team-a", TenantID: "acme"}, TaskType: entities.TaskTypeScheduled, Tags: []string{"cd", "api"},
+			Executions: []*entities.TaskExecution{{ID: "b-1", Status: entities.TaskSucceeded}, {ID: "b-2", Status: entities.TaskFailed}}},
+		{ID: "c", Name: "Backup", Workspace: entities.Workspace{ID: "team-b", TenantID: "acme"}, TaskType: entities.TaskTypeScheduled, Tags: []string{"ops"},
+			Executions: []*entities.TaskExecution{{ID: "c-1", Status: entities.TaskSucceeded}}},
+		{ID: "d", Name: "Build web", Workspace: entities.Workspace{ID: "team-c", TenantID: "globex"}, TaskType: entities.TaskTypeManual, Tags: []string{"ci"}},
+	}
+	for i := range tasks {
+		tasks[i].CreatedAt = suite.start.Add(time.Duration(i) * time.Hour)
+		tasks[i].UpdatedAt = suite.start.Add(time.Duration(10-i) * time.Hour)
+		suite.Require().NoError(suite.repo.Create(context.Background(), &tasks[i]))
+	}
+}
+
+func (suite *TaskFiltersTestSuite) ids(filters ports.TaskFilters) []string {
+	page, err := suite.service.GetTasks(context.Background(), filters)
+	suite.Require().NoError(err)
+	ids := make([]string, len(page.Tasks))
+	for i, task := range page.Tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+func (suite *TaskFiltersTestSuite) TestGetTasks_Filters() {
+	assert.Equal(suite.T(), []string{"a", "b", "c", "d"}, suite.ids(ports.TaskFilters{}))
+	assert.Equal(suite.T(), []string{"a", "b"}, suite.ids(ports.TaskFilters{WorkspaceID: "team-a"}))
+	assert.Equal(suite.T(), []string{"d"}, suite.ids(ports.TaskFilters{TenantID: "globex"}))
+	assert.Equal(suite.T(), []string{"b", "c"}, suite.ids(ports.TaskFilters{TaskType: entities.TaskTypeScheduled}))
+	assert.Equal(suite.T(), []string{"a"}, suite.ids(ports.TaskFilters{Tags: []string{"ci", "api"}}))
+	assert.Equal(suite.T(), []string{"a", "d"}, suite.ids(ports.TaskFilters{Search: "build"}))
+	assert.Equal(suite.T(), []string{"b", "c"}, suite.ids(ports.TaskFilters{CreatedAfter: suite.start.Add(time.Hour), CreatedBefore: suite.start.Add(3 * time.Hour)}))
+	assert.Equal(suite.T(), []string{"c", "d"}, suite.ids(ports.TaskFilters{UpdatedBefore: suite.start.Add(9 * time.Hour)}))
+	assert.Equal(suite.T(), []string{"b"}, suite.ids(ports.TaskFilters{LastExecutionStatus: entities.TaskFailed}))
+}
+
+func (suite *TaskFiltersTestSuite) TestGetTasks_SortsAndPaginates() {
+	assert.Equal(suite.T(), []string{"c", "a", "d", "b"}, suite.ids(ports.TaskFilters{SortBy: ports.TaskSortByName}))
+	assert.Equal(suite.T(), []string{"d", "c", "b", "a"}, suite.ids(ports.TaskFilters{SortBy: ports.TaskSortByUpdatedAt}))
+
+	filters := ports.TaskFilters{SortBy: ports.TaskSortByName, Descending: true, Limit: 3}
+	first, err := suite.service.GetTasks(context.Background(), filters)
+	suite.Require().NoError(err)
+	suite.Require().Len(first.Tasks, 3)
+	assert.Equal(suite.T(), "b", first.Tasks[0].ID)
+	suite.Require().NotEmpty(first.NextCursor)
+
+	filters.Cursor = first.NextCursor
+	second, err := suite.service.GetTasks(context.Background(), filters)
+	suite.Require().NoError(err)
+	suite.Require().Len(second.Tasks, 1)
+	assert.Equal(suite.T(), "c", second.Tasks[0].ID)
+	assert.Empty(suite.T(), second.NextCursor)
+
+	// El cursor solo vale para el orden con el que se obtuvo.
+	_, err = suite.service.GetTasks(context.Background(), ports.TaskFilters{Cursor: first.NextCursor})
+	assert.ErrorIs(suite.T(), err, ports.ErrInvalidCursor)
+}
+
+func TestTaskFiltersTestSuite(t *testing.T) {
+	suite.Run(t, new(TaskFiltersTestSuite))
+}
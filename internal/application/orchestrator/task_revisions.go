@@ -0,0 +1,139 @@
+package orchestrator
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrRevisionNotFound = errors.New("task revision not found")
+
+// ListTaskRevisions devuelve el historial de revisiones de la tarea, de la más antigua a
+// la más reciente.
+func (s *TaskServiceImpl) ListTaskRevisions(ctx context.Context, taskID string) ([]entities.TaskRevision, error) {
+	task, err := s.repository.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, nil, entities.PermissionView, task.Workspace); err != nil {
+		return nil, err
+	}
+	revisions := make([]entities.TaskRevision, len(task.Revisions))
+	for i, revision := range task.Revisions {
+		revisions[i] = *revision
+	}
+	return revisions, nil
+}
+
+func (s *TaskServiceImpl) GetTaskRevision(ctx context.Context, taskID string, revision int) (entities.TaskRevision, error) {
+	task, err := s.repository.GetByID(ctx, taskID)
+	if err != nil {
+		return entities.TaskRevision{}, err
+	}
+	if err := s.authorize(ctx, nil, entities.PermissionView, task.Workspace); err != nil {
+		return entities.TaskRevision{}, err
+	}
+	found, err := findRevision(&task, revision)
+	if err != nil {
+		return entities.TaskRevision{}, err
+	}
+	return *found, nil
+}
+
+// DiffTaskRevisions compara campo a campo dos revisiones de la tarea.
+func (s *TaskServiceImpl) DiffTaskRevisions(ctx context.Context, taskID string, from, to int) ([]entities.FieldChange, error) {
+	task, err := s.repository.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, nil, entities.PermissionView, task.Workspace); err != nil {
+		return nil, err
+	}
+	fromRevision, err := findRevision(&task, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := findRevision(&task, to)
+	if err != nil {
+		return nil, err
+	}
+	return entities.DiffTasks(fromRevision.Task, toRevision.Task), nil
+}
+
+// RollbackTask restaura la configuración de una revisión anterior. El historial no se
+// reescribe: el rollback queda registrado como una revisión nueva.
+func (s *TaskServiceImpl) RollbackTask(ctx context.Context, taskID string, revision int) (rolledBack entities.DevOpsTask, err error) {
+	var before *entities.DevOpsTask
+	defer func() { s.recordUpdate(ctx, taskID, before, rolledBack, err) }()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.repository.GetByID(ctx, taskID)
+	if err != nil {
+		return entities.DevOpsTask{}, err
+	}
+	current := task.Snapshot()
+	before = &current
+
+	target, err := findRevision(&task, revision)
+	if err != nil {
+		return entities.DevOpsTask{}, err
+	}
+	if err := s.authorizeUpdate(ctx, task.Workspace, target.Task.Workspace); err != nil {
+		return entities.DevOpsTask{}, err
+	}
+
+	restored := target.Task.Snapshot()
+	restored.ID = task.ID
+	restored.CreatedAt = task.CreatedAt
+	restored.UpdatedAt = time.Now()
+	restored.Executions = task.Executions
+	restored.Approvals = task.Approvals
+	restored.Revisions = task.Revisions
+	recordRevision(&restored, revision)
+	// El executor pudo cambiar desde que se guardó la revisión.
+	if err := s.validateExecutor(&restored); err != nil {
+		return entities.DevOpsTask{}, err
+	}
+
+	if err := s.repository.Update(ctx, &restored); err != nil {
+		return entities.DevOpsTask{}, err
+	}
+	s.reschedule(&restored)
+	return restored, nil
+}
+
+// recordRevision añade la configuración actual de la tarea como revisión nueva.
+func recordRevision(task *entities.DevOpsTask, rolledBackFrom int) {
+	next := 1
+	if len(task.Revisions) > 0 {
+		next = task.Revisions[len(task.Revisions)-1].Revision + 1
+	}
+	task.Revision = next
+	task.Revisions = append(task.Revisions, &entities.TaskRevision{
+		Revision:       next,
+		CreatedAt:      task.UpdatedAt,
+		Task:           task.Snapshot(),
+		RolledBackFrom: rolledBackFrom,
+	})
+}
+
+// pinnedTask devuelve la tarea tal y como era en la revisión con la que se lanzó la
+// ejecución; las ejecuciones anteriores al versionado usan la actual.
+func pinnedTask(task *entities.DevOpsTask, execution *entities.TaskExecution) entities.DevOpsTask {
+	if revision, err := findRevision(task, execution.TaskRevision); err == nil {
+		return revision.Task
+	}
+	return *task
+}
+
+func findRevision(task *entities.DevOpsTask, revision int) (*entities.TaskRevision, error) {
+	for _, candidate := range task.Revisions {
+		if candidate.Revision == revision {
+			return candidate, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: task %s has no revision %d", ErrRevisionNotFound, task.ID, revision)
+}
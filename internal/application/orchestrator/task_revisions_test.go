@@ -0,0 +1,112 @@
+package orchestrator_test
+
+import (
+	"context"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	ports "devops_console/internal/ports/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type TaskRevisionsTestSuite struct {
+	suite.Suite
+	service  *orchestrator2.TaskServiceImpl
+	repo     *adapters.InMemoryTaskRepository
+	executor *fakeExecutor
+}
+
+func (suite *TaskRevisionsTestSuite) SetupTest() {
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.executor = newFakeExecutor()
+	suite.service = orchestrator2.NewTaskServiceImpl(suite.repo)
+	suite.service.RegisterExecutor("Stub", suite.executor)
+}
+
+func (suite *TaskRevisionsTestSuite) createTask() entities.DevOpsTask {
+	task, err := suite.service.CreateTask(context.Background(), entities.DevOpsTask{
+		ID:     "deploy",
+		Name:   "deploy",
+		Worker: &stubWorker{},
+		Config: entities.TaskConfig{
+			Parameters: map[string]interface{}{"replicas": 2},
+			Retry:      &entities.RetryPolicy{MaxAttempts: 2},
+		},
+	})
+	suite.Require().NoError(err)
+	return task
+}
+
+func (suite *TaskRevisionsTestSuite) TestUpdateTask_RecordsRevisions() {
+	task := suite.createTask()
+	assert.Equal(suite.T(), 1, task.Revision)
+
+	updated, err := suite.service.UpdateTask(context.Background(), task.ID, ports.TaskUpdate{
+		Description: "Despliegue a producción",
+		Config: entities.TaskConfig{
+			Parameters: map[string]interface{}{"replicas": 3},
+			Retry:      &entities.RetryPolicy{MaxAttempts: 5},
+		},
+	})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 2, updated.Revision)
+
+	revisions, err := suite.service.ListTaskRevisions(context.Background(), task.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(revisions, 2)
+	assert.Equal(suite.T(), 2, revisions[0].Task.Config.Parameters["replicas"])
+	assert.Equal(suite.T(), 3, revisions[1].Task.Config.Parameters["replicas"])
+
+	changes, err := suite.service.DiffTaskRevisions(context.Background(), task.ID, 1, 2)
+	suite.Require().NoError(err)
+	assert.ElementsMatch(suite.T(), []entities.FieldChange{
+		{Field: "Description", From: "", To: "Despliegue a producción"},
+		{Field: "Config.Parameters", From: map[string]interface{}{"replicas": 2}, To: map[string]interface{}{"replicas": 3}},
+		{Field: "Config.Retry.MaxAttempts", From: 2, To: 5},
+	}, changes)
+
+	_, err = suite.service.DiffTaskRevisions(context.Background(), task.ID, 1, 7)
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrRevisionNotFound)
+}
+
+func (suite *TaskRevisionsTestSuite) TestRollbackTask_AddsRevision() {
+	task := suite.createTask()
+	_, err := suite.service.UpdateTask(context.Background(), task.ID, ports.TaskUpdate{Config: entities.TaskConfig{Parameters: map[string]interface{}{"replicas": 9}}})
+	suite.Require().NoError(err)
+
+	restored, err := suite.service.RollbackTask(context.Background(), task.ID, 1)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 3, restored.Revision)
+	assert.Equal(suite.T(), 2, restored.Config.Parameters["replicas"])
+
+	revision, err := suite.service.GetTaskRevision(context.Background(), task.ID, 3)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 1, revision.RolledBackFrom)
+	changes, err := suite.service.DiffTaskRevisions(context.Background(), task.ID, 1, 3)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), changes)
+}
+
+func (suite *TaskRevisionsTestSuite) TestExecution_PinsRevision() {
+	task := suite.createTask()
+	_, err := suite.service.UpdateTask(context.Background(), task.ID, ports.TaskUpdate{Name: "deploy-v2"})
+	suite.Require().NoError(err)
+
+	executionID, err := suite.service.ExecuteTask(context.Background(), task.ID)
+	suite.Require().NoError(err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = suite.service.WaitForExecution(ctx, executionID)
+	suite.Require().NoError(err)
+
+	stored, err := suite.repo.GetByExecutionID(context.Background(), executionID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 2, stored.Executions[0].TaskRevision)
+}
+
+func TestTaskRevisionsTestSuite(t *testing.T) {
+	suite.Run(t, new(TaskRevisionsTestSuite))
+}
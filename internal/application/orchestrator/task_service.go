@@ -5,27 +5,64 @@ import (
 	"devops_console/internal/domain/entities/orchestrator"
 	ports "devops_console/internal/ports/orchestrator"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
+	"log"
+	"sync"
 	"time"
 )
 
 type TaskService interface {
-	CreateTask(task entities.DevOpsTask) (entities.DevOpsTask, error)
-	UpdateTask(taskID string, updates ports.TaskUpdate) (entities.DevOpsTask, error)
-	DeleteTask(taskID string) error
-	GetTask(taskID string) (entities.DevOpsTask, error)
-	GetTasks(filters ports.TaskFilters) ([]entities.DevOpsTask, error)
-	ExecuteTask(taskID string) (string, error)
-	GetTaskStatus(executionID string) (entities.TaskStatus, error)
-	CancelTask(executionID string) error
-	SubscribeToTaskEvents(executionID string) (<-chan entities.TaskEvent, error)
+	CreateTask(ctx context.Context, task entities.DevOpsTask) (entities.DevOpsTask, error)
+	UpdateTask(ctx context.Context, taskID string, updates ports.TaskUpdate) (entities.DevOpsTask, error)
+	ReplaceTask(ctx context.Context, task entities.DevOpsTask) (entities.DevOpsTask, error)
+	DeleteTask(ctx context.Context, taskID string) error
+	GetTask(ctx context.Context, taskID string) (entities.DevOpsTask, error)
+	GetTasks(ctx context.Context, filters ports.TaskFilters) (ports.TaskPage, error)
+	ExecuteTask(ctx context.Context, taskID string) (string, error)
+	ExecuteTaskAs(ctx context.Context, taskID string, triggeredBy entities.Subject) (string, error)
+	ExecuteTaskWith(ctx context.Context, taskID string, options ExecutionOptions) (string, error)
+	DryRunTask(ctx context.Context, taskID string, options ExecutionOptions) (entities.ExecutionPlan, error)
+	GetTaskStatus(ctx context.Context, executionID string) (entities.TaskStatus, error)
+	WaitForExecution(ctx context.Context, executionID string) (entities.TaskStatus, error)
+	GetQueuePosition(ctx context.Context, executionID string) (int, error)
+	CancelTask(ctx context.Context, executionID string) error
+	SubscribeToTaskEvents(ctx context.Context, executionID string) (<-chan entities.TaskEvent, error)
+	Approve(ctx context.Context, executionID string, subject entities.Subject, comment string) error
+	Reject(ctx context.Context, executionID string, subject entities.Subject, comment string) error
+	SubmitInput(ctx context.Context, executionID string, subject entities.Subject, values map[string]interface{}) error
+	AbortInput(ctx context.Context, executionID string, subject entities.Subject, reason string) error
+	ListTaskRevisions(ctx context.Context, taskID string) ([]entities.TaskRevision, error)
+	GetTaskRevision(ctx context.Context, taskID string, revision int) (entities.TaskRevision, error)
+	DiffTaskRevisions(ctx context.Context, taskID string, from, to int) ([]entities.FieldChange, error)
+	RollbackTask(ctx context.Context, taskID string, revision int) (entities.DevOpsTask, error)
+	// Shutdown cancela el trabajo en segundo plano del servicio (reconciliación, cola,
+	// reintentos y caducidades) y las ejecuciones en curso. Cancelar el ctx de una
+	// petición no lo hace.
+	Shutdown()
 }
 
+var _ TaskService = (*TaskServiceImpl)(nil)
+
 var (
-	ErrTaskNotFound = errors.New("task not found")
-	ErrInvalidTask  = errors.New("invalid task")
+	ErrTaskNotFound      = errors.New("task not found")
+	ErrInvalidTask       = errors.New("invalid task")
+	ErrInvalidParameters = errors.New("invalid parameters")
+	ErrPlanNotSupported  = errors.New("executor does not support dry-run")
+	ErrExecutionNotFound = errors.New("execution not found")
 )
 
+// ExecutionOptions son los datos de una ejecución lanzada con ExecuteTaskWith.
+type ExecutionOptions struct {
+	TriggeredBy entities.Subject
+	// Parameters son los valores de los parámetros definidos en la tarea.
+	Parameters map[string]interface{}
+	// UpstreamExecutionID y TriggerChain identifican la ejecución que lanzó esta cuando
+	// viene de un TaskCompletionTrigger.
+	UpstreamExecutionID string
+	TriggerChain        []string
+}
+
 type IDGenerator func() string
 
 func defaultIDGenerator() string {
@@ -36,13 +73,70 @@ type TaskServiceImpl struct {
 	repository ports.TaskRepository
 	executors  map[string]ports.TaskExecutor
 	GenerateID IDGenerator
+	// EventStream, si se configura, recibe los eventos propios del servicio (p.ej. reintentos).
+	EventStream ports.TaskEventStream
+	// Metrics, si se configura, recibe las mediciones de las ejecuciones.
+	Metrics    ports.ExecutionMetrics
+	mu         sync.Mutex // serializa las lecturas-escrituras de ejecuciones en el repositorio
+	finished   map[string]chan struct{}
+	finishedMu sync.Mutex
+	queue      *executionQueue // nil si no hay límites de concurrencia
+	// runParameters guarda los valores sin enmascarar mientras la cola o los reintentos
+	// puedan necesitarlos; la ejecución persistida solo guarda la versión enmascarada.
+	runParameters map[string]map[string]interface{}
+	parametersMu  sync.Mutex
+	// lifetime vive lo que el servicio: el trabajo que sigue tras una petición (reconciliar
+	// ejecuciones, la cola, los reintentos y las caducidades) cuelga de él y no de la petición.
+	lifetime context.Context
+	shutdown context.CancelFunc
+	// authorizer es nil si no hay control de acceso.
+	authorizer ports.Authorizer
+	// audit es nil si no se auditan las operaciones.
+	audit *AuditServiceImpl
+	// maxInputSize limita el tamaño de cada artefacto de entrada; 0 no limita.
+	maxInputSize int64
+	// matrices son las ejecuciones de matriz en curso, por su ID.
+	matrices   map[string]*matrixRun
+	matricesMu sync.Mutex
+	// scheduler es nil si las tareas programadas no se planifican al guardarlas.
+	scheduler ports.TaskScheduler
 }
 
 func NewTaskServiceImpl(taskRepo ports.TaskRepository) *TaskServiceImpl {
+	lifetime, shutdown := context.WithCancel(context.Background())
 	return &TaskServiceImpl{
 		repository: taskRepo,
 		executors:  make(map[string]ports.TaskExecutor),
 		GenerateID: defaultIDGenerator,
+		finished:   make(map[string]chan struct{}),
+		matrices:   make(map[string]*matrixRun),
+
+		runParameters: make(map[string]map[string]interface{}),
+		lifetime:      lifetime,
+		shutdown:      shutdown,
+	}
+}
+
+// Shutdown cancela el trabajo en segundo plano del servicio y apaga los executors que lo
+// admiten, lo que cancela sus ejecuciones en curso.
+func (s *TaskServiceImpl) Shutdown() {
+	s.shutdown()
+	for _, executor := range s.executors {
+		if shutdowner, ok := executor.(ports.ExecutorShutdowner); ok {
+			shutdowner.Shutdown()
+		}
+	}
+}
+
+// detach devuelve un contexto con los valores de ctx (identidad, trazas...) que no se
+// cancela con él sino con Shutdown, para el trabajo que sigue cuando la petición termina.
+// cancel libera el contexto al acabar ese trabajo.
+func (s *TaskServiceImpl) detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(s.lifetime, cancel)
+	return detached, func() {
+		stop()
+		cancel()
 	}
 }
 
@@ -51,26 +145,62 @@ func (s *TaskServiceImpl) RegisterExecutor(workerType string, executor ports.Tas
 }
 
 // Implementación de la interfaz TaskService
-func (s *TaskServiceImpl) CreateTask(task entities.DevOpsTask) (entities.DevOpsTask, error) {
+func (s *TaskServiceImpl) CreateTask(ctx context.Context, task entities.DevOpsTask) (created entities.DevOpsTask, err error) {
+	defer func() {
+		event := auditEvent{action: entities.AuditCreate, workspace: task.Workspace, taskID: task.ID}
+		if err == nil {
+			event.after = &created
+		}
+		s.recordAudit(ctx, &event, err)
+	}()
+	if scope, ok := ports.ScopeFromContext(ctx); ok {
+		task.Workspace = scopedWorkspace(task.Workspace, scope)
+	}
+	if err := s.authorize(ctx, nil, entities.PermissionCreate, task.Workspace); err != nil {
+		return entities.DevOpsTask{}, err
+	}
 	if task.ID == "" {
 		task.ID = s.GenerateID()
 	}
+	if err := validateTask(&task); err != nil {
+		return entities.DevOpsTask{}, err
+	}
+	if err := s.validateExecutor(&task); err != nil {
+		return entities.DevOpsTask{}, err
+	}
 	task.CreatedAt = time.Now()
 	task.UpdatedAt = time.Now()
+	task.Revisions = nil
+	recordRevision(&task, 0)
 
-	err := s.repository.Create(context.Background(), &task)
+	err = s.repository.Create(ctx, &task)
 	if err != nil {
 		return entities.DevOpsTask{}, err
 	}
+	s.reschedule(&task)
 	return task, nil
 }
 
-func (s *TaskServiceImpl) UpdateTask(taskID string, updates ports.TaskUpdate) (entities.DevOpsTask, error) {
-	ctx := context.Background()
+// UpdateTask aplica los cambios y registra la configuración resultante como revisión nueva.
+func (s *TaskServiceImpl) UpdateTask(ctx context.Context, taskID string, updates ports.TaskUpdate) (updated entities.DevOpsTask, err error) {
+	var before *entities.DevOpsTask
+	defer func() { s.recordUpdate(ctx, taskID, before, updated, err) }()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	task, err := s.repository.GetByID(ctx, taskID)
 	if err != nil {
 		return entities.DevOpsTask{}, err
 	}
+	current := task.Snapshot()
+	before = &current
+	if err := s.authorize(ctx, nil, entities.PermissionUpdate, task.Workspace); err != nil {
+		return entities.DevOpsTask{}, err
+	}
+	// Las tareas anteriores al versionado guardan su configuración actual como primera revisión.
+	if len(task.Revisions) == 0 {
+		recordRevision(&task, 0)
+	}
 
 	// Actualizar los campos según el TaskUpdate
 	if updates.Name != "" {
@@ -85,75 +215,452 @@ func (s *TaskServiceImpl) UpdateTask(taskID string, updates ports.TaskUpdate) (e
 	if updates.TaskType != "" {
 		task.TaskType = updates.TaskType
 	}
-	// if updates.Approvals != nil {
-	//     task.Approvals = *updates.Approvals
-	// }
+	if updates.Workspace.ID != "" {
+		if scope, ok := ports.ScopeFromContext(ctx); ok {
+			updates.Workspace = scopedWorkspace(updates.Workspace, scope)
+		}
+		if err := s.authorizeUpdate(ctx, task.Workspace, updates.Workspace); err != nil {
+			return entities.DevOpsTask{}, err
+		}
+		task.Workspace = updates.Workspace
+	}
+	if updates.ApprovalPolicy != nil {
+		task.ApprovalPolicy = updates.ApprovalPolicy
+	}
+	if updates.InputForm != nil {
+		task.InputForm = updates.InputForm
+	}
+	if updates.Parameters != nil {
+		task.Parameters = updates.Parameters
+	}
+	if updates.Parameters != nil || updates.InputForm != nil {
+		if err := entities.ValidateParameterDefinitions(task.AllParameters()); err != nil {
+			return entities.DevOpsTask{}, fmt.Errorf("%w: %v", ErrInvalidTask, err)
+		}
+	}
+	if err := validateMatrix(&task); err != nil {
+		return entities.DevOpsTask{}, err
+	}
+	if err := validateSchedule(&task); err != nil {
+		return entities.DevOpsTask{}, err
+	}
+	if err := s.validateExecutor(&task); err != nil {
+		return entities.DevOpsTask{}, err
+	}
 
 	task.UpdatedAt = time.Now()
+	recordRevision(&task, 0)
 
 	err = s.repository.Update(ctx, &task)
 	if err != nil {
 		return entities.DevOpsTask{}, err
 	}
+	s.reschedule(&task)
+	return task, nil
+}
+
+// ReplaceTask sustituye la configuración de una tarea existente por la de task, conservando
+// sus ejecuciones, aprobaciones e historial, y la registra como revisión nueva.
+func (s *TaskServiceImpl) ReplaceTask(ctx context.Context, task entities.DevOpsTask) (replaced entities.DevOpsTask, err error) {
+	var before *entities.DevOpsTask
+	defer func() { s.recordUpdate(ctx, task.ID, before, replaced, err) }()
+	if err := validateTask(&task); err != nil {
+		return entities.DevOpsTask{}, err
+	}
+	if err := s.validateExecutor(&task); err != nil {
+		return entities.DevOpsTask{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.repository.GetByID(ctx, task.ID)
+	if err != nil {
+		return entities.DevOpsTask{}, err
+	}
+	snapshot := current.Snapshot()
+	before = &snapshot
+	if err := s.authorizeUpdate(ctx, current.Workspace, task.Workspace); err != nil {
+		return entities.DevOpsTask{}, err
+	}
+	if len(current.Revisions) == 0 {
+		recordRevision(&current, 0)
+	}
+	task.CreatedAt = current.CreatedAt
+	task.UpdatedAt = time.Now()
+	task.Executions = current.Executions
+	task.Approvals = current.Approvals
+	task.Revisions = current.Revisions
+	recordRevision(&task, 0)
+
+	if err := s.repository.Update(ctx, &task); err != nil {
+		return entities.DevOpsTask{}, err
+	}
+	s.reschedule(&task)
 	return task, nil
 }
 
-func (s *TaskServiceImpl) DeleteTask(taskID string, workspace entities.Workspace) error {
-	ctx := context.Background()
-	return s.repository.Delete(ctx, taskID)
+// validateTask comprueba la configuración de una tarea antes de guardarla y genera el
+// token de su webhook si no lo tiene.
+func validateTask(task *entities.DevOpsTask) error {
+	if err := entities.ValidateParameterDefinitions(task.AllParameters()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTask, err)
+	}
+	if err := entities.ValidateOutputs(task.Config.Outputs); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTask, err)
+	}
+	if err := entities.ValidateArtifactInputs(task.ID, task.Config.Inputs); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTask, err)
+	}
+	if err := validateMatrix(task); err != nil {
+		return err
+	}
+	if err := validateSchedule(task); err != nil {
+		return err
+	}
+	if trigger, ok := task.WebhookTrigger(); ok && trigger.Secret == "" && !trigger.AllowUnsigned {
+		return fmt.Errorf("%w: webhook triggers need a secret unless they allow unsigned deliveries", ErrInvalidTask)
+	}
+	if err := ensureWebhookToken(task); err != nil {
+		return err
+	}
+	if trigger, ok := task.CompletionTrigger(); ok && trigger.TaskID == task.ID {
+		return fmt.Errorf("%w: task %q cannot be chained to itself", ErrInvalidTask, task.ID)
+	}
+	return nil
+}
+
+// validateSchedule comprueba la expresión cron y la zona horaria de las tareas programadas:
+// el scheduler no puede planificar las que no las tienen válidas.
+func validateSchedule(task *entities.DevOpsTask) error {
+	if task.TaskType != entities.TaskTypeScheduled {
+		return nil
+	}
+	trigger, err := scheduledTrigger(task)
+	if err != nil {
+		return err
+	}
+	if _, err := trigger.Schedule(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTask, err)
+	}
+	return nil
+}
+
+// validateExecutor rechaza la configuración que el executor del worker de la tarea no
+// lleva a cabo y que, si no, se ignoraría al ejecutarla.
+func (s *TaskServiceImpl) validateExecutor(task *entities.DevOpsTask) error {
+	var workerType string
+	if task.Worker != nil {
+		workerType = task.Worker.GetType()
+	}
+	executor := s.executors[workerType]
+	if len(task.Config.Outputs) > 0 {
+		if collector, ok := executor.(ports.OutputCollector); !ok || !collector.CollectsOutputs() {
+			return fmt.Errorf("%w: %q workers do not collect outputs", ErrInvalidTask, workerType)
+		}
+	}
+	if len(task.Config.Inputs) > 0 {
+		if injector, ok := executor.(ports.InputInjector); !ok || !injector.InjectsInputs() {
+			return fmt.Errorf("%w: %q workers do not receive inputs", ErrInvalidTask, workerType)
+		}
+	}
+	if task.TaskType == entities.TaskTypeManual && task.InputForm != nil {
+		if _, ok := executor.(ports.InputReceiver); !ok {
+			return fmt.Errorf("%w: %q workers do not receive input from operators", ErrInvalidTask, workerType)
+		}
+	}
+	return nil
+}
+
+// scopedWorkspace completa con el ámbito de la petición el tenant y el workspace que la
+// tarea no indica.
+func scopedWorkspace(workspace entities.Workspace, scope entities.Scope) entities.Workspace {
+	if workspace.TenantID == "" {
+		workspace.TenantID = scope.TenantID
+	}
+	if workspace.ID == "" {
+		workspace.ID = scope.WorkspaceID
+	}
+	return workspace
 }
 
-func (s *TaskServiceImpl) GetTask(taskID string, workspace entities.Workspace) (entities.DevOpsTask, error) {
-	ctx := context.Background()
-	return s.repository.GetByID(ctx, taskID)
+// authorizeUpdate autoriza la modificación de una tarea en su workspace y, si la cambia
+// de workspace, también en el de destino.
+func (s *TaskServiceImpl) authorizeUpdate(ctx context.Context, from, to entities.Workspace) error {
+	if err := s.authorize(ctx, nil, entities.PermissionUpdate, from); err != nil {
+		return err
+	}
+	if to != from {
+		return s.authorize(ctx, nil, entities.PermissionUpdate, to)
+	}
+	return nil
 }
 
-func (s *TaskServiceImpl) GetTasks(filters ports.TaskFilters) ([]entities.DevOpsTask, error) {
-	ctx := context.Background()
-	return s.repository.GetAll(ctx, filters)
+func (s *TaskServiceImpl) DeleteTask(ctx context.Context, taskID string) (err error) {
+	event := auditEvent{action: entities.AuditDelete, taskID: taskID}
+	defer func() { s.recordAudit(ctx, &event, err) }()
+	task, err := s.repository.GetByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	event.workspace = task.Workspace
+	event.before = &task
+	if err := s.authorize(ctx, nil, entities.PermissionDelete, task.Workspace); err != nil {
+		return err
+	}
+	if err := s.repository.Delete(ctx, taskID); err != nil {
+		return err
+	}
+	s.unschedule(taskID)
+	return nil
 }
 
-func (s *TaskServiceImpl) ExecuteTask(taskID string) (string, error) {
-	ctx := context.Background()
+func (s *TaskServiceImpl) GetTask(ctx context.Context, taskID string) (entities.DevOpsTask, error) {
 	task, err := s.repository.GetByID(ctx, taskID)
 	if err != nil {
+		return entities.DevOpsTask{}, err
+	}
+	if err := s.authorize(ctx, nil, entities.PermissionView, task.Workspace); err != nil {
+		return entities.DevOpsTask{}, err
+	}
+	return task, nil
+}
+
+// GetTasks devuelve una página del listado de tareas filtrado y ordenado según filters.
+// Con control de acceso se omiten las tareas que el sujeto no puede ver, por lo que la
+// página puede traer menos de filters.Limit aunque haya más.
+func (s *TaskServiceImpl) GetTasks(ctx context.Context, filters ports.TaskFilters) (ports.TaskPage, error) {
+	page, err := s.repository.GetAll(ctx, filters)
+	if err != nil || s.authorizer == nil {
+		return page, err
+	}
+	visible := page.Tasks[:0:0]
+	for _, task := range page.Tasks {
+		err := s.authorize(ctx, nil, entities.PermissionView, task.Workspace)
+		switch {
+		case err == nil:
+			visible = append(visible, task)
+		case !errors.Is(err, ErrPermissionDenied):
+			return ports.TaskPage{}, err
+		}
+	}
+	page.Tasks = visible
+	return page, nil
+}
+
+func (s *TaskServiceImpl) ExecuteTask(ctx context.Context, taskID string) (string, error) {
+	return s.ExecuteTaskAs(ctx, taskID, nil)
+}
+
+// ExecuteTaskAs lanza la tarea registrando quién la dispara.
+func (s *TaskServiceImpl) ExecuteTaskAs(ctx context.Context, taskID string, triggeredBy entities.Subject) (string, error) {
+	return s.ExecuteTaskWith(ctx, taskID, ExecutionOptions{TriggeredBy: triggeredBy})
+}
+
+// ExecuteTaskWith valida los parámetros de la ejecución y lanza la tarea. Las tareas de
+// aprobación no llegan al executor: quedan esperando a que se alcance el quórum.
+func (s *TaskServiceImpl) ExecuteTaskWith(ctx context.Context, taskID string, options ExecutionOptions) (executionID string, err error) {
+	event := auditEvent{action: entities.AuditExecute, taskID: taskID}
+	defer func() {
+		event.subject = options.TriggeredBy
+		event.executionID = executionID
+		s.recordAudit(ctx, &event, err)
+	}()
+	triggeredBy := options.TriggeredBy
+	options.TriggeredBy = nil
+	task, err := s.repository.GetByID(ctx, taskID)
+	if err != nil {
+		return "", err
+	}
+	event.workspace = task.Workspace
+	// La ejecución queda a nombre del sujeto autenticado, no del que diga quien la lanza.
+	if options.TriggeredBy, err = s.actingSubject(ctx, triggeredBy); err != nil {
+		return "", err
+	}
+	if err := s.authorize(ctx, options.TriggeredBy, entities.PermissionExecute, task.Workspace); err != nil {
+		return "", err
+	}
+
+	attempt := entities.TaskExecution{
+		Attempt:             1,
+		TriggeredBy:         subjectID(options.TriggeredBy),
+		TriggeredByKind:     subjectKind(options.TriggeredBy),
+		UpstreamExecutionID: options.UpstreamExecutionID,
+		TriggerChain:        options.TriggerChain,
+	}
+	if task.Config.Matrix != nil {
+		return s.launchMatrix(ctx, &task, attempt, options.Parameters)
+	}
+
+	parameters, err := entities.ResolveParameters(task.Parameters, options.Parameters)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidParameters, err)
+	}
+	if task.TaskType == entities.TaskTypeApproval {
+		return s.requestApproval(ctx, task, attempt, parameters)
+	}
+	return s.launchAttempt(ctx, &task, attempt, parameters)
+}
+
+// DryRunTask devuelve lo que lanzaría el executor para la tarea con esos parámetros, sin
+// lanzarlo ni registrar ninguna ejecución.
+func (s *TaskServiceImpl) DryRunTask(ctx context.Context, taskID string, options ExecutionOptions) (entities.ExecutionPlan, error) {
+	task, err := s.repository.GetByID(ctx, taskID)
+	if err != nil {
+		return entities.ExecutionPlan{}, err
+	}
+	if err := s.authorize(ctx, options.TriggeredBy, entities.PermissionExecute, task.Workspace); err != nil {
+		return entities.ExecutionPlan{}, err
+	}
+	parameters, err := entities.ResolveParameters(task.Parameters, options.Parameters)
+	if err != nil {
+		return entities.ExecutionPlan{}, fmt.Errorf("%w: %v", ErrInvalidParameters, err)
+	}
+
+	executor, ok := s.executors[task.Worker.GetType()]
+	if !ok {
+		return entities.ExecutionPlan{}, errors.New("unsupported worker type")
+	}
+	planner, ok := executor.(ports.TaskPlanner)
+	if !ok {
+		return entities.ExecutionPlan{}, fmt.Errorf("%w: %s", ErrPlanNotSupported, task.Worker.GetType())
+	}
+	runTask := withParameters(task, parameters)
+	plan, err := planner.Plan(ctx, &runTask)
+	if errors.Is(err, entities.ErrUndefinedTemplateVariable) {
+		return entities.ExecutionPlan{}, fmt.Errorf("%w: %v", ErrInvalidTask, err)
+	}
+	return plan, err
+}
+
+// launchAttempt encola la ejecución si hay límites de concurrencia o la lanza directamente.
+func (s *TaskServiceImpl) launchAttempt(ctx context.Context, task *entities.DevOpsTask, attempt entities.TaskExecution, parameters map[string]interface{}) (string, error) {
+	// Las plantillas se comprueban antes de lanzar para no dejar en la cola una ejecución
+	// que el executor va a rechazar.
+	if err := checkTemplates(task, parameters); err != nil {
+		return "", err
+	}
+	// Igual que las plantillas, los inputs se comprueban antes de encolar; se vuelven a
+	// elegir al lanzar por si la tarea de origen ha terminado otra vez mientras tanto.
+	if _, err := s.resolveInputs(ctx, task, attempt.UpstreamExecutionID); err != nil {
 		return "", err
 	}
 
+	attempt.Parameters = entities.MaskSecretParameters(task.AllParameters(), parameters)
+	attempt.TaskRevision = task.Revision
+	if s.queue != nil {
+		return s.enqueue(ctx, task, attempt, parameters)
+	}
+	return s.startAttempt(ctx, task, attempt, parameters)
+}
+
+// checkTemplates comprueba que las plantillas del worker se resuelven con esos parámetros.
+func checkTemplates(task *entities.DevOpsTask, parameters map[string]interface{}) error {
+	runTask := withParameters(*task, parameters)
+	if _, err := entities.RenderWorkerSpec(task.Worker.GetDetails(), entities.NewTemplateContext(&runTask, "", true)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTask, err)
+	}
+	return nil
+}
+
+// startAttempt lanza la tarea en su executor y registra la ejecución a partir de attempt,
+// que aporta el número de intento y los enlaces con el intento anterior.
+func (s *TaskServiceImpl) startAttempt(ctx context.Context, task *entities.DevOpsTask, attempt entities.TaskExecution, parameters map[string]interface{}) (string, error) {
 	worker := task.Worker
 	executor, ok := s.executors[worker.GetType()]
 	if !ok {
 		return "", errors.New("unsupported worker type")
 	}
 
-	executionID, err := executor.ExecuteTask(ctx, &task)
+	runTask := withParameters(*task, parameters)
+	inputs, err := s.resolveInputs(ctx, task, attempt.UpstreamExecutionID)
+	if err != nil {
+		return "", err
+	}
+	runTask.Config.Inputs = inputs
+	executionID, err := executor.ExecuteTask(ctx, &runTask)
 	if err != nil {
 		return "", err
 	}
+	s.rememberParameters(executionID, parameters)
 
-	// Update the task with the new execution
-	taskExecution := entities.TaskExecution{
-		ID:           executionID,
-		DevOpsTaskID: task.ID,
-		Status:       entities.TaskRunning,
-		StartedAt:    time.Now(),
+	// Nos suscribimos antes de registrar la ejecución para no perder sus cambios de estado
+	events, err := executor.SubscribeToTaskEvents(executionID)
+	if err != nil {
+		log.Printf("Error subscribing to events of execution %s: %v", executionID, err)
 	}
-	task.Executions = append(task.Executions, &taskExecution)
-	task.UpdatedAt = time.Now()
-	err = s.repository.Update(ctx, &task)
+
+	// Update the task with the new execution
+	taskExecution := attempt
+	taskExecution.ID = executionID
+	taskExecution.TaskExecutorID = executionID
+	taskExecution.DevOpsTaskID = task.ID
+	taskExecution.Status = entities.TaskRunning
+	taskExecution.StartedAt = time.Now()
+	taskExecution.RenderedSpec = renderedSpec(&runTask, executionID)
+	taskExecution.Inputs = inputs
+	err = s.appendExecution(ctx, task.ID, &taskExecution)
 	if err != nil {
+		s.forgetParameters(executionID)
 		return "", err
 	}
+	if s.Metrics != nil {
+		s.Metrics.ExecutionStarted(worker.GetType(), task.Workspace.ID)
+	}
+
+	if events != nil {
+		// La reconciliación sigue cuando la petición termina; solo la detiene Shutdown.
+		reconcileCtx, release := s.detach(ctx)
+		go func() {
+			defer release()
+			s.reconcileExecution(reconcileCtx, executor, executionID, executionID, events)
+		}()
+	}
 
 	return executionID, nil
 }
 
-func (s *TaskServiceImpl) CancelTask(executionID string) error {
-	ctx := context.Background()
+func (s *TaskServiceImpl) appendExecution(ctx context.Context, taskID string, execution *entities.TaskExecution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.repository.GetByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	task.Executions = append(task.Executions, execution)
+	task.UpdatedAt = time.Now()
+	return s.repository.Update(ctx, &task)
+}
+
+func (s *TaskServiceImpl) CancelTask(ctx context.Context, executionID string) (err error) {
+	defer func() { s.recordAudit(ctx, &auditEvent{action: entities.AuditCancel, executionID: executionID}, err) }()
 	task, err := s.repository.GetByExecutionID(ctx, executionID)
 	if err != nil {
 		return err
 	}
+	if err := s.authorize(ctx, nil, entities.PermissionCancel, task.Workspace); err != nil {
+		return err
+	}
+
+	// Una matriz no tiene nada en ningún executor: se cancelan sus combinaciones.
+	if s.cancelMatrix(ctx, executionID) {
+		return nil
+	}
+	// Si la ejecución ya falló y espera su reintento basta con anularlo.
+	if s.cancelPendingRetry(ctx, executionID) {
+		return nil
+	}
+	// Tampoco hay nada en marcha mientras espera los datos del operador.
+	if s.finishInput(ctx, executionID, entities.TaskCanceled, "") {
+		return nil
+	}
+
+	// Las peticiones de aprobación y las ejecuciones encoladas no tienen nada en marcha en
+	// ningún executor.
+	if task.TaskType == entities.TaskTypeApproval || s.dequeue(executionID) {
+		s.applyTerminalStatus(ctx, executionID, entities.TaskCanceled, "", "", nil, time.Now())
+		return nil
+	}
 
 	worker := task.Worker
 	executor, ok := s.executors[worker.GetType()]
@@ -161,15 +668,44 @@ func (s *TaskServiceImpl) CancelTask(executionID string) error {
 		return errors.New("unsupported worker type")
 	}
 
-	return executor.CancelTask(ctx, executionID)
+	execution, err := s.getExecution(ctx, executionID)
+	if err != nil {
+		return err
+	}
+	if err := executor.CancelTask(ctx, executorID(execution)); err != nil {
+		return err
+	}
+
+	s.applyTerminalStatus(ctx, executionID, entities.TaskCanceled, "", "", nil, time.Now())
+	return nil
 }
 
-func (s *TaskServiceImpl) SubscribeToTaskEvents(executionID string) (<-chan entities.TaskEvent, error) {
-	ctx := context.Background()
+func (s *TaskServiceImpl) SubscribeToTaskEvents(ctx context.Context, executionID string) (<-chan entities.TaskEvent, error) {
 	task, err := s.repository.GetByExecutionID(ctx, executionID)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.authorize(ctx, nil, entities.PermissionSubscribe, task.Workspace); err != nil {
+		return nil, err
+	}
+
+	execution, err := s.getExecution(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	// Mientras está en la cola solo el servicio publica eventos (posición en la cola), igual
+	// que en las matrices, de las que solo publica el resultado.
+	if ((execution.TaskExecutorID == "" && execution.Status == entities.TaskPending) || execution.MatrixSize > 0) && s.EventStream != nil {
+		return s.EventStream.Subscribe(executionID)
+	}
+	// Mientras espera datos se vuelve a publicar el formulario para el nuevo suscriptor.
+	if form := pinnedTask(&task, &execution).InputForm; execution.Status == entities.TaskWaitingInput && form != nil && s.EventStream != nil {
+		events, err := s.EventStream.Subscribe(executionID)
+		if err == nil {
+			s.publishInputRequest(executionID, form, execution.InputDeadline)
+		}
+		return events, err
+	}
 
 	worker := task.Worker
 	executor, ok := s.executors[worker.GetType()]
@@ -177,21 +713,45 @@ func (s *TaskServiceImpl) SubscribeToTaskEvents(executionID string) (<-chan enti
 		return nil, errors.New("unsupported worker type")
 	}
 
-	return executor.SubscribeToTaskEvents(executionID)
+	return executor.SubscribeToTaskEvents(executorID(execution))
 }
 
-func (s *TaskServiceImpl) GetTaskStatus(executionID string) (entities.TaskStatus, error) {
-	ctx := context.Background()
-	task, err := s.repository.GetByExecutionID(ctx, executionID)
+// executorID devuelve el ID de la ejecución en el executor; las ejecuciones registradas
+// antes de la cola no lo guardan porque coincide con el propio.
+func executorID(execution entities.TaskExecution) string {
+	if execution.TaskExecutorID != "" {
+		return execution.TaskExecutorID
+	}
+	return execution.ID
+}
+
+func (s *TaskServiceImpl) GetTaskStatus(ctx context.Context, executionID string) (entities.TaskStatus, error) {
+	if err := s.authorizeExecution(ctx, nil, entities.PermissionView, executionID); err != nil {
+		return "", err
+	}
+	execution, err := s.getExecution(ctx, executionID)
 	if err != nil {
 		return "", err
 	}
+	return execution.Status, nil
+}
+
+// getExecution devuelve una copia de la ejecución leída bajo s.mu, ya que el reconciliador
+// la modifica en segundo plano.
+func (s *TaskServiceImpl) getExecution(ctx context.Context, executionID string) (entities.TaskExecution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.repository.GetByExecutionID(ctx, executionID)
+	if err != nil {
+		return entities.TaskExecution{}, err
+	}
 
 	for _, execution := range task.Executions {
 		if execution.ID == executionID {
-			return execution.Status, nil
+			return *execution, nil
 		}
 	}
 
-	return "", errors.New("execution not found")
+	return entities.TaskExecution{}, ErrExecutionNotFound
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"testing"
+	"time"
 )
 
 // Mocking the repository and executor
@@ -37,11 +38,22 @@ func (m *MockTaskRepository) Delete(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
-func (m *MockTaskRepository) GetAll(ctx context.Context, filters ports.TaskFilters) ([]entities.DevOpsTask, error) {
+func (m *MockTaskRepository) GetAll(ctx context.Context, filters ports.TaskFilters) (ports.TaskPage, error) {
 	args := m.Called(ctx, filters)
-	return args.Get(0).([]entities.DevOpsTask), args.Error(1)
+	return args.Get(0).(ports.TaskPage), args.Error(1)
 }
 
+func (m *MockTaskRepository) GetByExecutionID(ctx context.Context, executionID string) (entities.DevOpsTask, error) {
+	args := m.Called(ctx, executionID)
+	return args.Get(0).(entities.DevOpsTask), args.Error(1)
+}
+
+type stubWorker struct{}
+
+func (w *stubWorker) GetID() string                      { return "stub" }
+func (w *stubWorker) GetType() string                    { return "Stub" }
+func (w *stubWorker) GetDetails() map[string]interface{} { return map[string]interface{}{} }
+
 type MockTaskExecutor struct {
 	mock.Mock
 }
@@ -78,12 +90,14 @@ func (suite *TaskServiceTestSuite) SetupTest() {
 	suite.repository = new(MockTaskRepository)
 	suite.executor = new(MockTaskExecutor)
 	suite.service = orchestrator2.NewTaskServiceImpl(suite.repository)
+	suite.service.RegisterExecutor("Stub", suite.executor)
 }
 
 func (suite *TaskServiceTestSuite) TestExecuteTask_Success() {
 	taskID := "integration-tests-task-id"
-	task := entities.DevOpsTask{ID: taskID}
+	task := entities.DevOpsTask{ID: taskID, Worker: &stubWorker{}}
 	executionID := "execution-id"
+	events := make(chan entities.TaskEvent)
 
 	// Mocking the repository to return a task when GetByID is called
 	suite.repository.On("GetByID", mock.Anything, taskID).Return(task, nil)
@@ -91,9 +105,12 @@ func (suite *TaskServiceTestSuite) TestExecuteTask_Success() {
 	suite.executor.On("ExecuteTask", mock.Anything, &task).Return(executionID, nil)
 	// Mocking the repository to successfully update the task
 	suite.repository.On("Update", mock.Anything, mock.AnythingOfType("*entities.DevOpsTask")).Return(nil)
+	// Mocking the executor event subscription used to reconcile the execution status
+	suite.executor.On("SubscribeToTaskEvents", executionID).Return((<-chan entities.TaskEvent)(events), nil)
+	suite.executor.On("GetTaskStatus", mock.Anything, executionID).Return(entities.TaskRunning, nil)
 
 	// Calling the ExecuteTask method and checking the results
-	resultExecutionID, err := suite.service.ExecuteTask(taskID)
+	resultExecutionID, err := suite.service.ExecuteTask(context.Background(), taskID)
 
 	// Asserting that no error occurred
 	assert.NoError(suite.T(), err)
@@ -101,9 +118,119 @@ func (suite *TaskServiceTestSuite) TestExecuteTask_Success() {
 	assert.Equal(suite.T(), executionID, resultExecutionID)
 }
 
+func (suite *TaskServiceTestSuite) TestExecuteTask_ReconcilesFinalStatus() {
+	taskID := "integration-tests-task-id"
+	executionID := "execution-id"
+	task := entities.DevOpsTask{ID: taskID, Worker: &stubWorker{}}
+	execution := &entities.TaskExecution{ID: executionID, DevOpsTaskID: taskID, Status: entities.TaskRunning}
+	storedTask := entities.DevOpsTask{ID: taskID, Worker: &stubWorker{}, Executions: []*entities.TaskExecution{execution}}
+	events := make(chan entities.TaskEvent, 1)
+	reconciled := make(chan entities.TaskExecution, 1)
+	finishedAt := time.Now()
+
+	suite.repository.On("GetByID", mock.Anything, taskID).Return(task, nil)
+	suite.repository.On("GetByExecutionID", mock.Anything, executionID).Return(storedTask, nil)
+	suite.repository.On("Update", mock.Anything, mock.AnythingOfType("*entities.DevOpsTask")).Return(nil).Run(func(args mock.Arguments) {
+		updated := args.Get(1).(*entities.DevOpsTask)
+		for _, e := range updated.Executions {
+			if e.ID == executionID && e.Status.IsTerminal() {
+				reconciled <- *e
+			}
+		}
+	})
+	suite.executor.On("ExecuteTask", mock.Anything, mock.Anything).Return(executionID, nil)
+	suite.executor.On("SubscribeToTaskEvents", executionID).Return((<-chan entities.TaskEvent)(events), nil)
+	suite.executor.On("GetTaskStatus", mock.Anything, executionID).Return(entities.TaskRunning, nil)
+
+	_, err := suite.service.ExecuteTask(context.Background(), taskID)
+	assert.NoError(suite.T(), err)
+
+	events <- entities.TaskEvent{
+		ExecutionID: executionID,
+		EventType:   entities.EventTypeTaskFailed,
+		Timestamp:   finishedAt,
+		Payload: entities.TaskProgressPayload{
+			Status:           entities.TaskFailed,
+			Error:            "Container exited with code 1",
+			ExecutionDetails: map[string]interface{}{"ContainerID": "container-1"},
+		},
+	}
+
+	select {
+	case result := <-reconciled:
+		assert.Equal(suite.T(), entities.TaskFailed, result.Status)
+		assert.Equal(suite.T(), "Container exited with code 1", result.Error)
+		assert.Equal(suite.T(), finishedAt, result.FinishedAt)
+		assert.Equal(suite.T(), "container-1", result.ExecutionDetails["ContainerID"])
+	case <-time.After(time.Second):
+		suite.T().Fatal("execution status was not reconciled")
+	}
+}
+
+func (suite *TaskServiceTestSuite) TestExecuteTask_KeepsRunningAfterAWarning() {
+	taskID := "integration-tests-task-id"
+	executionID := "execution-id"
+	task := entities.DevOpsTask{ID: taskID, Worker: &stubWorker{}}
+	execution := &entities.TaskExecution{ID: executionID, DevOpsTaskID: taskID, Status: entities.TaskRunning}
+	storedTask := entities.DevOpsTask{ID: taskID, Worker: &stubWorker{}, Executions: []*entities.TaskExecution{execution}}
+	events := make(chan entities.TaskEvent, 2)
+	reconciled := make(chan entities.TaskExecution, 2)
+
+	suite.repository.On("GetByID", mock.Anything, taskID).Return(task, nil)
+	suite.repository.On("GetByExecutionID", mock.Anything, executionID).Return(storedTask, nil)
+	suite.repository.On("Update", mock.Anything, mock.AnythingOfType("*entities.DevOpsTask")).Return(nil).Run(func(args mock.Arguments) {
+		updated := args.Get(1).(*entities.DevOpsTask)
+		for _, e := range updated.Executions {
+			if e.ID == executionID && e.Status.IsTerminal() {
+				reconciled <- *e
+			}
+		}
+	})
+	suite.executor.On("ExecuteTask", mock.Anything, mock.Anything).Return(executionID, nil)
+	suite.executor.On("SubscribeToTaskEvents", executionID).Return((<-chan entities.TaskEvent)(events), nil)
+	suite.executor.On("GetTaskStatus", mock.Anything, executionID).Return(entities.TaskRunning, nil)
+
+	_, err := suite.service.ExecuteTask(context.Background(), taskID)
+	assert.NoError(suite.T(), err)
+
+	// El executor sigue esperando al contenedor aunque se corte el streaming de los logs.
+	events <- entities.TaskEvent{ExecutionID: executionID, EventType: entities.EventTypeTaskWarning, Timestamp: time.Now(), Payload: "Error streaming logs: unexpected EOF"}
+	events <- entities.TaskEvent{
+		ExecutionID: executionID,
+		EventType:   entities.EventTypeTaskCompleted,
+		Timestamp:   time.Now(),
+		Payload:     entities.TaskProgressPayload{Status: entities.TaskSucceeded},
+	}
+
+	select {
+	case result := <-reconciled:
+		assert.Equal(suite.T(), entities.TaskSucceeded, result.Status)
+		assert.Empty(suite.T(), result.Error)
+	case <-time.After(time.Second):
+		suite.T().Fatal("execution status was not reconciled")
+	}
+}
+
+func (suite *TaskServiceTestSuite) TestCancelTask_KeepsCanceledStatus() {
+	executionID := "execution-id"
+	execution := &entities.TaskExecution{ID: executionID, Status: entities.TaskRunning}
+	task := entities.DevOpsTask{ID: "task-id", Worker: &stubWorker{}, Executions: []*entities.TaskExecution{execution}}
+
+	suite.repository.On("GetByExecutionID", mock.Anything, executionID).Return(task, nil)
+	suite.repository.On("Update", mock.Anything, mock.AnythingOfType("*entities.DevOpsTask")).Return(nil)
+	suite.executor.On("CancelTask", mock.Anything, executionID).Return(nil)
+
+	err := suite.service.CancelTask(context.Background(), executionID)
+	assert.NoError(suite.T(), err)
+
+	status, err := suite.service.GetTaskStatus(context.Background(), executionID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entities.TaskCanceled, status)
+}
+
 func (suite *TaskServiceTestSuite) TestExecuteTask_Failure() {
 	taskID := "integration-tests-task-id"
-	task := entities.DevOpsTask{ID: taskID}
+	task := entities.DevOpsTask{ID: taskID, Worker: &stubWorker{}}
 	_ = "execution-id"
 	execErr := fmt.Errorf("execution error")
 
@@ -115,7 +242,7 @@ func (suite *TaskServiceTestSuite) TestExecuteTask_Failure() {
 	suite.repository.On("Update", mock.Anything, &task).Return(nil)
 
 	// Calling the ExecuteTask method and checking the results
-	resultExecutionID, err := suite.service.ExecuteTask(taskID)
+	resultExecutionID, err := suite.service.ExecuteTask(context.Background(), taskID)
 
 	// Asserting that an error occurred
 	assert.Error(suite.T(), err)
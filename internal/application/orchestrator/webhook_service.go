@@ -0,0 +1,233 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type WebhookService interface {
+	HandleWebhook(ctx context.Context, token string, headers http.Header, body []byte) (entities.WebhookDelivery, error)
+	ListWebhookDeliveries(ctx context.Context, taskID string) ([]entities.WebhookDelivery, error)
+}
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrInvalidPayload   = errors.New("invalid webhook payload")
+)
+
+// webhookSubject es quien figura como TriggeredBy en las ejecuciones lanzadas por webhook.
+var webhookSubject = entities.ServiceAccount{ID: "webhook", Name: "Webhook"}
+
+// WebhookServiceImpl recibe las entregas de los webhooks de las tareas TaskTypeTriggered,
+// comprueba su firma y sus condiciones y lanza la ejecución a través del TaskService.
+// Todas las entregas de un token conocido se registran con su resultado.
+type WebhookServiceImpl struct {
+	repository ports.TaskRepository
+	deliveries ports.WebhookDeliveryRepository
+	tasks      *TaskServiceImpl
+	GenerateID IDGenerator
+}
+
+func NewWebhookServiceImpl(taskRepo ports.TaskRepository, deliveryRepo ports.WebhookDeliveryRepository, taskService *TaskServiceImpl) *WebhookServiceImpl {
+	return &WebhookServiceImpl{
+		repository: taskRepo,
+		deliveries: deliveryRepo,
+		tasks:      taskService,
+		GenerateID: defaultIDGenerator,
+	}
+}
+
+// HandleWebhook procesa una entrega. Devuelve ErrWebhookNotFound si el token no corresponde
+// a ninguna tarea, ErrInvalidSignature si la firma no es válida y ErrInvalidPayload o
+// ErrInvalidParameters si no se puede construir la ejecución; una entrega filtrada no es un error.
+func (s *WebhookServiceImpl) HandleWebhook(ctx context.Context, token string, headers http.Header, body []byte) (entities.WebhookDelivery, error) {
+	task, trigger, err := s.findTask(ctx, token)
+	if err != nil {
+		return entities.WebhookDelivery{}, err
+	}
+	// El token se busca en todos los tenants; lo que sigue queda en el de la tarea.
+	ctx = ports.WithScope(ctx, entities.Scope{TenantID: task.Workspace.TenantID})
+	delivery := entities.WebhookDelivery{
+		ID:         s.GenerateID(),
+		TaskID:     task.ID,
+		ReceivedAt: time.Now(),
+		Event:      webhookEvent(headers),
+	}
+	err = s.deliver(ctx, task, trigger, headers, body, &delivery)
+	// El payload de una entrega sin firma válida no se guarda: puede venir de cualquiera.
+	if delivery.Status != entities.WebhookRejected {
+		delivery.Payload = string(body)
+	}
+	if saveErr := s.deliveries.Save(ctx, &delivery); saveErr != nil {
+		return delivery, saveErr
+	}
+	return delivery, err
+}
+
+func (s *WebhookServiceImpl) deliver(ctx context.Context, task *entities.DevOpsTask, trigger *entities.WebhookTrigger, headers http.Header, body []byte, delivery *entities.WebhookDelivery) error {
+	fail := func(status entities.WebhookDeliveryStatus, err error) error {
+		delivery.Status = status
+		delivery.Reason = err.Error()
+		return err
+	}
+
+	if trigger.Secret == "" && !trigger.AllowUnsigned {
+		return fail(entities.WebhookRejected, fmt.Errorf("%w: the webhook has no secret", ErrInvalidSignature))
+	}
+	if err := verifySignature(trigger.Secret, headers, body); err != nil {
+		return fail(entities.WebhookRejected, err)
+	}
+	if len(trigger.Events) > 0 && !containsString(trigger.Events, delivery.Event) {
+		delivery.Status = entities.WebhookFiltered
+		delivery.Reason = fmt.Sprintf("event %q is not one of %v", delivery.Event, trigger.Events)
+		return nil
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fail(entities.WebhookFailed, fmt.Errorf("%w: %v", ErrInvalidPayload, err))
+	}
+	for _, condition := range trigger.Conditions {
+		satisfied, err := condition.Satisfied(payload)
+		if err != nil {
+			return fail(entities.WebhookFailed, fmt.Errorf("%w: condition on %s: %v", ErrInvalidTask, condition.Path, err))
+		}
+		if !satisfied {
+			delivery.Status = entities.WebhookFiltered
+			delivery.Reason = fmt.Sprintf("condition on %s not met", condition.Path)
+			return nil
+		}
+	}
+
+	parameters := make(map[string]interface{}, len(trigger.Parameters))
+	for name, path := range trigger.Parameters {
+		if value, ok := entities.LookupJSONPath(payload, path); ok {
+			parameters[name] = value
+		}
+	}
+	delivery.Parameters = entities.MaskSecretParameters(task.Parameters, parameters)
+
+	// La entrega ya se ha autenticado con el token y la firma del webhook.
+	executionID, err := s.tasks.ExecuteTaskWith(trusted(ctx), task.ID, ExecutionOptions{
+		TriggeredBy: webhookSubject,
+		Parameters:  parameters,
+	})
+	if err != nil {
+		return fail(entities.WebhookFailed, err)
+	}
+	delivery.Status = entities.WebhookDelivered
+	delivery.ExecutionID = executionID
+	return nil
+}
+
+func (s *WebhookServiceImpl) ListWebhookDeliveries(ctx context.Context, taskID string) ([]entities.WebhookDelivery, error) {
+	// La tarea se busca antes para no mostrar entregas de fuera del ámbito de la petición ni
+	// a quien no puede verla.
+	task, err := s.repository.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tasks.authorize(ctx, nil, entities.PermissionView, task.Workspace); err != nil {
+		return nil, err
+	}
+	return s.deliveries.ListByTask(ctx, taskID)
+}
+
+// findTask busca la tarea TaskTypeTriggered cuyo webhook tiene el token.
+func (s *WebhookServiceImpl) findTask(ctx context.Context, token string) (*entities.DevOpsTask, *entities.WebhookTrigger, error) {
+	if token == "" {
+		return nil, nil, ErrWebhookNotFound
+	}
+	page, err := s.repository.GetAll(ctx, ports.TaskFilters{TaskType: entities.TaskTypeTriggered})
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range page.Tasks {
+		trigger, ok := page.Tasks[i].WebhookTrigger()
+		if ok && subtle.ConstantTimeCompare([]byte(trigger.Token), []byte(token)) == 1 {
+			return &page.Tasks[i], trigger, nil
+		}
+	}
+	return nil, nil, ErrWebhookNotFound
+}
+
+// webhookEvent devuelve el tipo de evento que indican las cabeceras de GitHub o GitLab.
+func webhookEvent(headers http.Header) string {
+	if event := headers.Get("X-GitHub-Event"); event != "" {
+		return event
+	}
+	return headers.Get("X-Gitlab-Event")
+}
+
+// verifySignature comprueba la firma de la entrega: HMAC-SHA256 en X-Hub-Signature-256,
+// HMAC-SHA1 en X-Hub-Signature (GitHub) o el secreto tal cual en X-Gitlab-Token.
+// Sin secreto no se comprueba nada: solo llega aquí si el trigger admite entregas sin firmar.
+func verifySignature(secret string, headers http.Header, body []byte) error {
+	if secret == "" {
+		return nil
+	}
+	if signature := headers.Get("X-Hub-Signature-256"); signature != "" {
+		return verifyHMAC(sha256.New, secret, "sha256=", signature, body)
+	}
+	if signature := headers.Get("X-Hub-Signature"); signature != "" {
+		return verifyHMAC(sha1.New, secret, "sha1=", signature, body)
+	}
+	if token := headers.Get("X-Gitlab-Token"); token != "" {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			return fmt.Errorf("%w: X-Gitlab-Token does not match", ErrInvalidSignature)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: missing signature header", ErrInvalidSignature)
+}
+
+func verifyHMAC(algorithm func() hash.Hash, secret, prefix, signature string, body []byte) error {
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, prefix))
+	if err != nil || !strings.HasPrefix(signature, prefix) {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+	mac := hmac.New(algorithm, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return fmt.Errorf("%w: signature does not match", ErrInvalidSignature)
+	}
+	return nil
+}
+
+// ensureWebhookToken genera el token del webhook de la tarea si no lo tiene.
+func ensureWebhookToken(task *entities.DevOpsTask) error {
+	trigger, ok := task.WebhookTrigger()
+	if !ok || trigger.Token != "" {
+		return nil
+	}
+	token := make([]byte, 20)
+	if _, err := rand.Read(token); err != nil {
+		return err
+	}
+	trigger.Token = hex.EncodeToString(token)
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+package orchestrator_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	orchestrator2 "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	adapters "devops_console/internal/infrastructure/orchestrator/repositories"
+	server "devops_console/internal/infrastructure/orchestrator/server"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const pushPayload = `{"ref":"refs/heads/main","after":"abc123","repository":{"full_name":"acme/api"},"commits":[{"id":"abc123"}]}`
+
+type WebhookServiceTestSuite struct {
+	suite.Suite
+	service    *orchestrator2.WebhookServiceImpl
+	tasks      *orchestrator2.TaskServiceImpl
+	repo       *adapters.InMemoryTaskRepository
+	deliveries *adapters.InMemoryWebhookDeliveryRepository
+	executor   *fakeExecutor
+	task       entities.DevOpsTask
+}
+
+func (suite *WebhookServiceTestSuite) SetupTest() {
+	suite.repo = adapters.NewInMemoryTaskRepository()
+	suite.deliveries = adapters.NewInMemoryWebhookDeliveryRepository()
+	suite.executor = newFakeExecutor()
+	suite.tasks = orchestrator2.NewTaskServiceImpl(suite.repo)
+	suite.tasks.RegisterExecutor("Stub", suite.executor)
+	suite.service = orchestrator2.NewWebhookServiceImpl(suite.repo, suite.deliveries, suite.tasks)
+
+	var trigger entities.Trigger = &entities.WebhookTrigger{
+		Secret: "hush",
+		Events: []string{"push"},
+		Parameters: map[string]string{
+			"repository": "$.repository.full_name",
+			"commit":     "$.commits[0].id",
+		},
+		Conditions: []entities.WebhookCondition{{Path: "$.ref", Equals: "refs/heads/main"}},
+	}
+	task, err := suite.tasks.CreateTask(context.Background(), entities.DevOpsTask{
+		ID:        "build",
+		Workspace: payments,
+		TaskType:  entities.TaskTypeTriggered,
+		Trigger:   &trigger,
+		Worker:    &stubWorker{},
+		Parameters: []entities.ParameterDefinition{
+			{Name: "repository", Type: entities.ParameterString, Required: true},
+			{Name: "commit", Type: entities.ParameterString, Secret: true},
+		},
+	})
+	suite.Require().NoError(err)
+	suite.task = task
+}
+
+func (suite *WebhookServiceTestSuite) token() string {
+	trigger, ok := suite.task.WebhookTrigger()
+	suite.Require().True(ok)
+	return trigger.Token
+}
+
+func signed(event, body, secret string) http.Header {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	headers := http.Header{}
+	headers.Set("X-GitHub-Event", event)
+	headers.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return headers
+}
+
+func (suite *WebhookServiceTestSuite) TestCreateTask_GeneratesToken() {
+	assert.Len(suite.T(), suite.token(), 40)
+}
+
+func (suite *WebhookServiceTestSuite) TestHandleWebhook_LaunchesExecution() {
+	delivery, err := suite.service.HandleWebhook(context.Background(), suite.token(), signed("push", pushPayload, "hush"), []byte(pushPayload))
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.WebhookDelivered, delivery.Status)
+	assert.Equal(suite.T(), "push", delivery.Event)
+	assert.Equal(suite.T(), map[string]interface{}{
+		"repository": "acme/api",
+		"commit":     entities.MaskedParameterValue,
+	}, delivery.Parameters)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	status, err := suite.tasks.WaitForExecution(ctx, delivery.ExecutionID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.TaskSucceeded, status)
+
+	launched := suite.executor.launched()
+	suite.Require().Len(launched, 1)
+	assert.Equal(suite.T(), "abc123", launched[0]["commit"])
+
+	stored, err := suite.repo.GetByExecutionID(context.Background(), delivery.ExecutionID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "webhook", stored.Executions[0].TriggeredBy)
+}
+
+func (suite *WebhookServiceTestSuite) TestHandleWebhook_RecordsOutcomes() {
+	ctx := context.Background()
+	otherBranch := strings.Replace(pushPayload, "refs/heads/main", "refs/heads/feature", 1)
+
+	_, err := suite.service.HandleWebhook(ctx, suite.token(), signed("push", pushPayload, "wrong"), []byte(pushPayload))
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidSignature)
+	_, err = suite.service.HandleWebhook(ctx, suite.token(), http.Header{}, []byte(pushPayload))
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidSignature)
+
+	delivery, err := suite.service.HandleWebhook(ctx, suite.token(), signed("issues", pushPayload, "hush"), []byte(pushPayload))
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.WebhookFiltered, delivery.Status)
+
+	delivery, err = suite.service.HandleWebhook(ctx, suite.token(), signed("push", otherBranch, "hush"), []byte(otherBranch))
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.WebhookFiltered, delivery.Status)
+
+	noRepository := `{"ref":"refs/heads/main"}`
+	_, err = suite.service.HandleWebhook(ctx, suite.token(), signed("push", noRepository, "hush"), []byte(noRepository))
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidParameters)
+
+	_, err = suite.service.HandleWebhook(ctx, "unknown", signed("push", pushPayload, "hush"), []byte(pushPayload))
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrWebhookNotFound)
+
+	deliveries, err := suite.service.ListWebhookDeliveries(context.Background(), suite.task.ID)
+	suite.Require().NoError(err)
+	statuses := map[entities.WebhookDeliveryStatus]int{}
+	for _, delivery := range deliveries {
+		statuses[delivery.Status]++
+		if delivery.Status == entities.WebhookRejected {
+			assert.Empty(suite.T(), delivery.Payload)
+		}
+	}
+	assert.Equal(suite.T(), map[entities.WebhookDeliveryStatus]int{
+		entities.WebhookRejected: 2,
+		entities.WebhookFiltered: 2,
+		entities.WebhookFailed:   1,
+	}, statuses)
+	assert.Empty(suite.T(), suite.executor.executedTasks())
+}
+
+func (suite *WebhookServiceTestSuite) TestWebhookWithoutSecret_RequiresOptingOut() {
+	ctx := context.Background()
+	newTask := func(id string, trigger entities.Trigger) entities.DevOpsTask {
+		return entities.DevOpsTask{ID: id, Workspace: payments, TaskType: entities.TaskTypeTriggered, Trigger: &trigger, Worker: &stubWorker{}}
+	}
+	_, err := suite.tasks.CreateTask(ctx, newTask("unsigned", &entities.WebhookTrigger{}))
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidTask)
+
+	// Una tarea guardada sin secreto ni AllowUnsigned no acepta entregas.
+	legacy := newTask("legacy", &entities.WebhookTrigger{Token: "legacy-token"})
+	suite.Require().NoError(suite.repo.Create(ctx, &legacy))
+	delivery, err := suite.service.HandleWebhook(ctx, "legacy-token", http.Header{}, []byte(pushPayload))
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrInvalidSignature)
+	assert.Equal(suite.T(), entities.WebhookRejected, delivery.Status)
+
+	public, err := suite.tasks.CreateTask(ctx, newTask("public", &entities.WebhookTrigger{AllowUnsigned: true}))
+	suite.Require().NoError(err)
+	trigger, _ := public.WebhookTrigger()
+	delivery, err = suite.service.HandleWebhook(ctx, trigger.Token, http.Header{}, []byte(pushPayload))
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), entities.WebhookDelivered, delivery.Status)
+	assert.Equal(suite.T(), []string{"public"}, suite.executor.executedTasks())
+}
+
+func (suite *WebhookServiceTestSuite) TestListWebhookDeliveries_RequiresView() {
+	body := pushPayload
+	_, err := suite.service.HandleWebhook(context.Background(), suite.token(), signed("push", body, "hush"), []byte(body))
+	suite.Require().NoError(err)
+	authorizer := orchestrator2.NewRBACAuthorizer(staticGroups{})
+	suite.Require().NoError(authorizer.Bind(entities.RoleBinding{Subject: alice, Role: entities.RoleViewer, TenantID: "acme", WorkspaceID: "payments"}))
+	suite.tasks.SetAuthorizer(authorizer)
+
+	_, err = suite.service.ListWebhookDeliveries(as(mallory), suite.task.ID)
+	assert.ErrorIs(suite.T(), err, orchestrator2.ErrPermissionDenied)
+	deliveries, err := suite.service.ListWebhookDeliveries(as(alice), suite.task.ID)
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), deliveries, 1)
+}
+
+func (suite *WebhookServiceTestSuite) TestWebhookHandler() {
+	handler := server.NewWebhookHandler(suite.service)
+	send := func(path string, headers http.Header, body string) int {
+		request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		for name, values := range headers {
+			request.Header[name] = values
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	assert.Equal(suite.T(), http.StatusAccepted, send("/webhooks/"+suite.token(), signed("push", pushPayload, "hush"), pushPayload))
+	assert.Equal(suite.T(), http.StatusOK, send("/webhooks/"+suite.token(), signed("issues", pushPayload, "hush"), pushPayload))
+	assert.Equal(suite.T(), http.StatusUnauthorized, send("/webhooks/"+suite.token(), http.Header{}, pushPayload))
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, send("/webhooks/"+suite.token(), signed("push", "{", "hush"), "{"))
+	assert.Equal(suite.T(), http.StatusNotFound, send("/webhooks/unknown", signed("push", pushPayload, "hush"), pushPayload))
+}
+
+func TestWebhookServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookServiceTestSuite))
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	var document interface{} = map[string]interface{}{
+		"pull_request": map[string]interface{}{
+			"labels": []interface{}{map[string]interface{}{"name": "deploy"}},
+		},
+		"a.b": "dotted",
+	}
+	value, ok := entities.LookupJSONPath(document, "$.pull_request.labels[0].name")
+	assert.True(t, ok)
+	assert.Equal(t, "deploy", value)
+	value, ok = entities.LookupJSONPath(document, "$['a.b']")
+	assert.True(t, ok)
+	assert.Equal(t, "dotted", value)
+	_, ok = entities.LookupJSONPath(document, "$.pull_request.labels[1].name")
+	assert.False(t, ok)
+}
@@ -1,8 +1,88 @@
 // internal/domain/entities/artifact.go
 package entities
 
+import (
+	"fmt"
+	"path"
+	"time"
+)
+
 type Artifact struct {
 	Name string
 	Data []byte
 	Type string // Por ejemplo, "text/plain", "application/json"
+	// Path es la ruta de la que se recogió dentro del contenedor.
+	Path string
+	// Digest ("sha256:<hex>") identifica el contenido en el ArtifactStore; los artefactos
+	// recogidos de una ejecución no llevan Data.
+	Digest    string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// ArtifactTypeTar es el Type de los artefactos que vienen de un directorio, que se
+// guardan empaquetados.
+const ArtifactTypeTar = "application/x-tar"
+
+// OutputSpec declara un fichero o directorio que se recoge del contenedor al terminar la
+// ejecución y se guarda como artefacto con el nombre Name.
+type OutputSpec struct {
+	Name string
+	// Path es absoluto dentro del contenedor.
+	Path string
+}
+
+// ValidateOutputs comprueba que las salidas tengan nombre único y ruta absoluta.
+func ValidateOutputs(outputs []OutputSpec) error {
+	names := make(map[string]bool, len(outputs))
+	for _, output := range outputs {
+		if output.Name == "" {
+			return fmt.Errorf("output without name")
+		}
+		if names[output.Name] {
+			return fmt.Errorf("duplicated output %q", output.Name)
+		}
+		names[output.Name] = true
+		if !path.IsAbs(output.Path) {
+			return fmt.Errorf("output %q must have an absolute path, got %q", output.Name, output.Path)
+		}
+	}
+	return nil
+}
+
+// ArtifactInput declara un artefacto de otra tarea que se deja en el contenedor antes de
+// arrancarlo.
+type ArtifactInput struct {
+	// TaskID es la tarea que produce el artefacto y Artifact su nombre en los Outputs de esa tarea.
+	TaskID   string
+	Artifact string
+	// Path es absoluto dentro del contenedor: el fichero o, si el artefacto es un
+	// directorio empaquetado, el directorio donde se desempaqueta.
+	Path string
+	// Source y ExecutionID son el artefacto elegido al lanzar la ejecución y la ejecución
+	// de TaskID de la que viene; los rellena el TaskService.
+	Source      *Artifact
+	ExecutionID string
+}
+
+// ValidateArtifactInputs comprueba que los inputs de la tarea taskID vengan de otra
+// tarea, nombren un artefacto y tengan rutas absolutas distintas.
+func ValidateArtifactInputs(taskID string, inputs []ArtifactInput) error {
+	paths := make(map[string]bool, len(inputs))
+	for _, input := range inputs {
+		switch {
+		case input.TaskID == "":
+			return fmt.Errorf("input without task")
+		case input.TaskID == taskID:
+			return fmt.Errorf("input %q cannot come from the task itself", input.Artifact)
+		case input.Artifact == "":
+			return fmt.Errorf("input from task %q without artifact", input.TaskID)
+		case !path.IsAbs(input.Path):
+			return fmt.Errorf("input %q must have an absolute path, got %q", input.Artifact, input.Path)
+		case paths[path.Clean(input.Path)]:
+			return fmt.Errorf("duplicated input path %q", input.Path)
+		}
+		paths[path.Clean(input.Path)] = true
+	}
+	return nil
 }
@@ -0,0 +1,118 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrAuditChainBroken = errors.New("audit chain broken")
+
+// AuditAction es la operación registrada en una entrada de auditoría.
+type AuditAction string
+
+const (
+	AuditCreate      AuditAction = "CREATE"
+	AuditUpdate      AuditAction = "UPDATE"
+	AuditDelete      AuditAction = "DELETE"
+	AuditExecute     AuditAction = "EXECUTE"
+	AuditCancel      AuditAction = "CANCEL"
+	AuditApprove     AuditAction = "APPROVE"
+	AuditReject      AuditAction = "REJECT"
+	AuditSubmitInput AuditAction = "SUBMIT_INPUT"
+	AuditAbortInput  AuditAction = "ABORT_INPUT"
+)
+
+// AuditOutcome es el resultado de la operación auditada.
+type AuditOutcome string
+
+const (
+	AuditSucceeded AuditOutcome = "SUCCEEDED"
+	AuditDenied    AuditOutcome = "DENIED"
+	AuditFailed    AuditOutcome = "FAILED"
+)
+
+// AuditChange es un campo modificado por la operación. Los valores van serializados en
+// JSON, con los secretos enmascarados, para que la entrada no cambie al exportarla.
+type AuditChange struct {
+	Field string `json:"field"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+// AuditEntry registra quién hizo qué operación, cuándo, desde dónde (Source, p.ej. la
+// dirección del cliente) y con qué resultado.
+// Las entradas forman una cadena: Hash cubre la entrada entera, incluido el Hash de la
+// anterior, así que modificar, quitar o reordenar una rompe las siguientes.
+type AuditEntry struct {
+	Sequence     int64         `json:"sequence"`
+	ID           string        `json:"id"`
+	Timestamp    time.Time     `json:"timestamp"`
+	Action       AuditAction   `json:"action"`
+	SubjectID    string        `json:"subjectId,omitempty"`
+	SubjectKind  string        `json:"subjectKind,omitempty"`
+	Source       string        `json:"source,omitempty"`
+	TenantID     string        `json:"tenantId,omitempty"`
+	WorkspaceID  string        `json:"workspaceId,omitempty"`
+	TaskID       string        `json:"taskId,omitempty"`
+	ExecutionID  string        `json:"executionId,omitempty"`
+	Changes      []AuditChange `json:"changes,omitempty"`
+	Outcome      AuditOutcome  `json:"outcome"`
+	Error        string        `json:"error,omitempty"`
+	PreviousHash string        `json:"previousHash,omitempty"`
+	Hash         string        `json:"hash"`
+}
+
+// ComputeHash devuelve el hash SHA-256 de la entrada sin contar su propio Hash.
+func (e AuditEntry) ComputeHash() string {
+	e.Hash = ""
+	e.Timestamp = e.Timestamp.UTC()
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyAuditChain comprueba que las entradas, en orden y empezando por la primera del
+// registro, forman una cadena íntegra.
+func VerifyAuditChain(entries []AuditEntry) error {
+	previous := ""
+	for i, entry := range entries {
+		if entry.Sequence != int64(i+1) {
+			return fmt.Errorf("%w: entry %d has sequence %d", ErrAuditChainBroken, i+1, entry.Sequence)
+		}
+		if entry.PreviousHash != previous {
+			return fmt.Errorf("%w: entry %d does not follow entry %d", ErrAuditChainBroken, entry.Sequence, entry.Sequence-1)
+		}
+		if entry.ComputeHash() != entry.Hash {
+			return fmt.Errorf("%w: entry %d was modified", ErrAuditChainBroken, entry.Sequence)
+		}
+		previous = entry.Hash
+	}
+	return nil
+}
+
+// AuditChanges convierte las diferencias entre dos tareas en cambios de auditoría.
+func AuditChanges(changes []FieldChange) []AuditChange {
+	if len(changes) == 0 {
+		return nil
+	}
+	audit := make([]AuditChange, len(changes))
+	for i, change := range changes {
+		audit[i] = AuditChange{Field: change.Field, From: auditValue(change.From), To: auditValue(change.To)}
+	}
+	return audit
+}
+
+func auditValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	if string(data) == "null" {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,81 @@
+package entities
+
+import "encoding/json"
+
+// TaskCompletionTrigger lanza una tarea TaskTypeTriggered cuando termina otra, sin
+// necesidad de un pipeline: "cuando A termine bien en el workspace X, lanza B".
+type TaskCompletionTrigger struct {
+	// TaskID es la tarea de la que depende; vacío acepta cualquier tarea del workspace.
+	TaskID string
+	// WorkspaceID limita las tareas de origen a un workspace; vacío acepta cualquiera.
+	WorkspaceID string
+	// On son los estados finales que lanzan la tarea; por defecto solo TaskSucceeded.
+	On []TaskStatus
+	// Parameters asigna a cada parámetro de la tarea una ruta del resultado de la ejecución
+	// de origen (ver CompletionDocument), p.ej. "$.execution.id" o "$.outputs.version".
+	Parameters map[string]string
+}
+
+// Evaluate siempre es false: el trigger se activa con los eventos de otras tareas.
+func (t *TaskCompletionTrigger) Evaluate() bool {
+	return false
+}
+
+// Matches indica si el resultado de la ejecución de origen activa el trigger.
+func (t *TaskCompletionTrigger) Matches(result TaskResultPayload) bool {
+	if t.TaskID != "" && t.TaskID != result.TaskID {
+		return false
+	}
+	if t.WorkspaceID != "" && t.WorkspaceID != result.WorkspaceID {
+		return false
+	}
+	if len(t.On) == 0 {
+		return result.Status == TaskSucceeded
+	}
+	for _, status := range t.On {
+		if status == result.Status {
+			return true
+		}
+	}
+	return false
+}
+
+// CompletionTrigger devuelve el trigger de encadenamiento de la tarea, si lo tiene.
+func (t *DevOpsTask) CompletionTrigger() (*TaskCompletionTrigger, bool) {
+	if t.Trigger == nil {
+		return nil, false
+	}
+	trigger, ok := (*t.Trigger).(*TaskCompletionTrigger)
+	return trigger, ok
+}
+
+// CompletionDocument es el resultado de una ejecución tal como lo ven las rutas de
+// TaskCompletionTrigger.Parameters:
+//
+//	{"execution": {"id", "status", "error"}, "task": {"id", "name"}, "workspace": {"id"},
+//	 "outputs": <Output, decodificado si es JSON>, "details": <ExecutionDetails>}
+func CompletionDocument(task *DevOpsTask, execution *TaskExecution) map[string]interface{} {
+	details := make(map[string]interface{}, len(execution.ExecutionDetails))
+	for key, value := range execution.ExecutionDetails {
+		details[key] = value
+	}
+	document := map[string]interface{}{
+		"execution": map[string]interface{}{
+			"id":     execution.ID,
+			"status": string(execution.Status),
+			"error":  execution.Error,
+		},
+		"task":      map[string]interface{}{"id": task.ID, "name": task.Name},
+		"workspace": map[string]interface{}{"id": task.Workspace.ID},
+		"details":   details,
+	}
+	if output := execution.Output; output != nil {
+		var decoded interface{}
+		if output.Type == "application/json" && json.Unmarshal(output.Data, &decoded) == nil {
+			document["outputs"] = decoded
+		} else {
+			document["outputs"] = string(output.Data)
+		}
+	}
+	return document
+}
@@ -0,0 +1,239 @@
+package entities
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule es una expresión cron ya interpretada. Admite el formato estándar de 5
+// campos (minuto, hora, día del mes, mes, día de la semana), el de 6 campos con los
+// segundos al principio y los descriptores @yearly, @monthly, @weekly, @daily y @hourly.
+type CronSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+	// Si día del mes y día de la semana están restringidos basta con que coincida uno de los dos.
+	domStar, dowStar bool
+	Location         *time.Location
+}
+
+type cronField struct {
+	name     string
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	secondField = cronField{name: "second", min: 0, max: 59}
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]uint{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	// Se acepta 7 como domingo y se normaliza a 0.
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]uint{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCronExpression interpreta una expresión cron en la zona horaria indicada.
+// Si loc es nil se usa la zona horaria local.
+func ParseCronExpression(expression string, loc *time.Location) (*CronSchedule, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	spec := strings.TrimSpace(expression)
+	if descriptor, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = descriptor
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 or 6 fields, got %d", expression, len(fields))
+	}
+
+	schedule := &CronSchedule{Location: loc}
+	var err error
+	if schedule.second, _, err = parseCronField(fields[0], secondField); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", expression, err)
+	}
+	if schedule.minute, _, err = parseCronField(fields[1], minuteField); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", expression, err)
+	}
+	if schedule.hour, _, err = parseCronField(fields[2], hourField); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", expression, err)
+	}
+	if schedule.dom, schedule.domStar, err = parseCronField(fields[3], domField); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", expression, err)
+	}
+	if schedule.month, _, err = parseCronField(fields[4], monthField); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", expression, err)
+	}
+	if schedule.dow, schedule.dowStar, err = parseCronField(fields[5], dowField); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", expression, err)
+	}
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow = schedule.dow&^(1<<7) | 1
+	}
+	return schedule, nil
+}
+
+// parseCronField convierte un campo (listas, rangos, pasos, nombres, * y ?) en un mapa de bits.
+func parseCronField(value string, field cronField) (uint64, bool, error) {
+	var bits uint64
+	star := false
+	for _, part := range strings.Split(value, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+
+		step := uint(1)
+		if hasStep {
+			parsed, err := strconv.ParseUint(stepExpr, 10, 8)
+			if err != nil || parsed == 0 {
+				return 0, false, fmt.Errorf("invalid step %q in %s field", stepExpr, field.name)
+			}
+			step = uint(parsed)
+		}
+
+		var start, end uint
+		switch {
+		case rangeExpr == "*" || rangeExpr == "?":
+			start, end = field.min, field.max
+			if !hasStep {
+				star = true
+			}
+		default:
+			low, high, isRange := strings.Cut(rangeExpr, "-")
+			var err error
+			if start, err = parseCronValue(low, field); err != nil {
+				return 0, false, err
+			}
+			end = start
+			if isRange {
+				if end, err = parseCronValue(high, field); err != nil {
+					return 0, false, err
+				}
+			} else if hasStep {
+				end = field.max
+			}
+		}
+		if start > end {
+			return 0, false, fmt.Errorf("invalid range %q in %s field", rangeExpr, field.name)
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, star, nil
+}
+
+func parseCronValue(value string, field cronField) (uint, error) {
+	if n, ok := field.names[strings.ToUpper(value)]; ok {
+		return n, nil
+	}
+	parsed, err := strconv.ParseUint(value, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s field", value, field.name)
+	}
+	if uint(parsed) < field.min || uint(parsed) > field.max {
+		return 0, fmt.Errorf("value %d out of range [%d-%d] in %s field", parsed, field.min, field.max, field.name)
+	}
+	return uint(parsed), nil
+}
+
+// Next devuelve la primera activación estrictamente posterior a t, o el instante cero si la
+// expresión no se activa en los próximos cinco años (p.ej. 30 de febrero).
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	original := t.Location()
+	t = t.In(s.Location).Truncate(time.Second).Add(time.Second)
+	yearLimit := t.Year() + 5
+
+	// Al avanzar un campo se ponen a cero los inferiores la primera vez.
+	added := false
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for s.month&(1<<uint(t.Month())) == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, s.Location)
+		}
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !s.dayMatches(t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.Location)
+		}
+		t = t.AddDate(0, 0, 1)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for s.hour&(1<<uint(t.Hour())) == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, s.Location)
+		}
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.Location)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+
+	for s.minute&(1<<uint(t.Minute())) == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Minute)
+		}
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+
+	for s.second&(1<<uint(t.Second())) == 0 {
+		if !added {
+			added = true
+		}
+		t = t.Add(time.Second)
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+
+	return t.In(original)
+}
+
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
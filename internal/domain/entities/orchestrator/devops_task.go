@@ -2,6 +2,9 @@
 package entities
 
 import (
+	"fmt"
+	"maps"
+	"slices"
 	"time"
 )
 
@@ -15,8 +18,25 @@ const (
 	TaskCanceled  TaskStatus = "CANCELED"
 	TaskError     TaskStatus = "ERROR"
 	TaskScheduled TaskStatus = "SCHEDULED"
+	TaskSkipped   TaskStatus = "SKIPPED"
+	// Estados de las tareas de aprobación
+	TaskWaitingApproval TaskStatus = "WAITING_APPROVAL"
+	TaskRejected        TaskStatus = "REJECTED"
+	TaskExpired         TaskStatus = "EXPIRED"
+	// Estado de las tareas manuales en curso mientras esperan los datos del operador
+	TaskWaitingInput TaskStatus = "WAITING_INPUT"
 )
 
+// IsTerminal indica si el estado es final y la ejecución ya no cambiará.
+func (s TaskStatus) IsTerminal() bool {
+	switch s {
+	case TaskSucceeded, TaskFailed, TaskCanceled, TaskError, TaskSkipped, TaskRejected, TaskExpired:
+		return true
+	default:
+		return false
+	}
+}
+
 type TaskType string
 
 const (
@@ -38,14 +58,63 @@ type DevOpsTask struct {
 	Workspace   Workspace
 	TaskType    TaskType
 	Approvals   []*Approval
-	Trigger     *Trigger
-	Tags        []string
-	Worker      Worker
+	// ApprovalPolicy solo se usa en las tareas TaskTypeApproval.
+	ApprovalPolicy *ApprovalPolicy
+	Trigger        *Trigger
+	Tags           []string
+	Worker         Worker
+	// InputForm solo se usa en las tareas TaskTypeManual: es lo que se pide al operador cuando
+	// la ejecución en curso necesita sus datos.
+	InputForm *InputForm
+	// Priority ordena la cola de ejecución: mayor valor, antes se lanza.
+	Priority int
+	// Parameters define las entradas que admite cada ejecución; sus valores se pasan en
+	// Config.Parameters.
+	Parameters []ParameterDefinition
+	// Revision es la revisión vigente; Revisions guarda el historial completo.
+	Revision  int
+	Revisions []*TaskRevision
+}
+
+// ParameterValues devuelve los valores de Config.Parameters que corresponden a parámetros
+// definidos, incluidos los campos del formulario de entrada.
+func (t *DevOpsTask) ParameterValues() map[string]interface{} {
+	definitions := t.AllParameters()
+	values := make(map[string]interface{}, len(definitions))
+	for _, definition := range definitions {
+		if value, ok := t.Config.Parameters[definition.Name]; ok {
+			values[definition.Name] = value
+		}
+	}
+	return values
+}
+
+// SecretParameterValues devuelve, tal y como llegan al entorno con ParameterEnv, los
+// valores de los parámetros secretos, para ocultarlos en la salida de la ejecución.
+func (t *DevOpsTask) SecretParameterValues() []string {
+	var values []string
+	for _, definition := range t.AllParameters() {
+		if value, ok := t.Config.Parameters[definition.Name]; ok && definition.Secret {
+			values = append(values, fmt.Sprint(value))
+		}
+	}
+	return values
 }
 
 type TaskConfig struct {
 	Parameters map[string]interface{}
 	Workspace  string
+	Retry      *RetryPolicy
+	// Secrets se resuelven contra el SecretStore del executor al lanzar cada ejecución.
+	Secrets []SecretReference
+	// Outputs se recogen del contenedor al terminar cada ejecución. Por ahora solo lo hace
+	// el executor de Docker: un pod terminado ya no admite exec.
+	Outputs []OutputSpec
+	// Inputs son artefactos de otras tareas que se dejan en el contenedor antes de que
+	// empiece la tarea.
+	Inputs []ArtifactInput
+	// Matrix, si se define, convierte cada ejecución en una por combinación de parámetros.
+	Matrix *Matrix
 }
 
 type TaskExecution struct {
@@ -58,29 +127,153 @@ type TaskExecution struct {
 	Output           *Artifact
 	Error            string
 	ExecutionDetails map[string]interface{}
+	// TriggeredBy es el ID del sujeto que lanzó la ejecución, si se conoce, y
+	// TriggeredByKind su tipo (SubjectKind).
+	TriggeredBy     string
+	TriggeredByKind string
+	// ApprovalDeadline es el instante en que caduca una ejecución pendiente de aprobación.
+	ApprovalDeadline time.Time
+	// InputDeadline es el instante en que caduca una ejecución que espera datos del operador;
+	// InputSubmittedBy es quien los envió.
+	InputDeadline    time.Time
+	InputSubmittedBy string
+	FailureClass     FailureClass
+	// Attempt empieza en 1; los reintentos se enlazan con PreviousAttemptID y NextAttemptID.
+	Attempt           int
+	PreviousAttemptID string
+	NextAttemptID     string
+	// RetryAt es el instante previsto para el siguiente intento mientras este no se lanza.
+	RetryAt time.Time
+	// Parameters son los valores de la ejecución, con los secretos enmascarados.
+	Parameters map[string]interface{}
+	// RenderedSpec es lo que se lanzó tras sustituir las plantillas, con los secretos enmascarados.
+	RenderedSpec *WorkerSpec
+	// TaskRevision es la revisión de la tarea con la que se lanzó la ejecución.
+	TaskRevision int
+	// UpstreamExecutionID es la ejecución cuyo final lanzó esta, si vino de un TaskCompletionTrigger.
+	UpstreamExecutionID string
+	// TriggerChain son los IDs de las tareas encadenadas que llevaron hasta esta ejecución,
+	// de la primera a la inmediatamente anterior; sirve para detectar ciclos.
+	TriggerChain []string
+	// Artifacts son las salidas recogidas al terminar, guardadas en el ArtifactStore.
+	Artifacts []Artifact
+	// Inputs son los artefactos que recibió la ejecución, con la ejecución de la que venían.
+	Inputs []ArtifactInput
+	// MatrixSize es el número de combinaciones de una ejecución de matriz, que solo agrega
+	// el resultado de las ejecuciones de ChildExecutionIDs.
+	MatrixSize        int
+	ChildExecutionIDs []string
+	// ParentExecutionID y MatrixValues identifican la ejecución de matriz y la combinación
+	// de las que forma parte una ejecución.
+	ParentExecutionID string
+	MatrixValues      map[string]interface{}
+}
+
+// Clone devuelve una copia de la ejecución que no comparte sus mapas ni sus listas.
+func (e TaskExecution) Clone() TaskExecution {
+	e.ExecutionDetails = maps.Clone(e.ExecutionDetails)
+	e.Parameters = maps.Clone(e.Parameters)
+	e.MatrixValues = maps.Clone(e.MatrixValues)
+	e.TriggerChain = slices.Clone(e.TriggerChain)
+	e.Artifacts = slices.Clone(e.Artifacts)
+	e.Inputs = slices.Clone(e.Inputs)
+	e.ChildExecutionIDs = slices.Clone(e.ChildExecutionIDs)
+	return e
 }
 
 type Approval struct {
-	ID         string
-	UserID     string
-	ApprovedAt time.Time
-	Approved   bool
+	ID          string
+	ExecutionID string
+	UserID      string
+	// SubjectKind es el tipo (SubjectKind) del sujeto UserID que votó.
+	SubjectKind string
+	ApprovedAt  time.Time
+	Approved    bool
+	Comment     string
+}
+
+// ApprovalPolicy define quién puede aprobar una tarea, cuántas aprobaciones hacen falta
+// y cuánto tiempo se espera antes de dar la petición por caducada.
+type ApprovalPolicy struct {
+	// Approvers son usuarios, grupos o service accounts; un usuario puede aprobar si
+	// aparece directamente o pertenece a alguno de los grupos.
+	Approvers []Subject
+	Quorum    int
+	Timeout   time.Duration
+}
+
+// CanApprove indica si el sujeto figura entre los aprobadores de la política. Un aprobador
+// solo coincide con un sujeto de su mismo tipo: un usuario "sre" no es el grupo "sre".
+func (p *ApprovalPolicy) CanApprove(subject Subject) bool {
+	var groups []string
+	if user, ok := subject.(User); ok {
+		groups = user.Groups
+	}
+	for _, approver := range p.Approvers {
+		if SubjectKind(approver) == SubjectKind(subject) && approver.GetID() == subject.GetID() {
+			return true
+		}
+		if _, ok := approver.(Group); ok {
+			for _, group := range groups {
+				if group == approver.GetID() {
+					return true
+				}
+			}
+		}
+	}
+	return false
 }
 
 type Trigger interface {
 	Evaluate() bool
 }
 
+// MissedRunPolicy indica qué hacer con las activaciones perdidas mientras el master estaba caído.
+type MissedRunPolicy string
+
+const (
+	MissedRunSkip    MissedRunPolicy = "SKIP"     // se descartan
+	MissedRunOnce    MissedRunPolicy = "RUN_ONCE" // se ejecuta una sola vez
+	MissedRunCatchUp MissedRunPolicy = "CATCH_UP" // se ejecutan todas, en orden
+)
+
 type ScheduledTrigger struct {
 	Expression string
+	// Timezone es un nombre IANA (p.ej. "Europe/Madrid"); vacío usa la zona horaria local.
+	Timezone        string
+	MissedRunPolicy MissedRunPolicy
+	// LastRun es la última activación lanzada; sirve para detectar las perdidas.
+	LastRun time.Time
 }
 
+// Schedule interpreta la expresión cron en la zona horaria del trigger.
+func (t *ScheduledTrigger) Schedule() (*CronSchedule, error) {
+	loc := time.Local
+	if t.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(t.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", t.Timezone, err)
+		}
+	}
+	return ParseCronExpression(t.Expression, loc)
+}
+
+// Evaluate indica si hay una activación pendiente desde LastRun. Sin LastRun no hay
+// ninguna pendiente.
 func (t *ScheduledTrigger) Evaluate() bool {
-	// Implementar la evaluación de la expresión
-	return false
+	if t.LastRun.IsZero() {
+		return false
+	}
+	schedule, err := t.Schedule()
+	if err != nil {
+		return false
+	}
+	next := schedule.Next(t.LastRun)
+	return !next.IsZero() && !next.After(time.Now())
 }
 
 type Workspace struct {
-	ID   string
-	Name string
+	ID       string
+	Name     string
+	TenantID string
 }
@@ -13,8 +13,20 @@ const (
 	EventTypeTaskCanceled    TaskEventType = "TaskCanceled"
 	EventTypeTaskOutput      TaskEventType = "TaskOutput"
 	EventTypeTaskError       TaskEventType = "TaskError"
+	EventTypeTaskRetrying    TaskEventType = "TaskRetrying"
+	EventTypeTaskQueued      TaskEventType = "TaskQueued"
 	EventTypePodName         TaskEventType = "POD_NAME"        // Nuevo tipo de evento
 	EventTypeWorkerConnected TaskEventType = "WorkerConnected" // Nuevo tipo de evento
+
+	// EventTypeTaskInputRequired se publica cuando una ejecución espera datos del operador.
+	// El executor lo publica sin payload cuando la tarea los pide y el servicio lo vuelve a
+	// publicar con el formulario (TaskInputPayload).
+	EventTypeTaskInputRequired TaskEventType = "TaskInputRequired"
+	// EventTypeTaskWarning avisa de un problema que no termina la ejecución, como un corte en
+	// el streaming de los logs. El payload es el mensaje.
+	EventTypeTaskWarning TaskEventType = "TaskWarning"
+	// EventTypeTaskApprovalRequired se publica cuando una ejecución queda esperando aprobación.
+	EventTypeTaskApprovalRequired TaskEventType = "TaskApprovalRequired"
 	// Otros tipos de eventos según sea necesario
 )
 
@@ -25,3 +37,78 @@ type TaskEvent struct {
 	EventType   TaskEventType
 	Payload     interface{}
 }
+
+// TaskProgressPayload acompaña a los eventos de cambio de estado de una ejecución.
+type TaskProgressPayload struct {
+	Status           TaskStatus             `json:"status"`
+	Error            string                 `json:"error,omitempty"`
+	ExecutionDetails map[string]interface{} `json:"executionDetails,omitempty"`
+	FailureClass     FailureClass           `json:"failureClass,omitempty"`
+	// Artifacts son las salidas recogidas por el executor, ya guardadas en el ArtifactStore.
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+}
+
+// TaskQueuePayload acompaña a EventTypeTaskQueued cuando cambia la posición de una
+// ejecución en la cola. Position empieza en 1.
+type TaskQueuePayload struct {
+	Position    int `json:"position"`
+	QueueLength int `json:"queueLength"`
+}
+
+// TaskRetryPayload acompaña a EventTypeTaskRetrying, publicado en la ejecución fallida
+// cuando se lanza el siguiente intento.
+type TaskRetryPayload struct {
+	Attempt         int    `json:"attempt"`
+	MaxAttempts     int    `json:"maxAttempts"`
+	NextExecutionID string `json:"nextExecutionId"`
+}
+
+// TaskResultPayload acompaña al evento terminal que publica el TaskService cuando una
+// ejecución termina definitivamente, sin reintentos pendientes.
+type TaskResultPayload struct {
+	TaskID      string     `json:"taskId"`
+	WorkspaceID string     `json:"workspaceId"`
+	Status      TaskStatus `json:"status"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// TaskApprovalPayload acompaña a EventTypeTaskApprovalRequired con quién puede aprobar la
+// ejecución y hasta cuándo.
+type TaskApprovalPayload struct {
+	TaskID      string    `json:"taskId"`
+	WorkspaceID string    `json:"workspaceId"`
+	Approvers   []string  `json:"approvers"`
+	Quorum      int       `json:"quorum"`
+	Deadline    time.Time `json:"deadline"`
+}
+
+// EventTypeFromStatus devuelve el tipo de evento con el que se publica un estado terminal.
+// Los rechazos y caducidades de las aprobaciones se publican como TaskFailed.
+func EventTypeFromStatus(status TaskStatus) TaskEventType {
+	switch status {
+	case TaskSucceeded, TaskSkipped:
+		return EventTypeTaskCompleted
+	case TaskError:
+		return EventTypeTaskError
+	case TaskCanceled:
+		return EventTypeTaskCanceled
+	default:
+		return EventTypeTaskFailed
+	}
+}
+
+// StatusFromEventType devuelve el estado terminal asociado a un tipo de evento, si lo tiene.
+func StatusFromEventType(eventType TaskEventType) (TaskStatus, bool) {
+	switch eventType {
+	case EventTypeTaskCompleted:
+		return TaskSucceeded, true
+	case EventTypeTaskFailed:
+		return TaskFailed, true
+	case EventTypeTaskError:
+		return TaskError, true
+	case EventTypeTaskCanceled:
+		return TaskCanceled, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,32 @@
+package entities
+
+import "time"
+
+// InputForm es el formulario que un operador debe rellenar para que una ejecución de una
+// tarea TaskTypeManual continúe cuando lo pide. Sus campos se validan como parámetros y
+// llegan a la ejecución en curso a través de su executor.
+type InputForm struct {
+	Prompt string
+	Fields []ParameterDefinition
+	// Timeout es la espera máxima; pasado ese tiempo la ejecución caduca.
+	Timeout time.Duration
+}
+
+// TaskInputPayload acompaña a EventTypeTaskInputRequired con lo necesario para mostrar
+// el formulario.
+type TaskInputPayload struct {
+	Prompt   string                `json:"prompt"`
+	Fields   []ParameterDefinition `json:"fields"`
+	Deadline time.Time             `json:"deadline"`
+}
+
+// AllParameters devuelve las definiciones de parámetros de la tarea más los campos de su
+// formulario de entrada, si lo tiene.
+func (t *DevOpsTask) AllParameters() []ParameterDefinition {
+	if t.InputForm == nil || len(t.InputForm.Fields) == 0 {
+		return t.Parameters
+	}
+	definitions := make([]ParameterDefinition, 0, len(t.Parameters)+len(t.InputForm.Fields))
+	definitions = append(definitions, t.Parameters...)
+	return append(definitions, t.InputForm.Fields...)
+}
@@ -0,0 +1,198 @@
+package entities
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MaxMatrixCombinations limita las ejecuciones que puede lanzar una matriz de una vez.
+const MaxMatrixCombinations = 256
+
+// Matrix lanza una ejecución de la tarea por cada combinación de los valores de sus ejes.
+// Cada eje da valores a un parámetro de la tarea.
+type Matrix struct {
+	Axes []MatrixAxis
+	// Exclude quita las combinaciones que coinciden con todos los valores de alguna de sus
+	// entradas; Include añade combinaciones aunque no salgan de los ejes.
+	Exclude []map[string]interface{}
+	Include []map[string]interface{}
+	// MaxParallel limita las combinaciones en curso a la vez; 0 no limita.
+	MaxParallel int
+	// FailFast cancela las combinaciones en curso y no lanza las pendientes cuando falla una.
+	FailFast bool
+}
+
+type MatrixAxis struct {
+	Parameter string
+	Values    []interface{}
+}
+
+// Combinations devuelve el producto de los ejes, en su orden, sin las combinaciones
+// excluidas y seguido de las incluidas que no estuvieran ya.
+func (m *Matrix) Combinations() []map[string]interface{} {
+	var combinations []map[string]interface{}
+	if len(m.Axes) > 0 {
+		combinations = []map[string]interface{}{{}}
+	}
+	for _, axis := range m.Axes {
+		next := make([]map[string]interface{}, 0, len(combinations)*len(axis.Values))
+		for _, combination := range combinations {
+			for _, value := range axis.Values {
+				extended := make(map[string]interface{}, len(combination)+1)
+				for name, current := range combination {
+					extended[name] = current
+				}
+				extended[axis.Parameter] = value
+				next = append(next, extended)
+			}
+		}
+		combinations = next
+	}
+
+	var result []map[string]interface{}
+	seen := make(map[string]bool)
+	for _, combination := range combinations {
+		if !m.excluded(combination) {
+			result = append(result, combination)
+			seen[FormatCombination(combination)] = true
+		}
+	}
+	for _, combination := range m.Include {
+		if key := FormatCombination(combination); !seen[key] {
+			result = append(result, combination)
+			seen[key] = true
+		}
+	}
+	return result
+}
+
+func (m *Matrix) excluded(combination map[string]interface{}) bool {
+	for _, exclude := range m.Exclude {
+		if matchesCombination(combination, exclude) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesCombination indica si la combinación tiene todos los valores de pattern; los
+// valores se comparan en su forma de texto, como los enumerados de los parámetros.
+func matchesCombination(combination, pattern map[string]interface{}) bool {
+	for name, value := range pattern {
+		current, ok := combination[name]
+		if !ok || fmt.Sprint(current) != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatCombination describe una combinación como "nombre=valor", ordenadas por nombre.
+func FormatCombination(combination map[string]interface{}) string {
+	names := make([]string, 0, len(combination))
+	for name := range combination {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%v", name, combination[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ValidateMatrix comprueba que los ejes y las reglas usen parámetros definidos con valores
+// válidos y que la matriz tenga entre una y MaxMatrixCombinations combinaciones.
+func ValidateMatrix(matrix *Matrix, definitions []ParameterDefinition) error {
+	if matrix == nil {
+		return nil
+	}
+	byName := make(map[string]ParameterDefinition, len(definitions))
+	for _, definition := range definitions {
+		byName[definition.Name] = definition
+	}
+	validate := func(name string, value interface{}) error {
+		definition, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("matrix uses undefined parameter %q", name)
+		}
+		if _, err := definition.Validate(value); err != nil {
+			return fmt.Errorf("matrix value of parameter %q: %w", name, err)
+		}
+		return nil
+	}
+
+	axes := make(map[string]bool, len(matrix.Axes))
+	for _, axis := range matrix.Axes {
+		if axes[axis.Parameter] {
+			return fmt.Errorf("duplicated matrix axis %q", axis.Parameter)
+		}
+		axes[axis.Parameter] = true
+		if len(axis.Values) == 0 {
+			return fmt.Errorf("matrix axis %q has no values", axis.Parameter)
+		}
+		for _, value := range axis.Values {
+			if err := validate(axis.Parameter, value); err != nil {
+				return err
+			}
+		}
+	}
+	for _, rules := range [][]map[string]interface{}{matrix.Exclude, matrix.Include} {
+		for _, rule := range rules {
+			if len(rule) == 0 {
+				return fmt.Errorf("matrix rule without values")
+			}
+			for name, value := range rule {
+				if err := validate(name, value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if matrix.MaxParallel < 0 {
+		return fmt.Errorf("matrix max parallel cannot be negative")
+	}
+
+	// El producto se acota antes de calcularlo para no generar matrices enormes.
+	size := 1
+	for _, axis := range matrix.Axes {
+		size *= len(axis.Values)
+		if size > MaxMatrixCombinations {
+			return fmt.Errorf("matrix has more than %d combinations", MaxMatrixCombinations)
+		}
+	}
+	count := len(matrix.Combinations())
+	if count == 0 {
+		return fmt.Errorf("matrix has no combinations")
+	}
+	if count > MaxMatrixCombinations {
+		return fmt.Errorf("matrix has more than %d combinations", MaxMatrixCombinations)
+	}
+	return nil
+}
+
+func (m *Matrix) copy() *Matrix {
+	matrix := *m
+	matrix.Axes = make([]MatrixAxis, len(m.Axes))
+	for i, axis := range m.Axes {
+		matrix.Axes[i] = MatrixAxis{Parameter: axis.Parameter, Values: append([]interface{}(nil), axis.Values...)}
+	}
+	matrix.Exclude = copyCombinations(m.Exclude)
+	matrix.Include = copyCombinations(m.Include)
+	return &matrix
+}
+
+func copyCombinations(combinations []map[string]interface{}) []map[string]interface{} {
+	if combinations == nil {
+		return nil
+	}
+	copied := make([]map[string]interface{}, len(combinations))
+	for i, combination := range combinations {
+		copied[i] = make(map[string]interface{}, len(combination))
+		for name, value := range combination {
+			copied[i][name] = value
+		}
+	}
+	return copied
+}
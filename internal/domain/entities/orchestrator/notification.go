@@ -0,0 +1,122 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NotificationTrigger es el suceso de una ejecución que dispara una notificación.
+type NotificationTrigger string
+
+const (
+	// NotifyOnFailure se dispara cuando una ejecución termina con error o caduca.
+	NotifyOnFailure NotificationTrigger = "FAILURE"
+	// NotifyOnRecovery se dispara cuando una ejecución termina bien tras una que falló.
+	NotifyOnRecovery NotificationTrigger = "RECOVERY"
+	// NotifyOnApprovalRequested se dispara cuando una ejecución queda esperando aprobación.
+	NotifyOnApprovalRequested NotificationTrigger = "APPROVAL_REQUESTED"
+)
+
+// NotificationRule envía por sus canales los sucesos On de una tarea o, si no indica
+// TaskID, de todas las tareas del workspace WorkspaceID.
+type NotificationRule struct {
+	ID          string
+	TaskID      string
+	WorkspaceID string
+	On          []NotificationTrigger
+	// Channels son los nombres con los que se registraron los canales en el notificador.
+	Channels []string
+}
+
+// Validate comprueba que la regla seleccione tareas, sucesos y canales.
+func (r NotificationRule) Validate() error {
+	if r.TaskID == "" && r.WorkspaceID == "" {
+		return fmt.Errorf("notification rule needs a task or a workspace")
+	}
+	if len(r.On) == 0 {
+		return fmt.Errorf("notification rule has no triggers")
+	}
+	for _, trigger := range r.On {
+		switch trigger {
+		case NotifyOnFailure, NotifyOnRecovery, NotifyOnApprovalRequested:
+		default:
+			return fmt.Errorf("unknown notification trigger %q", trigger)
+		}
+	}
+	if len(r.Channels) == 0 {
+		return fmt.Errorf("notification rule has no channels")
+	}
+	return nil
+}
+
+// Matches indica si la regla envía el suceso de esa tarea.
+func (r NotificationRule) Matches(task *DevOpsTask, trigger NotificationTrigger) bool {
+	if r.TaskID != "" && r.TaskID != task.ID {
+		return false
+	}
+	if r.WorkspaceID != "" && r.WorkspaceID != task.Workspace.ID {
+		return false
+	}
+	for _, on := range r.On {
+		if on == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification es lo que reciben los canales. Sus campos son los datos de las plantillas
+// de los webhooks salientes.
+type Notification struct {
+	Trigger     NotificationTrigger `json:"trigger"`
+	TaskID      string              `json:"taskId"`
+	TaskName    string              `json:"taskName,omitempty"`
+	WorkspaceID string              `json:"workspaceId"`
+	ExecutionID string              `json:"executionId"`
+	Status      TaskStatus          `json:"status"`
+	Error       string              `json:"error,omitempty"`
+	Timestamp   time.Time           `json:"timestamp"`
+	// Approvers y Deadline solo se rellenan en NotifyOnApprovalRequested.
+	Approvers []string  `json:"approvers,omitempty"`
+	Deadline  time.Time `json:"deadline,omitempty"`
+}
+
+// Subject resume la notificación en una línea.
+func (n Notification) Subject() string {
+	name := n.TaskName
+	if name == "" {
+		name = n.TaskID
+	}
+	switch n.Trigger {
+	case NotifyOnFailure:
+		return fmt.Sprintf("Task %q failed with status %s", name, n.Status)
+	case NotifyOnRecovery:
+		return fmt.Sprintf("Task %q recovered", name)
+	case NotifyOnApprovalRequested:
+		return fmt.Sprintf("Task %q is waiting for approval", name)
+	default:
+		return fmt.Sprintf("Task %q: %s", name, n.Status)
+	}
+}
+
+// Text es la notificación en texto plano: el resumen seguido de los datos de la ejecución.
+func (n Notification) Text() string {
+	var b strings.Builder
+	b.WriteString(n.Subject())
+	b.WriteString("\n\n")
+	fmt.Fprintf(&b, "Task: %s\n", n.TaskID)
+	fmt.Fprintf(&b, "Workspace: %s\n", n.WorkspaceID)
+	fmt.Fprintf(&b, "Execution: %s\n", n.ExecutionID)
+	fmt.Fprintf(&b, "Status: %s\n", n.Status)
+	if n.Error != "" {
+		fmt.Fprintf(&b, "Error: %s\n", n.Error)
+	}
+	if len(n.Approvers) > 0 {
+		fmt.Fprintf(&b, "Approvers: %s\n", strings.Join(n.Approvers, ", "))
+	}
+	if !n.Deadline.IsZero() {
+		fmt.Fprintf(&b, "Deadline: %s\n", n.Deadline.UTC().Format(time.RFC3339))
+	}
+	return b.String()
+}
@@ -0,0 +1,219 @@
+package entities
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type ParameterType string
+
+const (
+	ParameterString  ParameterType = "STRING"
+	ParameterNumber  ParameterType = "NUMBER"
+	ParameterInteger ParameterType = "INTEGER"
+	ParameterBoolean ParameterType = "BOOLEAN"
+)
+
+// MaskedParameterValue sustituye a los parámetros secretos al registrarlos en una ejecución.
+const MaskedParameterValue = "******"
+
+// ParameterDefinition describe un parámetro de entrada de una tarea. El formulario del
+// frontend se genera a partir de estas definiciones.
+type ParameterDefinition struct {
+	Name        string
+	Description string
+	Type        ParameterType
+	Default     interface{}
+	Required    bool
+	// Enum limita los valores admitidos; se comparan en su forma de texto.
+	Enum []string
+	// Pattern es una expresión regular que debe cumplir el valor en su forma de texto.
+	Pattern string
+	// Secret evita que el valor se registre en la ejecución.
+	Secret bool
+}
+
+// ValidateParameterDefinitions comprueba que los nombres sean únicos y que tipos,
+// patrones, enumerados y valores por defecto sean coherentes.
+func ValidateParameterDefinitions(definitions []ParameterDefinition) error {
+	names := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		if definition.Name == "" {
+			return fmt.Errorf("parameter without name")
+		}
+		if names[definition.Name] {
+			return fmt.Errorf("duplicated parameter %q", definition.Name)
+		}
+		names[definition.Name] = true
+
+		switch definition.Type {
+		case ParameterString, ParameterNumber, ParameterInteger, ParameterBoolean:
+		default:
+			return fmt.Errorf("parameter %q has unknown type %q", definition.Name, definition.Type)
+		}
+		if definition.Pattern != "" {
+			if _, err := regexp.Compile(definition.Pattern); err != nil {
+				return fmt.Errorf("parameter %q has an invalid pattern: %w", definition.Name, err)
+			}
+		}
+		for _, value := range definition.Enum {
+			if _, err := definition.convert(value); err != nil {
+				return fmt.Errorf("parameter %q has an invalid enum value: %w", definition.Name, err)
+			}
+		}
+		if definition.Default != nil {
+			if _, err := definition.Validate(definition.Default); err != nil {
+				return fmt.Errorf("parameter %q has an invalid default: %w", definition.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveParameters valida los valores de una ejecución contra las definiciones y aplica
+// los valores por defecto. Devuelve los valores ya convertidos a su tipo.
+func ResolveParameters(definitions []ParameterDefinition, values map[string]interface{}) (map[string]interface{}, error) {
+	known := make(map[string]bool, len(definitions))
+	resolved := make(map[string]interface{}, len(definitions))
+	for _, definition := range definitions {
+		known[definition.Name] = true
+		value, ok := values[definition.Name]
+		if !ok || value == nil {
+			value = definition.Default
+		}
+		if value == nil {
+			if definition.Required {
+				return nil, fmt.Errorf("parameter %q is required", definition.Name)
+			}
+			continue
+		}
+		converted, err := definition.Validate(value)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %q: %w", definition.Name, err)
+		}
+		resolved[definition.Name] = converted
+	}
+
+	var unknown []string
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown parameters %v", unknown)
+	}
+	return resolved, nil
+}
+
+// MaskSecretParameters devuelve una copia de los valores con los secretos ocultos.
+func MaskSecretParameters(definitions []ParameterDefinition, values map[string]interface{}) map[string]interface{} {
+	masked := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		masked[name] = value
+	}
+	for _, definition := range definitions {
+		if _, ok := masked[definition.Name]; ok && definition.Secret {
+			masked[definition.Name] = MaskedParameterValue
+		}
+	}
+	return masked
+}
+
+// ParameterEnv convierte los valores en variables de entorno NOMBRE=valor, con el nombre
+// en mayúsculas, ordenadas por nombre.
+func ParameterEnv(values map[string]interface{}) []string {
+	env := make([]string, 0, len(values))
+	for name, value := range values {
+		env = append(env, fmt.Sprintf("%s=%v", strings.ToUpper(name), value))
+	}
+	sort.Strings(env)
+	return env
+}
+
+// Validate convierte el valor al tipo del parámetro y comprueba el enumerado y el patrón.
+func (d ParameterDefinition) Validate(value interface{}) (interface{}, error) {
+	converted, err := d.convert(value)
+	if err != nil {
+		return nil, err
+	}
+	text := fmt.Sprint(converted)
+
+	if len(d.Enum) > 0 {
+		allowed := false
+		for _, option := range d.Enum {
+			if enumValue, err := d.convert(option); err == nil && fmt.Sprint(enumValue) == text {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, fmt.Errorf("value %q is not one of %v", text, d.Enum)
+		}
+	}
+	if d.Pattern != "" {
+		matched, err := regexp.MatchString(d.Pattern, text)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			return nil, fmt.Errorf("value %q does not match pattern %q", text, d.Pattern)
+		}
+	}
+	return converted, nil
+}
+
+// convert admite el valor con su tipo nativo o como texto, que es como llega de los formularios.
+func (d ParameterDefinition) convert(value interface{}) (interface{}, error) {
+	switch d.Type {
+	case ParameterString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case ParameterBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+	case ParameterNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case float32:
+			return float64(v), nil
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case string:
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return f, nil
+			}
+		}
+	case ParameterInteger:
+		switch v := value.(type) {
+		case int:
+			return int64(v), nil
+		case int64:
+			return v, nil
+		case float64:
+			// Los números de JSON llegan como float64.
+			if v == float64(int64(v)) {
+				return int64(v), nil
+			}
+		case string:
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return i, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("value %v is not a valid %s", value, strings.ToLower(string(d.Type)))
+}
@@ -0,0 +1,189 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+)
+
+// Pipeline es un grafo acíclico de DevOpsTasks. Cada nodo referencia una tarea y puede
+// depender de otros nodos; los nodos sin dependencias pendientes se ejecutan en paralelo.
+type Pipeline struct {
+	ID          string
+	Name        string
+	Description string
+	Workspace   Workspace
+	// Stages define el orden de las etapas. Los nodos de una etapa dependen implícitamente
+	// de todos los nodos de la etapa anterior.
+	Stages    []string
+	Nodes     []PipelineNode
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type PipelineNode struct {
+	ID        string
+	TaskID    string
+	Stage     string
+	DependsOn []string
+}
+
+type PipelineExecution struct {
+	ID         string
+	PipelineID string
+	Status     TaskStatus
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Error      string
+	Nodes      map[string]*PipelineNodeExecution
+}
+
+type PipelineNodeExecution struct {
+	NodeID      string
+	TaskID      string
+	ExecutionID string
+	Status      TaskStatus
+	StartedAt   time.Time
+	FinishedAt  time.Time
+	Error       string
+}
+
+// Clone devuelve una copia de la ejecución que no comparte los estados de los nodos.
+func (e PipelineExecution) Clone() PipelineExecution {
+	nodes := make(map[string]*PipelineNodeExecution, len(e.Nodes))
+	for id, node := range e.Nodes {
+		copied := *node
+		nodes[id] = &copied
+	}
+	e.Nodes = nodes
+	return e
+}
+
+// Dependencies devuelve las dependencias de un nodo: las declaradas en DependsOn y las
+// implícitas por pertenecer a una etapa posterior.
+func (p *Pipeline) Dependencies(nodeID string) []string {
+	node, ok := p.node(nodeID)
+	if !ok {
+		return nil
+	}
+
+	deps := append([]string{}, node.DependsOn...)
+	if previous := p.previousStage(node.Stage); previous != "" {
+		for _, other := range p.Nodes {
+			if other.Stage == previous && !contains(deps, other.ID) {
+				deps = append(deps, other.ID)
+			}
+		}
+	}
+	return deps
+}
+
+// Validate comprueba que los nodos sean únicos, que las dependencias y etapas existan
+// y que el grafo no tenga ciclos.
+func (p *Pipeline) Validate() error {
+	if len(p.Nodes) == 0 {
+		return fmt.Errorf("pipeline %q has no nodes", p.Name)
+	}
+
+	ids := make(map[string]bool, len(p.Nodes))
+	for _, node := range p.Nodes {
+		if node.ID == "" {
+			return fmt.Errorf("pipeline node without ID")
+		}
+		if node.TaskID == "" {
+			return fmt.Errorf("pipeline node %q does not reference a task", node.ID)
+		}
+		if ids[node.ID] {
+			return fmt.Errorf("duplicated pipeline node %q", node.ID)
+		}
+		if node.Stage != "" && !contains(p.Stages, node.Stage) {
+			return fmt.Errorf("pipeline node %q references unknown stage %q", node.ID, node.Stage)
+		}
+		ids[node.ID] = true
+	}
+
+	for _, node := range p.Nodes {
+		for _, dep := range node.DependsOn {
+			if dep == node.ID {
+				return fmt.Errorf("pipeline node %q depends on itself", node.ID)
+			}
+			if !ids[dep] {
+				return fmt.Errorf("pipeline node %q depends on unknown node %q", node.ID, dep)
+			}
+		}
+	}
+
+	if _, err := p.TopologicalOrder(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// TopologicalOrder devuelve los IDs de los nodos en un orden compatible con sus
+// dependencias o un error si el grafo contiene un ciclo.
+func (p *Pipeline) TopologicalOrder() ([]string, error) {
+	pending := make(map[string]int, len(p.Nodes))
+	dependents := make(map[string][]string, len(p.Nodes))
+	for _, node := range p.Nodes {
+		deps := p.Dependencies(node.ID)
+		pending[node.ID] = len(deps)
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], node.ID)
+		}
+	}
+
+	var ready, order []string
+	for _, node := range p.Nodes {
+		if pending[node.ID] == 0 {
+			ready = append(ready, node.ID)
+		}
+	}
+	for len(ready) > 0 {
+		current := ready[0]
+		ready = ready[1:]
+		order = append(order, current)
+		for _, dependent := range dependents[current] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(order) != len(p.Nodes) {
+		var cyclic []string
+		for _, node := range p.Nodes {
+			if pending[node.ID] > 0 {
+				cyclic = append(cyclic, node.ID)
+			}
+		}
+		return nil, fmt.Errorf("pipeline %q has a dependency cycle between nodes %v", p.Name, cyclic)
+	}
+	return order, nil
+}
+
+func (p *Pipeline) node(nodeID string) (PipelineNode, bool) {
+	for _, node := range p.Nodes {
+		if node.ID == nodeID {
+			return node, true
+		}
+	}
+	return PipelineNode{}, false
+}
+
+func (p *Pipeline) previousStage(stage string) string {
+	for i, s := range p.Stages {
+		if s == stage && i > 0 {
+			return p.Stages[i-1]
+		}
+	}
+	return ""
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
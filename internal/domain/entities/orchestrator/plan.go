@@ -0,0 +1,25 @@
+package entities
+
+// DryRunExecutionID ocupa el lugar del ID de ejecución en las plantillas de un plan.
+const DryRunExecutionID = "dry-run"
+
+// ExecutionPlan es lo que lanzaría un executor para una tarea, sin lanzarlo: la
+// especificación renderizada y el manifiesto propio del executor (configuración del
+// contenedor, Job de Kubernetes o comando del agente). Los secretos van enmascarados.
+type ExecutionPlan struct {
+	WorkerType string
+	Spec       WorkerSpec
+	// Format es el formato de Manifest: "json" o "yaml".
+	Format   string
+	Manifest string
+}
+
+// MaskedCopy devuelve una copia de la tarea con los valores de los parámetros secretos
+// enmascarados en Config.Parameters.
+func (t *DevOpsTask) MaskedCopy() DevOpsTask {
+	masked := *t
+	if t.Config.Parameters != nil {
+		masked.Config.Parameters = MaskSecretParameters(t.AllParameters(), t.Config.Parameters)
+	}
+	return masked
+}
@@ -0,0 +1,103 @@
+package entities
+
+// Role agrupa los permisos que un RoleBinding concede a un sujeto.
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"   // consulta tareas y sigue sus ejecuciones
+	RoleOperator Role = "operator" // además lanza y cancela ejecuciones y responde a los formularios
+	RoleApprover Role = "approver" // además aprueba o rechaza ejecuciones
+	RoleAdmin    Role = "admin"    // además crea, modifica y borra tareas
+)
+
+// Permission es una operación del TaskService sujeta a control de acceso.
+type Permission string
+
+const (
+	PermissionView      Permission = "view"
+	PermissionSubscribe Permission = "subscribe"
+	PermissionCreate    Permission = "create"
+	PermissionUpdate    Permission = "update"
+	PermissionDelete    Permission = "delete"
+	PermissionExecute   Permission = "execute"
+	PermissionCancel    Permission = "cancel"
+	PermissionApprove   Permission = "approve"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleViewer:   {PermissionView, PermissionSubscribe},
+	RoleOperator: {PermissionView, PermissionSubscribe, PermissionExecute, PermissionCancel},
+	RoleApprover: {PermissionView, PermissionSubscribe, PermissionApprove},
+	RoleAdmin: {
+		PermissionView, PermissionSubscribe, PermissionCreate, PermissionUpdate, PermissionDelete,
+		PermissionExecute, PermissionCancel, PermissionApprove,
+	},
+}
+
+// IsValid indica si el rol es uno de los definidos.
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Grants indica si el rol concede el permiso.
+func (r Role) Grants(permission Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleBinding concede un rol a un usuario, grupo o service account sobre un tenant entero
+// o, si WorkspaceID no está vacío, solo sobre ese workspace del tenant. Un binding a un
+// grupo cubre a todos sus miembros.
+type RoleBinding struct {
+	Subject     Subject
+	Role        Role
+	TenantID    string
+	WorkspaceID string
+}
+
+// Covers indica si el ámbito del binding incluye el workspace.
+func (b RoleBinding) Covers(workspace Workspace) bool {
+	return Scope{TenantID: b.TenantID, WorkspaceID: b.WorkspaceID}.Contains(workspace)
+}
+
+// Binds indica si el binding se aplica al sujeto, directamente o a través de uno de los
+// grupos a los que pertenece.
+func (b RoleBinding) Binds(subject Subject, groups []string) bool {
+	if b.Subject == nil || subject == nil {
+		return false
+	}
+	if SubjectKind(b.Subject) == SubjectKind(subject) && b.Subject.GetID() == subject.GetID() {
+		return true
+	}
+	if _, ok := b.Subject.(Group); ok {
+		for _, group := range groups {
+			if group == b.Subject.GetID() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SubjectKind devuelve "user", "group" o "serviceAccount" según el tipo del sujeto.
+func SubjectKind(subject Subject) string {
+	switch subject.(type) {
+	case Group:
+		return "group"
+	case ServiceAccount:
+		return "serviceAccount"
+	default:
+		return "user"
+	}
+}
+
+// IsSubject indica si el tipo y el ID registrados son los del sujeto: un usuario "sre" no
+// es el grupo "sre".
+func IsSubject(kind, id string, subject Subject) bool {
+	return kind == SubjectKind(subject) && id == subject.GetID()
+}
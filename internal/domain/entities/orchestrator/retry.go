@@ -0,0 +1,78 @@
+package entities
+
+import (
+	"math"
+	"time"
+)
+
+// FailureClass clasifica el motivo por el que falló una ejecución para decidir si se reintenta.
+type FailureClass string
+
+const (
+	FailureImagePull      FailureClass = "IMAGE_PULL"     // no se pudo descargar la imagen
+	FailureNonZeroExit    FailureClass = "NON_ZERO_EXIT"  // el proceso terminó con error
+	FailureInfrastructure FailureClass = "INFRASTRUCTURE" // fallo del runtime (crear contenedor, job...)
+	FailureUnknown        FailureClass = "UNKNOWN"
+)
+
+// Valores por defecto de RetryPolicy.
+const (
+	DefaultRetryInitialBackoff = 10 * time.Second
+	DefaultRetryMaxBackoff     = 5 * time.Minute
+	DefaultRetryMultiplier     = 2.0
+)
+
+// RetryPolicy define cuántas veces y con qué espera se relanza una tarea fallida.
+type RetryPolicy struct {
+	// MaxAttempts incluye el primer intento: 3 significa un intento y dos reintentos.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter es la fracción (0-1) en la que se varía aleatoriamente cada espera.
+	Jitter float64
+	// RetryOn son las clases de fallo reintentables; vacío equivale a imagen e infraestructura.
+	RetryOn []FailureClass
+}
+
+// ShouldRetry indica si tras fallar el intento attempt (empezando en 1) con la clase dada
+// hay que lanzar otro.
+func (p *RetryPolicy) ShouldRetry(attempt int, class FailureClass) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	retryOn := p.RetryOn
+	if len(retryOn) == 0 {
+		retryOn = []FailureClass{FailureImagePull, FailureInfrastructure}
+	}
+	for _, retryable := range retryOn {
+		if retryable == class {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff devuelve la espera antes del intento attempt+1. random es un valor en [0, 1)
+// que se usa para aplicar el jitter.
+func (p *RetryPolicy) Backoff(attempt int, random float64) time.Duration {
+	initial, maxBackoff, multiplier := p.InitialBackoff, p.MaxBackoff, p.Multiplier
+	if initial <= 0 {
+		initial = DefaultRetryInitialBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultRetryMaxBackoff
+	}
+	if multiplier < 1 {
+		multiplier = DefaultRetryMultiplier
+	}
+
+	backoff := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if backoff > float64(maxBackoff) {
+		backoff = float64(maxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff *= 1 + p.Jitter*(2*random-1)
+	}
+	return time.Duration(backoff)
+}
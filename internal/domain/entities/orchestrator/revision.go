@@ -0,0 +1,106 @@
+package entities
+
+import (
+	"reflect"
+	"time"
+)
+
+// TaskRevision es una versión inmutable de la configuración de una tarea. Cada alta,
+// modificación o rollback añade una revisión nueva; las anteriores no se tocan.
+type TaskRevision struct {
+	Revision  int
+	CreatedAt time.Time
+	// Task es la configuración de la tarea en esa revisión, sin ejecuciones, aprobaciones
+	// ni historial.
+	Task DevOpsTask
+	// RolledBackFrom es la revisión restaurada cuando esta procede de un rollback.
+	RolledBackFrom int
+}
+
+// FieldChange describe un campo que difiere entre dos revisiones. Field es la ruta del
+// campo, p.ej. "Config.Retry.MaxAttempts".
+type FieldChange struct {
+	Field string
+	From  interface{}
+	To    interface{}
+}
+
+// revisionIgnoredFields no forman parte de la configuración versionada.
+var revisionIgnoredFields = map[string]bool{
+	"ID":         true,
+	"CreatedAt":  true,
+	"UpdatedAt":  true,
+	"Executions": true,
+	"Approvals":  true,
+	"Revision":   true,
+	"Revisions":  true,
+}
+
+// Snapshot devuelve una copia de la configuración de la tarea que no comparte mapas ni
+// slices con ella, para guardarla como revisión.
+func (t DevOpsTask) Snapshot() DevOpsTask {
+	snapshot := t
+	snapshot.Executions = nil
+	snapshot.Approvals = nil
+	snapshot.Revisions = nil
+	if t.Config.Parameters != nil {
+		snapshot.Config.Parameters = make(map[string]interface{}, len(t.Config.Parameters))
+		for name, value := range t.Config.Parameters {
+			snapshot.Config.Parameters[name] = value
+		}
+	}
+	if t.Config.Retry != nil {
+		retry := *t.Config.Retry
+		retry.RetryOn = append([]FailureClass(nil), t.Config.Retry.RetryOn...)
+		snapshot.Config.Retry = &retry
+	}
+	if t.Config.Matrix != nil {
+		snapshot.Config.Matrix = t.Config.Matrix.copy()
+	}
+	if t.ApprovalPolicy != nil {
+		policy := *t.ApprovalPolicy
+		policy.Approvers = append([]Subject(nil), t.ApprovalPolicy.Approvers...)
+		snapshot.ApprovalPolicy = &policy
+	}
+	if t.InputForm != nil {
+		form := *t.InputForm
+		form.Fields = append([]ParameterDefinition(nil), t.InputForm.Fields...)
+		snapshot.InputForm = &form
+	}
+	snapshot.Config.Secrets = append([]SecretReference(nil), t.Config.Secrets...)
+	snapshot.Config.Outputs = append([]OutputSpec(nil), t.Config.Outputs...)
+	snapshot.Config.Inputs = append([]ArtifactInput(nil), t.Config.Inputs...)
+	snapshot.Tags = append([]string(nil), t.Tags...)
+	snapshot.Parameters = append([]ParameterDefinition(nil), t.Parameters...)
+	return snapshot
+}
+
+// DiffTasks compara campo a campo la configuración de dos versiones de una tarea. Los
+// structs se recorren por dentro; el resto de campos se comparan enteros.
+func DiffTasks(from, to DevOpsTask) []FieldChange {
+	var changes []FieldChange
+	diffStruct("", reflect.ValueOf(from), reflect.ValueOf(to), &changes)
+	return changes
+}
+
+func diffStruct(prefix string, from, to reflect.Value, changes *[]FieldChange) {
+	for i := 0; i < from.NumField(); i++ {
+		field := from.Type().Field(i)
+		if !field.IsExported() || (prefix == "" && revisionIgnoredFields[field.Name]) {
+			continue
+		}
+		name := prefix + field.Name
+		diffValue(name, from.Field(i), to.Field(i), changes)
+	}
+}
+
+func diffValue(name string, from, to reflect.Value, changes *[]FieldChange) {
+	switch {
+	case from.Kind() == reflect.Struct && from.Type() != reflect.TypeOf(time.Time{}):
+		diffStruct(name+".", from, to, changes)
+	case from.Kind() == reflect.Ptr && from.Type().Elem().Kind() == reflect.Struct && !from.IsNil() && !to.IsNil():
+		diffStruct(name+".", from.Elem(), to.Elem(), changes)
+	case !reflect.DeepEqual(from.Interface(), to.Interface()):
+		*changes = append(*changes, FieldChange{Field: name, From: from.Interface(), To: to.Interface()})
+	}
+}
@@ -0,0 +1,16 @@
+package entities
+
+// Scope es el ámbito de una petición: un tenant entero o, si WorkspaceID no está vacío,
+// solo ese workspace del tenant.
+type Scope struct {
+	TenantID    string
+	WorkspaceID string
+}
+
+// Contains indica si el workspace está dentro del ámbito.
+func (s Scope) Contains(workspace Workspace) bool {
+	if s.TenantID != workspace.TenantID {
+		return false
+	}
+	return s.WorkspaceID == "" || s.WorkspaceID == workspace.ID
+}
@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"sort"
+	"strings"
+)
+
+// SecretReference apunta a un secreto del almacén que se inyecta como variable de entorno
+// solo al lanzar la ejecución; su valor nunca se guarda en la tarea.
+type SecretReference struct {
+	// EnvVar es la variable de entorno que recibe el valor.
+	EnvVar string
+	// Key identifica el secreto en el almacén. En Kubernetes tiene la forma <secret>/<clave>.
+	Key string
+}
+
+// RedactSecrets sustituye en text cada valor secreto por MaskedParameterValue. Los valores
+// más largos se sustituyen primero para no dejar a la vista restos de uno que contenga a otro.
+func RedactSecrets(text string, values []string) string {
+	sorted := append([]string{}, values...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, value := range sorted {
+		if value != "" {
+			text = strings.ReplaceAll(text, value, MaskedParameterValue)
+		}
+	}
+	return text
+}
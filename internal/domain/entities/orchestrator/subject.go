@@ -9,6 +9,8 @@ type User struct {
 	ID   string
 	Name string
 	Emai string
+	// Groups son los IDs de los grupos a los que pertenece el usuario.
+	Groups []string
 }
 
 func (u User) GetID() string {
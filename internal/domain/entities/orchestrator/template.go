@@ -0,0 +1,132 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var ErrUndefinedTemplateVariable = errors.New("undefined template variable")
+
+// templateExpression reconoce las expresiones ${{ nombre }}.
+var templateExpression = regexp.MustCompile(`\$\{\{(.*?)\}\}`)
+
+// WorkerSpec es lo que el executor lanza una vez sustituidas las expresiones de la plantilla.
+type WorkerSpec struct {
+	Image      string
+	Command    []string
+	Args       []string
+	Env        map[string]string
+	WorkingDir string
+}
+
+// TemplateContext son las variables disponibles en las plantillas, por su nombre completo:
+// params.<nombre>, secrets.<nombre>, execution.id, task.id, task.name, workspace.id y
+// workspace.name.
+type TemplateContext map[string]interface{}
+
+// NewTemplateContext construye las variables de una ejecución a partir de los valores de
+// Config.Parameters. Los parámetros secretos están además disponibles como secrets.<nombre>;
+// con mask se sustituyen por MaskedParameterValue, que es lo que se guarda para auditoría.
+func NewTemplateContext(task *DevOpsTask, executionID string, mask bool) TemplateContext {
+	variables := TemplateContext{
+		"execution.id":   executionID,
+		"task.id":        task.ID,
+		"task.name":      task.Name,
+		"workspace.id":   task.Workspace.ID,
+		"workspace.name": task.Workspace.Name,
+	}
+	values := task.ParameterValues()
+	for _, definition := range task.AllParameters() {
+		value, ok := values[definition.Name]
+		if !ok {
+			continue
+		}
+		if definition.Secret {
+			if mask {
+				value = MaskedParameterValue
+			}
+			variables["secrets."+definition.Name] = value
+		}
+		variables["params."+definition.Name] = value
+	}
+	return variables
+}
+
+// Render sustituye las expresiones del texto. Una variable que no existe es un error.
+func (c TemplateContext) Render(text string) (string, error) {
+	var undefined []string
+	rendered := templateExpression.ReplaceAllStringFunc(text, func(expression string) string {
+		name := strings.TrimSpace(templateExpression.FindStringSubmatch(expression)[1])
+		value, ok := c[name]
+		if !ok {
+			undefined = append(undefined, name)
+			return expression
+		}
+		return fmt.Sprint(value)
+	})
+	if len(undefined) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrUndefinedTemplateVariable, strings.Join(undefined, ", "))
+	}
+	return rendered, nil
+}
+
+func (c TemplateContext) renderAll(values []string) ([]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+	rendered := make([]string, len(values))
+	for i, value := range values {
+		var err error
+		if rendered[i], err = c.Render(value); err != nil {
+			return nil, err
+		}
+	}
+	return rendered, nil
+}
+
+// RenderWorkerSpec sustituye las plantillas de la imagen, el comando, los argumentos, el
+// entorno y el directorio de trabajo que describen los detalles del worker.
+func RenderWorkerSpec(details map[string]interface{}, variables TemplateContext) (WorkerSpec, error) {
+	var spec WorkerSpec
+	var err error
+	if image, ok := details["Image"].(string); ok {
+		if spec.Image, err = variables.Render(image); err != nil {
+			return WorkerSpec{}, fmt.Errorf("image: %w", err)
+		}
+	}
+	command, _ := details["Command"].([]string)
+	if spec.Command, err = variables.renderAll(command); err != nil {
+		return WorkerSpec{}, fmt.Errorf("command: %w", err)
+	}
+	args, _ := details["Args"].([]string)
+	if spec.Args, err = variables.renderAll(args); err != nil {
+		return WorkerSpec{}, fmt.Errorf("args: %w", err)
+	}
+	if workingDir, ok := details["WorkingDir"].(string); ok {
+		if spec.WorkingDir, err = variables.Render(workingDir); err != nil {
+			return WorkerSpec{}, fmt.Errorf("working dir: %w", err)
+		}
+	}
+	if environment, ok := details["Environment"].(map[string]string); ok && len(environment) > 0 {
+		spec.Env = make(map[string]string, len(environment))
+		for name, value := range environment {
+			if spec.Env[name], err = variables.Render(value); err != nil {
+				return WorkerSpec{}, fmt.Errorf("env %s: %w", name, err)
+			}
+		}
+	}
+	return spec, nil
+}
+
+// EnvList devuelve el entorno como NOMBRE=valor ordenado por nombre.
+func (s WorkerSpec) EnvList() []string {
+	env := make([]string, 0, len(s.Env))
+	for name, value := range s.Env {
+		env = append(env, name+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
@@ -0,0 +1,139 @@
+package entities
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookTrigger expone una tarea TaskTypeTriggered en /webhooks/<Token>. Cada entrega
+// válida que cumple las condiciones lanza una ejecución.
+type WebhookTrigger struct {
+	// Token identifica el endpoint de la tarea; se genera al crearla si está vacío.
+	Token string
+	// Secret firma las entregas (X-Hub-Signature-256, X-Hub-Signature o X-Gitlab-Token).
+	// Solo puede quedar vacío con AllowUnsigned.
+	Secret string
+	// AllowUnsigned acepta, si no hay Secret, entregas sin firmar de cualquiera que conozca
+	// el token.
+	AllowUnsigned bool
+	// Events limita los eventos aceptados (cabecera X-GitHub-Event o X-Gitlab-Event).
+	Events []string
+	// Parameters asigna a cada parámetro de la tarea una ruta del payload, p.ej. "$.ref".
+	Parameters map[string]string
+	// Conditions deben cumplirse todas para lanzar la ejecución.
+	Conditions []WebhookCondition
+}
+
+// Evaluate siempre es false: el trigger se activa con cada entrega, no con el tiempo.
+func (t *WebhookTrigger) Evaluate() bool {
+	return false
+}
+
+// WebhookCondition compara el valor de una ruta del payload con Equals o con la expresión
+// regular Matches.
+type WebhookCondition struct {
+	Path    string
+	Equals  string
+	Matches string
+}
+
+// Satisfied indica si el payload cumple la condición. Una ruta inexistente no la cumple.
+func (c WebhookCondition) Satisfied(document interface{}) (bool, error) {
+	value, ok := LookupJSONPath(document, c.Path)
+	if !ok {
+		return false, nil
+	}
+	text := fmt.Sprint(value)
+	if c.Equals != "" && text != c.Equals {
+		return false, nil
+	}
+	if c.Matches != "" {
+		return regexp.MatchString(c.Matches, text)
+	}
+	return true, nil
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDelivered WebhookDeliveryStatus = "DELIVERED" // se lanzó la ejecución
+	WebhookRejected  WebhookDeliveryStatus = "REJECTED"  // firma ausente o incorrecta
+	WebhookFiltered  WebhookDeliveryStatus = "FILTERED"  // evento o condiciones no cumplidos
+	WebhookFailed    WebhookDeliveryStatus = "FAILED"    // payload o parámetros inválidos, o error al lanzar
+)
+
+// WebhookDelivery registra cada entrega recibida y su resultado.
+type WebhookDelivery struct {
+	ID          string
+	TaskID      string
+	ReceivedAt  time.Time
+	Event       string
+	Status      WebhookDeliveryStatus
+	Reason      string
+	ExecutionID string
+	// Parameters son los valores extraídos del payload, con los secretos enmascarados.
+	Parameters map[string]interface{}
+	Payload    string
+}
+
+// WebhookTrigger devuelve el trigger de webhook de la tarea, si lo tiene.
+func (t *DevOpsTask) WebhookTrigger() (*WebhookTrigger, bool) {
+	if t.Trigger == nil {
+		return nil, false
+	}
+	trigger, ok := (*t.Trigger).(*WebhookTrigger)
+	return trigger, ok
+}
+
+// LookupJSONPath busca una ruta de estilo JSONPath ($.a.b[0].c) en un documento JSON
+// decodificado. El "$." inicial es opcional.
+func LookupJSONPath(document interface{}, path string) (interface{}, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	current := document
+	for path != "" {
+		var segment string
+		if strings.HasPrefix(path, "[") {
+			end := strings.Index(path, "]")
+			if end < 0 {
+				return nil, false
+			}
+			segment, path = path[1:end], path[end+1:]
+			index, err := strconv.Atoi(segment)
+			if err != nil {
+				// ['clave con puntos']
+				key := strings.Trim(segment, `'"`)
+				object, ok := current.(map[string]interface{})
+				if !ok {
+					return nil, false
+				}
+				if current, ok = object[key]; !ok {
+					return nil, false
+				}
+			} else {
+				array, ok := current.([]interface{})
+				if !ok || index < 0 || index >= len(array) {
+					return nil, false
+				}
+				current = array[index]
+			}
+		} else {
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			segment, path = path[:end], path[end:]
+			object, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if current, ok = object[segment]; !ok {
+				return nil, false
+			}
+		}
+		path = strings.TrimPrefix(path, ".")
+	}
+	return current, true
+}
@@ -118,7 +118,7 @@ func (a *Agent) runEventLoop(ctx context.Context, client pb.AgentServiceClient)
 
 	go func() {
 		for event := range a.executor.eventChan {
-			log.Printf("Sending event: %v", event)
+			log.Printf("Sending event %s of command %s", event.Type, event.CommandId)
 			if _, err := client.SendEvent(ctx, event); err != nil {
 				log.Printf("Error sending event: %v", err)
 			}
@@ -131,7 +131,8 @@ func (a *Agent) runEventLoop(ctx context.Context, client pb.AgentServiceClient)
 			log.Printf("Error receiving command: %v", err)
 			return err
 		}
-		log.Printf("Received command: %v", cmd)
+		// El entorno del comando puede llevar secretos: solo se registra su ID.
+		log.Printf("Received command: %s", cmd.CommandId)
 		go a.executor.Execute(ctx, cmd)
 	}
 }
@@ -190,7 +191,6 @@ func (e *CommandExecutor) Execute(ctx context.Context, cmd *pb.Command) {
 	}
 
 	output, err := command.CombinedOutput()
-	log.Printf("Log Command output: %s", output)
 	if err != nil {
 		e.eventChan <- &pb.ExecutionEvent{
 			CommandId: cmd.CommandId,
@@ -0,0 +1,226 @@
+package adapters
+
+import (
+	"context"
+	"crypto/sha256"
+	ports "devops_console/internal/ports/orchestrator"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const digestPrefix = "sha256:"
+
+// ArtifactLimits acota lo que guarda el almacén. Un valor cero no limita.
+type ArtifactLimits struct {
+	// MaxArtifactSize es el tamaño máximo de cada artefacto en bytes.
+	MaxArtifactSize int64
+	// MaxTotalSize es lo que pueden ocupar entre todos los artefactos guardados.
+	MaxTotalSize int64
+	// Retention es cuánto se conserva un contenido desde la última vez que se guardó.
+	Retention time.Duration
+}
+
+// LocalArtifactStore guarda los artefactos en disco bajo root, en
+// blobs/sha256/<2 primeros caracteres>/<hex>. La fecha de modificación de cada fichero es
+// la última vez que se guardó su contenido y es la que cuenta para la retención.
+type LocalArtifactStore struct {
+	root   string
+	limits ArtifactLimits
+	// Now permite fijar la hora en las pruebas.
+	Now func() time.Time
+	mu  sync.Mutex
+	// total es lo que ocupan los blobs guardados.
+	total int64
+}
+
+// NewLocalArtifactStore abre el almacén de root, creándolo si no existe.
+func NewLocalArtifactStore(root string, limits ArtifactLimits) (*LocalArtifactStore, error) {
+	s := &LocalArtifactStore{root: root, limits: limits, Now: time.Now}
+	if err := os.MkdirAll(s.blobsDir(), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(s.tmpDir(), 0o755); err != nil {
+		return nil, err
+	}
+	err := filepath.WalkDir(s.blobsDir(), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		s.total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *LocalArtifactStore) Put(ctx context.Context, r io.Reader) (string, int64, error) {
+	// El contenido se escribe en un temporal mientras se calcula el digest y después se
+	// mueve a su sitio, así que nunca queda un blob a medias.
+	tmp, err := os.CreateTemp(s.tmpDir(), "upload-")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	source := r
+	if s.limits.MaxArtifactSize > 0 {
+		source = io.LimitReader(r, s.limits.MaxArtifactSize+1)
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), contextReader{ctx: ctx, r: source})
+	if err != nil {
+		return "", 0, err
+	}
+	if s.limits.MaxArtifactSize > 0 && size > s.limits.MaxArtifactSize {
+		return "", 0, fmt.Errorf("%w: more than %d bytes", ports.ErrArtifactTooLarge, s.limits.MaxArtifactSize)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", 0, err
+	}
+	digest := digestPrefix + hex.EncodeToString(hash.Sum(nil))
+	path, _ := s.blobPath(digest)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.Now()
+	// Si el contenido ya estaba solo se renueva su retención.
+	if _, err := os.Stat(path); err == nil {
+		return digest, size, os.Chtimes(path, now, now)
+	}
+	if s.limits.MaxTotalSize > 0 && s.total+size > s.limits.MaxTotalSize {
+		if _, err := s.prune(ctx); err != nil {
+			return "", 0, err
+		}
+		if s.total+size > s.limits.MaxTotalSize {
+			return "", 0, fmt.Errorf("%w: %d of %d bytes in use", ports.ErrArtifactStoreFull, s.total, s.limits.MaxTotalSize)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", 0, err
+	}
+	if err := os.Chtimes(tmp.Name(), now, now); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, err
+	}
+	s.total += size
+	return digest, size, nil
+}
+
+func (s *LocalArtifactStore) Open(ctx context.Context, digest string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	path, err := s.blobPath(digest)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ports.ErrArtifactNotFound, digest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Lo que caducó y aún no se ha borrado ya no se sirve.
+	if info, err := file.Stat(); err == nil && s.expired(info) {
+		file.Close()
+		return nil, fmt.Errorf("%w: %s", ports.ErrArtifactNotFound, digest)
+	}
+	return file, nil
+}
+
+func (s *LocalArtifactStore) Prune(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.prune(ctx)
+}
+
+// TotalSize devuelve lo que ocupan los artefactos guardados.
+func (s *LocalArtifactStore) TotalSize() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.total
+}
+
+func (s *LocalArtifactStore) prune(ctx context.Context) (int, error) {
+	if s.limits.Retention <= 0 {
+		return 0, nil
+	}
+	removed := 0
+	err := filepath.WalkDir(s.blobsDir(), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if !s.expired(info) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		s.total -= info.Size()
+		removed++
+		return nil
+	})
+	return removed, err
+}
+
+func (s *LocalArtifactStore) expired(info fs.FileInfo) bool {
+	return s.limits.Retention > 0 && s.Now().Sub(info.ModTime()) > s.limits.Retention
+}
+
+func (s *LocalArtifactStore) blobsDir() string {
+	return filepath.Join(s.root, "blobs", "sha256")
+}
+
+func (s *LocalArtifactStore) tmpDir() string {
+	return filepath.Join(s.root, "tmp")
+}
+
+// blobPath devuelve dónde se guarda el digest, que tiene que ser un sha256 válido para no
+// poder salir de root.
+func (s *LocalArtifactStore) blobPath(digest string) (string, error) {
+	sum, ok := strings.CutPrefix(digest, digestPrefix)
+	if _, err := hex.DecodeString(sum); !ok || err != nil || len(sum) != sha256.Size*2 {
+		return "", fmt.Errorf("%w: invalid digest %q", ports.ErrArtifactNotFound, digest)
+	}
+	return filepath.Join(s.blobsDir(), sum[:2], sum), nil
+}
+
+// contextReader deja de leer en cuanto se cancela el contexto.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,69 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	ports "devops_console/internal/ports/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newStore crea un almacén en un directorio temporal cuyo reloj es el tiempo devuelto.
+func newStore(t *testing.T, limits ArtifactLimits) (*LocalArtifactStore, *time.Time) {
+	store, err := NewLocalArtifactStore(t.TempDir(), limits)
+	require.NoError(t, err)
+	now := time.Now()
+	store.Now = func() time.Time { return now }
+	return store, &now
+}
+
+func TestStore_DeduplicatesContent(t *testing.T) {
+	store, _ := newStore(t, ArtifactLimits{})
+	first, _, err := store.Put(context.Background(), strings.NewReader("same bytes"))
+	require.NoError(t, err)
+	second, _, err := store.Put(context.Background(), strings.NewReader("same bytes"))
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, int64(len("same bytes")), store.TotalSize())
+
+	_, err = store.Open(context.Background(), "sha256:../../etc/passwd")
+	assert.ErrorIs(t, err, ports.ErrArtifactNotFound)
+}
+
+func TestStore_EnforcesSizeLimits(t *testing.T) {
+	store, _ := newStore(t, ArtifactLimits{MaxArtifactSize: 8, MaxTotalSize: 12})
+	_, _, err := store.Put(context.Background(), strings.NewReader("123456789"))
+	assert.ErrorIs(t, err, ports.ErrArtifactTooLarge)
+
+	_, _, err = store.Put(context.Background(), strings.NewReader("12345678"))
+	require.NoError(t, err)
+	_, _, err = store.Put(context.Background(), strings.NewReader("abcdefgh"))
+	assert.ErrorIs(t, err, ports.ErrArtifactStoreFull)
+	assert.Equal(t, int64(8), store.TotalSize())
+}
+
+func TestStore_ExpiresContentAfterRetention(t *testing.T) {
+	store, now := newStore(t, ArtifactLimits{MaxTotalSize: 12, Retention: time.Hour})
+	old, _, err := store.Put(context.Background(), strings.NewReader("12345678"))
+	require.NoError(t, err)
+
+	*now = now.Add(2 * time.Hour)
+	_, err = store.Open(context.Background(), old)
+	assert.ErrorIs(t, err, ports.ErrArtifactNotFound)
+
+	// Si no cabe, el almacén hace sitio borrando lo caducado.
+	fresh, _, err := store.Put(context.Background(), bytes.NewReader([]byte("abcdefgh")))
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), store.TotalSize())
+
+	*now = now.Add(2 * time.Hour)
+	removed, err := store.Prune(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	_, err = store.Open(context.Background(), fresh)
+	assert.ErrorIs(t, err, ports.ErrArtifactNotFound)
+	assert.Zero(t, store.TotalSize())
+}
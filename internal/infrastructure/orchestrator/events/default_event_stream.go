@@ -2,13 +2,20 @@ package adapters
 
 import (
 	entities "devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
 	"log"
 	"sync"
+	"sync/atomic"
 )
 
 type TaskEventStreamImpl struct {
 	subscribers map[string][]chan entities.TaskEvent
 	mu          sync.RWMutex
+	// sending lo tiene Publish mientras envía fuera de mu, para que Unsubscribe no cierre un
+	// canal al que todavía se está enviando.
+	sending sync.RWMutex
+	// unrouted cuenta los eventos publicados sin ningún suscriptor que los recibiera.
+	unrouted atomic.Uint64
 }
 
 func NewTaskEventStream() *TaskEventStreamImpl {
@@ -26,9 +33,18 @@ func (es *TaskEventStreamImpl) Subscribe(taskExecutionID string) (<-chan entitie
 }
 
 func (es *TaskEventStreamImpl) Publish(event entities.TaskEvent) error {
+	es.sending.RLock()
+	defer es.sending.RUnlock()
 	es.mu.RLock()
 	subs, ok := es.subscribers[event.ExecutionID]
+	all := es.subscribers[ports.AllExecutions]
 	es.mu.RUnlock()
+	if len(all) == 0 && len(subs) == 0 {
+		es.unrouted.Add(1)
+	}
+	for _, ch := range all {
+		ch <- event
+	}
 	if ok {
 		for _, ch := range subs {
 			ch <- event
@@ -46,6 +62,28 @@ func (es *TaskEventStreamImpl) Publish(event entities.TaskEvent) error {
 	return nil
 }
 
+// Unsubscribe espera a que terminen los envíos en curso antes de cerrar el canal, así que
+// quien lo consume tiene que seguir leyéndolo hasta que se cierre.
+func (s *TaskEventStreamImpl) Unsubscribe(taskExecutionID string, events <-chan entities.TaskEvent) {
+	s.mu.Lock()
+	var found chan entities.TaskEvent
+	subs := s.subscribers[taskExecutionID]
+	for i, ch := range subs {
+		if ch == events {
+			found = ch
+			s.subscribers[taskExecutionID] = append(subs[:i:i], subs[i+1:]...)
+			break
+		}
+	}
+	s.mu.Unlock()
+	if found == nil {
+		return
+	}
+	s.sending.Lock()
+	close(found)
+	s.sending.Unlock()
+}
+
 func (s *TaskEventStreamImpl) Close(taskExecutionID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -56,3 +94,21 @@ func (s *TaskEventStreamImpl) Close(taskExecutionID string) {
 		delete(s.subscribers, taskExecutionID)
 	}
 }
+
+// Subscribers devuelve el número de suscripciones abiertas, incluidas las de AllExecutions.
+func (s *TaskEventStreamImpl) Subscribers() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	count := 0
+	for _, subs := range s.subscribers {
+		count += len(subs)
+	}
+	return count
+}
+
+// Unrouted devuelve cuántos eventos se han publicado sin que nadie estuviera suscrito a
+// ellos (p.ej. los que llegan de un agente después de que su ejecución terminara). Publish
+// espera a cada suscriptor, así que los suscritos no pierden eventos.
+func (s *TaskEventStreamImpl) Unrouted() uint64 {
+	return s.unrouted.Load()
+}
@@ -0,0 +1,119 @@
+package adapters
+
+import (
+	entities "devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxPendingOutput limita lo que PublishOutput guarda de una línea sin terminar; al pasarlo
+// se publica aunque no haya llegado el salto de línea.
+const maxPendingOutput = 64 * 1024
+
+// RedactingTaskEventStream envuelve un TaskEventStream y oculta los valores secretos de
+// cada ejecución en sus eventos TaskOutput antes de publicarlos. Un valor solo se encuentra
+// si está entero en un payload: la salida que llega a trozos, que puede partirlo, se
+// publica con PublishOutput.
+type RedactingTaskEventStream struct {
+	ports.TaskEventStream
+	secrets map[string][]string
+	// pending guarda por ejecución la última línea sin terminar de PublishOutput.
+	pending map[string]string
+	mu      sync.RWMutex
+}
+
+func NewRedactingTaskEventStream(stream ports.TaskEventStream) *RedactingTaskEventStream {
+	return &RedactingTaskEventStream{
+		TaskEventStream: stream,
+		secrets:         make(map[string][]string),
+		pending:         make(map[string]string),
+	}
+}
+
+// Redact registra los valores que hay que ocultar en la salida de la ejecución. Se olvidan
+// cuando se publica su evento terminal o se cierra la ejecución.
+func (s *RedactingTaskEventStream) Redact(executionID string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secrets[executionID] = append(s.secrets[executionID], values...)
+}
+
+func (s *RedactingTaskEventStream) Publish(event entities.TaskEvent) error {
+	if _, terminal := entities.StatusFromEventType(event.EventType); terminal {
+		s.flushOutput(event.ExecutionID)
+		defer s.Forget(event.ExecutionID)
+	}
+	return s.publish(event)
+}
+
+func (s *RedactingTaskEventStream) publish(event entities.TaskEvent) error {
+	s.mu.RLock()
+	values := s.secrets[event.ExecutionID]
+	s.mu.RUnlock()
+
+	if event.EventType == entities.EventTypeTaskOutput && len(values) > 0 {
+		switch payload := event.Payload.(type) {
+		case string:
+			event.Payload = entities.RedactSecrets(payload, values)
+		case []byte:
+			event.Payload = []byte(entities.RedactSecrets(string(payload), values))
+		}
+	}
+	return s.TaskEventStream.Publish(event)
+}
+
+// PublishOutput publica la salida que llega a trozos línea a línea, cada una en un evento
+// TaskOutput, para que ningún secreto quede partido entre dos eventos. La última línea sin
+// terminar se publica con el siguiente trozo o con el evento terminal de la ejecución.
+func (s *RedactingTaskEventStream) PublishOutput(executionID string, chunk string, timestamp time.Time) error {
+	s.mu.Lock()
+	text := s.pending[executionID] + chunk
+	lines := strings.Split(text, "\n")
+	last := len(lines) - 1
+	if len(lines[last]) > maxPendingOutput {
+		last++
+	}
+	if last < len(lines) {
+		s.pending[executionID] = lines[last]
+	} else {
+		delete(s.pending, executionID)
+	}
+	s.mu.Unlock()
+
+	for _, line := range lines[:last] {
+		if err := s.publish(entities.TaskEvent{ExecutionID: executionID, EventType: entities.EventTypeTaskOutput, Payload: line, Timestamp: timestamp}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushOutput publica la línea sin terminar de PublishOutput, si la hay.
+func (s *RedactingTaskEventStream) flushOutput(executionID string) {
+	s.mu.Lock()
+	line, ok := s.pending[executionID]
+	delete(s.pending, executionID)
+	s.mu.Unlock()
+	if ok && line != "" {
+		s.publish(entities.TaskEvent{ExecutionID: executionID, EventType: entities.EventTypeTaskOutput, Payload: line, Timestamp: time.Now()})
+	}
+}
+
+func (s *RedactingTaskEventStream) Close(taskExecutionID string) {
+	s.flushOutput(taskExecutionID)
+	s.Forget(taskExecutionID)
+	s.TaskEventStream.Close(taskExecutionID)
+}
+
+// Forget descarta los valores registrados para la ejecución.
+func (s *RedactingTaskEventStream) Forget(executionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.secrets, executionID)
+	delete(s.pending, executionID)
+}
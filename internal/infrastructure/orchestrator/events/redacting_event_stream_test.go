@@ -0,0 +1,40 @@
+package adapters
+
+import (
+	"devops_console/internal/domain/entities/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRedactingTaskEventStream_MasksOutput(t *testing.T) {
+	stream := NewRedactingTaskEventStream(NewTaskEventStream())
+	events, err := stream.Subscribe("execution-1")
+	require.NoError(t, err)
+
+	stream.Redact("execution-1", []string{"hunter2"})
+	require.NoError(t, stream.Publish(entities.TaskEvent{ExecutionID: "execution-1", EventType: entities.EventTypeTaskOutput, Payload: "connecting with hunter2"}))
+	require.NoError(t, stream.Publish(entities.TaskEvent{ExecutionID: "execution-1", EventType: entities.EventTypeTaskCompleted}))
+
+	output := <-events
+	assert.Equal(t, "connecting with "+entities.MaskedParameterValue, output.Payload)
+	assert.Equal(t, entities.EventTypeTaskCompleted, (<-events).EventType)
+}
+
+func TestRedactingTaskEventStream_MasksSecretsSplitAcrossChunks(t *testing.T) {
+	stream := NewRedactingTaskEventStream(NewTaskEventStream())
+	events, err := stream.Subscribe("execution-1")
+	require.NoError(t, err)
+
+	stream.Redact("execution-1", []string{"hunter2"})
+	require.NoError(t, stream.PublishOutput("execution-1", "login ok\nconnecting with hun", time.Now()))
+	require.NoError(t, stream.PublishOutput("execution-1", "ter2\nusing hunt", time.Now()))
+	require.NoError(t, stream.PublishOutput("execution-1", "er2", time.Now()))
+	require.NoError(t, stream.Publish(entities.TaskEvent{ExecutionID: "execution-1", EventType: entities.EventTypeTaskCompleted}))
+
+	assert.Equal(t, "login ok", (<-events).Payload)
+	assert.Equal(t, "connecting with "+entities.MaskedParameterValue, (<-events).Payload)
+	assert.Equal(t, "using "+entities.MaskedParameterValue, (<-events).Payload)
+	assert.Equal(t, entities.EventTypeTaskCompleted, (<-events).EventType)
+}
@@ -0,0 +1,263 @@
+package adapters
+
+import (
+	"archive/tar"
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ArtifactSync mueve artefactos entre los contenedores y Store: deja los TaskConfig.Inputs
+// antes de arrancar el contenedor y recoge los TaskConfig.Outputs cuando termina. Sin él
+// las salidas se pierden al borrar el contenedor y las tareas con inputs no se lanzan.
+type ArtifactSync struct {
+	Files ports.FileSyncService
+	Store ports.ArtifactStore
+}
+
+// Collect copia cada salida a un directorio temporal con SyncFromContainer y la guarda en
+// el almacén; los directorios se guardan como tar. Una salida que no se puede recoger no
+// impide recoger las demás: su error se devuelve junto a los artefactos guardados.
+func (c *ArtifactSync) Collect(ctx context.Context, containerID string, outputs []entities.OutputSpec) ([]entities.Artifact, []error) {
+	if c == nil || len(outputs) == 0 {
+		return nil, nil
+	}
+	var artifacts []entities.Artifact
+	var errs []error
+	for _, output := range outputs {
+		artifact, err := c.collect(ctx, containerID, output)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("collecting output %q from %s: %w", output.Name, output.Path, err))
+			continue
+		}
+		artifacts = append(artifacts, artifact)
+	}
+	return artifacts, errs
+}
+
+func (c *ArtifactSync) collect(ctx context.Context, containerID string, output entities.OutputSpec) (entities.Artifact, error) {
+	dir, err := os.MkdirTemp("", "artifact-")
+	if err != nil {
+		return entities.Artifact{}, err
+	}
+	defer os.RemoveAll(dir)
+	if err := c.Files.SyncFromContainer(ctx, containerID, output.Path, dir); err != nil {
+		return entities.Artifact{}, err
+	}
+	local := filepath.Join(dir, path.Base(output.Path))
+	info, err := os.Stat(local)
+	if err != nil {
+		return entities.Artifact{}, err
+	}
+
+	artifact := entities.Artifact{Name: output.Name, Path: output.Path, Type: artifactType(output.Path)}
+	var content io.Reader
+	if info.IsDir() {
+		artifact.Type = entities.ArtifactTypeTar
+		reader, writer := io.Pipe()
+		go func() { writer.CloseWithError(writeTar(writer, local)) }()
+		defer reader.Close()
+		content = reader
+	} else {
+		file, err := os.Open(local)
+		if err != nil {
+			return entities.Artifact{}, err
+		}
+		defer file.Close()
+		content = file
+	}
+	artifact.Digest, artifact.Size, err = c.Store.Put(ctx, content)
+	if err != nil {
+		return entities.Artifact{}, err
+	}
+	artifact.CreatedAt = time.Now()
+	return artifact, nil
+}
+
+// Inject deja en el contenedor el artefacto de cada input en su Path. Todos los inputs se
+// preparan en un directorio local que reproduce las rutas del contenedor y se copian de
+// una vez desde la raíz; los artefactos empaquetados se desempaquetan en su Path.
+func (c *ArtifactSync) Inject(ctx context.Context, containerID string, inputs []entities.ArtifactInput) error {
+	if len(inputs) == 0 {
+		return nil
+	}
+	if c == nil {
+		return fmt.Errorf("task declares inputs but the executor has no artifact store")
+	}
+	dir, err := stagingDir()
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	for _, input := range inputs {
+		if err := c.stage(ctx, dir, input); err != nil {
+			return fmt.Errorf("input %q of task %q: %w", input.Artifact, input.TaskID, err)
+		}
+	}
+	return c.Files.SyncToContainer(ctx, dir, "/", containerID)
+}
+
+func (c *ArtifactSync) stage(ctx context.Context, dir string, input entities.ArtifactInput) error {
+	if input.Source == nil {
+		return fmt.Errorf("artifact not resolved")
+	}
+	content, err := c.Store.Open(ctx, input.Source.Digest)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	target := filepath.Join(dir, filepath.FromSlash(path.Clean(input.Path)))
+	if input.Source.Type == entities.ArtifactTypeTar {
+		return extractTar(content, target)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	return writeFile(target, content, 0o644)
+}
+
+// inputsReadyPath es el fichero que se crea en el contenedor cuando ya tiene sus inputs,
+// para los executors cuyo contenedor arranca antes de recibirlos.
+const inputsReadyPath = "/.inputs-ready"
+
+// waitForInputs envuelve command para que no empiece hasta que aparezca inputsReadyPath.
+func waitForInputs(command []string) []string {
+	script := fmt.Sprintf(`while [ ! -f %s ]; do sleep 1; done; exec "$@"`, inputsReadyPath)
+	return append([]string{"sh", "-c", script, "sh"}, command...)
+}
+
+// signalInputsReady crea inputsReadyPath en el contenedor.
+func (c *ArtifactSync) signalInputsReady(ctx context.Context, containerID string) error {
+	dir, err := stagingDir()
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(inputsReadyPath)), nil, 0o644); err != nil {
+		return err
+	}
+	return c.Files.SyncToContainer(ctx, dir, "/", containerID)
+}
+
+// stagingDir crea el directorio donde se preparan los inputs. Hace de raíz del
+// contenedor, así que tiene los permisos de una.
+func stagingDir() (string, error) {
+	dir, err := os.MkdirTemp("", "inputs-")
+	if err != nil {
+		return "", err
+	}
+	if err := os.Chmod(dir, 0o755); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+func writeFile(name string, content io.Reader, mode fs.FileMode) error {
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// extractTar desempaqueta en dir un tar creado por writeTar. Las entradas que saldrían de
+// dir se rechazan.
+func extractTar(r io.Reader, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path %q in archive", header.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := writeFile(target, tr, header.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// artifactType deduce el tipo MIME por la extensión.
+func artifactType(name string) string {
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// writeTar empaqueta el contenido de dir con rutas relativas a él.
+func writeTar(w io.Writer, dir string) error {
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || name == dir {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relative)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tw, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
@@ -0,0 +1,55 @@
+package adapters
+
+import (
+	"sync"
+	"time"
+)
+
+// jobDeadline llama a expire cuando se agota el JobTimeout de una ejecución. El tiempo que
+// la tarea pasa en pausa, esperando los datos del operador, no cuenta.
+type jobDeadline struct {
+	mu        sync.Mutex
+	expire    func()
+	remaining time.Duration
+	started   time.Time
+	timer     *time.Timer // nil mientras está en pausa
+	stopped   bool
+}
+
+func newJobDeadline(timeout time.Duration, expire func()) *jobDeadline {
+	d := &jobDeadline{expire: expire, remaining: timeout}
+	d.resume()
+	return d
+}
+
+// pause detiene la cuenta. No hace nada si ya está en pausa o si el plazo ha vencido.
+func (d *jobDeadline) pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer == nil || !d.timer.Stop() {
+		return
+	}
+	d.remaining -= time.Since(d.started)
+	d.timer = nil
+}
+
+// resume sigue la cuenta con el tiempo que quedaba al pausarla.
+func (d *jobDeadline) resume() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil || d.stopped {
+		return
+	}
+	d.started = time.Now()
+	d.timer = time.AfterFunc(d.remaining, d.expire)
+}
+
+// stop descarta el plazo cuando la ejecución termina.
+func (d *jobDeadline) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopped = true
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+}
@@ -1,24 +1,59 @@
 package adapters
 
 import (
+	"archive/tar"
 	"bufio"
+	"bytes"
 	"context"
 	"devops_console/internal/domain/entities/orchestrator"
+	eventstream "devops_console/internal/infrastructure/orchestrator/events"
+	secrets "devops_console/internal/infrastructure/orchestrator/secrets"
 	ports "devops_console/internal/ports/orchestrator"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/docker/docker/api/types/container"
 	containerImage "github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/google/uuid"
 	"io"
+	"maps"
 	"os"
+	"strings"
+	"sync"
 	"time"
 )
 
+const (
+	// inputRequestLine es la línea que escribe la tarea en su salida cuando necesita los
+	// datos del operador. Los recibe en inputPath, como un objeto JSON, y debe esperar a
+	// que aparezca el fichero.
+	inputRequestLine = "::input-required::"
+	inputPath        = "/devops/input.json"
+)
+
+// errJobTimeout es la causa con la que se cancela una ejecución que agota su JobTimeout.
+var errJobTimeout = errors.New("job timeout exceeded")
+
 type DockerTaskExecutor struct {
-	client         *client.Client
-	eventStream    ports.TaskEventStream
+	lifetime
+	client      *client.Client
+	eventStream *eventstream.RedactingTaskEventStream
+	// mu protege taskExecutions y las ejecuciones que guarda, que cambian desde la
+	// goroutine de cada contenedor mientras se consultan o cancelan.
+	mu             sync.Mutex
 	taskExecutions map[string]*entities.TaskExecution
+	// deadlines son los JobTimeout de las ejecuciones en curso, que se pausan mientras la
+	// tarea espera los datos del operador.
+	deadlines map[string]*jobDeadline
+	// SecretStore resuelve TaskConfig.Secrets; sin él las tareas con secretos no se lanzan.
+	SecretStore ports.SecretStore
+	// Artifacts deja los inputs de la tarea antes de arrancar el contenedor y recoge sus
+	// salidas antes de borrarlo.
+	Artifacts *ArtifactSync
+	// Metrics, si se configura, recibe lo que tardan en descargarse las imágenes.
+	Metrics ports.ExecutionMetrics
 }
 
 func NewDockerTaskExecutor(eventStream ports.TaskEventStream) (*DockerTaskExecutor, error) {
@@ -29,8 +64,9 @@ func NewDockerTaskExecutor(eventStream ports.TaskEventStream) (*DockerTaskExecut
 
 	return &DockerTaskExecutor{
 		client:         cli,
-		eventStream:    eventStream,
+		eventStream:    eventstream.NewRedactingTaskEventStream(eventStream),
 		taskExecutions: make(map[string]*entities.TaskExecution),
+		deadlines:      make(map[string]*jobDeadline),
 	}, nil
 }
 
@@ -39,8 +75,24 @@ func (e *DockerTaskExecutor) ExecuteTask(ctx context.Context, task *entities.Dev
 	if !ok {
 		timeout = 30 * time.Second // Default value
 	}
-	ctx, cancel := context.WithTimeout(ctx, timeout)
 	executionID := uuid.New().String()
+	spec, err := entities.RenderWorkerSpec(task.Worker.GetDetails(), entities.NewTemplateContext(task, executionID, false))
+	if err != nil {
+		return "", err
+	}
+	if len(task.Config.Inputs) > 0 && e.Artifacts == nil {
+		return "", fmt.Errorf("task %s declares inputs but the executor has no artifact store", task.ID)
+	}
+	// Los secretos se resuelven en el último momento y solo viven en el entorno del contenedor.
+	secretEnv, secretValues, err := secrets.ResolveSecrets(ctx, e.SecretStore, task.Config.Secrets)
+	if err != nil {
+		return "", err
+	}
+	e.eventStream.Redact(executionID, append(secretValues, task.SecretParameterValues()...))
+	// La ejecución no se cancela con la petición que la lanza, solo por timeout o con Shutdown.
+	ctx, release := e.detach(ctx)
+	ctx, cancel := context.WithCancelCause(ctx)
+	deadline := newJobDeadline(timeout, func() { cancel(errJobTimeout) })
 	taskExecution := &entities.TaskExecution{
 		ID:           executionID,
 		DevOpsTaskID: task.ID,
@@ -48,83 +100,216 @@ func (e *DockerTaskExecutor) ExecuteTask(ctx context.Context, task *entities.Dev
 		StartedAt:    time.Now(),
 	}
 
+	e.mu.Lock()
 	e.taskExecutions[executionID] = taskExecution
+	e.deadlines[executionID] = deadline
+	e.mu.Unlock()
 
 	go func() {
-		defer cancel()
-		e.runTask(ctx, task, taskExecution)
+		defer release()
+		defer cancel(nil)
+		defer e.stopDeadline(executionID)
+		e.runTask(ctx, task, spec, secretEnv, taskExecution)
 	}()
 
 	return executionID, nil
 }
 
-func (e *DockerTaskExecutor) runTask(ctx context.Context, task *entities.DevOpsTask, taskExecution *entities.TaskExecution) {
-	image := task.Worker.GetDetails()["Image"].(string)
+func (e *DockerTaskExecutor) runTask(ctx context.Context, task *entities.DevOpsTask, spec entities.WorkerSpec, secretEnv []string, taskExecution *entities.TaskExecution) {
+	image := spec.Image
 
 	// Pull the image if it does not exist
 	_, _, err := e.client.ImageInspectWithRaw(ctx, image)
 	if err != nil {
 		if client.IsErrNotFound(err) {
 			e.publishEvent(taskExecution.ID, entities.EventTypeTaskProgress, fmt.Sprintf("Pulling image: %s", image))
+			pullStarted := time.Now()
 			out, err := e.client.ImagePull(ctx, image, containerImage.PullOptions{})
 			if err != nil {
-				e.updateTaskExecutionStatus(taskExecution.ID, entities.TaskFailed, fmt.Sprintf("Failed to pull image: %v", err))
+				e.failTaskExecution(taskExecution.ID, entities.FailureImagePull, fmt.Sprintf("Failed to pull image: %v", err))
 				return
 			}
 			defer out.Close()
-			io.Copy(os.Stdout, out)
+			// La descarga no termina hasta que se lee todo su progreso.
+			if _, err := io.Copy(os.Stdout, out); err == nil && e.Metrics != nil {
+				e.Metrics.ImagePulled(task.Worker.GetType(), time.Since(pullStarted))
+			}
 		} else {
-			e.updateTaskExecutionStatus(taskExecution.ID, entities.TaskFailed, fmt.Sprintf("Failed to inspect image: %v", err))
+			e.failTaskExecution(taskExecution.ID, entities.FailureImagePull, fmt.Sprintf("Failed to inspect image: %v", err))
 			return
 		}
 	}
 
-	config := &container.Config{
-		Image: image,
-		Cmd:   task.Worker.GetDetails()["Command"].([]string),
-		Env:   getDockerEnvVars(task.Worker.GetDetails()),
-	}
-
-	resp, err := e.client.ContainerCreate(ctx, config, nil, nil, nil, "")
+	config, hostConfig := containerConfig(task, spec, secretEnv)
+	resp, err := e.client.ContainerCreate(ctx, config, hostConfig, nil, nil, "")
 	if err != nil {
-		e.updateTaskExecutionStatus(taskExecution.ID, entities.TaskFailed, fmt.Sprintf("Failed to create container: %v", err))
+		e.failTaskExecution(taskExecution.ID, entities.FailureInfrastructure, fmt.Sprintf("Failed to create container: %v", err))
 		return
 	}
 
 	containerID := resp.ID
 	defer e.cleanup(ctx, containerID)
 
+	// Almacenar detalles específicos del ejecutor
+	e.mu.Lock()
+	taskExecution.ExecutionDetails = map[string]interface{}{
+		"ContainerID": containerID,
+	}
+	e.mu.Unlock()
+
+	if err := e.Artifacts.Inject(ctx, containerID, task.Config.Inputs); err != nil {
+		e.failTaskExecution(taskExecution.ID, entities.FailureInfrastructure, fmt.Sprintf("Failed to inject inputs: %v", err))
+		return
+	}
+
 	if err := e.client.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
-		e.updateTaskExecutionStatus(taskExecution.ID, entities.TaskFailed, fmt.Sprintf("Failed to start container: %v", err))
+		e.failTaskExecution(taskExecution.ID, entities.FailureInfrastructure, fmt.Sprintf("Failed to start container: %v", err))
 		return
 	}
 
 	e.publishEvent(taskExecution.ID, entities.EventTypeTaskStarted, "Container is started")
 
 	if err := e.streamContainerLogs(ctx, containerID, taskExecution); err != nil {
-		e.publishEvent(taskExecution.ID, entities.EventTypeTaskError, fmt.Sprintf("Error streaming logs: %v", err))
+		e.publishEvent(taskExecution.ID, entities.EventTypeTaskWarning, fmt.Sprintf("Error streaming logs: %v", err))
 	}
 
 	statusCh, errCh := e.client.ContainerWait(ctx, containerID, container.WaitConditionNotRunning)
 	select {
 	case err := <-errCh:
 		if err != nil {
-			e.updateTaskExecutionStatus(taskExecution.ID, entities.TaskFailed, fmt.Sprintf("Container wait error: %v", err))
+			if ctx.Err() != nil {
+				err = context.Cause(ctx)
+			}
+			e.failTaskExecution(taskExecution.ID, entities.FailureInfrastructure, fmt.Sprintf("Container wait error: %v", err))
+			return
+		}
+	case status := <-statusCh:
+		e.mu.Lock()
+		taskExecution.ExecutionDetails["ExitCode"] = status.StatusCode
+		e.mu.Unlock()
+		// Las salidas se recogen también si falla: los informes suelen explicar el fallo.
+		e.collectArtifacts(ctx, containerID, task, taskExecution)
+		if status.StatusCode != 0 {
+			e.failTaskExecution(taskExecution.ID, entities.FailureNonZeroExit, fmt.Sprintf("Container exited with code %d", status.StatusCode))
 			return
 		}
-	case <-statusCh:
 		e.updateTaskExecutionStatus(taskExecution.ID, entities.TaskSucceeded, "")
 	}
 }
 
+// collectArtifacts guarda las salidas declaradas por la tarea; las que no se pueden
+// recoger se notifican como progreso sin hacer fallar la ejecución.
+func (e *DockerTaskExecutor) collectArtifacts(ctx context.Context, containerID string, task *entities.DevOpsTask, taskExecution *entities.TaskExecution) {
+	artifacts, errs := e.Artifacts.Collect(ctx, containerID, task.Config.Outputs)
+	e.mu.Lock()
+	taskExecution.Artifacts = artifacts
+	e.mu.Unlock()
+	for _, err := range errs {
+		e.publishEvent(taskExecution.ID, entities.EventTypeTaskProgress, err.Error())
+	}
+}
+
+// CollectsOutputs indica si hay almacén donde guardar las salidas.
+func (e *DockerTaskExecutor) CollectsOutputs() bool {
+	return e.Artifacts != nil
+}
+
+// InjectsInputs indica si hay almacén del que sacar los inputs.
+func (e *DockerTaskExecutor) InjectsInputs() bool {
+	return e.Artifacts != nil
+}
+
+// ProvideInput deja en inputPath los datos del operador que pidió la tarea.
+func (e *DockerTaskExecutor) ProvideInput(ctx context.Context, executionID string, values map[string]interface{}, secrets []string) error {
+	e.mu.Lock()
+	var containerID string
+	if state, ok := e.taskExecutions[executionID]; ok {
+		containerID, _ = state.ExecutionDetails["ContainerID"].(string)
+	}
+	e.mu.Unlock()
+	if containerID == "" {
+		return fmt.Errorf("task execution %s has no container", executionID)
+	}
+	e.eventStream.Redact(executionID, secrets)
+
+	content, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	header := &tar.Header{Name: strings.TrimPrefix(inputPath, "/"), Mode: 0o644, Size: int64(len(content)), ModTime: time.Now()}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := tw.Write(content); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := e.client.CopyToContainer(ctx, containerID, "/", &archive, container.CopyToContainerOptions{}); err != nil {
+		return err
+	}
+	// Con los datos en el contenedor la tarea sigue y vuelve a contar su JobTimeout.
+	e.mu.Lock()
+	deadline := e.deadlines[executionID]
+	e.mu.Unlock()
+	if deadline != nil {
+		deadline.resume()
+	}
+	return nil
+}
+
+// pauseDeadline deja de contar el JobTimeout de la ejecución hasta que llegan los datos
+// del operador.
+func (e *DockerTaskExecutor) pauseDeadline(executionID string) {
+	e.mu.Lock()
+	deadline := e.deadlines[executionID]
+	e.mu.Unlock()
+	if deadline != nil {
+		deadline.pause()
+	}
+}
+
+func (e *DockerTaskExecutor) stopDeadline(executionID string) {
+	e.mu.Lock()
+	deadline := e.deadlines[executionID]
+	delete(e.deadlines, executionID)
+	e.mu.Unlock()
+	if deadline != nil {
+		deadline.stop()
+	}
+}
+
+// failTaskExecution marca la ejecución como fallida indicando la clase de fallo, que usa
+// la política de reintentos.
+func (e *DockerTaskExecutor) failTaskExecution(executionID string, class entities.FailureClass, errMsg string) {
+	e.mu.Lock()
+	if taskExecution, ok := e.taskExecutions[executionID]; ok {
+		taskExecution.FailureClass = class
+	}
+	e.mu.Unlock()
+	e.updateTaskExecutionStatus(executionID, entities.TaskFailed, errMsg)
+}
+
 func (e *DockerTaskExecutor) updateTaskExecutionStatus(executionID string, status entities.TaskStatus, errMsg string) {
+	var details map[string]interface{}
+	var class entities.FailureClass
+	var artifacts []entities.Artifact
+	e.mu.Lock()
 	if taskExecution, ok := e.taskExecutions[executionID]; ok {
 		taskExecution.Status = status
 		taskExecution.FinishedAt = time.Now()
 		if errMsg != "" {
 			taskExecution.Error = errMsg
 		}
+		// Los suscriptores reciben una copia: el mapa de la ejecución sigue cambiando.
+		details = maps.Clone(taskExecution.ExecutionDetails)
+		class = taskExecution.FailureClass
+		artifacts = taskExecution.Artifacts
 	}
+	e.mu.Unlock()
 
 	typeEvent := entities.EventTypeTaskProgress
 	if status == entities.TaskSucceeded {
@@ -136,8 +321,11 @@ func (e *DockerTaskExecutor) updateTaskExecutionStatus(executionID string, statu
 	}
 
 	e.publishEvent(executionID, typeEvent, TaskProgressPayload{
-		Status: status,
-		Error:  errMsg,
+		Status:           status,
+		Error:            errMsg,
+		ExecutionDetails: details,
+		FailureClass:     class,
+		Artifacts:        artifacts,
 	})
 }
 
@@ -148,9 +336,12 @@ func (e *DockerTaskExecutor) streamContainerLogs(ctx context.Context, containerI
 	}
 	defer out.Close()
 
-	scanner := bufio.NewScanner(out)
-	for scanner.Scan() {
-		line := scanner.Text()
+	return scanContainerLogs(out, func(line string) {
+		if line == inputRequestLine {
+			e.pauseDeadline(taskExecution.ID)
+			e.publishEvent(taskExecution.ID, entities.EventTypeTaskInputRequired, nil)
+			return
+		}
 		event := entities.TaskEvent{
 			ExecutionID: taskExecution.ID,
 			Payload:     line,
@@ -158,12 +349,27 @@ func (e *DockerTaskExecutor) streamContainerLogs(ctx context.Context, containerI
 			EventType:   entities.EventTypeTaskOutput,
 		}
 		e.eventStream.Publish(event)
-	}
+	})
+}
 
+// scanContainerLogs pasa a handle cada línea de los logs de un contenedor sin TTY, que
+// Docker multiplexa en tramas con una cabecera de 8 bytes por escritura en stdout o stderr.
+func scanContainerLogs(logs io.Reader, handle func(line string)) error {
+	reader, writer := io.Pipe()
+	go func() {
+		_, err := stdcopy.StdCopy(writer, writer, logs)
+		writer.CloseWithError(err)
+	}()
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		handle(scanner.Text())
+	}
 	if err := scanner.Err(); err != nil {
+		// Desbloquea a StdCopy si se queda escribiendo.
+		reader.CloseWithError(err)
 		return err
 	}
-
 	return nil
 }
 
@@ -172,6 +378,8 @@ func (e *DockerTaskExecutor) cleanup(ctx context.Context, containerID string) er
 }
 
 func (e *DockerTaskExecutor) GetTaskStatus(ctx context.Context, taskExecutionID string) (entities.TaskStatus, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	if state, ok := e.taskExecutions[taskExecutionID]; ok {
 		return state.Status, nil
 	}
@@ -179,10 +387,20 @@ func (e *DockerTaskExecutor) GetTaskStatus(ctx context.Context, taskExecutionID
 }
 
 func (e *DockerTaskExecutor) CancelTask(ctx context.Context, executionID string) error {
-	if state, ok := e.taskExecutions[executionID]; ok {
-		return e.cleanup(ctx, state.ID)
+	e.mu.Lock()
+	state, ok := e.taskExecutions[executionID]
+	var containerID string
+	if ok {
+		containerID, _ = state.ExecutionDetails["ContainerID"].(string)
+	}
+	e.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("task execution ID not found")
+	}
+	if containerID == "" {
+		return fmt.Errorf("container for task execution %s not created yet", executionID)
 	}
-	return fmt.Errorf("task execution ID not found")
+	return e.cleanup(ctx, containerID)
 }
 
 func (e *DockerTaskExecutor) SubscribeToTaskEvents(taskExecutionID string) (<-chan entities.TaskEvent, error) {
@@ -200,6 +418,54 @@ func (e *DockerTaskExecutor) publishEvent(executionID string, eventType entities
 	e.eventStream.Publish(event)
 }
 
+// Plan devuelve la configuración del contenedor que crearía ExecuteTask, sin tocar Docker.
+// Los secretos se comprueban pero aparecen enmascarados.
+func (e *DockerTaskExecutor) Plan(ctx context.Context, task *entities.DevOpsTask) (entities.ExecutionPlan, error) {
+	masked := task.MaskedCopy()
+	spec, err := entities.RenderWorkerSpec(task.Worker.GetDetails(), entities.NewTemplateContext(&masked, entities.DryRunExecutionID, true))
+	if err != nil {
+		return entities.ExecutionPlan{}, err
+	}
+	secretEnv, err := secrets.PlanSecrets(ctx, e.SecretStore, task.Config.Secrets)
+	if err != nil {
+		return entities.ExecutionPlan{}, err
+	}
+	config, hostConfig := containerConfig(&masked, spec, secretEnv)
+	manifest, err := json.MarshalIndent(struct {
+		Config     *container.Config
+		HostConfig *container.HostConfig
+	}{config, hostConfig}, "", "  ")
+	if err != nil {
+		return entities.ExecutionPlan{}, err
+	}
+	return entities.ExecutionPlan{
+		WorkerType: task.Worker.GetType(),
+		Spec:       spec,
+		Format:     "json",
+		Manifest:   string(manifest),
+	}, nil
+}
+
+// containerConfig construye la configuración con la que se crea el contenedor de la tarea.
+func containerConfig(task *entities.DevOpsTask, spec entities.WorkerSpec, secretEnv []string) (*container.Config, *container.HostConfig) {
+	config := &container.Config{
+		Image:      spec.Image,
+		Cmd:        append(append([]string{}, spec.Command...), spec.Args...),
+		Env:        dockerEnv(task, spec, secretEnv),
+		WorkingDir: spec.WorkingDir,
+	}
+	return config, &container.HostConfig{}
+}
+
+// dockerEnv une el entorno del worker, el renderizado desde la plantilla, los parámetros y
+// los secretos resueltos.
+func dockerEnv(task *entities.DevOpsTask, spec entities.WorkerSpec, secretEnv []string) []string {
+	env := append([]string{}, getDockerEnvVars(task.Worker.GetDetails())...)
+	env = append(env, spec.EnvList()...)
+	env = append(env, entities.ParameterEnv(task.ParameterValues())...)
+	return append(env, secretEnv...)
+}
+
 func getDockerEnvVars(parameters map[string]interface{}) []string {
 	if env, ok := parameters["EnvVars"].([]string); ok {
 		return env
@@ -0,0 +1,43 @@
+package adapters
+
+import (
+	"bytes"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestScanContainerLogs_DemultiplexesFrames(t *testing.T) {
+	// Así llegan los logs de un contenedor sin TTY: cada escritura en su trama.
+	var logs bytes.Buffer
+	stdout := stdcopy.NewStdWriter(&logs, stdcopy.Stdout)
+	stderr := stdcopy.NewStdWriter(&logs, stdcopy.Stderr)
+	stdout.Write([]byte("building\n::input-"))
+	stdout.Write([]byte("required::\n"))
+	stderr.Write([]byte("warning: cache miss\n"))
+
+	var lines []string
+	require.NoError(t, scanContainerLogs(&logs, func(line string) { lines = append(lines, line) }))
+	assert.Equal(t, []string{"building", inputRequestLine, "warning: cache miss"}, lines)
+}
+
+func TestJobDeadline_DoesNotCountPausedTime(t *testing.T) {
+	expired := make(chan struct{})
+	deadline := newJobDeadline(50*time.Millisecond, func() { close(expired) })
+	deadline.pause()
+
+	select {
+	case <-expired:
+		t.Fatal("the deadline expired while paused")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	deadline.resume()
+	select {
+	case <-expired:
+	case <-time.After(time.Second):
+		t.Fatal("the deadline did not expire after resuming")
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"bufio"
 	"context"
 	entities "devops_console/internal/domain/entities/orchestrator"
+	eventstream "devops_console/internal/infrastructure/orchestrator/events"
+	secrets "devops_console/internal/infrastructure/orchestrator/secrets"
 	ports "devops_console/internal/ports/orchestrator"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	batchv1 "k8s.io/api/batch/v1"
@@ -12,6 +15,8 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,6 +24,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
 )
 
 type taskState struct {
@@ -42,17 +48,26 @@ func NewExecutionError(code, message string) ExecutionError {
 	}
 }
 
-type TaskProgressPayload struct {
-	Status entities.TaskStatus `json:"status"`
-	Error  string              `json:"error,omitempty"`
-}
+type TaskProgressPayload = entities.TaskProgressPayload
 
 type K8sTaskExecutor struct {
+	lifetime
 	clientset      *kubernetes.Clientset
 	namespace      string
-	eventStream    ports.TaskEventStream
+	eventStream    *eventstream.RedactingTaskEventStream
 	taskExecutions map[string]*entities.TaskExecution
 	tasks          sync.Map // Usar sync.Map en lugar de map con mutex
+	// SecretStore resuelve TaskConfig.Secrets. Si es un KubernetesSecretStore del mismo
+	// namespace los secretos llegan al pod con secretKeyRef y su valor no se copia al Job.
+	SecretStore ports.SecretStore
+	// Artifacts deja los inputs de la tarea en el pod. El pod arranca antes de recibirlos,
+	// así que su comando espera a que estén: las tareas con inputs necesitan un Command
+	// explícito y una imagen con sh y tar. No implementa ports.InputInjector mientras
+	// K8sFileSync no sepa copiar a los pods, así que el servicio de tareas no acepta
+	// inputs en sus workers.
+	Artifacts *ArtifactSync
+	// Metrics, si se configura, recibe lo que tarda el kubelet en descargar las imágenes.
+	Metrics ports.ExecutionMetrics
 }
 
 func NewK8sTaskExecutor(namespace string, eventStream ports.TaskEventStream) (*K8sTaskExecutor, error) {
@@ -83,7 +98,7 @@ func NewK8sTaskExecutor(namespace string, eventStream ports.TaskEventStream) (*K
 	return &K8sTaskExecutor{
 		clientset:      clientset,
 		namespace:      namespace,
-		eventStream:    eventStream,
+		eventStream:    eventstream.NewRedactingTaskEventStream(eventStream),
 		taskExecutions: make(map[string]*entities.TaskExecution),
 	}, nil
 }
@@ -93,8 +108,27 @@ func (e *K8sTaskExecutor) ExecuteTask(ctx context.Context, task *entities.DevOps
 	if !ok {
 		timeout = 30 * time.Second // Default value
 	}
-	ctx, cancel := context.WithTimeout(ctx, timeout)
 	executionID := uuid.New().String()
+	spec, err := entities.RenderWorkerSpec(task.Worker.GetDetails(), entities.NewTemplateContext(task, executionID, false))
+	if err != nil {
+		return "", err
+	}
+	if len(task.Config.Inputs) > 0 {
+		if e.Artifacts == nil {
+			return "", fmt.Errorf("task %s declares inputs but the executor has no artifact store", task.ID)
+		}
+		if len(spec.Command) == 0 {
+			return "", fmt.Errorf("task %s declares inputs but has no command to run once they are injected", task.ID)
+		}
+	}
+	secretEnv, err := e.podSecretEnv(ctx, executionID, task)
+	if err != nil {
+		return "", err
+	}
+	e.eventStream.Redact(executionID, task.SecretParameterValues())
+	// La ejecución no se cancela con la petición que la lanza, solo por timeout o con Shutdown.
+	ctx, release := e.detach(ctx)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	taskExecution := &entities.TaskExecution{
 		ID:           executionID,
 		DevOpsTaskID: task.ID,
@@ -108,36 +142,33 @@ func (e *K8sTaskExecutor) ExecuteTask(ctx context.Context, task *entities.DevOps
 	})
 
 	go func() {
+		defer release()
 		defer cancel()
-		e.runTask(ctx, task, taskExecution)
+		e.runTask(ctx, task, spec, secretEnv, taskExecution)
 	}()
 
 	return executionID, nil
 }
 
-func (e *K8sTaskExecutor) runTask(ctx context.Context, task *entities.DevOpsTask, taskExecution *entities.TaskExecution) {
+func (e *K8sTaskExecutor) runTask(ctx context.Context, task *entities.DevOpsTask, spec entities.WorkerSpec, secretEnv podSecrets, taskExecution *entities.TaskExecution) {
 	jobName := fmt.Sprintf("task-%s", taskExecution.ID)
 
 	// Crear el objeto Job
-	job := &batchv1.Job{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: jobName,
-		},
-		Spec: batchv1.JobSpec{
-			Template: corev1.PodTemplateSpec{
-				Spec: corev1.PodSpec{
-					RestartPolicy: corev1.RestartPolicyNever,
-					Containers: []corev1.Container{
-						{
-							Name:    task.Name,
-							Image:   task.Worker.GetDetails()["Image"].(string),
-							Command: task.Worker.GetDetails()["Command"].([]string),
-							Env:     getEnvVars(task.Worker.GetDetails()),
-						},
-					},
-				},
-			},
-		},
+	job := e.buildJob(task, spec, secretEnv, jobName)
+
+	// Los valores secretos que no están ya en un Secret del namespace van en uno propio de
+	// la ejecución, que el Job referencia y se borra con él.
+	if len(secretEnv.data) > 0 {
+		secretsClient := e.clientset.CoreV1().Secrets(e.namespace)
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: envSecretName(jobName), Namespace: e.namespace},
+			StringData: secretEnv.data,
+		}
+		if _, err := secretsClient.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			e.failTaskExecution(taskExecution.ID, entities.FailureInfrastructure, fmt.Sprintf("Failed to create secret: %v", err))
+			return
+		}
+		defer secretsClient.Delete(context.Background(), secret.Name, metav1.DeleteOptions{})
 	}
 
 	defer e.cleanup(context.Background(), jobName, e.namespace)
@@ -146,22 +177,31 @@ func (e *K8sTaskExecutor) runTask(ctx context.Context, task *entities.DevOpsTask
 	jobsClient := e.clientset.BatchV1().Jobs(e.namespace)
 	_, err := jobsClient.Create(ctx, job, metav1.CreateOptions{})
 	if err != nil {
-		e.updateTaskExecutionStatus(taskExecution.ID, entities.TaskFailed, fmt.Sprintf("Failed to create job: %v", err))
+		e.failTaskExecution(taskExecution.ID, entities.FailureInfrastructure, fmt.Sprintf("Failed to create job: %v", err))
 		return
 	}
 
 	// Esperar a que el Pod esté en ejecución
 	podName, err := e.waitForPodRunning(ctx, jobName)
 	if err != nil {
-		e.updateTaskExecutionStatus(taskExecution.ID, entities.TaskFailed, fmt.Sprintf("Failed to wait for pod running: %v", err))
+		e.failTaskExecution(taskExecution.ID, failureClassOf(err), fmt.Sprintf("Failed to wait for pod running: %v", err))
 		return
 	}
 
+	e.observeImagePull(ctx, podName)
+
 	// Almacenar detalles específicos del ejecutor
 	taskExecution.ExecutionDetails = map[string]interface{}{
 		"PodName": podName,
 	}
 
+	if len(task.Config.Inputs) > 0 {
+		if err := e.injectInputs(ctx, podName, task.Config.Inputs); err != nil {
+			e.failTaskExecution(taskExecution.ID, entities.FailureInfrastructure, fmt.Sprintf("Failed to inject inputs: %v", err))
+			return
+		}
+	}
+
 	// Publish event that the pod is running
 	e.publishEvent(taskExecution.ID, entities.EventTypeTaskStarted, "Pod is started")
 
@@ -170,13 +210,13 @@ func (e *K8sTaskExecutor) runTask(ctx context.Context, task *entities.DevOpsTask
 
 	// Hacer streaming de los logs
 	if err := e.streamPodLogs(ctx, podName, taskExecution); err != nil {
-		e.publishEvent(taskExecution.ID, entities.EventTypeTaskError, fmt.Sprintf("Error streaming logs: %v", err))
+		e.publishEvent(taskExecution.ID, entities.EventTypeTaskWarning, fmt.Sprintf("Error streaming logs: %v", err))
 	}
 
 	defer e.eventStream.Close(taskExecution.ID)
 	// Esperar a que el Job complete
 	if err := e.waitForJobCompletion(ctx, jobName); err != nil {
-		e.updateTaskExecutionStatus(taskExecution.ID, entities.TaskFailed, fmt.Sprintf("Failed to wait for job completion: %v", err))
+		e.failTaskExecution(taskExecution.ID, failureClassOf(err), fmt.Sprintf("Failed to wait for job completion: %v", err))
 		return
 	}
 
@@ -184,6 +224,93 @@ func (e *K8sTaskExecutor) runTask(ctx context.Context, task *entities.DevOpsTask
 	e.updateTaskExecutionStatus(taskExecution.ID, entities.TaskSucceeded, "")
 }
 
+// injectInputs deja los inputs en el pod y le avisa de que ya puede empezar.
+func (e *K8sTaskExecutor) injectInputs(ctx context.Context, podName string, inputs []entities.ArtifactInput) error {
+	if err := e.Artifacts.Inject(ctx, podName, inputs); err != nil {
+		return err
+	}
+	return e.Artifacts.signalInputsReady(ctx, podName)
+}
+
+// buildJob construye el Job con el que se lanza la tarea. Si la tarea tiene inputs, el
+// comando espera a que el executor los deje en el pod.
+func (e *K8sTaskExecutor) buildJob(task *entities.DevOpsTask, spec entities.WorkerSpec, secretEnv podSecrets, jobName string) *batchv1.Job {
+	command, args := spec.Command, spec.Args
+	if len(task.Config.Inputs) > 0 {
+		command, args = waitForInputs(append(append([]string{}, command...), args...)), nil
+	}
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: e.namespace,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:       task.Name,
+							Image:      spec.Image,
+							Command:    command,
+							Args:       args,
+							WorkingDir: spec.WorkingDir,
+							Env:        podEnv(task, spec, secretEnv, envSecretName(jobName)),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Plan devuelve en YAML el Job que crearía ExecuteTask, sin tocar el clúster. Los secretos
+// aparecen como secretKeyRef y el Secret de la ejecución no se muestra.
+func (e *K8sTaskExecutor) Plan(ctx context.Context, task *entities.DevOpsTask) (entities.ExecutionPlan, error) {
+	masked := task.MaskedCopy()
+	spec, err := entities.RenderWorkerSpec(task.Worker.GetDetails(), entities.NewTemplateContext(&masked, entities.DryRunExecutionID, true))
+	if err != nil {
+		return entities.ExecutionPlan{}, err
+	}
+
+	_, secretParameters := parameterEnv(&masked)
+	secretEnv := newPodSecrets(secretParameters)
+	if store, ok := e.SecretStore.(*secrets.KubernetesSecretStore); ok && store.Namespace() == e.namespace {
+		for _, reference := range task.Config.Secrets {
+			selector, err := store.SecretKeySelector(reference.Key)
+			if err != nil {
+				return entities.ExecutionPlan{}, err
+			}
+			secretEnv.refs = append(secretEnv.refs, corev1.EnvVar{
+				Name:      reference.EnvVar,
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: selector},
+			})
+		}
+	} else {
+		variables, err := secrets.PlanSecrets(ctx, e.SecretStore, task.Config.Secrets)
+		if err != nil {
+			return entities.ExecutionPlan{}, err
+		}
+		secretEnv.add(variables)
+	}
+
+	job := e.buildJob(&masked, spec, secretEnv, fmt.Sprintf("task-%s", entities.DryRunExecutionID))
+	manifest, err := yaml.Marshal(job)
+	if err != nil {
+		return entities.ExecutionPlan{}, err
+	}
+	return entities.ExecutionPlan{
+		WorkerType: task.Worker.GetType(),
+		Spec:       spec,
+		Format:     "yaml",
+		Manifest:   string(manifest),
+	}, nil
+}
+
 func (e *K8sTaskExecutor) cleanup(ctx context.Context, jobName string, namespace string) error {
 	propagationPolicy := metav1.DeletePropagationBackground
 	return e.clientset.BatchV1().Jobs(namespace).Delete(ctx, jobName, metav1.DeleteOptions{
@@ -213,12 +340,51 @@ func (e *K8sTaskExecutor) waitForPodRunning(ctx context.Context, jobName string)
 		case corev1.PodFailed, corev1.PodSucceeded:
 			return false, NewExecutionError("POD_TERMINATED", "Pod terminated before running")
 		default:
+			for _, status := range pod.Status.ContainerStatuses {
+				if waiting := status.State.Waiting; waiting != nil && (waiting.Reason == "ErrImagePull" || waiting.Reason == "ImagePullBackOff") {
+					return false, NewExecutionError("IMAGE_PULL", fmt.Sprintf("Failed to pull image: %s", waiting.Message))
+				}
+			}
 			return false, nil
 		}
 	})
 	return podName, err
 }
 
+// observeImagePull mide la descarga de la imagen con los eventos Pulling y Pulled del pod.
+// Si la imagen ya estaba en el nodo no hay Pulling y no se mide nada.
+func (e *K8sTaskExecutor) observeImagePull(ctx context.Context, podName string) {
+	if e.Metrics == nil {
+		return
+	}
+	events, err := e.clientset.CoreV1().Events(e.namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.kind=Pod,involvedObject.name=%s", podName),
+	})
+	if err != nil {
+		// La medición no puede hacer fallar la ejecución.
+		return
+	}
+	var pulling, pulled time.Time
+	for _, event := range events.Items {
+		switch event.Reason {
+		case "Pulling":
+			pulling = eventTime(event)
+		case "Pulled":
+			pulled = eventTime(event)
+		}
+	}
+	if !pulling.IsZero() && !pulled.Before(pulling) {
+		e.Metrics.ImagePulled("Kubernetes", pulled.Sub(pulling))
+	}
+}
+
+func eventTime(event corev1.Event) time.Time {
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.LastTimestamp.Time
+}
+
 func (e *K8sTaskExecutor) SubscribeToTaskEvents(taskExecutionID string) (<-chan entities.TaskEvent, error) {
 	return e.eventStream.Subscribe(taskExecutionID)
 }
@@ -272,7 +438,35 @@ func (e *K8sTaskExecutor) waitForJobCompletion(ctx context.Context, jobName stri
 	})
 }
 
+// failureClassOf traduce los ExecutionError del executor a la clase de fallo de la política
+// de reintentos.
+func failureClassOf(err error) entities.FailureClass {
+	var executionErr ExecutionError
+	if !errors.As(err, &executionErr) {
+		return entities.FailureInfrastructure
+	}
+	switch executionErr.Code {
+	case "IMAGE_PULL":
+		return entities.FailureImagePull
+	case "JOB_FAILED", "POD_TERMINATED":
+		return entities.FailureNonZeroExit
+	default:
+		return entities.FailureInfrastructure
+	}
+}
+
+// failTaskExecution marca la ejecución como fallida indicando la clase de fallo, que usa
+// la política de reintentos.
+func (e *K8sTaskExecutor) failTaskExecution(executionID string, class entities.FailureClass, errMsg string) {
+	if state, ok := e.tasks.Load(executionID); ok {
+		state.(*taskState).execution.FailureClass = class
+	}
+	e.updateTaskExecutionStatus(executionID, entities.TaskFailed, errMsg)
+}
+
 func (e *K8sTaskExecutor) updateTaskExecutionStatus(executionID string, status entities.TaskStatus, errMsg string) {
+	var details map[string]interface{}
+	var class entities.FailureClass
 	if state, ok := e.tasks.Load(executionID); ok {
 		taskState := state.(*taskState)
 		taskState.execution.Status = status
@@ -280,6 +474,8 @@ func (e *K8sTaskExecutor) updateTaskExecutionStatus(executionID string, status e
 		if errMsg != "" {
 			taskState.execution.Error = errMsg
 		}
+		details = taskState.execution.ExecutionDetails
+		class = taskState.execution.FailureClass
 	}
 	typeEvent := entities.EventTypeTaskProgress
 	if status == entities.TaskSucceeded {
@@ -291,8 +487,10 @@ func (e *K8sTaskExecutor) updateTaskExecutionStatus(executionID string, status e
 	}
 
 	e.publishEvent(executionID, typeEvent, TaskProgressPayload{
-		Status: status,
-		Error:  errMsg,
+		Status:           status,
+		Error:            errMsg,
+		ExecutionDetails: details,
+		FailureClass:     class,
 	})
 }
 
@@ -315,7 +513,7 @@ func (e *K8sTaskExecutor) CancelTask(ctx context.Context, executionID string) er
 func (e *K8sTaskExecutor) monitorJobProgress(ctx context.Context, jobName string, executionID string) {
 	job, err := e.clientset.BatchV1().Jobs(e.namespace).Get(ctx, jobName, metav1.GetOptions{})
 	if err != nil {
-		e.publishEvent(executionID, entities.EventTypeTaskError, err.Error())
+		e.publishEvent(executionID, entities.EventTypeTaskWarning, err.Error())
 		return
 	}
 
@@ -323,7 +521,7 @@ func (e *K8sTaskExecutor) monitorJobProgress(ctx context.Context, jobName string
 		LabelSelector: fmt.Sprintf("job-name=%s", jobName),
 	})
 	if err != nil || len(pods.Items) == 0 {
-		e.publishEvent(executionID, entities.EventTypeTaskError, "No pods found for job")
+		e.publishEvent(executionID, entities.EventTypeTaskWarning, "No pods found for job")
 		return
 	}
 	pod := pods.Items[0]
@@ -337,7 +535,7 @@ func (e *K8sTaskExecutor) monitorJobProgress(ctx context.Context, jobName string
 			if err == nil {
 				buffer := make([]byte, 2048)
 				if n, err := logs.Read(buffer); err == nil {
-					e.publishEvent(executionID, entities.EventTypeTaskOutput, string(buffer[:n]))
+					e.eventStream.PublishOutput(executionID, string(buffer[:n]), time.Now())
 				}
 				logs.Close()
 			}
@@ -377,6 +575,120 @@ func getEnvVars(parameters map[string]interface{}) []corev1.EnvVar {
 	return []corev1.EnvVar{} // Return an empty slice if "Env" is not defined
 }
 
+// podSecrets son las variables de entorno secretas del pod. Las que ya están en un Secret
+// del namespace se referencian tal cual (refs); el resto (data, por nombre de variable) se
+// guardan en el Secret de la ejecución, de modo que ningún valor secreto aparece en el Job.
+type podSecrets struct {
+	refs []corev1.EnvVar
+	data map[string]string
+}
+
+func newPodSecrets(variables []string) podSecrets {
+	secretEnv := podSecrets{data: make(map[string]string)}
+	secretEnv.add(variables)
+	return secretEnv
+}
+
+// add guarda en data las variables NOMBRE=valor.
+func (s *podSecrets) add(variables []string) {
+	for _, variable := range variables {
+		name, value, _ := strings.Cut(variable, "=")
+		s.data[name] = value
+	}
+}
+
+// envSecretName es el nombre del Secret con los valores secretos de la ejecución del Job.
+func envSecretName(jobName string) string {
+	return jobName + "-env"
+}
+
+// parameterEnv devuelve por separado las variables de los parámetros normales y las de los
+// secretos.
+func parameterEnv(task *entities.DevOpsTask) (plain, secret []string) {
+	values := task.ParameterValues()
+	secretValues := make(map[string]interface{})
+	for _, definition := range task.AllParameters() {
+		if value, ok := values[definition.Name]; ok && definition.Secret {
+			secretValues[definition.Name] = value
+			delete(values, definition.Name)
+		}
+	}
+	return entities.ParameterEnv(values), entities.ParameterEnv(secretValues)
+}
+
+// podEnv une el entorno del worker, el renderizado desde la plantilla, los parámetros y
+// los secretos, estos siempre con secretKeyRef; secretName es el Secret de la ejecución.
+func podEnv(task *entities.DevOpsTask, spec entities.WorkerSpec, secretEnv podSecrets, secretName string) []corev1.EnvVar {
+	plain, _ := parameterEnv(task)
+	env := append([]corev1.EnvVar{}, getEnvVars(task.Worker.GetDetails())...)
+	env = append(env, toEnvVars(spec.EnvList())...)
+	env = append(env, toEnvVars(plain)...)
+	env = append(env, secretEnv.refs...)
+	names := make([]string, 0, len(secretEnv.data))
+	for name := range secretEnv.data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  name,
+			}},
+		})
+	}
+	return env
+}
+
+// podSecretEnv prepara las variables de los parámetros secretos y de los secretos de la
+// tarea y registra sus valores para ocultarlos en la salida del pod.
+func (e *K8sTaskExecutor) podSecretEnv(ctx context.Context, executionID string, task *entities.DevOpsTask) (podSecrets, error) {
+	_, secretParameters := parameterEnv(task)
+	secretEnv := newPodSecrets(secretParameters)
+	references := task.Config.Secrets
+	store, ok := e.SecretStore.(*secrets.KubernetesSecretStore)
+	if !ok || store.Namespace() != e.namespace {
+		variables, values, err := secrets.ResolveSecrets(ctx, e.SecretStore, references)
+		if err != nil {
+			return podSecrets{}, err
+		}
+		e.eventStream.Redact(executionID, values)
+		secretEnv.add(variables)
+		return secretEnv, nil
+	}
+
+	values := make([]string, 0, len(references))
+	for _, reference := range references {
+		selector, err := store.SecretKeySelector(reference.Key)
+		if err != nil {
+			return podSecrets{}, err
+		}
+		// El valor solo se lee para poder ocultarlo en los logs.
+		value, err := store.GetSecret(ctx, reference.Key)
+		if err != nil {
+			return podSecrets{}, fmt.Errorf("resolving secret for %s: %w", reference.EnvVar, err)
+		}
+		values = append(values, value)
+		secretEnv.refs = append(secretEnv.refs, corev1.EnvVar{
+			Name:      reference.EnvVar,
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: selector},
+		})
+	}
+	e.eventStream.Redact(executionID, values)
+	return secretEnv, nil
+}
+
+// toEnvVars convierte variables NOMBRE=valor en variables de entorno del pod.
+func toEnvVars(variables []string) []corev1.EnvVar {
+	var env []corev1.EnvVar
+	for _, variable := range variables {
+		name, value, _ := strings.Cut(variable, "=")
+		env = append(env, corev1.EnvVar{Name: name, Value: value})
+	}
+	return env
+}
+
 func (e *K8sTaskExecutor) WaitForPodReady(ctx context.Context, podName string) error {
 	podsClient := e.clientset.CoreV1().Pods(e.namespace)
 
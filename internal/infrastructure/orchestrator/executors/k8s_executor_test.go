@@ -0,0 +1,41 @@
+package adapters
+
+import (
+	entities "devops_console/internal/domain/entities/orchestrator"
+	workers "devops_console/internal/infrastructure/orchestrator/workers"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBuildJob_ReferencesSecretParametersFromTheExecutionSecret(t *testing.T) {
+	task := &entities.DevOpsTask{
+		ID:     "deploy",
+		Name:   "deploy",
+		Worker: &workers.KubernetesWorker{Image: "registry/deployer:1.4"},
+		Parameters: []entities.ParameterDefinition{
+			{Name: "ref", Type: entities.ParameterString},
+			{Name: "token", Type: entities.ParameterString, Secret: true},
+		},
+		Config: entities.TaskConfig{Parameters: map[string]interface{}{"ref": "main", "token": "hunter2"}},
+	}
+	executor := &K8sTaskExecutor{namespace: "ci"}
+	spec, err := entities.RenderWorkerSpec(task.Worker.GetDetails(), entities.NewTemplateContext(task, "execution-1", false))
+	require.NoError(t, err)
+	_, secretParameters := parameterEnv(task)
+
+	job := executor.buildJob(task, spec, newPodSecrets(secretParameters), "task-execution-1")
+	env := map[string]string{}
+	for _, variable := range job.Spec.Template.Spec.Containers[0].Env {
+		if variable.ValueFrom != nil {
+			ref := variable.ValueFrom.SecretKeyRef
+			env[variable.Name] = ref.Name + "/" + ref.Key
+			continue
+		}
+		env[variable.Name] = variable.Value
+	}
+	assert.Equal(t, "main", env["REF"])
+	assert.Equal(t, "task-execution-1-env/TOKEN", env["TOKEN"])
+	assert.NotContains(t, fmt.Sprint(job), "hunter2")
+}
@@ -0,0 +1,38 @@
+package adapters
+
+import (
+	"context"
+	"sync"
+)
+
+// lifetime es el contexto de vida de un executor. Las ejecuciones se lanzan desde una
+// petición pero duran más que ella: su contexto conserva los valores de la petición
+// (identidad, trazas...) pero solo se cancela con Shutdown. El valor cero es válido.
+type lifetime struct {
+	once     sync.Once
+	ctx      context.Context
+	shutdown context.CancelFunc
+}
+
+func (l *lifetime) init() {
+	l.once.Do(func() {
+		l.ctx, l.shutdown = context.WithCancel(context.Background())
+	})
+}
+
+// detach devuelve el contexto de una ejecución lanzada desde ctx; cancel lo libera.
+func (l *lifetime) detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	l.init()
+	detached, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(l.ctx, cancel)
+	return detached, func() {
+		stop()
+		cancel()
+	}
+}
+
+// Shutdown cancela todas las ejecuciones en curso del executor.
+func (l *lifetime) Shutdown() {
+	l.init()
+	l.shutdown()
+}
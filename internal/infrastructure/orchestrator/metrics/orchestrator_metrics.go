@@ -0,0 +1,115 @@
+package adapters
+
+import (
+	"devops_console/internal/domain/entities/orchestrator"
+	"sync"
+	"time"
+)
+
+var (
+	// executionBuckets van de segundos a una hora, lo que dura casi cualquier tarea.
+	executionBuckets = []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1800, 3600}
+	imagePullBuckets = []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+)
+
+// EventStreamStats es lo que se expone del stream de eventos; lo implementa
+// TaskEventStreamImpl.
+type EventStreamStats interface {
+	Subscribers() int
+	Unrouted() uint64
+}
+
+// OrchestratorMetrics registra en Registry las métricas del master y los executors.
+// Implementa ports.ExecutionMetrics y ports.AgentMetrics.
+type OrchestratorMetrics struct {
+	Registry         *Registry
+	started          *CounterVec
+	finished         *CounterVec
+	duration         *HistogramVec
+	queueWait        *HistogramVec
+	imagePull        *HistogramVec
+	agentsConnected  *GaugeVec
+	agentCPU         *GaugeVec
+	agentMemory      *GaugeVec
+	agentConnections map[string]int
+	mu               sync.Mutex
+}
+
+func NewOrchestratorMetrics(registry *Registry) *OrchestratorMetrics {
+	m := &OrchestratorMetrics{
+		Registry: registry,
+		started: registry.NewCounterVec("devops_executions_started_total",
+			"Executions started by an executor.", "worker_type", "workspace"),
+		finished: registry.NewCounterVec("devops_executions_finished_total",
+			"Executions finished, by final status.", "worker_type", "workspace", "status"),
+		duration: registry.NewHistogramVec("devops_execution_duration_seconds",
+			"Time from the start of an execution to its end.", executionBuckets, "worker_type", "workspace"),
+		queueWait: registry.NewHistogramVec("devops_execution_queue_wait_seconds",
+			"Time executions waited in the queue for a free slot.", executionBuckets, "worker_type", "workspace"),
+		imagePull: registry.NewHistogramVec("devops_image_pull_duration_seconds",
+			"Time spent pulling images before starting an execution. Reported by the Docker and Kubernetes executors; agents pull on their own.", imagePullBuckets, "worker_type"),
+		agentsConnected: registry.NewGaugeVec("devops_agents_connected",
+			"Agents connected to the master."),
+		agentCPU: registry.NewGaugeVec("devops_agent_cpu_usage_percent",
+			"CPU usage last reported by each agent.", "agent"),
+		agentMemory: registry.NewGaugeVec("devops_agent_memory_usage_percent",
+			"Memory usage last reported by each agent.", "agent"),
+		agentConnections: make(map[string]int),
+	}
+	m.agentsConnected.Set(0)
+	return m
+}
+
+// ObserveEventStream expone las suscripciones abiertas y los eventos que nadie recibió.
+func (m *OrchestratorMetrics) ObserveEventStream(stream EventStreamStats) {
+	m.Registry.NewGaugeFunc("devops_event_stream_subscribers",
+		"Open subscriptions to the task event stream.",
+		func() float64 { return float64(stream.Subscribers()) })
+	m.Registry.NewCounterFunc("devops_event_stream_unrouted_events_total",
+		"Events published to the task event stream while nobody was subscribed to them.",
+		func() float64 { return float64(stream.Unrouted()) })
+}
+
+func (m *OrchestratorMetrics) ExecutionStarted(workerType, workspaceID string) {
+	m.started.Inc(workerType, workspaceID)
+}
+
+func (m *OrchestratorMetrics) ExecutionFinished(workerType, workspaceID string, status entities.TaskStatus, duration time.Duration) {
+	m.finished.Inc(workerType, workspaceID, string(status))
+	m.duration.Observe(duration.Seconds(), workerType, workspaceID)
+}
+
+func (m *OrchestratorMetrics) ExecutionDequeued(workerType, workspaceID string, wait time.Duration) {
+	m.queueWait.Observe(wait.Seconds(), workerType, workspaceID)
+}
+
+func (m *OrchestratorMetrics) ImagePulled(workerType string, duration time.Duration) {
+	m.imagePull.Observe(duration.Seconds(), workerType)
+}
+
+// AgentConnected cuenta el agente una sola vez aunque tenga varias conexiones abiertas.
+func (m *OrchestratorMetrics) AgentConnected(agentID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.agentConnections[agentID]++
+	m.agentsConnected.Set(float64(len(m.agentConnections)))
+}
+
+// AgentDisconnected quita el uso de recursos del agente al cerrarse su última conexión.
+func (m *OrchestratorMetrics) AgentDisconnected(agentID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.agentConnections[agentID] > 1 {
+		m.agentConnections[agentID]--
+		return
+	}
+	delete(m.agentConnections, agentID)
+	m.agentsConnected.Set(float64(len(m.agentConnections)))
+	m.agentCPU.Delete(agentID)
+	m.agentMemory.Delete(agentID)
+}
+
+func (m *OrchestratorMetrics) AgentUsage(agentID string, cpu, memory float64) {
+	m.agentCPU.Set(cpu, agentID)
+	m.agentMemory.Set(memory, agentID)
+}
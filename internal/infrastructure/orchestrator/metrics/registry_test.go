@@ -0,0 +1,34 @@
+package adapters
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WritesTextFormat(t *testing.T) {
+	registry := NewRegistry()
+	latency := registry.NewHistogramVec("latency_seconds", "Request latency.\nIn seconds.", []float64{0.1, 1}, "path")
+	latency.Observe(0.05, `/a"b`)
+	latency.Observe(0.5, `/a"b`)
+	latency.Observe(3, `/a"b`)
+	registry.NewGaugeVec("temperature", "Current temperature.").Set(21.5)
+
+	var b strings.Builder
+	_, err := registry.WriteTo(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, `# HELP latency_seconds Request latency.\nIn seconds.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{path="/a\"b",le="0.1"} 1
+latency_seconds_bucket{path="/a\"b",le="1"} 2
+latency_seconds_bucket{path="/a\"b",le="+Inf"} 3
+latency_seconds_sum{path="/a\"b"} 3.55
+latency_seconds_count{path="/a\"b"} 3
+# HELP temperature Current temperature.
+# TYPE temperature gauge
+temperature 21.5
+`, b.String())
+
+	assert.Panics(t, func() { registry.NewGaugeVec("temperature", "Again.") })
+	assert.Panics(t, func() { latency.Observe(1) })
+}
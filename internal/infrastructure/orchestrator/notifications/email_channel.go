@@ -0,0 +1,119 @@
+package adapters
+
+import (
+	"context"
+	"crypto/tls"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+const defaultSMTPTimeout = 30 * time.Second
+
+// EmailChannel envía cada notificación como un correo de texto plano a través del servidor
+// SMTP Addr (host:puerto). Usa STARTTLS si el servidor lo ofrece y Auth si está definido.
+type EmailChannel struct {
+	Addr string
+	From string
+	To   []string
+	Auth smtp.Auth
+	// TLSConfig se usa en STARTTLS; por defecto se verifica el certificado contra el host.
+	TLSConfig *tls.Config
+}
+
+func NewEmailChannel(addr, from string, to []string) *EmailChannel {
+	return &EmailChannel{Addr: addr, From: from, To: append([]string(nil), to...)}
+}
+
+func (c *EmailChannel) Send(ctx context.Context, notification entities.Notification) error {
+	if len(c.To) == 0 {
+		return fmt.Errorf("%w: email channel has no recipients", ports.ErrNotificationRejected)
+	}
+	host, _, err := net.SplitHostPort(c.Addr)
+	if err != nil {
+		return fmt.Errorf("%w: invalid SMTP address: %v", ports.ErrNotificationRejected, err)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", c.Addr)
+	if err != nil {
+		return fmt.Errorf("connecting to SMTP server: %w", err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultSMTPTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return smtpError(err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		config := c.TLSConfig
+		if config == nil {
+			config = &tls.Config{ServerName: host}
+		}
+		if err := client.StartTLS(config); err != nil {
+			return smtpError(err)
+		}
+	}
+	if c.Auth != nil {
+		if err := client.Auth(c.Auth); err != nil {
+			return smtpError(err)
+		}
+	}
+	if err := client.Mail(c.From); err != nil {
+		return smtpError(err)
+	}
+	for _, to := range c.To {
+		if err := client.Rcpt(to); err != nil {
+			return smtpError(err)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return smtpError(err)
+	}
+	if _, err := writer.Write(c.message(notification)); err != nil {
+		writer.Close()
+		return smtpError(err)
+	}
+	if err := writer.Close(); err != nil {
+		return smtpError(err)
+	}
+	return client.Quit()
+}
+
+func (c *EmailChannel) message(notification entities.Notification) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", c.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(c.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Subject()))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(notification.Text(), "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// smtpError marca como definitivas las respuestas 5xx del servidor (p.ej. un buzón que no
+// existe); las 4xx y los errores de red se pueden reintentar.
+func smtpError(err error) error {
+	var protocolErr *textproto.Error
+	if errors.As(err, &protocolErr) && protocolErr.Code >= 500 {
+		return fmt.Errorf("%w: SMTP server responded %v", ports.ErrNotificationRejected, err)
+	}
+	return fmt.Errorf("sending email: %w", err)
+}
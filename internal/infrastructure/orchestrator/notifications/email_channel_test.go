@@ -0,0 +1,123 @@
+package adapters
+
+import (
+	"bufio"
+	"context"
+	ports "devops_console/internal/ports/orchestrator"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// smtpServer es un servidor SMTP mínimo que acepta una conexión y guarda el remitente,
+// los destinatarios y el mensaje. Rechaza con 550 los destinatarios de rejected.
+type smtpServer struct {
+	listener   net.Listener
+	rejected   string
+	mu         sync.Mutex
+	from       string
+	recipients []string
+	message    string
+}
+
+func newSMTPServer(t *testing.T, rejected string) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &smtpServer{listener: listener, rejected: rejected}
+	t.Cleanup(func() { listener.Close() })
+	go server.serve()
+	return server
+}
+
+func (s *smtpServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+	reply("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimSpace(line)
+		switch verb := strings.ToUpper(strings.SplitN(command, " ", 2)[0]); verb {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = strings.TrimPrefix(command, "MAIL FROM:")
+			s.mu.Unlock()
+			reply("250 OK")
+		case "RCPT":
+			recipient := strings.TrimPrefix(command, "RCPT TO:")
+			if strings.Contains(recipient, s.rejected) {
+				reply("550 No such user")
+				continue
+			}
+			s.mu.Lock()
+			s.recipients = append(s.recipients, recipient)
+			s.mu.Unlock()
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var message strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				message.WriteString(line)
+			}
+			s.mu.Lock()
+			s.message = message.String()
+			s.mu.Unlock()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *smtpServer) received() (string, []string, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.from, append([]string{}, s.recipients...), s.message
+}
+
+func TestEmailChannel_SendsMessage(t *testing.T) {
+	server := newSMTPServer(t, "nobody@")
+	channel := NewEmailChannel(server.listener.Addr().String(), "devops@example.com", []string{"oncall@example.com", "sre@example.com"})
+	assert.NoError(t, channel.Send(context.Background(), failedDeploy))
+
+	from, recipients, message := server.received()
+	assert.Equal(t, "<devops@example.com>", from)
+	assert.Equal(t, []string{"<oncall@example.com>", "<sre@example.com>"}, recipients)
+	assert.Contains(t, message, "To: oncall@example.com, sre@example.com\r\n")
+	assert.Contains(t, message, "Subject: Task \"Deploy\" failed with status FAILED\r\n")
+	assert.Contains(t, message, "\r\n\r\nTask \"Deploy\" failed with status FAILED\r\n")
+	assert.Contains(t, message, "Error: exit code 1\r\n")
+
+	rejected := NewEmailChannel(server.listener.Addr().String(), "devops@example.com", []string{"nobody@example.com"})
+	assert.ErrorIs(t, rejected.Send(context.Background(), failedDeploy), ports.ErrNotificationRejected)
+}
@@ -0,0 +1,129 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"text/template"
+	"time"
+)
+
+const defaultWebhookTimeout = 10 * time.Second
+
+// WebhookChannel envía cada notificación con un POST a URL. Sin plantilla el cuerpo es la
+// notificación en JSON; con ella, la plantilla ejecutada sobre la notificación, que tiene
+// que dar un JSON válido. La función json de la plantilla escapa un valor como JSON, p.ej.
+// {"text": {{json .Subject}}}.
+type WebhookChannel struct {
+	URL     string
+	Headers map[string]string
+	Client  *http.Client
+	body    *template.Template
+}
+
+// NewWebhookChannel crea el canal; bodyTemplate puede estar vacío.
+func NewWebhookChannel(url, bodyTemplate string) (*WebhookChannel, error) {
+	c := &WebhookChannel{URL: url, Client: &http.Client{Timeout: defaultWebhookTimeout}}
+	if bodyTemplate != "" {
+		body, err := template.New("body").Funcs(template.FuncMap{"json": jsonValue}).Parse(bodyTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook body template: %w", err)
+		}
+		c.body = body
+	}
+	return c, nil
+}
+
+func (c *WebhookChannel) Send(ctx context.Context, notification entities.Notification) error {
+	body, err := c.render(notification)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, c.Client, c.URL, c.Headers, body)
+}
+
+func (c *WebhookChannel) render(notification entities.Notification) ([]byte, error) {
+	if c.body == nil {
+		return json.Marshal(notification)
+	}
+	var body bytes.Buffer
+	if err := c.body.Execute(&body, notification); err != nil {
+		return nil, fmt.Errorf("%w: rendering webhook body: %v", ports.ErrNotificationRejected, err)
+	}
+	if !json.Valid(body.Bytes()) {
+		return nil, fmt.Errorf("%w: webhook body template does not produce valid JSON", ports.ErrNotificationRejected)
+	}
+	return body.Bytes(), nil
+}
+
+func jsonValue(value interface{}) (string, error) {
+	encoded, err := json.Marshal(value)
+	return string(encoded), err
+}
+
+// SlackChannel envía el texto de cada notificación a un incoming webhook de Slack o de
+// cualquier servicio compatible (Mattermost, Rocket.Chat...).
+type SlackChannel struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewSlackChannel(url string) *SlackChannel {
+	return &SlackChannel{URL: url, Client: &http.Client{Timeout: defaultWebhookTimeout}}
+}
+
+func (c *SlackChannel) Send(ctx context.Context, notification entities.Notification) error {
+	body, err := json.Marshal(map[string]string{"text": slackText(notification)})
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, c.Client, c.URL, nil, body)
+}
+
+// slackText pone el resumen en negrita sobre los datos de la ejecución.
+func slackText(notification entities.Notification) string {
+	text := notification.Text()
+	subject := notification.Subject()
+	return "*" + subject + "*" + text[len(subject):]
+}
+
+// postJSON hace el POST y clasifica la respuesta: los 4xx son definitivos salvo 408 y 429,
+// que igual que los 5xx y los errores de red se pueden reintentar. La URL no aparece en
+// los errores porque en muchos servicios es el propio secreto.
+func postJSON(ctx context.Context, client *http.Client, target string, headers map[string]string, body []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: invalid webhook URL", ports.ErrNotificationRejected)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("sending webhook: %w", err)
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	switch {
+	case response.StatusCode >= 200 && response.StatusCode < 300:
+		return nil
+	case response.StatusCode >= 400 && response.StatusCode < 500 &&
+		response.StatusCode != http.StatusRequestTimeout && response.StatusCode != http.StatusTooManyRequests:
+		return fmt.Errorf("%w: webhook responded %s", ports.ErrNotificationRejected, response.Status)
+	default:
+		return fmt.Errorf("webhook responded %s", response.Status)
+	}
+}
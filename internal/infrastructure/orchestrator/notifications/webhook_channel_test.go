@@ -0,0 +1,94 @@
+package adapters
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var failedDeploy = entities.Notification{
+	Trigger:     entities.NotifyOnFailure,
+	TaskID:      "deploy",
+	TaskName:    "Deploy",
+	WorkspaceID: "payments",
+	ExecutionID: "execution-1",
+	Status:      entities.TaskFailed,
+	Error:       "exit code 1",
+	Timestamp:   time.Now(),
+}
+
+// webhookServer responde con status y guarda los cuerpos recibidos.
+func webhookServer(t *testing.T, status int) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, bodies...)
+	}
+}
+
+func TestWebhookChannel_RendersTemplate(t *testing.T) {
+	server, bodies := webhookServer(t, http.StatusNoContent)
+	channel, err := NewWebhookChannel(server.URL, `{"summary": {{json .Subject}}, "execution": {{json .ExecutionID}}}`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, channel.Send(context.Background(), failedDeploy))
+
+	var body map[string]string
+	if assert.Len(t, bodies(), 1) && assert.NoError(t, json.Unmarshal([]byte(bodies()[0]), &body)) {
+		assert.Equal(t, `Task "Deploy" failed with status FAILED`, body["summary"])
+		assert.Equal(t, "execution-1", body["execution"])
+	}
+
+	invalid, err := NewWebhookChannel(server.URL, `{"summary": {{.Subject}}}`)
+	if assert.NoError(t, err) {
+		assert.ErrorIs(t, invalid.Send(context.Background(), failedDeploy), ports.ErrNotificationRejected)
+	}
+	assert.Len(t, bodies(), 1)
+}
+
+func TestWebhookChannel_ClassifiesResponses(t *testing.T) {
+	badRequest, _ := webhookServer(t, http.StatusBadRequest)
+	channel, err := NewWebhookChannel(badRequest.URL, "")
+	if assert.NoError(t, err) {
+		assert.ErrorIs(t, channel.Send(context.Background(), failedDeploy), ports.ErrNotificationRejected)
+	}
+
+	unavailable, _ := webhookServer(t, http.StatusServiceUnavailable)
+	channel, err = NewWebhookChannel(unavailable.URL, "")
+	if assert.NoError(t, err) {
+		err = channel.Send(context.Background(), failedDeploy)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ports.ErrNotificationRejected)
+	}
+}
+
+func TestSlackChannel_PostsText(t *testing.T) {
+	server, bodies := webhookServer(t, http.StatusOK)
+	assert.NoError(t, NewSlackChannel(server.URL).Send(context.Background(), failedDeploy))
+
+	var body map[string]string
+	if assert.Len(t, bodies(), 1) && assert.NoError(t, json.Unmarshal([]byte(bodies()[0]), &body)) {
+		assert.True(t, strings.HasPrefix(body["text"], `*Task "Deploy" failed with status FAILED*`))
+		assert.Contains(t, body["text"], "Error: exit code 1")
+	}
+}
@@ -0,0 +1,85 @@
+package adapters
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	"devops_console/internal/ports/orchestrator"
+	"fmt"
+	"sync"
+)
+
+// Implementación en memoria de AuditRepository. Append rechaza las entradas que no
+// continúan la cadena, para que dos escritores no puedan bifurcarla.
+type InMemoryAuditRepository struct {
+	entries []entities.AuditEntry
+	mu      sync.Mutex
+}
+
+func NewInMemoryAuditRepository() *InMemoryAuditRepository {
+	return &InMemoryAuditRepository{}
+}
+
+func (r *InMemoryAuditRepository) Append(ctx context.Context, entry *entities.AuditEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	previous := ""
+	if len(r.entries) > 0 {
+		previous = r.entries[len(r.entries)-1].Hash
+	}
+	if entry.Sequence != int64(len(r.entries)+1) || entry.PreviousHash != previous {
+		return fmt.Errorf("%w: entry %d does not follow the last entry", entities.ErrAuditChainBroken, entry.Sequence)
+	}
+	stored := *entry
+	stored.Changes = append([]entities.AuditChange(nil), entry.Changes...)
+	r.entries = append(r.entries, stored)
+	return nil
+}
+
+func (r *InMemoryAuditRepository) Last(ctx context.Context) (entities.AuditEntry, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return entities.AuditEntry{}, false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) == 0 {
+		return entities.AuditEntry{}, false, nil
+	}
+	return r.entries[len(r.entries)-1], true, nil
+}
+
+func (r *InMemoryAuditRepository) Query(ctx context.Context, filters ports.AuditFilters) ([]entities.AuditEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var entries []entities.AuditEntry
+	for _, entry := range r.entries {
+		if filters.Limit > 0 && len(entries) == filters.Limit {
+			break
+		}
+		if matchesAudit(entry, filters) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func matchesAudit(entry entities.AuditEntry, filters ports.AuditFilters) bool {
+	switch {
+	case filters.TenantID != "" && entry.TenantID != filters.TenantID,
+		filters.WorkspaceID != "" && entry.WorkspaceID != filters.WorkspaceID,
+		filters.SubjectID != "" && entry.SubjectID != filters.SubjectID,
+		filters.TaskID != "" && entry.TaskID != filters.TaskID,
+		filters.ExecutionID != "" && entry.ExecutionID != filters.ExecutionID,
+		filters.Action != "" && entry.Action != filters.Action,
+		filters.Outcome != "" && entry.Outcome != filters.Outcome,
+		!filters.From.IsZero() && entry.Timestamp.Before(filters.From),
+		!filters.To.IsZero() && !entry.Timestamp.Before(filters.To):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+// internal/infrastructure/repositories/memory_pipeline_repository.go
+package adapters
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	"devops_console/internal/ports/orchestrator"
+	"fmt"
+	"sync"
+)
+
+// Implementación en memoria de PipelineRepository. Como InMemoryTaskRepository, limita los
+// pipelines al ámbito del contexto; las ejecuciones las limita el servicio por su pipeline.
+type InMemoryPipelineRepository struct {
+	pipelines  map[string]entities.Pipeline
+	executions map[string]entities.PipelineExecution
+	mu         sync.Mutex
+}
+
+func NewInMemoryPipelineRepository() *InMemoryPipelineRepository {
+	return &InMemoryPipelineRepository{
+		pipelines:  make(map[string]entities.Pipeline),
+		executions: make(map[string]entities.PipelineExecution),
+	}
+}
+
+func (r *InMemoryPipelineRepository) Create(ctx context.Context, pipeline *entities.Pipeline) error {
+	if !ports.InScope(ctx, pipeline.Workspace) {
+		return pipelineOutOfScope(pipeline)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.pipelines[pipeline.ID]; ok {
+		// Uno de fuera del ámbito no se encuentra, tampoco al reutilizar su ID.
+		if !ports.InScope(ctx, existing.Workspace) {
+			return fmt.Errorf("pipeline not found")
+		}
+		return fmt.Errorf("pipeline %s already exists", pipeline.ID)
+	}
+	r.pipelines[pipeline.ID] = *pipeline
+	return nil
+}
+
+func (r *InMemoryPipelineRepository) GetByID(ctx context.Context, pipelineID string) (entities.Pipeline, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pipeline, ok := r.pipelines[pipelineID]
+	if !ok || !ports.InScope(ctx, pipeline.Workspace) {
+		return entities.Pipeline{}, fmt.Errorf("pipeline not found")
+	}
+	return pipeline, nil
+}
+
+func (r *InMemoryPipelineRepository) Update(ctx context.Context, pipeline *entities.Pipeline) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.pipelines[pipeline.ID]; !ok || !ports.InScope(ctx, existing.Workspace) {
+		return fmt.Errorf("pipeline not found")
+	}
+	if !ports.InScope(ctx, pipeline.Workspace) {
+		return pipelineOutOfScope(pipeline)
+	}
+	r.pipelines[pipeline.ID] = *pipeline
+	return nil
+}
+
+func (r *InMemoryPipelineRepository) Delete(ctx context.Context, pipelineID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if pipeline, ok := r.pipelines[pipelineID]; ok && ports.InScope(ctx, pipeline.Workspace) {
+		delete(r.pipelines, pipelineID)
+	}
+	return nil
+}
+
+func (r *InMemoryPipelineRepository) GetAll(ctx context.Context) ([]entities.Pipeline, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pipelines := make([]entities.Pipeline, 0, len(r.pipelines))
+	for _, pipeline := range r.pipelines {
+		if ports.InScope(ctx, pipeline.Workspace) {
+			pipelines = append(pipelines, pipeline)
+		}
+	}
+	return pipelines, nil
+}
+
+func (r *InMemoryPipelineRepository) SaveExecution(ctx context.Context, execution *entities.PipelineExecution) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.executions[execution.ID] = execution.Clone()
+	return nil
+}
+
+func (r *InMemoryPipelineRepository) GetExecution(ctx context.Context, executionID string) (entities.PipelineExecution, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	execution, ok := r.executions[executionID]
+	if !ok {
+		return entities.PipelineExecution{}, fmt.Errorf("pipeline execution not found")
+	}
+	return execution.Clone(), nil
+}
+
+func pipelineOutOfScope(pipeline *entities.Pipeline) error {
+	return fmt.Errorf("%w: pipeline %s in workspace %q of tenant %q", ports.ErrOutOfScope, pipeline.ID, pipeline.Workspace.ID, pipeline.Workspace.TenantID)
+}
@@ -9,7 +9,11 @@ import (
 	"sync"
 )
 
-// Implementación sencilla de TaskRepository en memoria para el integration-tests
+// Implementación sencilla de TaskRepository en memoria para el integration-tests. Como un
+// repositorio real, falla con ctx.Err() si el contexto ya terminó y limita cada operación
+// al ámbito del contexto (ports.WithScope): las tareas de otro tenant o workspace no se
+// encuentran aunque se conozca su ID. Guarda y devuelve copias de las ejecuciones, que el
+// servicio modifica mientras otros las leen.
 type InMemoryTaskRepository struct {
 	tasks map[string]entities.DevOpsTask
 	mu    sync.Mutex
@@ -22,55 +26,119 @@ func NewInMemoryTaskRepository() *InMemoryTaskRepository {
 }
 
 func (r *InMemoryTaskRepository) Create(ctx context.Context, task *entities.DevOpsTask) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !ports.InScope(ctx, task.Workspace) {
+		return outOfScope(task)
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.tasks[task.ID] = *task
+	// Una tarea de fuera del ámbito no se encuentra, tampoco al reutilizar su ID.
+	if existing, ok := r.tasks[task.ID]; ok && !ports.InScope(ctx, existing.Workspace) {
+		return fmt.Errorf("task not found")
+	}
+	r.tasks[task.ID] = copyTask(*task)
 	return nil
 }
 
 func (r *InMemoryTaskRepository) Update(ctx context.Context, task *entities.DevOpsTask) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.tasks[task.ID] = *task
+	if existing, ok := r.tasks[task.ID]; !ok || !ports.InScope(ctx, existing.Workspace) {
+		return fmt.Errorf("task not found")
+	}
+	if !ports.InScope(ctx, task.Workspace) {
+		return outOfScope(task)
+	}
+	r.tasks[task.ID] = copyTask(*task)
 	return nil
 }
 
 func (r *InMemoryTaskRepository) Delete(ctx context.Context, taskID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	delete(r.tasks, taskID)
+	if task, ok := r.tasks[taskID]; ok && ports.InScope(ctx, task.Workspace) {
+		delete(r.tasks, taskID)
+	}
 	return nil
 }
 
 func (r *InMemoryTaskRepository) GetByID(ctx context.Context, taskID string) (entities.DevOpsTask, error) {
+	if err := ctx.Err(); err != nil {
+		return entities.DevOpsTask{}, err
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	task, ok := r.tasks[taskID]
-	if !ok {
+	if !ok || !ports.InScope(ctx, task.Workspace) {
 		return entities.DevOpsTask{}, fmt.Errorf("task not found")
 	}
-	return task, nil
+	return copyTask(task), nil
 }
 
-func (r *InMemoryTaskRepository) GetAll(ctx context.Context, filters ports.TaskFilters) ([]entities.DevOpsTask, error) {
+func (r *InMemoryTaskRepository) GetAll(ctx context.Context, filters ports.TaskFilters) (ports.TaskPage, error) {
+	if err := ctx.Err(); err != nil {
+		return ports.TaskPage{}, err
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	tasks := make([]entities.DevOpsTask, 0, len(r.tasks))
 	for _, task := range r.tasks {
-		tasks = append(tasks, task)
+		if ports.InScope(ctx, task.Workspace) {
+			tasks = append(tasks, copyTask(task))
+		}
 	}
-	return tasks, nil
+	return queryTasks(tasks, filters)
 }
 
 func (r *InMemoryTaskRepository) GetByExecutionID(ctx context.Context, executionID string) (entities.DevOpsTask, error) {
+	if err := ctx.Err(); err != nil {
+		return entities.DevOpsTask{}, err
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	for _, task := range r.tasks {
+		if !ports.InScope(ctx, task.Workspace) {
+			continue
+		}
 		for _, execution := range task.Executions {
 			if execution.ID == executionID {
-				return task, nil
+				return copyTask(task), nil
 			}
 		}
 	}
 	return entities.DevOpsTask{}, fmt.Errorf("task not found")
 }
+
+// copyTask devuelve una copia de la tarea que no comparte sus ejecuciones ni sus
+// aprobaciones.
+func copyTask(task entities.DevOpsTask) entities.DevOpsTask {
+	if task.Executions != nil {
+		executions := make([]*entities.TaskExecution, len(task.Executions))
+		for i, execution := range task.Executions {
+			copied := execution.Clone()
+			executions[i] = &copied
+		}
+		task.Executions = executions
+	}
+	if task.Approvals != nil {
+		approvals := make([]*entities.Approval, len(task.Approvals))
+		for i, approval := range task.Approvals {
+			copied := *approval
+			approvals[i] = &copied
+		}
+		task.Approvals = approvals
+	}
+	return task
+}
+
+func outOfScope(task *entities.DevOpsTask) error {
+	return fmt.Errorf("%w: task %s in workspace %q of tenant %q", ports.ErrOutOfScope, task.ID, task.Workspace.ID, task.Workspace.TenantID)
+}
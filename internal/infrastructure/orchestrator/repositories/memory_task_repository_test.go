@@ -0,0 +1,29 @@
+package adapters
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestInMemoryTaskRepository_CopiesExecutions(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryTaskRepository()
+	execution := &entities.TaskExecution{ID: "execution-1", Status: entities.TaskRunning, ExecutionDetails: map[string]interface{}{}}
+	task := entities.DevOpsTask{ID: "build", Executions: []*entities.TaskExecution{execution}}
+	require.NoError(t, repo.Create(ctx, &task))
+
+	// Ni lo que se guardó ni lo que se leyó comparte la ejecución con el repositorio.
+	execution.Status = entities.TaskFailed
+	read, err := repo.GetByExecutionID(ctx, "execution-1")
+	require.NoError(t, err)
+	read.Executions[0].Status = entities.TaskSucceeded
+	read.Executions[0].ExecutionDetails["ContainerID"] = "container-1"
+
+	stored, err := repo.GetByID(ctx, "build")
+	require.NoError(t, err)
+	assert.Equal(t, entities.TaskRunning, stored.Executions[0].Status)
+	assert.Empty(t, stored.Executions[0].ExecutionDetails)
+}
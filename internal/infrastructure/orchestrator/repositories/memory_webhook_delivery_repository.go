@@ -0,0 +1,53 @@
+package adapters
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Implementación en memoria de WebhookDeliveryRepository
+type InMemoryWebhookDeliveryRepository struct {
+	deliveries map[string]entities.WebhookDelivery
+	mu         sync.Mutex
+}
+
+func NewInMemoryWebhookDeliveryRepository() *InMemoryWebhookDeliveryRepository {
+	return &InMemoryWebhookDeliveryRepository{
+		deliveries: make(map[string]entities.WebhookDelivery),
+	}
+}
+
+func (r *InMemoryWebhookDeliveryRepository) Save(ctx context.Context, delivery *entities.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (r *InMemoryWebhookDeliveryRepository) GetByID(ctx context.Context, deliveryID string) (entities.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery, ok := r.deliveries[deliveryID]
+	if !ok {
+		return entities.WebhookDelivery{}, fmt.Errorf("webhook delivery not found")
+	}
+	return delivery, nil
+}
+
+func (r *InMemoryWebhookDeliveryRepository) ListByTask(ctx context.Context, taskID string) ([]entities.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deliveries []entities.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.TaskID == taskID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].ReceivedAt.After(deliveries[j].ReceivedAt)
+	})
+	return deliveries, nil
+}
@@ -0,0 +1,163 @@
+package adapters
+
+import (
+	"devops_console/internal/domain/entities/orchestrator"
+	"devops_console/internal/ports/orchestrator"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// taskCursor identifica la última tarea de una página y el orden con el que se obtuvo.
+type taskCursor struct {
+	SortBy     ports.TaskSortField `json:"s"`
+	Descending bool                `json:"d"`
+	Name       string              `json:"n,omitempty"`
+	Time       time.Time           `json:"t,omitempty"`
+	ID         string              `json:"id"`
+}
+
+// queryTasks filtra, ordena y pagina las tareas según filters.
+func queryTasks(tasks []entities.DevOpsTask, filters ports.TaskFilters) (ports.TaskPage, error) {
+	sortBy := filters.SortBy
+	if sortBy == "" {
+		sortBy = ports.TaskSortByCreatedAt
+	}
+	switch sortBy {
+	case ports.TaskSortByCreatedAt, ports.TaskSortByUpdatedAt, ports.TaskSortByName:
+	default:
+		return ports.TaskPage{}, fmt.Errorf("unknown sort field %q", sortBy)
+	}
+
+	matched := make([]entities.DevOpsTask, 0, len(tasks))
+	for _, task := range tasks {
+		if matchesTask(&task, filters) {
+			matched = append(matched, task)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		c := compareTasks(cursorOf(&matched[i], sortBy), cursorOf(&matched[j], sortBy))
+		if filters.Descending {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	if filters.Cursor != "" {
+		cursor, err := decodeCursor(filters.Cursor)
+		if err != nil || cursor.SortBy != sortBy || cursor.Descending != filters.Descending {
+			return ports.TaskPage{}, ports.ErrInvalidCursor
+		}
+		start := sort.Search(len(matched), func(i int) bool {
+			c := compareTasks(cursor, cursorOf(&matched[i], sortBy))
+			if filters.Descending {
+				return c > 0
+			}
+			return c < 0
+		})
+		matched = matched[start:]
+	}
+
+	page := ports.TaskPage{Tasks: matched}
+	if filters.Limit > 0 && len(matched) > filters.Limit {
+		page.Tasks = matched[:filters.Limit]
+		last := cursorOf(&page.Tasks[filters.Limit-1], sortBy)
+		last.Descending = filters.Descending
+		page.NextCursor = encodeCursor(last)
+	}
+	return page, nil
+}
+
+func matchesTask(task *entities.DevOpsTask, filters ports.TaskFilters) bool {
+	if filters.WorkspaceID != "" && task.Workspace.ID != filters.WorkspaceID {
+		return false
+	}
+	if filters.TenantID != "" && task.Workspace.TenantID != filters.TenantID {
+		return false
+	}
+	if filters.TaskType != "" && task.TaskType != filters.TaskType {
+		return false
+	}
+	for _, tag := range filters.Tags {
+		if !containsString(task.Tags, tag) {
+			return false
+		}
+	}
+	if filters.Search != "" {
+		search := strings.ToLower(filters.Search)
+		if !strings.Contains(strings.ToLower(task.Name), search) && !strings.Contains(strings.ToLower(task.Title), search) {
+			return false
+		}
+	}
+	if !inRange(task.CreatedAt, filters.CreatedAfter, filters.CreatedBefore) || !inRange(task.UpdatedAt, filters.UpdatedAfter, filters.UpdatedBefore) {
+		return false
+	}
+	if filters.LastExecutionStatus != "" {
+		if len(task.Executions) == 0 || task.Executions[len(task.Executions)-1].Status != filters.LastExecutionStatus {
+			return false
+		}
+	}
+	return true
+}
+
+func inRange(t, from, to time.Time) bool {
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+	return to.IsZero() || t.Before(to)
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+func cursorOf(task *entities.DevOpsTask, sortBy ports.TaskSortField) taskCursor {
+	cursor := taskCursor{SortBy: sortBy, ID: task.ID}
+	switch sortBy {
+	case ports.TaskSortByName:
+		cursor.Name = task.Name
+	case ports.TaskSortByUpdatedAt:
+		cursor.Time = task.UpdatedAt
+	default:
+		cursor.Time = task.CreatedAt
+	}
+	return cursor
+}
+
+// compareTasks compara por el campo de orden y, a igualdad, por ID.
+func compareTasks(a, b taskCursor) int {
+	if a.SortBy == ports.TaskSortByName {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+	} else if !a.Time.Equal(b.Time) {
+		if a.Time.Before(b.Time) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+func encodeCursor(cursor taskCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (taskCursor, error) {
+	var cursor taskCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
+}
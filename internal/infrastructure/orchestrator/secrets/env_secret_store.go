@@ -0,0 +1,33 @@
+package adapters
+
+import (
+	"context"
+	ports "devops_console/internal/ports/orchestrator"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvSecretStore lee los secretos de las variables de entorno del proceso. La clave
+// "db/password" con el prefijo "SECRET_" se busca en SECRET_DB_PASSWORD.
+type EnvSecretStore struct {
+	Prefix string
+}
+
+func NewEnvSecretStore(prefix string) *EnvSecretStore {
+	return &EnvSecretStore{Prefix: prefix}
+}
+
+func (s *EnvSecretStore) GetSecret(ctx context.Context, key string) (string, error) {
+	name := s.Prefix + strings.ToUpper(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, key))
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ports.ErrSecretNotFound, key)
+	}
+	return value, nil
+}
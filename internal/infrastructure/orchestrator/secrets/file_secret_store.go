@@ -0,0 +1,123 @@
+package adapters
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	ports "devops_console/internal/ports/orchestrator"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// FileSecretStore guarda los secretos en un fichero local cifrado con AES-256-GCM. El
+// fichero contiene el nonce seguido del JSON cifrado con todos los secretos.
+type FileSecretStore struct {
+	path string
+	aead cipher.AEAD
+	mu   sync.Mutex
+}
+
+// NewFileSecretStore abre el almacén de path con una clave de 32 bytes. El fichero se crea
+// al guardar el primer secreto.
+func NewFileSecretStore(path string, key []byte) (*FileSecretStore, error) {
+	if len(key) != 32 {
+		return nil, errors.New("secret store key must be 32 bytes long")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSecretStore{path: path, aead: aead}, nil
+}
+
+func (s *FileSecretStore) GetSecret(ctx context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ports.ErrSecretNotFound, key)
+	}
+	return value, nil
+}
+
+// SetSecret guarda o sustituye un secreto y reescribe el fichero.
+func (s *FileSecretStore) SetSecret(ctx context.Context, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[key] = value
+	return s.save(secrets)
+}
+
+// DeleteSecret elimina un secreto del fichero.
+func (s *FileSecretStore) DeleteSecret(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[key]; !ok {
+		return fmt.Errorf("%w: %s", ports.ErrSecretNotFound, key)
+	}
+	delete(secrets, key)
+	return s.save(secrets)
+}
+
+func (s *FileSecretStore) load() (map[string]string, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]string), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := s.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("secret store file is corrupted")
+	}
+	plaintext, err := s.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting secret store: %w", err)
+	}
+	secrets := make(map[string]string)
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("decoding secret store: %w", err)
+	}
+	return secrets, nil
+}
+
+func (s *FileSecretStore) save(secrets map[string]string) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	data := s.aead.Seal(nonce, nonce, plaintext, nil)
+
+	// Se escribe en un temporal y se renombra para no dejar el fichero a medias.
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,61 @@
+package adapters
+
+import (
+	"context"
+	ports "devops_console/internal/ports/orchestrator"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// KubernetesSecretStore lee los secretos de objetos Secret de un namespace. Las claves
+// tienen la forma <secret>/<clave>.
+type KubernetesSecretStore struct {
+	clientset kubernetes.Interface
+	namespace string
+}
+
+func NewKubernetesSecretStore(clientset kubernetes.Interface, namespace string) *KubernetesSecretStore {
+	return &KubernetesSecretStore{clientset: clientset, namespace: namespace}
+}
+
+func (s *KubernetesSecretStore) GetSecret(ctx context.Context, key string) (string, error) {
+	selector, err := s.SecretKeySelector(key)
+	if err != nil {
+		return "", err
+	}
+	secret, err := s.clientset.CoreV1().Secrets(s.namespace).Get(ctx, selector.Name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return "", fmt.Errorf("%w: %s", ports.ErrSecretNotFound, key)
+	}
+	if err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[selector.Key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ports.ErrSecretNotFound, key)
+	}
+	return string(value), nil
+}
+
+// SecretKeySelector traduce la clave a la referencia secretKeyRef con la que el pod lee el
+// secreto sin que su valor pase por el orquestador.
+func (s *KubernetesSecretStore) SecretKeySelector(key string) (*corev1.SecretKeySelector, error) {
+	name, secretKey, ok := strings.Cut(key, "/")
+	if !ok || name == "" || secretKey == "" {
+		return nil, fmt.Errorf("invalid kubernetes secret key %q, expected <secret>/<key>", key)
+	}
+	return &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: name},
+		Key:                  secretKey,
+	}, nil
+}
+
+// Namespace es el namespace del que se leen los secretos.
+func (s *KubernetesSecretStore) Namespace() string {
+	return s.namespace
+}
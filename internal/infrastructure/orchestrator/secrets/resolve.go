@@ -0,0 +1,44 @@
+package adapters
+
+import (
+	"context"
+	entities "devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"errors"
+	"fmt"
+)
+
+// ResolveSecrets obtiene del almacén el valor de cada referencia. Devuelve las variables de
+// entorno NOMBRE=valor, en el orden de las referencias, y los valores a ocultar en la salida.
+func ResolveSecrets(ctx context.Context, store ports.SecretStore, references []entities.SecretReference) ([]string, []string, error) {
+	if len(references) == 0 {
+		return nil, nil, nil
+	}
+	if store == nil {
+		return nil, nil, errors.New("task references secrets but no secret store is configured")
+	}
+	env := make([]string, 0, len(references))
+	values := make([]string, 0, len(references))
+	for _, reference := range references {
+		value, err := store.GetSecret(ctx, reference.Key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("resolving secret for %s: %w", reference.EnvVar, err)
+		}
+		env = append(env, reference.EnvVar+"="+value)
+		values = append(values, value)
+	}
+	return env, values, nil
+}
+
+// PlanSecrets comprueba que existen los secretos referenciados y devuelve sus variables de
+// entorno con el valor enmascarado, para mostrarlas en un plan.
+func PlanSecrets(ctx context.Context, store ports.SecretStore, references []entities.SecretReference) ([]string, error) {
+	env, _, err := ResolveSecrets(ctx, store, references)
+	if err != nil {
+		return nil, err
+	}
+	for i, reference := range references {
+		env[i] = reference.EnvVar + "=" + entities.MaskedParameterValue
+	}
+	return env, nil
+}
@@ -0,0 +1,57 @@
+package adapters
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSecretStore_EncryptsSecrets(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "enc")
+	key := []byte(strings.Repeat("k", 32))
+
+	store, err := NewFileSecretStore(path, key)
+	require.NoError(t, err)
+	require.NoError(t, store.SetSecret(ctx, "db/password", "hunter2"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+
+	reopened, err := NewFileSecretStore(path, key)
+	require.NoError(t, err)
+	value, err := reopened.GetSecret(ctx, "db/password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", value)
+
+	_, err = reopened.GetSecret(ctx, "db/user")
+	assert.ErrorIs(t, err, ports.ErrSecretNotFound)
+
+	wrongKey, err := NewFileSecretStore(path, []byte(strings.Repeat("x", 32)))
+	require.NoError(t, err)
+	_, err = wrongKey.GetSecret(ctx, "db/password")
+	assert.Error(t, err)
+}
+
+func TestResolveSecrets(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("SECRET_DB_PASSWORD", "hunter2")
+	references := []entities.SecretReference{{EnvVar: "PGPASSWORD", Key: "db/password"}}
+
+	env, values, err := ResolveSecrets(ctx, NewEnvSecretStore("SECRET_"), references)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PGPASSWORD=hunter2"}, env)
+	assert.Equal(t, []string{"hunter2"}, values)
+
+	_, _, err = ResolveSecrets(ctx, NewEnvSecretStore("OTHER_"), references)
+	assert.ErrorIs(t, err, ports.ErrSecretNotFound)
+	_, _, err = ResolveSecrets(ctx, nil, references)
+	assert.Error(t, err)
+}
@@ -4,9 +4,14 @@ import (
 	"context"
 	entities "devops_console/internal/domain/entities/orchestrator"
 	pb "devops_console/internal/infrastructure/agent/proto/agent/v1"
+	eventstream "devops_console/internal/infrastructure/orchestrator/events"
+	secrets "devops_console/internal/infrastructure/orchestrator/secrets"
 	ports "devops_console/internal/ports/orchestrator"
 	"fmt"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 	"log"
+	"strings"
 	"sync"
 	"time"
 )
@@ -15,9 +20,13 @@ import (
 type AgentServer struct {
 	pb.UnimplementedAgentServiceServer
 	agents      map[string]*ConnectedAgent
-	eventStream ports.TaskEventStream
+	eventStream *eventstream.RedactingTaskEventStream
 	TaskQueue   chan *pb.Command
 	mu          sync.RWMutex
+	// SecretStore resuelve los secretos de los comandos enviados con Dispatch.
+	SecretStore ports.SecretStore
+	// Metrics, si se configura, recibe las conexiones de los agentes y sus recursos.
+	Metrics ports.AgentMetrics
 }
 
 // ConnectedAgent representa un agente conectado.
@@ -32,7 +41,7 @@ type ConnectedAgent struct {
 func NewAgentServer(eventStream ports.TaskEventStream) *AgentServer {
 	return &AgentServer{
 		agents:      make(map[string]*ConnectedAgent),
-		eventStream: eventStream,
+		eventStream: eventstream.NewRedactingTaskEventStream(eventStream),
 		TaskQueue:   make(chan *pb.Command, 100),
 	}
 }
@@ -48,6 +57,9 @@ func (s *AgentServer) Connect(req *pb.ConnectRequest, stream pb.AgentService_Con
 	}
 	s.agents[agent.ID] = agent
 	s.mu.Unlock()
+	if s.Metrics != nil {
+		s.Metrics.AgentConnected(agent.ID)
+	}
 
 	log.Printf("Agent %s connected with system info: %+v", agent.ID, agent.Info)
 
@@ -55,6 +67,9 @@ func (s *AgentServer) Connect(req *pb.ConnectRequest, stream pb.AgentService_Con
 		s.mu.Lock()
 		delete(s.agents, agent.ID)
 		s.mu.Unlock()
+		if s.Metrics != nil {
+			s.Metrics.AgentDisconnected(agent.ID)
+		}
 		log.Printf("Agent %s disconnected", agent.ID)
 	}()
 
@@ -71,29 +86,89 @@ func (s *AgentServer) Connect(req *pb.ConnectRequest, stream pb.AgentService_Con
 			log.Printf("Error sending command to agent %s: %v", agent.ID, err)
 			return err
 		}
-		log.Printf("Command sent to agent %s: %+v", agent.ID, cmd)
+		// El comando no se registra entero porque su entorno puede llevar secretos.
+		log.Printf("Command %s sent to agent %s", cmd.CommandId, agent.ID)
 	}
 
 	return nil
 }
 
+// Dispatch encola un comando para los agentes inyectando en su entorno los secretos
+// referenciados, cuyos valores se ocultan en la salida que devuelva el agente.
+func (s *AgentServer) Dispatch(ctx context.Context, cmd *pb.Command, references []entities.SecretReference) error {
+	env, values, err := secrets.ResolveSecrets(ctx, s.SecretStore, references)
+	if err != nil {
+		return err
+	}
+	if len(env) > 0 && cmd.Environment == nil {
+		cmd.Environment = make(map[string]string, len(env))
+	}
+	for _, variable := range env {
+		name, value, _ := strings.Cut(variable, "=")
+		cmd.Environment[name] = value
+	}
+	s.eventStream.Redact(cmd.CommandId, values)
+
+	select {
+	case s.TaskQueue <- cmd:
+		return nil
+	case <-ctx.Done():
+		s.eventStream.Forget(cmd.CommandId)
+		return ctx.Err()
+	}
+}
+
+// Plan devuelve en JSON el comando que enviaría Dispatch, sin encolarlo. Los secretos se
+// comprueban pero aparecen enmascarados.
+func (s *AgentServer) Plan(ctx context.Context, cmd *pb.Command, references []entities.SecretReference) (entities.ExecutionPlan, error) {
+	env, err := secrets.PlanSecrets(ctx, s.SecretStore, references)
+	if err != nil {
+		return entities.ExecutionPlan{}, err
+	}
+	planned := proto.Clone(cmd).(*pb.Command)
+	if len(env) > 0 && planned.Environment == nil {
+		planned.Environment = make(map[string]string, len(env))
+	}
+	for _, variable := range env {
+		name, value, _ := strings.Cut(variable, "=")
+		planned.Environment[name] = value
+	}
+	manifest, err := protojson.MarshalOptions{Multiline: true}.Marshal(planned)
+	if err != nil {
+		return entities.ExecutionPlan{}, err
+	}
+	return entities.ExecutionPlan{
+		WorkerType: "Agent",
+		Format:     "json",
+		Manifest:   string(manifest),
+	}, nil
+}
+
 // SendEvent maneja el envío de eventos desde el agente.
 func (s *AgentServer) SendEvent(ctx context.Context, event *pb.ExecutionEvent) (*pb.EventAck, error) {
-	log.Printf("Received event from agent: %s, type: %s, payload: %s", event.CommandId, event.Type, event.Payload)
+	log.Printf("Received event from agent: %s, type: %s", event.CommandId, event.Type)
 
+	// La salida llega a trozos: se publica por líneas para poder ocultar los secretos.
+	if event.Type == pb.EventType_OUTPUT {
+		s.eventStream.PublishOutput(event.CommandId, event.Payload, time.Unix(0, event.Timestamp))
+		return &pb.EventAck{}, nil
+	}
 	// Convertir y publicar en EventStream
 	s.eventStream.Publish(entities.TaskEvent{
-		ID:        event.CommandId,
-		EventType: mapEventType(event.Type),
-		Payload:   event.Payload,
-		Timestamp: time.Unix(0, event.Timestamp),
+		ID:          event.CommandId,
+		ExecutionID: event.CommandId,
+		EventType:   mapEventType(event.Type),
+		Payload:     event.Payload,
+		Timestamp:   time.Unix(0, event.Timestamp),
 	})
-	log.Printf("Event received from agent %s: %+v", event.CommandId, event)
 	return &pb.EventAck{}, nil
 }
 
 // SendMetrics maneja el envío de métricas desde el agente.
 func (s *AgentServer) SendMetrics(ctx context.Context, metrics *pb.MetricsUpdate) (*pb.MetricsAck, error) {
+	if s.Metrics != nil {
+		s.Metrics.AgentUsage(metrics.AgentId, metrics.System.GetCpuUsage(), metrics.System.GetMemoryUsage())
+	}
 	// Procesar métricas recibidas
 	s.eventStream.Publish(entities.TaskEvent{
 		ID:        metrics.AgentId,
@@ -0,0 +1,99 @@
+package server
+
+import (
+	orchestrator "devops_console/internal/application/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ArtifactHandler expone los artefactos de las ejecuciones: GET
+// /executions/{id}/artifacts los lista y GET /executions/{id}/artifacts/{name} descarga
+// uno. El ETag es el digest del contenido, que no cambia.
+type ArtifactHandler struct {
+	service orchestrator.ArtifactService
+	mux     *http.ServeMux
+}
+
+// NewArtifactHandler crea el handler HTTP de los artefactos.
+func NewArtifactHandler(service orchestrator.ArtifactService) *ArtifactHandler {
+	h := &ArtifactHandler{service: service, mux: http.NewServeMux()}
+	h.mux.HandleFunc("GET /executions/{id}/artifacts", h.list)
+	h.mux.HandleFunc("GET /executions/{id}/artifacts/{name}", h.download)
+	return h
+}
+
+func (h *ArtifactHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+type artifactResponse struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Type      string `json:"type"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (h *ArtifactHandler) list(w http.ResponseWriter, r *http.Request) {
+	artifacts, err := h.service.ListArtifacts(requestContext(r), r.PathValue("id"))
+	if err != nil {
+		writeArtifactError(w, err)
+		return
+	}
+	response := make([]artifactResponse, len(artifacts))
+	for i, artifact := range artifacts {
+		response[i] = artifactResponse{
+			Name:      artifact.Name,
+			Path:      artifact.Path,
+			Type:      artifact.Type,
+			Digest:    artifact.Digest,
+			Size:      artifact.Size,
+			CreatedAt: artifact.CreatedAt.UTC().Format(time.RFC3339),
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *ArtifactHandler) download(w http.ResponseWriter, r *http.Request) {
+	artifact, content, err := h.service.OpenArtifact(requestContext(r), r.PathValue("id"), r.PathValue("name"))
+	if err != nil {
+		writeArtifactError(w, err)
+		return
+	}
+	defer content.Close()
+
+	etag := strconv.Quote(artifact.Digest)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", artifact.Type)
+	w.Header().Set("Content-Length", strconv.FormatInt(artifact.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": artifact.Name}))
+	w.Header().Set("ETag", etag)
+	// El contenido se escribe a medida que se lee: un error a mitad solo puede ir al log.
+	if _, err := io.Copy(w, content); err != nil {
+		log.Printf("Error sending artifact %s: %v", artifact.Digest, err)
+	}
+}
+
+func writeArtifactError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, orchestrator.ErrExecutionNotFound), errors.Is(err, ports.ErrArtifactNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, orchestrator.ErrPermissionDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		log.Printf("Error serving artifacts: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	orchestrator "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// AuditHandler expone el registro de auditoría: GET /audit devuelve en JSON lines las
+// entradas que cumplen los filtros de la query (tenant, workspace, subject, task,
+// execution, action, outcome, from, to en RFC 3339 y limit) y GET /audit/verify comprueba
+// la cadena de hashes. El ámbito de la petición (ports.WithScope) lo pone la autenticación
+// que va delante del handler; sin él GET /audit responde 403.
+type AuditHandler struct {
+	service orchestrator.AuditService
+	mux     *http.ServeMux
+}
+
+// NewAuditHandler crea el handler HTTP del registro de auditoría.
+func NewAuditHandler(service orchestrator.AuditService) *AuditHandler {
+	h := &AuditHandler{service: service, mux: http.NewServeMux()}
+	h.mux.HandleFunc("GET /audit", h.export)
+	h.mux.HandleFunc("GET /audit/verify", h.verify)
+	return h
+}
+
+func (h *AuditHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *AuditHandler) export(w http.ResponseWriter, r *http.Request) {
+	filters, err := auditFilters(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Las entradas se escriben a medida que se codifican: un error a mitad solo puede ir al
+	// log. La autorización se comprueba antes de escribir nada.
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	err = h.service.Export(requestContext(r), filters, w)
+	switch {
+	case errors.Is(err, orchestrator.ErrPermissionDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		log.Printf("Error exporting audit log: %v", err)
+	}
+}
+
+func (h *AuditHandler) verify(w http.ResponseWriter, r *http.Request) {
+	err := h.service.Verify(requestContext(r))
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case err == nil:
+		json.NewEncoder(w).Encode(map[string]interface{}{"valid": true})
+	case errors.Is(err, entities.ErrAuditChainBroken):
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{"valid": false, "error": err.Error()})
+	default:
+		log.Printf("Error verifying audit log: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func auditFilters(r *http.Request) (ports.AuditFilters, error) {
+	query := r.URL.Query()
+	filters := ports.AuditFilters{
+		TenantID:    query.Get("tenant"),
+		WorkspaceID: query.Get("workspace"),
+		SubjectID:   query.Get("subject"),
+		TaskID:      query.Get("task"),
+		ExecutionID: query.Get("execution"),
+		Action:      entities.AuditAction(query.Get("action")),
+		Outcome:     entities.AuditOutcome(query.Get("outcome")),
+	}
+	for name, target := range map[string]*time.Time{"from": &filters.From, "to": &filters.To} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return ports.AuditFilters{}, fmt.Errorf("invalid %s: %v", name, err)
+			}
+			*target = parsed
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return ports.AuditFilters{}, fmt.Errorf("invalid limit %q", value)
+		}
+		filters.Limit = limit
+	}
+	return filters, nil
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	orchestrator "devops_console/internal/application/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// maxManifestSize limita el tamaño de los manifiestos aceptados.
+const maxManifestSize = 4 << 20
+
+// ManifestHandler expone la aplicación y exportación de manifiestos de tareas:
+// POST /manifests/apply?prune=&dryRun= con el manifiesto en el cuerpo y
+// GET /manifests/export?format=&workspace=.
+type ManifestHandler struct {
+	service orchestrator.ManifestService
+	mux     *http.ServeMux
+}
+
+// NewManifestHandler crea el handler HTTP de los manifiestos.
+func NewManifestHandler(service orchestrator.ManifestService) *ManifestHandler {
+	h := &ManifestHandler{service: service, mux: http.NewServeMux()}
+	h.mux.HandleFunc("POST /manifests/apply", h.apply)
+	h.mux.HandleFunc("GET /manifests/export", h.export)
+	return h
+}
+
+func (h *ManifestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+type applyResponse struct {
+	Changes []orchestrator.ManifestChange `json:"changes"`
+	Error   string                        `json:"error,omitempty"`
+}
+
+func (h *ManifestHandler) apply(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxManifestSize))
+	if err != nil {
+		writeApplyResponse(w, http.StatusRequestEntityTooLarge, applyResponse{Error: "manifest too large"})
+		return
+	}
+	options := orchestrator.ApplyOptions{Prune: queryBool(r, "prune"), DryRun: queryBool(r, "dryRun")}
+
+	changes, err := h.service.Apply(requestContext(r), body, options)
+	response := applyResponse{Changes: changes}
+	if changes == nil {
+		response.Changes = []orchestrator.ManifestChange{}
+	}
+	switch {
+	case err == nil:
+		writeApplyResponse(w, http.StatusOK, response)
+	case errors.Is(err, orchestrator.ErrInvalidManifest), errors.Is(err, orchestrator.ErrInvalidTask):
+		response.Error = err.Error()
+		writeApplyResponse(w, http.StatusUnprocessableEntity, response)
+	case errors.Is(err, orchestrator.ErrPermissionDenied), errors.Is(err, ports.ErrOutOfScope):
+		response.Error = err.Error()
+		writeApplyResponse(w, http.StatusForbidden, response)
+	default:
+		log.Printf("Error applying manifest: %v", err)
+		response.Error = err.Error()
+		writeApplyResponse(w, http.StatusInternalServerError, response)
+	}
+}
+
+func (h *ManifestHandler) export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	filters := ports.TaskFilters{WorkspaceID: r.URL.Query().Get("workspace")}
+
+	data, err := h.service.Export(requestContext(r), filters, format)
+	switch {
+	case err == nil:
+	case errors.Is(err, orchestrator.ErrInvalidManifest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, orchestrator.ErrPermissionDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	default:
+		log.Printf("Error exporting manifest: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "application/yaml")
+	}
+	w.Write(data)
+}
+
+func queryBool(r *http.Request, name string) bool {
+	value, _ := strconv.ParseBool(r.URL.Query().Get(name))
+	return value
+}
+
+func writeApplyResponse(w http.ResponseWriter, status int, response applyResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
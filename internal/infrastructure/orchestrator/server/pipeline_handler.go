@@ -0,0 +1,141 @@
+package server
+
+import (
+	orchestrator "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// maxPipelineSize limita el tamaño de las definiciones de pipeline aceptadas.
+const maxPipelineSize = 1 << 20
+
+// PipelineHandler expone los pipelines en JSON: POST /pipelines y GET /pipelines, GET, PUT
+// y DELETE /pipelines/{id}, POST /pipelines/{id}/executions para lanzarlo y GET y DELETE
+// /pipeline-executions/{id} para seguir o cancelar una ejecución.
+type PipelineHandler struct {
+	service orchestrator.PipelineService
+	mux     *http.ServeMux
+}
+
+// NewPipelineHandler crea el handler HTTP de los pipelines.
+func NewPipelineHandler(service orchestrator.PipelineService) *PipelineHandler {
+	h := &PipelineHandler{service: service, mux: http.NewServeMux()}
+	h.mux.HandleFunc("POST /pipelines", h.create)
+	h.mux.HandleFunc("GET /pipelines", h.list)
+	h.mux.HandleFunc("GET /pipelines/{id}", h.get)
+	h.mux.HandleFunc("PUT /pipelines/{id}", h.update)
+	h.mux.HandleFunc("DELETE /pipelines/{id}", h.delete)
+	h.mux.HandleFunc("POST /pipelines/{id}/executions", h.execute)
+	h.mux.HandleFunc("GET /pipeline-executions/{id}", h.getExecution)
+	h.mux.HandleFunc("DELETE /pipeline-executions/{id}", h.cancelExecution)
+	return h
+}
+
+func (h *PipelineHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *PipelineHandler) create(w http.ResponseWriter, r *http.Request) {
+	var pipeline entities.Pipeline
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPipelineSize)).Decode(&pipeline); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	created, err := h.service.CreatePipeline(requestContext(r), pipeline)
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (h *PipelineHandler) list(w http.ResponseWriter, r *http.Request) {
+	pipelines, err := h.service.GetPipelines(requestContext(r))
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pipelines)
+}
+
+func (h *PipelineHandler) get(w http.ResponseWriter, r *http.Request) {
+	pipeline, err := h.service.GetPipeline(requestContext(r), r.PathValue("id"))
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pipeline)
+}
+
+func (h *PipelineHandler) update(w http.ResponseWriter, r *http.Request) {
+	var pipeline entities.Pipeline
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPipelineSize)).Decode(&pipeline); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pipeline.ID = r.PathValue("id")
+	updated, err := h.service.UpdatePipeline(requestContext(r), pipeline)
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (h *PipelineHandler) delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeletePipeline(requestContext(r), r.PathValue("id")); err != nil {
+		writePipelineError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PipelineHandler) execute(w http.ResponseWriter, r *http.Request) {
+	executionID, err := h.service.ExecutePipeline(requestContext(r), r.PathValue("id"))
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"executionId": executionID})
+}
+
+func (h *PipelineHandler) getExecution(w http.ResponseWriter, r *http.Request) {
+	execution, err := h.service.GetPipelineExecution(requestContext(r), r.PathValue("id"))
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, execution)
+}
+
+func (h *PipelineHandler) cancelExecution(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.CancelPipelineExecution(requestContext(r), r.PathValue("id")); err != nil {
+		writePipelineError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writePipelineError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, orchestrator.ErrInvalidPipeline):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, orchestrator.ErrPipelineExecutionNotRunning):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, orchestrator.ErrPermissionDenied), errors.Is(err, ports.ErrOutOfScope):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		log.Printf("Error serving pipelines: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
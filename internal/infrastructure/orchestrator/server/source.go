@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	orchestrator "devops_console/internal/application/orchestrator"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"net"
+	"net/http"
+)
+
+// requestContext devuelve el contexto de la petición con su origen registrado para la
+// auditoría: la dirección del cliente o, detrás de un proxy, la cadena de X-Forwarded-For
+// seguida de la dirección del proxy. Solo esta última la comprueba el servidor; las de la
+// cabecera las puede poner el cliente.
+func requestContext(r *http.Request) context.Context {
+	source := remoteHost(r.RemoteAddr)
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		source = forwarded + ", " + source
+	}
+	return orchestrator.WithSource(r.Context(), source)
+}
+
+// SourceUnaryInterceptor registra como origen de cada llamada gRPC la dirección del cliente.
+func SourceUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(peerContext(ctx), req)
+}
+
+// SourceStreamInterceptor hace lo mismo que SourceUnaryInterceptor con los streams.
+func SourceStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &sourceStream{ServerStream: stream, ctx: peerContext(stream.Context())})
+}
+
+type sourceStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *sourceStream) Context() context.Context {
+	return s.ctx
+}
+
+func peerContext(ctx context.Context) context.Context {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return orchestrator.WithSource(ctx, remoteHost(p.Addr.String()))
+	}
+	return ctx
+}
+
+// remoteHost quita el puerto de la dirección, que cambia en cada conexión.
+func remoteHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
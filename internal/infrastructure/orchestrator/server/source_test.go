@@ -0,0 +1,35 @@
+package server
+
+import (
+	"context"
+	orchestrator "devops_console/internal/application/orchestrator"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestContext_RecordsTheClientAddress(t *testing.T) {
+	r := httptest.NewRequest("GET", "/audit", nil)
+	r.RemoteAddr = "10.0.0.7:51234"
+	source, _ := orchestrator.SourceFromContext(requestContext(r))
+	assert.Equal(t, "10.0.0.7", source)
+
+	// Detrás de un proxy se guarda la cadena completa, con el proxy al final.
+	r.Header.Set("X-Forwarded-For", "203.0.113.5")
+	source, _ = orchestrator.SourceFromContext(requestContext(r))
+	assert.Equal(t, "203.0.113.5, 10.0.0.7", source)
+}
+
+func TestSourceUnaryInterceptor_RecordsThePeerAddress(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.9"), Port: 50123}})
+	var source string
+	_, err := SourceUnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		source, _ = orchestrator.SourceFromContext(ctx)
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.9", source)
+}
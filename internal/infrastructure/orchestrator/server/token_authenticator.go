@@ -0,0 +1,62 @@
+package server
+
+import (
+	"crypto/sha256"
+	orchestrator "devops_console/internal/application/orchestrator"
+	"devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// TokenIdentity es a quién identifica un token: el sujeto que autoriza el TaskService y,
+// si Scope.TenantID no está vacío, el ámbito al que quedan limitadas sus peticiones.
+type TokenIdentity struct {
+	Subject entities.Subject
+	Scope   entities.Scope
+}
+
+// TokenAuthenticator identifica cada petición HTTP por su cabecera "Authorization: Bearer
+// <token>" y registra su sujeto (orchestrator.WithSubject) y su ámbito (ports.WithScope)
+// en el contexto. Solo guarda el SHA-256 de los tokens.
+type TokenAuthenticator struct {
+	identities map[string]TokenIdentity
+}
+
+// NewTokenAuthenticator crea el authenticator a partir del SHA-256 en hexadecimal de cada
+// token y la identidad que le corresponde.
+func NewTokenAuthenticator(identities map[string]TokenIdentity) *TokenAuthenticator {
+	a := &TokenAuthenticator{identities: make(map[string]TokenIdentity, len(identities))}
+	for digest, identity := range identities {
+		a.identities[strings.ToLower(digest)] = identity
+	}
+	return a
+}
+
+// Authenticate responde 401 a las peticiones sin un token conocido y pasa las demás a next.
+func (a *TokenAuthenticator) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, ok := a.identify(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="devops-console"`)
+			http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+		ctx := orchestrator.WithSubject(r.Context(), identity.Subject)
+		if identity.Scope.TenantID != "" {
+			ctx = ports.WithScope(ctx, identity.Scope)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (a *TokenAuthenticator) identify(r *http.Request) (TokenIdentity, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return TokenIdentity{}, false
+	}
+	digest := sha256.Sum256([]byte(token))
+	identity, ok := a.identities[hex.EncodeToString(digest[:])]
+	return identity, ok
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	orchestrator "devops_console/internal/application/orchestrator"
+	entities "devops_console/internal/domain/entities/orchestrator"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+)
+
+// maxWebhookPayload limita el tamaño de las entregas aceptadas.
+const maxWebhookPayload = 1 << 20
+
+// WebhookHandler recibe en POST /webhooks/{token} las entregas de los webhooks de las tareas.
+type WebhookHandler struct {
+	service orchestrator.WebhookService
+	mux     *http.ServeMux
+}
+
+// NewWebhookHandler crea el handler HTTP de los webhooks.
+func NewWebhookHandler(service orchestrator.WebhookService) *WebhookHandler {
+	h := &WebhookHandler{service: service, mux: http.NewServeMux()}
+	h.mux.HandleFunc("POST /webhooks/{token}", h.receive)
+	return h
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+type webhookResponse struct {
+	DeliveryID  string `json:"delivery_id,omitempty"`
+	Status      string `json:"status,omitempty"`
+	ExecutionID string `json:"execution_id,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+func (h *WebhookHandler) receive(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookPayload))
+	if err != nil {
+		writeWebhookResponse(w, http.StatusRequestEntityTooLarge, webhookResponse{Reason: "payload too large"})
+		return
+	}
+
+	delivery, err := h.service.HandleWebhook(requestContext(r), r.PathValue("token"), r.Header, body)
+	response := webhookResponse{
+		DeliveryID:  delivery.ID,
+		Status:      string(delivery.Status),
+		ExecutionID: delivery.ExecutionID,
+		Reason:      delivery.Reason,
+	}
+	switch {
+	case err == nil && delivery.Status == entities.WebhookDelivered:
+		writeWebhookResponse(w, http.StatusAccepted, response)
+	case err == nil:
+		writeWebhookResponse(w, http.StatusOK, response)
+	case errors.Is(err, orchestrator.ErrWebhookNotFound):
+		// No se distingue un token inexistente de uno sin webhook para no revelar tareas.
+		writeWebhookResponse(w, http.StatusNotFound, webhookResponse{Reason: "not found"})
+	case errors.Is(err, orchestrator.ErrInvalidSignature):
+		writeWebhookResponse(w, http.StatusUnauthorized, response)
+	case errors.Is(err, orchestrator.ErrInvalidPayload), errors.Is(err, orchestrator.ErrInvalidParameters):
+		writeWebhookResponse(w, http.StatusUnprocessableEntity, response)
+	default:
+		log.Printf("Error handling webhook delivery %s: %v", delivery.ID, err)
+		writeWebhookResponse(w, http.StatusInternalServerError, response)
+	}
+}
+
+func writeWebhookResponse(w http.ResponseWriter, status int, response webhookResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
@@ -4,16 +4,20 @@ package adapters
 import (
 	"archive/tar"
 	"context"
+	ports "devops_console/internal/ports/orchestrator"
 	"fmt"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
 	"github.com/fsnotify/fsnotify"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 )
 
+var _ ports.FileSyncService = (*DockerFileSync)(nil)
+
 type DockerFileSync struct {
 	client *client.Client
 }
@@ -52,6 +56,45 @@ func (d *DockerFileSync) SyncFromContainer(ctx context.Context, containerId stri
 	return d.extractTar(reader, targetPath)
 }
 
+// ListFiles lista el contenido de path, o el propio path si es un fichero, a partir de
+// las cabeceras de la copia del contenedor; no necesita ls en la imagen.
+func (d *DockerFileSync) ListFiles(ctx context.Context, containerId string, path string) ([]ports.FileInfo, error) {
+	reader, stat, err := d.client.CopyFromContainer(ctx, containerId, path)
+	if err != nil {
+		return nil, fmt.Errorf("error copying from container: %v", err)
+	}
+	defer reader.Close()
+
+	// La copia empieza por el propio path con su nombre base: "data/", "data/a.txt"...
+	var files []ports.FileInfo
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(header.Name, "/")
+		if stat.Mode.IsDir() {
+			_, rest, ok := strings.Cut(name, "/")
+			if !ok || strings.Contains(rest, "/") {
+				continue
+			}
+			name = rest
+		}
+		files = append(files, ports.FileInfo{
+			Name:    name,
+			Size:    header.Size,
+			Mode:    uint32(header.FileInfo().Mode()),
+			ModTime: header.ModTime.Unix(),
+			IsDir:   header.Typeflag == tar.TypeDir,
+		})
+	}
+	return files, nil
+}
+
 func (d *DockerFileSync) Watch(ctx context.Context, sourcePath string, targetPath string, containerId string) error {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -125,6 +168,9 @@ func (d *DockerFileSync) createTar(sourcePath string, writer io.Writer) error {
 	})
 }
 
+// extractTar desempaqueta en targetPath lo copiado del contenedor. El contenido lo controla
+// el contenedor: se rechazan las entradas que saldrían de targetPath y se ignoran los
+// enlaces.
 func (d *DockerFileSync) extractTar(reader io.Reader, targetPath string) error {
 	tr := tar.NewReader(reader)
 
@@ -137,7 +183,11 @@ func (d *DockerFileSync) extractTar(reader io.Reader, targetPath string) error {
 			return err
 		}
 
-		target := filepath.Join(targetPath, header.Name)
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path %q in archive", header.Name)
+		}
+		target := filepath.Join(targetPath, filepath.FromSlash(name))
 
 		switch header.Typeflag {
 		case tar.TypeDir:
@@ -145,17 +195,26 @@ func (d *DockerFileSync) extractTar(reader io.Reader, targetPath string) error {
 				return err
 			}
 		case tar.TypeReg:
-			f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
-			if err != nil {
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
 				return err
 			}
-			defer f.Close()
-
-			if _, err := io.Copy(f, tr); err != nil {
+			if err := writeFile(target, tr, header.FileInfo().Mode().Perm()); err != nil {
 				return err
 			}
 		}
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func writeFile(name string, content io.Reader, mode os.FileMode) error {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,46 @@
+package adapters
+
+import (
+	"archive/tar"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func tarOf(t *testing.T, files map[string]string) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return &buf
+}
+
+func TestExtractTar_CreatesParentDirectories(t *testing.T) {
+	dir := t.TempDir()
+	d := &DockerFileSync{}
+	require.NoError(t, d.extractTar(tarOf(t, map[string]string{"report/html/index.html": "<html>"}), dir))
+
+	data, err := os.ReadFile(filepath.Join(dir, "report", "html", "index.html"))
+	require.NoError(t, err)
+	assert.Equal(t, "<html>", string(data))
+}
+
+func TestExtractTar_RejectsEntriesOutsideTheTarget(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "target")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+	d := &DockerFileSync{}
+
+	for _, name := range []string{"../escaped", "report/../../escaped", "/etc/escaped"} {
+		assert.Error(t, d.extractTar(tarOf(t, map[string]string{name: "x"}), dir), name)
+	}
+	_, err := os.Stat(filepath.Join(parent, "escaped"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -5,6 +5,8 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	orchestrator "devops_console/internal/domain/entities/orchestrator"
+	orchestrator2 "devops_console/internal/ports/orchestrator"
 	"fmt"
 	"github.com/fsnotify/fsnotify"
 	"io"
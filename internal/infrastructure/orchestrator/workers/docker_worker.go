@@ -1,10 +1,20 @@
 package adapters
 
+import (
+	entities "devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"errors"
+)
+
+// DockerWorker: Image, Command, Args, WorkingDir y los valores de Environment admiten
+// expresiones ${{ ... }} que se sustituyen al lanzar cada ejecución.
 type DockerWorker struct {
 	Name        string
 	ContainerID string
 	Image       string
 	Command     []string
+	Args        []string
+	WorkingDir  string
 	Environment map[string]string
 }
 
@@ -20,6 +30,23 @@ func (d *DockerWorker) GetDetails() map[string]interface{} {
 	return map[string]interface{}{
 		"Image":       d.Image,
 		"Command":     d.Command,
+		"Args":        d.Args,
+		"WorkingDir":  d.WorkingDir,
 		"Environment": d.Environment,
 	}
 }
+
+// NewDockerWorkerFromManifest es la WorkerFactory de los workers "Docker".
+func NewDockerWorkerFromManifest(manifest ports.WorkerManifest) (entities.Worker, error) {
+	if manifest.Image == "" {
+		return nil, errors.New("docker worker requires an image")
+	}
+	return &DockerWorker{
+		Name:        manifest.Name,
+		Image:       manifest.Image,
+		Command:     manifest.Command,
+		Args:        manifest.Args,
+		WorkingDir:  manifest.WorkingDir,
+		Environment: manifest.Environment,
+	}, nil
+}
@@ -1,11 +1,21 @@
 package adapters
 
+import (
+	entities "devops_console/internal/domain/entities/orchestrator"
+	ports "devops_console/internal/ports/orchestrator"
+	"errors"
+)
+
+// KubernetesWorker: Image, Command, Args, WorkingDir y los valores de Environment admiten
+// expresiones ${{ ... }} que se sustituyen al lanzar cada ejecución.
 type KubernetesWorker struct {
 	Name        string
 	JobName     string
 	Namespace   string
 	Image       string
 	Command     []string
+	Args        []string
+	WorkingDir  string
 	Environment map[string]string
 }
 
@@ -22,6 +32,24 @@ func (k *KubernetesWorker) GetDetails() map[string]interface{} {
 		"Namespace":   k.Namespace,
 		"Image":       k.Image,
 		"Command":     k.Command,
+		"Args":        k.Args,
+		"WorkingDir":  k.WorkingDir,
 		"Environment": k.Environment,
 	}
 }
+
+// NewKubernetesWorkerFromManifest es la WorkerFactory de los workers "Kubernetes".
+func NewKubernetesWorkerFromManifest(manifest ports.WorkerManifest) (entities.Worker, error) {
+	if manifest.Image == "" {
+		return nil, errors.New("kubernetes worker requires an image")
+	}
+	return &KubernetesWorker{
+		Name:        manifest.Name,
+		Namespace:   manifest.Namespace,
+		Image:       manifest.Image,
+		Command:     manifest.Command,
+		Args:        manifest.Args,
+		WorkingDir:  manifest.WorkingDir,
+		Environment: manifest.Environment,
+	}, nil
+}
@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	ErrArtifactNotFound  = errors.New("artifact not found")
+	ErrArtifactTooLarge  = errors.New("artifact too large")
+	ErrArtifactStoreFull = errors.New("artifact store full")
+)
+
+// ArtifactStore guarda el contenido de los artefactos direccionado por su digest
+// ("sha256:<hex>"): el mismo contenido se guarda una sola vez aunque lo produzcan varias
+// ejecuciones.
+type ArtifactStore interface {
+	// Put guarda el contenido de r y devuelve su digest y su tamaño. Falla con
+	// ErrArtifactTooLarge o ErrArtifactStoreFull si supera los límites del almacén.
+	Put(ctx context.Context, r io.Reader) (digest string, size int64, err error)
+	// Open devuelve el contenido con el digest dado o ErrArtifactNotFound si no existe o
+	// ha caducado.
+	Open(ctx context.Context, digest string) (io.ReadCloser, error)
+	// Prune borra el contenido que ha superado la retención y devuelve cuántos borró.
+	Prune(ctx context.Context) (int, error)
+}
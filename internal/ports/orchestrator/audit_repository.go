@@ -0,0 +1,34 @@
+package ports
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	"time"
+)
+
+// AuditFilters son los criterios de consulta del registro de auditoría. Los campos vacíos
+// no filtran.
+type AuditFilters struct {
+	TenantID    string
+	WorkspaceID string
+	SubjectID   string
+	TaskID      string
+	ExecutionID string
+	Action      entities.AuditAction
+	Outcome     entities.AuditOutcome
+	// El rango incluye el inicio y excluye el final.
+	From time.Time
+	To   time.Time
+	// Limit 0 devuelve todas las entradas.
+	Limit int
+}
+
+// AuditRepository guarda el registro de auditoría, aparte de las tareas. Solo admite
+// añadir entradas: no hay forma de modificarlas ni de borrarlas.
+type AuditRepository interface {
+	Append(ctx context.Context, entry *entities.AuditEntry) error
+	// Last devuelve la última entrada; ok es false si el registro está vacío.
+	Last(ctx context.Context) (entry entities.AuditEntry, ok bool, err error)
+	// Query devuelve las entradas que cumplen filters en orden de secuencia.
+	Query(ctx context.Context, filters AuditFilters) ([]entities.AuditEntry, error)
+}
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+)
+
+// Authorizer decide si un sujeto puede realizar una operación sobre las tareas de un
+// workspace. Devuelve nil si puede y un error que explica el motivo si no.
+type Authorizer interface {
+	Authorize(ctx context.Context, subject entities.Subject, permission entities.Permission, workspace entities.Workspace) error
+}
+
+// GroupResolver devuelve los IDs de los grupos a los que pertenece un sujeto, para que los
+// bindings a un grupo cubran a sus miembros.
+type GroupResolver interface {
+	GroupsOf(ctx context.Context, subject entities.Subject) ([]string, error)
+}
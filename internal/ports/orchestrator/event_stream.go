@@ -5,9 +5,16 @@ import (
 	"devops_console/internal/domain/entities/orchestrator"
 )
 
+// AllExecutions suscribe a los eventos de todas las ejecuciones. Ese canal no se cierra con
+// los eventos terminales, solo con Close(AllExecutions).
+const AllExecutions = "*"
+
 // TaskEventStream representa un flujo de eventos al que los consumidores pueden suscribirse.
 type TaskEventStream interface {
 	Subscribe(taskExecutionID string) (<-chan entities.TaskEvent, error)
 	Publish(event entities.TaskEvent) error
 	Close(taskExecutionID string)
+	// Unsubscribe cierra y da de baja solo la suscripción events, dejando las demás de la
+	// misma ejecución.
+	Unsubscribe(taskExecutionID string, events <-chan entities.TaskEvent)
 }
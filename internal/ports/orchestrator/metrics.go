@@ -0,0 +1,28 @@
+package ports
+
+import (
+	"devops_console/internal/domain/entities/orchestrator"
+	"time"
+)
+
+// ExecutionMetrics recibe las mediciones de las ejecuciones para exponerlas (p.ej. en el
+// /metrics del master). workerType es el tipo del worker de la tarea (Docker, Kubernetes...).
+type ExecutionMetrics interface {
+	// ExecutionStarted se llama cuando un executor acepta una ejecución.
+	ExecutionStarted(workerType, workspaceID string)
+	// ExecutionFinished se llama cuando termina una ejecución lanzada en un executor, con lo
+	// que tardó desde que se lanzó.
+	ExecutionFinished(workerType, workspaceID string, status entities.TaskStatus, duration time.Duration)
+	// ExecutionDequeued se llama cuando una ejecución sale de la cola, con lo que esperó en ella.
+	ExecutionDequeued(workerType, workspaceID string, wait time.Duration)
+	// ImagePulled se llama cuando un executor termina de descargar una imagen.
+	ImagePulled(workerType string, duration time.Duration)
+}
+
+// AgentMetrics recibe las conexiones de los agentes y los recursos que cada uno reporta.
+type AgentMetrics interface {
+	AgentConnected(agentID string)
+	AgentDisconnected(agentID string)
+	// AgentUsage recibe el uso de CPU y memoria del agente en porcentaje.
+	AgentUsage(agentID string, cpu, memory float64)
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	"errors"
+)
+
+// ErrNotificationRejected indica que el destino rechazó la notificación y que reenviarla
+// no serviría de nada (p.ej. un 4xx o una dirección de correo inexistente).
+var ErrNotificationRejected = errors.New("notification rejected")
+
+// NotificationChannel entrega las notificaciones en un destino concreto: un webhook, un
+// buzón de correo, un canal de Slack...
+type NotificationChannel interface {
+	// Send devuelve un error envuelto en ErrNotificationRejected si no tiene sentido
+	// reintentar el envío; cualquier otro error se reintenta.
+	Send(ctx context.Context, notification entities.Notification) error
+}
@@ -0,0 +1,18 @@
+// internal/ports/pipeline_repository.go
+package ports
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+)
+
+// PipelineRepository define las operaciones de persistencia para los pipelines y sus ejecuciones.
+type PipelineRepository interface {
+	Create(ctx context.Context, pipeline *entities.Pipeline) error
+	GetByID(ctx context.Context, pipelineID string) (entities.Pipeline, error)
+	Update(ctx context.Context, pipeline *entities.Pipeline) error
+	Delete(ctx context.Context, pipelineID string) error
+	GetAll(ctx context.Context) ([]entities.Pipeline, error)
+	SaveExecution(ctx context.Context, execution *entities.PipelineExecution) error
+	GetExecution(ctx context.Context, executionID string) (entities.PipelineExecution, error)
+}
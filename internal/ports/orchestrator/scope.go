@@ -0,0 +1,30 @@
+package ports
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+	"errors"
+)
+
+var ErrOutOfScope = errors.New("outside of the request scope")
+
+type scopeKey struct{}
+
+// WithScope limita al ámbito las operaciones de los repositorios hechas con el contexto:
+// las tareas de fuera no se encuentran ni se listan y no se pueden crear ni mover fuera de
+// él. Sin ámbito, como en el trabajo interno del orquestador, no hay límite.
+func WithScope(ctx context.Context, scope entities.Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// ScopeFromContext devuelve el ámbito registrado con WithScope.
+func ScopeFromContext(ctx context.Context) (entities.Scope, bool) {
+	scope, ok := ctx.Value(scopeKey{}).(entities.Scope)
+	return scope, ok
+}
+
+// InScope indica si el workspace está dentro del ámbito del contexto.
+func InScope(ctx context.Context, workspace entities.Workspace) bool {
+	scope, ok := ScopeFromContext(ctx)
+	return !ok || scope.Contains(workspace)
+}
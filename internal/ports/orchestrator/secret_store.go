@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretStore resuelve las referencias a secretos de las tareas en el momento de lanzarlas.
+type SecretStore interface {
+	GetSecret(ctx context.Context, key string) (string, error)
+}
@@ -8,10 +8,46 @@ import (
 
 type TaskExecutor interface {
 	// ExecuteTask: Ejecuta una tarea y devuelve un executionID que el cliente puede usar
-	// para consultar el estado o suscribirse a eventos.
+	// para consultar el estado o suscribirse a eventos. La ejecución no debe cancelarse
+	// cuando se cancela ctx: la petición que la lanza suele terminar antes que ella.
 	ExecuteTask(ctx context.Context, task *entities.DevOpsTask) (string, error)
 	GetTaskStatus(ctx context.Context, taskExecutionID string) (entities.TaskStatus, error)
 	CancelTask(ctx context.Context, taskExecutionID string) error
 	// SubscribeToTaskEvents: Permite al cliente suscribirse a los eventos de la tarea, incluyendo logs y cambios de estado.
 	SubscribeToTaskEvents(taskExecutionID string) (<-chan entities.TaskEvent, error)
 }
+
+// TaskPlanner lo implementan los executors que pueden mostrar lo que lanzarían para una
+// tarea sin efectos secundarios (dry-run).
+type TaskPlanner interface {
+	Plan(ctx context.Context, task *entities.DevOpsTask) (entities.ExecutionPlan, error)
+}
+
+// ExecutorShutdowner lo implementan los executors que cancelan sus ejecuciones en curso
+// al apagarse.
+type ExecutorShutdowner interface {
+	Shutdown()
+}
+
+// OutputCollector lo implementan los executors que recogen los TaskConfig.Outputs cuando
+// termina la ejecución. Las tareas que declaran outputs solo se aceptan en los workers
+// cuyo executor los recoge; en los demás se perderían sin avisar.
+type OutputCollector interface {
+	CollectsOutputs() bool
+}
+
+// InputInjector lo implementan los executors que dejan los TaskConfig.Inputs en el
+// contenedor antes de que empiece la tarea. Las tareas que declaran inputs solo se aceptan
+// en los workers cuyo executor los deja.
+type InputInjector interface {
+	InjectsInputs() bool
+}
+
+// InputReceiver lo implementan los executors cuyas ejecuciones pueden pedir datos al
+// operador mientras corren: publican EventTypeTaskInputRequired cuando la tarea los pide y
+// ProvideInput se los entrega para que continúe. secrets son los valores que hay que
+// ocultar en su salida. Los formularios de las tareas manuales solo se aceptan en los
+// workers cuyo executor lo implementa.
+type InputReceiver interface {
+	ProvideInput(ctx context.Context, taskExecutionID string, values map[string]interface{}, secrets []string) error
+}
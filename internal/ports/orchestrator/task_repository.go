@@ -4,12 +4,52 @@ package ports
 import (
 	"context"
 	"devops_console/internal/domain/entities/orchestrator"
+	"errors"
+	"time"
 )
 
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// TaskSortField es el campo por el que se ordena el listado de tareas. A igual valor se
+// ordena por ID para que el orden sea estable entre páginas.
+type TaskSortField string
+
+const (
+	TaskSortByCreatedAt TaskSortField = "CREATED_AT"
+	TaskSortByUpdatedAt TaskSortField = "UPDATED_AT"
+	TaskSortByName      TaskSortField = "NAME"
+)
+
+// TaskFilters son los criterios del listado de tareas. Los campos vacíos no filtran.
 type TaskFilters struct {
-	workspaceID string
-	taskType    string
-	subjects    []entities.Subject
+	WorkspaceID string
+	TenantID    string
+	TaskType    entities.TaskType
+	// Tags: la tarea debe tenerlas todas.
+	Tags []string
+	// Search busca el texto en el nombre y el título, sin distinguir mayúsculas.
+	Search string
+	// Los rangos incluyen el inicio y excluyen el final.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+	// LastExecutionStatus es el estado de la ejecución más reciente de la tarea.
+	LastExecutionStatus entities.TaskStatus
+
+	// SortBy vacío ordena por fecha de creación.
+	SortBy     TaskSortField
+	Descending bool
+	// Limit 0 devuelve todas las tareas. Cursor es el NextCursor de la página anterior y
+	// solo es válido con el mismo orden.
+	Limit  int
+	Cursor string
+}
+
+// TaskPage es una página del listado de tareas. NextCursor está vacío en la última.
+type TaskPage struct {
+	Tasks      []entities.DevOpsTask
+	NextCursor string
 }
 
 type TaskUpdate struct {
@@ -18,8 +58,10 @@ type TaskUpdate struct {
 	Config      entities.TaskConfig
 	entities.Workspace
 	entities.TaskType
-	Approvals []entities.Approval
-	Triggers  []entities.Trigger
+	ApprovalPolicy *entities.ApprovalPolicy
+	InputForm      *entities.InputForm
+	Triggers       []entities.Trigger
+	Parameters     []entities.ParameterDefinition
 }
 
 // TaskRepository define las operaciones de persistencia para las tareas.
@@ -28,6 +70,6 @@ type TaskRepository interface {
 	GetByID(ctx context.Context, taskID string) (entities.DevOpsTask, error)
 	Update(ctx context.Context, task *entities.DevOpsTask) error
 	Delete(ctx context.Context, taskID string) error
-	GetAll(ctx context.Context, filters TaskFilters) ([]entities.DevOpsTask, error)
+	GetAll(ctx context.Context, filters TaskFilters) (TaskPage, error)
 	GetByExecutionID(ctx context.Context, executionID string) (entities.DevOpsTask, error)
 }
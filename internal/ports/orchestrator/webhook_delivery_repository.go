@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+	"devops_console/internal/domain/entities/orchestrator"
+)
+
+// WebhookDeliveryRepository guarda las entregas de webhooks recibidas para poder revisarlas.
+type WebhookDeliveryRepository interface {
+	Save(ctx context.Context, delivery *entities.WebhookDelivery) error
+	GetByID(ctx context.Context, deliveryID string) (entities.WebhookDelivery, error)
+	// ListByTask devuelve las entregas de la tarea, de la más reciente a la más antigua.
+	ListByTask(ctx context.Context, taskID string) ([]entities.WebhookDelivery, error)
+}
@@ -0,0 +1,19 @@
+package ports
+
+import "devops_console/internal/domain/entities/orchestrator"
+
+// WorkerManifest es la descripción declarativa de un worker en los manifiestos de tareas.
+type WorkerManifest struct {
+	Type        string            `yaml:"type" json:"type"`
+	Name        string            `yaml:"name,omitempty" json:"name,omitempty"`
+	Image       string            `yaml:"image,omitempty" json:"image,omitempty"`
+	Command     []string          `yaml:"command,omitempty" json:"command,omitempty"`
+	Args        []string          `yaml:"args,omitempty" json:"args,omitempty"`
+	WorkingDir  string            `yaml:"workingDir,omitempty" json:"workingDir,omitempty"`
+	Environment map[string]string `yaml:"environment,omitempty" json:"environment,omitempty"`
+	// Namespace solo se usa en los workers de Kubernetes.
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// WorkerFactory construye el worker de un tipo a partir de su manifiesto.
+type WorkerFactory func(manifest WorkerManifest) (entities.Worker, error)